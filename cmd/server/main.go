@@ -1,74 +1,173 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"task_manager/internal/clock"
 	"task_manager/internal/config"
 	"task_manager/internal/database"
+	"task_manager/internal/features"
 	"task_manager/internal/handlers"
+	"task_manager/internal/logging"
 	"task_manager/internal/migrations"
 	"task_manager/internal/redis"
 	"task_manager/internal/repository"
+	"task_manager/internal/secretbox"
 	"task_manager/internal/services"
 	"task_manager/pkg/whatsapp"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 )
 
+// shutdownTimeout bounds how long in-flight requests get to finish once a
+// stop signal arrives
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Structured logger; the standard log package writes through it too
+	logger := logging.New(cfg.LogFormat, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	if err := cfg.Validate(); err != nil {
+		fatal(logger, "Invalid configuration", err)
+	}
+
 	// Initialize database
 	db, err := database.Initialize(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal(logger, "Failed to connect to database", err)
 	}
 
 	// Run database migrations
 	err = migrations.RunMigrations(db)
 	if err != nil {
-		log.Fatal("Failed to run migrations:", err)
+		fatal(logger, "Failed to run migrations", err)
 	}
 
 	// Initialize Redis
 	redisClient, err := redis.Initialize(cfg.RedisURL)
 	if err != nil {
-		log.Fatal("Failed to connect to Redis:", err)
+		fatal(logger, "Failed to connect to Redis", err)
 	}
 
+	// Initialize feature flags (Redis overrides take precedence)
+	features.Init(cfg.FeatureFlags, redisClient)
+
 	// Initialize WhatsApp client
 	whatsappClient := whatsapp.NewClient(cfg.WhatsAppAPIURL, cfg.WhatsAppUsername, cfg.WhatsAppPassword, cfg.WhatsAppPath)
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
-	taskRepo := repository.NewTaskRepository(db)
-	orderRepo := repository.NewOrderRepository(db)
+	taskRepo := repository.NewTaskRepository(db, clock.Real{})
+	orderRepo := repository.NewOrderRepository(db, clock.Real{})
 	orderItemRepo := repository.NewOrderItemRepository(db)
+	orderNoteRepo := repository.NewOrderNoteRepository(db)
 	reminderRepo := repository.NewReminderRepository(db)
 	financialRepo := repository.NewFinancialRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
 
 	// Initialize services
-	userService := services.NewUserService(userRepo)
-	taskService := services.NewTaskService(taskRepo, redisClient)
-	orderService := services.NewOrderService(orderRepo, orderItemRepo, financialRepo)
-	whatsappService := services.NewWhatsAppService(whatsappClient, redisClient)
-	reminderService := services.NewReminderService(reminderRepo, whatsappService)
-	aiProcessor := services.NewAIProcessor(cfg.OpenAIAPIKey, redisClient)
+	userService := services.NewUserService(userRepo, redisClient, logger.With("component", "users"))
+	taskService := services.NewTaskService(taskRepo, userRepo, redisClient, clock.Real{})
+	financialSettingsService := services.NewFinancialSettingsService(financialRepo, redisClient, logger.With("component", "financial_settings"))
+	orderService := services.NewOrderService(orderRepo, orderItemRepo, orderNoteRepo, financialRepo, financialSettingsService, services.ItemStatusConfig{
+		Statuses:         cfg.OrderItemStatuses,
+		TerminalStatuses: cfg.OrderItemTerminalStatuses,
+	}, cfg.Currency, clock.Real{})
+	whatsappService := services.NewWhatsAppService(whatsappClient, redisClient, cfg.WhatsAppMessageLimit)
+	// Validate has checked the zone already
+	location, _ := time.LoadLocation(cfg.Timezone)
+	reminderService := services.NewReminderService(reminderRepo, whatsappService, taskService, userService, redisClient, services.QuietHours{
+		Start:    cfg.QuietHoursStart,
+		End:      cfg.QuietHoursEnd,
+		Location: location,
+	}, logger.With("component", "reminders"), clock.Real{})
+	aiProcessor := services.NewAIProcessor(cfg.OpenAIAPIKey, redisClient, services.OpenAIConfig{
+		Model:       cfg.OpenAIModel,
+		MaxTokens:   cfg.OpenAIMaxTokens,
+		Temperature: cfg.OpenAITemperature,
+		CacheTTL:    time.Duration(cfg.AICacheTTLSeconds) * time.Second,
+	}, services.HistoryConfig{
+		Size:     cfg.AIHistorySize,
+		TTL:      time.Duration(cfg.AIHistoryTTLMinutes) * time.Minute,
+		MaxChars: cfg.AIHistoryMaxChars,
+	})
+	cleanupService := services.NewCleanupService(retentionRepo, services.RetentionConfig{
+		TaskProgressDays:       cfg.RetentionTaskProgressDays,
+		CalculationHistoryDays: cfg.RetentionCalculationHistoryDays,
+		ReportQueryDays:        cfg.RetentionReportQueryDays,
+	})
+	undoService := services.NewUndoService(taskRepo, orderRepo, orderItemRepo, redisClient, clock.Real{})
+	// Tenant gateway passwords are stored encrypted; Validate has checked the key
+	var tenantBox *secretbox.Box
+	if cfg.TenantSecretKey != "" {
+		if tenantBox, err = secretbox.New(cfg.TenantSecretKey); err != nil {
+			fatal(logger, "Invalid TENANT_SECRET_KEY", err)
+		}
+	}
+	tenantService := services.NewTenantService(tenantRepo, whatsappClient, tenantBox)
+	rateLimiter := services.NewRateLimiter(redisClient, services.RateLimitConfig{
+		Limit:  cfg.RateLimitMessages,
+		Window: time.Duration(cfg.RateLimitWindowSeconds) * time.Second,
+	})
 
 	// Initialize handlers
-	whatsappHandler := handlers.NewWhatsAppHandler(whatsappService, userService, taskService, orderService, reminderService, aiProcessor)
-	apiHandler := handlers.NewAPIHandler(userService, taskService, orderService)
+	whatsappHandler := handlers.NewWhatsAppHandler(cfg, whatsappService, userService, taskService, orderService, reminderService, aiProcessor, undoService, cleanupService, tenantService, rateLimiter, logger, clock.Real{})
+	apiHandler := handlers.NewAPIHandler(userService, taskService, orderService, tenantService, logger.With("component", "api"), clock.Real{})
+	healthHandler := handlers.NewHealthHandler(db, redisClient)
+
+	// Stop on SIGINT/SIGTERM; cancelling ctx also stops the background jobs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background jobs
+	var jobs sync.WaitGroup
+	jobs.Add(4)
+	go func() {
+		defer jobs.Done()
+		runDailyJobs(ctx, logger.With("component", "daily_jobs"), clock.Real{}, redisClient, taskService, userService, reminderService, cleanupService)
+	}()
+	go func() {
+		defer jobs.Done()
+		reminderService.StartReminderScheduler(ctx, time.Duration(cfg.ReminderIntervalSeconds)*time.Second)
+	}()
+	go func() {
+		defer jobs.Done()
+		runEscalationJob(ctx, logger.With("component", "escalation"), clock.Real{}, redisClient, taskService, userService, reminderService, services.EscalationConfig{
+			MediumWithin: time.Duration(cfg.EscalationMediumHours) * time.Hour,
+			HighWithin:   time.Duration(cfg.EscalationHighHours) * time.Hour,
+		}, time.Duration(cfg.EscalationIntervalMinutes)*time.Minute)
+	}()
+	go func() {
+		defer jobs.Done()
+		runCacheWarmer(ctx, logger.With("component", "cache_warmer"), financialSettingsService, time.Duration(cfg.CacheWarmIntervalMinutes)*time.Minute)
+	}()
 
 	// Setup routes
 	router := gin.Default()
+
+	// Health check for load balancers and uptime monitors, unauthenticated
+	router.GET("/health", healthHandler.Health)
 	
-	// WhatsApp webhook
+	// WhatsApp webhook, authenticated by its own secret
 	router.POST("/api/whatsapp/webhook", whatsappHandler.HandleWebhook)
-	router.POST("/api/whatsapp/send-message", whatsappHandler.SendMessage)
 	
-	// API endpoints
-	api := router.Group("/api")
+	// API endpoints, all behind X-API-Key
+	api := router.Group("/api", handlers.RequireAPIKey(cfg.APIKey))
 	{
+		api.POST("/whatsapp/send-message", whatsappHandler.SendMessage)
 		api.POST("/whatsapp/interactive-session", whatsappHandler.StartInteractiveSession)
 		api.PUT("/whatsapp/session/:session_id", whatsappHandler.UpdateSession)
 		api.DELETE("/whatsapp/session/:session_id", whatsappHandler.EndSession)
@@ -82,11 +181,223 @@ func main() {
 		api.GET("/cache/temp-data/:key", apiHandler.GetTempData)
 		api.POST("/cache/temp-data", apiHandler.StoreTempData)
 		api.DELETE("/cache/temp-data/:key", apiHandler.DeleteTempData)
+
+		// Tasks
+		api.POST("/tasks", apiHandler.CreateTask)
+		api.GET("/tasks/export.csv", apiHandler.ExportTasksCSV)
+
+		// Tenants
+		api.POST("/tenants", apiHandler.CreateTenant)
+
+		// Orders
+		api.GET("/orders", apiHandler.ListOrders)
+		api.POST("/orders/import", apiHandler.ImportOrders)
+		api.GET("/orders/:id", apiHandler.GetOrder)
+		api.GET("/orders/:id/invoice.pdf", apiHandler.GetOrderInvoice)
 	}
 
 	// Start server
-	log.Printf("Server starting on port %s", cfg.ServerPort)
-	if err := router.Run(":" + cfg.ServerPort); err != nil {
-		log.Fatal("Failed to start server:", err)
+	server := &http.Server{
+		Addr:    ":" + cfg.ServerPort,
+		Handler: router,
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		fatal(logger, "Failed to start server", err)
+	}
+	logger.Info("Server starting", "port", cfg.ServerPort)
+	if err := serve(ctx, logger, server, listener, &jobs, shutdownTimeout); err != nil {
+		fatal(logger, "Failed to start server", err)
+	}
+	stop()
+
+	if err := redisClient.Close(); err != nil {
+		logger.Error("Failed to close Redis", "error", err)
+	}
+	if err := database.Close(db); err != nil {
+		logger.Error("Failed to close database", "error", err)
+	}
+	logger.Info("Server stopped")
+}
+
+// serve handles requests on listener until ctx is done, then gives in-flight
+// requests up to timeout to finish and waits for the background jobs, so
+// neither loses the connections they use. It returns an error only when the
+// server could not serve at all
+func serve(ctx context.Context, logger *slog.Logger, server *http.Server, listener net.Listener, jobs *sync.WaitGroup, timeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server forced to shut down", "error", err)
+	}
+
+	// A job caught mid-pass finishes before its connections are closed
+	jobs.Wait()
+	return nil
+}
+
+// fatal logs err and exits, for failures the server cannot start without
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// runDailyJobs closes each day at midnight: it records daily streaks for the
+// day that just ended and sends each user their progress, resets daily tasks for the new day and weekly tasks
+// for a new week, and removes history past its retention period. The lock is
+// keyed by day so only one replica closes it. It returns once ctx is done
+func runDailyJobs(ctx context.Context, logger *slog.Logger, clk clock.Clock, redisClient *redis.Client, taskService services.TaskService, userService services.UserService, reminderService services.ReminderService, cleanupService services.CleanupService) {
+	for {
+		now := clk.Now()
+		nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(nextMidnight.Sub(now)):
+		}
+
+		endedDay := nextMidnight.AddDate(0, 0, -1)
+		services.RunExclusive(redisClient, logger, "daily_jobs:"+endedDay.Format("2006-01-02"), 12*time.Hour, func() {
+			closeDay(logger, clk, taskService, userService, reminderService, cleanupService, endedDay, nextMidnight)
+		})
+	}
+}
+
+// closeDay runs the midnight jobs for endedDay
+func closeDay(logger *slog.Logger, clk clock.Clock, taskService services.TaskService, userService services.UserService, reminderService services.ReminderService, cleanupService services.CleanupService, endedDay, nextMidnight time.Time) {
+	streaks, err := taskService.UpdateDailyStreaks(endedDay)
+	if err != nil {
+		logger.Error("Failed to update daily streaks", "error", err)
+	}
+	sendDailyProgress(logger, userService, reminderService, streaks)
+	if err := taskService.ResetDailyTasks(); err != nil {
+		logger.Error("Failed to reset daily tasks", "error", err)
+	}
+	if reset, err := taskService.ResetWeeklyTasks(nextMidnight); err != nil {
+		logger.Error("Failed to reset weekly tasks", "error", err)
+	} else if reset {
+		logger.Info("Weekly tasks reset", "week", services.WeekKey(nextMidnight))
+	}
+	if result, err := cleanupService.Run(clk.Now()); err != nil {
+		logger.Error("Failed to clean up old records", "error", err)
+	} else {
+		logger.Info("Cleanup finished", "task_progress", result.TaskProgress,
+			"calculation_history", result.CalculationHistory, "report_queries", result.ReportQueries)
+	}
+}
+
+// sendDailyProgress tells each user how the day that just closed went and
+// how long their streak now is
+func sendDailyProgress(logger *slog.Logger, userService services.UserService, reminderService services.ReminderService, streaks []services.DailyStreak) {
+	for _, result := range streaks {
+		user, err := userService.GetUserByID(result.UserID)
+		if err != nil {
+			logger.Error("Failed to load user for daily progress", "user_id", result.UserID, "error", err)
+			continue
+		}
+		if err := reminderService.SendDailyProgressReminder(user.WhatsAppNumber, result.Progress, result.Streak); err != nil {
+			logger.Error("Failed to send daily progress reminder", "user_id", user.ID, "error", err)
+		}
+	}
+}
+
+// runEscalationJob periodically raises the priority of unfinished tasks that
+// are nearing their due date and tells each assignee about the change, then
+// marks tasks whose due day has passed as overdue. Only the replica holding
+// the "escalation" lock runs a given pass. It returns once ctx is done
+func runEscalationJob(ctx context.Context, logger *slog.Logger, clk clock.Clock, redisClient *redis.Client, taskService services.TaskService, userService services.UserService, reminderService services.ReminderService, escalation services.EscalationConfig, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	for {
+		services.RunExclusive(redisClient, logger, "escalation", interval, func() {
+			escalatePriorities(logger, clk, taskService, userService, reminderService, escalation)
+			markOverdue(logger, clk, taskService)
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// escalatePriorities runs one escalation pass and notifies the assignees
+func escalatePriorities(logger *slog.Logger, clk clock.Clock, taskService services.TaskService, userService services.UserService, reminderService services.ReminderService, escalation services.EscalationConfig) {
+	escalations, err := taskService.EscalatePriorities(clk.Now(), escalation)
+	if err != nil {
+		logger.Error("Failed to escalate task priorities", "error", err)
+	}
+
+	for _, e := range escalations {
+		logger.Info("audit: task priority escalated", "task_id", e.Task.ID, "title", e.Task.Title, "from", e.From, "to", e.To)
+
+		assignee, err := userService.GetUserByID(e.Task.AssignedTo)
+		if err != nil {
+			logger.Error("Failed to load assignee", "task_id", e.Task.ID, "error", err)
+			continue
+		}
+		if err := reminderService.SendEscalationNotice(assignee.WhatsAppNumber, &e.Task, e.From, e.To); err != nil {
+			logger.Error("Failed to notify assignee of escalation", "task_id", e.Task.ID, "username", assignee.Username, "error", err)
+		}
+	}
+}
+
+// markOverdue sets the Overdue status on open tasks past their due day
+func markOverdue(logger *slog.Logger, clk clock.Clock, taskService services.TaskService) {
+	marked, err := taskService.MarkOverdue(clk.Now())
+	if err != nil {
+		logger.Error("Failed to mark overdue tasks", "error", err)
+	}
+	for _, task := range marked {
+		logger.Info("audit: task marked overdue", "task_id", task.ID, "title", task.Title, "from", task.Status)
+	}
+}
+
+// runCacheWarmer refills the financial settings cache every interval while
+// the cache_warming feature is on, so order calculations after an
+// invalidation or expiry do not wait on the database. It returns once ctx is
+// done
+func runCacheWarmer(ctx context.Context, logger *slog.Logger, settingsService services.FinancialSettingsService, interval time.Duration) {
+	if interval <= 0 {
+		interval = 4 * time.Minute
+	}
+
+	for {
+		warmCaches(logger, settingsService)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// warmCaches runs one warming pass when the cache_warming feature is on
+func warmCaches(logger *slog.Logger, settingsService services.FinancialSettingsService) {
+	if !features.Enabled(features.CacheWarming) {
+		return
+	}
+	warmed, err := settingsService.Warm()
+	if err != nil {
+		logger.Error("Failed to warm financial settings cache", "error", err)
+	}
+	if warmed > 0 {
+		logger.Debug("Warmed financial settings cache", "settings", warmed)
 	}
 }
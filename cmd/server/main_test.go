@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"task_manager/internal/clock"
+	"task_manager/internal/features"
+	"task_manager/internal/models"
+	"task_manager/internal/services"
+	"testing"
+	"time"
+)
+
+func TestServeStopsOnSIGTERM(t *testing.T) {
+	const timeout = 500 * time.Millisecond
+
+	tests := []struct {
+		name        string
+		requestTime time.Duration // how long the in-flight request takes; 0 for none
+		wantStatus  int
+		wantForced  bool
+	}{
+		{name: "idle server"},
+		{name: "in-flight request finishes", requestTime: 200 * time.Millisecond, wantStatus: http.StatusOK},
+		{name: "stuck request is cut off", requestTime: time.Minute, wantForced: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+			defer stop()
+
+			release := make(chan struct{})
+			defer close(release)
+			started := make(chan struct{})
+			mux := http.NewServeMux()
+			mux.HandleFunc("/work", func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tt.requestTime):
+				case <-release:
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+			// A background job that runs until the context is cancelled
+			var jobs sync.WaitGroup
+			jobDone := false
+			jobs.Add(1)
+			go func() {
+				defer jobs.Done()
+				<-ctx.Done()
+				jobDone = true
+			}()
+
+			served := make(chan error, 1)
+			go func() {
+				served <- serve(ctx, logger, &http.Server{Handler: mux}, listener, &jobs, timeout)
+			}()
+
+			status := make(chan int, 1)
+			if tt.requestTime > 0 {
+				go func() {
+					resp, err := http.Get("http://" + listener.Addr().String() + "/work")
+					if err != nil {
+						status <- 0
+						return
+					}
+					resp.Body.Close()
+					status <- resp.StatusCode
+				}()
+				<-started
+			}
+
+			begin := time.Now()
+			if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case err := <-served:
+				if err != nil {
+					t.Fatalf("serve = %v, want nil", err)
+				}
+			case <-time.After(timeout + time.Second):
+				t.Fatal("server did not stop within the shutdown timeout")
+			}
+			if elapsed := time.Since(begin); elapsed > timeout+200*time.Millisecond {
+				t.Errorf("stopping took %v, want at most %v", elapsed, timeout)
+			}
+			if !jobDone {
+				t.Error("serve returned before the background job finished")
+			}
+			if forced := strings.Contains(logs.String(), "Server forced to shut down"); forced != tt.wantForced {
+				t.Errorf("forced shutdown logged = %v, want %v", forced, tt.wantForced)
+			}
+			if tt.wantStatus != 0 {
+				if got := <-status; got != tt.wantStatus {
+					t.Errorf("in-flight request got %d, want %d", got, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestServeReportsListenerFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	err = serve(context.Background(), logger, &http.Server{Handler: http.NewServeMux()}, listener, &sync.WaitGroup{}, time.Second)
+	if err == nil {
+		t.Error("serve on a closed listener returned nil")
+	}
+}
+
+type fakeSettingsService struct {
+	services.FinancialSettingsService
+	warms int
+}
+
+func (s *fakeSettingsService) Warm() (int, error) {
+	s.warms++
+	return 3, nil
+}
+
+func TestWarmCachesFollowsFeatureFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		wantWarms int
+	}{
+		{name: "off", enabled: false, wantWarms: 0},
+		{name: "on", enabled: true, wantWarms: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := features.Set(features.CacheWarming, tt.enabled); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { features.Set(features.CacheWarming, false) })
+
+			settings := &fakeSettingsService{}
+			warmCaches(slog.New(slog.NewTextHandler(io.Discard, nil)), settings)
+			if settings.warms != tt.wantWarms {
+				t.Errorf("warmed %d times, want %d", settings.warms, tt.wantWarms)
+			}
+		})
+	}
+}
+
+// fakeJobTaskService records the time each job pass asked about
+type fakeJobTaskService struct {
+	services.TaskService
+	calls map[string]time.Time
+}
+
+func (s *fakeJobTaskService) UpdateDailyStreaks(day time.Time) ([]services.DailyStreak, error) {
+	s.calls["streaks"] = day
+	return nil, nil
+}
+
+func (s *fakeJobTaskService) ResetDailyTasks() error {
+	return nil
+}
+
+func (s *fakeJobTaskService) ResetWeeklyTasks(now time.Time) (bool, error) {
+	s.calls["weekly_reset"] = now
+	return false, nil
+}
+
+func (s *fakeJobTaskService) EscalatePriorities(now time.Time, cfg services.EscalationConfig) ([]services.Escalation, error) {
+	s.calls["escalation"] = now
+	return nil, nil
+}
+
+func (s *fakeJobTaskService) MarkOverdue(now time.Time) ([]models.Task, error) {
+	s.calls["overdue"] = now
+	return nil, nil
+}
+
+type fakeJobCleanupService struct {
+	runs []time.Time
+}
+
+func (s *fakeJobCleanupService) Run(now time.Time) (*services.CleanupResult, error) {
+	s.runs = append(s.runs, now)
+	return &services.CleanupResult{}, nil
+}
+
+func TestJobsUseClock(t *testing.T) {
+	now := time.Date(2025, 1, 16, 0, 0, 5, 0, time.UTC)
+	endedDay := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	midnight := endedDay.AddDate(0, 0, 1)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tasks := &fakeJobTaskService{calls: map[string]time.Time{}}
+	cleanup := &fakeJobCleanupService{}
+	clk := clock.NewFake(now)
+
+	closeDay(logger, clk, tasks, nil, nil, cleanup, endedDay, midnight)
+	escalatePriorities(logger, clk, tasks, nil, nil, services.EscalationConfig{})
+	markOverdue(logger, clk, tasks)
+
+	tests := []struct {
+		call string
+		want time.Time
+	}{
+		{call: "streaks", want: endedDay},
+		{call: "weekly_reset", want: midnight},
+		{call: "escalation", want: now},
+		{call: "overdue", want: now},
+	}
+	for _, tt := range tests {
+		if got := tasks.calls[tt.call]; !got.Equal(tt.want) {
+			t.Errorf("%s ran for %s, want %s", tt.call, got, tt.want)
+		}
+	}
+	if len(cleanup.runs) != 1 || !cleanup.runs[0].Equal(now) {
+		t.Errorf("cleanup ran at %v, want once at %s", cleanup.runs, now)
+	}
+}
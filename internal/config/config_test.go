@@ -0,0 +1,114 @@
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		secret  string
+		wantErr bool
+	}{
+		{name: "hmac with a secret", mode: WebhookAuthHMAC, secret: "3f9c2a7d41e0b8"},
+		{name: "static with a secret", mode: WebhookAuthStatic, secret: "3f9c2a7d41e0b8"},
+		{name: "none needs no secret", mode: WebhookAuthNone},
+		{name: "hmac without a secret", mode: WebhookAuthHMAC, wantErr: true},
+		{name: "static with a blank secret", mode: WebhookAuthStatic, secret: "   ", wantErr: true},
+		{name: "old placeholder", mode: WebhookAuthHMAC, secret: "superadmin", wantErr: true},
+		{name: "placeholder in another case", mode: WebhookAuthStatic, secret: "SuperAdmin", wantErr: true},
+		{name: "unknown mode", mode: "basic", secret: "3f9c2a7d41e0b8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{WebhookAuthMode: tt.mode, WhatsappWebhookSecret: tt.secret}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadLeavesWebhookSecretEmpty(t *testing.T) {
+	t.Setenv("WHATSAPP_WEBHOOK_SECRET", "")
+	t.Setenv("WEBHOOK_AUTH_MODE", "")
+	cfg := Load()
+	if cfg.WhatsappWebhookSecret != "" {
+		t.Errorf("default secret = %q, want empty", cfg.WhatsappWebhookSecret)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("default configuration should not validate without a secret")
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		start    int
+		end      int
+		wantErr  bool
+	}{
+		{name: "defaults", timezone: "Asia/Jakarta", start: 21, end: 7},
+		{name: "midnight to morning", timezone: "UTC", start: 0, end: 6},
+		{name: "unknown zone", timezone: "Mars/Olympus", start: 21, end: 7, wantErr: true},
+		{name: "start past 23", timezone: "UTC", start: 24, end: 7, wantErr: true},
+		{name: "negative end", timezone: "UTC", start: 21, end: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{WebhookAuthMode: WebhookAuthNone, Timezone: tt.timezone, QuietHoursStart: tt.start, QuietHoursEnd: tt.end}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTenantSecretKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "unset", key: ""},
+		{name: "long enough", key: "0123456789abcdef"},
+		{name: "too short", key: "secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{WebhookAuthMode: WebhookAuthNone, TenantSecretKey: tt.key}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadOpenAIParameters(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		wantModel       string
+		wantMaxTokens   int
+		wantTemperature float64
+	}{
+		{name: "defaults", wantModel: "gpt-3.5-turbo", wantMaxTokens: 500, wantTemperature: 0.1},
+		{name: "configured", env: map[string]string{"OPENAI_MODEL": "gpt-4o-mini", "OPENAI_MAX_TOKENS": "800", "OPENAI_TEMPERATURE": "0.7"}, wantModel: "gpt-4o-mini", wantMaxTokens: 800, wantTemperature: 0.7},
+		{name: "unparseable numbers keep defaults", env: map[string]string{"OPENAI_MAX_TOKENS": "many", "OPENAI_TEMPERATURE": "warm"}, wantModel: "gpt-3.5-turbo", wantMaxTokens: 500, wantTemperature: 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"OPENAI_MODEL", "OPENAI_MAX_TOKENS", "OPENAI_TEMPERATURE"} {
+				t.Setenv(key, tt.env[key])
+			}
+			cfg := Load()
+			if cfg.OpenAIModel != tt.wantModel || cfg.OpenAIMaxTokens != tt.wantMaxTokens || cfg.OpenAITemperature != tt.wantTemperature {
+				t.Errorf("got %s %d %v, want %s %d %v", cfg.OpenAIModel, cfg.OpenAIMaxTokens, cfg.OpenAITemperature, tt.wantModel, tt.wantMaxTokens, tt.wantTemperature)
+			}
+		})
+	}
+}
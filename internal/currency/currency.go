@@ -0,0 +1,100 @@
+package currency
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Format renders an amount in the given currency: Rupiah with dot thousands
+// separators (Rp 1.000.000) or dollars with cents ($1,000.00). Anything
+// other than USD is treated as IDR
+func Format(amount float64, code string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	if strings.EqualFold(code, "USD") {
+		cents := int64(math.Round(amount * 100))
+		return fmt.Sprintf("%s$%s.%02d", sign, groupThousands(cents/100, ","), cents%100)
+	}
+
+	return fmt.Sprintf("%sRp %s", sign, groupThousands(int64(math.Round(amount)), "."))
+}
+
+// groupThousands formats a non-negative integer with sep between each
+// group of three digits
+func groupThousands(n int64, sep string) string {
+	digits := strconv.FormatInt(n, 10)
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// Parse reads an amount typed the way people write it in chat: with or
+// without a currency prefix (Rp, IDR, $, USD) and with dots or commas as
+// thousands separators ("Rp 100.000", "100,000", "1.250.000,50"). A single
+// separator followed by exactly three digits groups thousands; otherwise it
+// marks the decimals. Negative amounts are rejected
+func Parse(input string) (float64, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	for _, prefix := range []string{"RP.", "RP", "IDR", "USD", "$"} {
+		if strings.HasPrefix(s, prefix) {
+			s = strings.TrimSpace(s[len(prefix):])
+			break
+		}
+	}
+	s = strings.ReplaceAll(s, " ", "")
+	if s == "" || strings.HasPrefix(s, "-") {
+		return 0, fmt.Errorf("invalid amount %q", input)
+	}
+
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Both appear: the one that comes last marks the decimals
+		if lastDot > lastComma {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", ".")
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		sep := "."
+		if lastComma >= 0 {
+			sep = ","
+		}
+		last := strings.LastIndex(s, sep)
+		if strings.Count(s, sep) > 1 || len(s)-last-1 == 3 {
+			s = strings.ReplaceAll(s, sep, "")
+		} else {
+			s = strings.Replace(s, sep, ".", 1)
+		}
+	}
+
+	// Only digits and one decimal point are left; ParseFloat alone would
+	// also take exponents, hex and "Inf"
+	if strings.Trim(s, "0123456789.") != "" || strings.Count(s, ".") > 1 {
+		return 0, fmt.Errorf("invalid amount %q", input)
+	}
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", input)
+	}
+	return amount, nil
+}
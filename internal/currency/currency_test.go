@@ -0,0 +1,71 @@
+package currency
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount float64
+		code   string
+		want   string
+	}{
+		{amount: 0, code: "IDR", want: "Rp 0"},
+		{amount: 999, code: "IDR", want: "Rp 999"},
+		{amount: 1500000, code: "IDR", want: "Rp 1.500.000"},
+		{amount: 1500000.6, code: "IDR", want: "Rp 1.500.001"},
+		{amount: -25000, code: "IDR", want: "-Rp 25.000"},
+		{amount: 1234.5, code: "USD", want: "$1,234.50"},
+		{amount: 1234.5, code: "usd", want: "$1,234.50"},
+		{amount: 1000, code: "EUR", want: "Rp 1.000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Format(tt.amount, tt.code); got != tt.want {
+				t.Errorf("Format(%v, %q) = %q, want %q", tt.amount, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: "100000", want: 100000},
+		{input: "100.000", want: 100000},
+		{input: "100,000", want: 100000},
+		{input: "Rp 100000", want: 100000},
+		{input: "Rp100.000", want: 100000},
+		{input: "Rp. 1.500.000", want: 1500000},
+		{input: "rp 250.000", want: 250000},
+		{input: "IDR 75,000", want: 75000},
+		{input: "1.250.000,50", want: 1250000.5},
+		{input: "1,250,000.50", want: 1250000.5},
+		{input: "$1,234.56", want: 1234.56},
+		{input: "USD 19.99", want: 19.99},
+		{input: "12.5", want: 12.5},
+		{input: "12,5", want: 12.5},
+		{input: " 0 ", want: 0},
+		{input: "", wantErr: true},
+		{input: "Rp", wantErr: true},
+		{input: "-5000", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "1e5", wantErr: true},
+		{input: "Inf", wantErr: true},
+		{input: "100.000,00.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -25,3 +25,12 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 	return db, nil
 }
 
+
+// Close releases the connection pool behind db
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
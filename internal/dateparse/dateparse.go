@@ -0,0 +1,173 @@
+package dateparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is a parsed point in time. HasTime is false when the phrase only
+// named a day, in which case Time is midnight of that day
+type Result struct {
+	Time    time.Time
+	HasTime bool
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "minggu": time.Sunday, "ahad": time.Sunday,
+	"monday": time.Monday, "senin": time.Monday,
+	"tuesday": time.Tuesday, "selasa": time.Tuesday,
+	"wednesday": time.Wednesday, "rabu": time.Wednesday,
+	"thursday": time.Thursday, "kamis": time.Thursday,
+	"friday": time.Friday, "jumat": time.Friday, "jum'at": time.Friday,
+	"saturday": time.Saturday, "sabtu": time.Saturday,
+}
+
+var strictLayouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{"2006-01-02 15:04", true},
+	{"2006-01-02T15:04", true},
+	{"2006-01-02", false},
+	{"02/01/2006 15:04", true},
+	{"02/01/2006", false},
+	{"02-01-2006", false},
+}
+
+var (
+	// "jam 9", "pukul 14:30", "at 9am", "9.30 pm", "jam 7 malam"
+	clockPattern = regexp.MustCompile(`(?:\b(?:jam|pukul|at)\s+)?\b(\d{1,2})(?:[:.](\d{2}))?\s*(am|pm|pagi|siang|sore|malam)?$`)
+	// "in 3 days", "3 hari lagi", "dalam 3 hari"
+	inDaysPattern = regexp.MustCompile(`^(?:in\s+(\d+)\s+days?|(?:dalam\s+)?(\d+)\s+hari(?:\s+lagi)?)$`)
+)
+
+// Parse converts a date phrase such as "besok jam 9", "next monday",
+// "akhir bulan" or "2025-01-10 09:00" into an absolute time in now's
+// location. Phrases that are not recognised fall back to strict layouts
+func Parse(input string, now time.Time) (Result, error) {
+	strict := strings.Join(strings.Fields(input), " ")
+	value := strings.ToLower(strict)
+	if value == "" {
+		return Result{}, fmt.Errorf("empty date")
+	}
+
+	loc := now.Location()
+	for _, l := range strictLayouts {
+		if t, err := time.ParseInLocation(l.layout, strict, loc); err == nil {
+			return Result{Time: t, HasTime: l.hasTime}, nil
+		}
+	}
+
+	dayPart, hour, minute, hasTime := splitClock(value)
+	if hasTime && (hour > 23 || minute > 59) {
+		return Result{}, fmt.Errorf("invalid time in %q", input)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day, ok := parseDay(dayPart, today)
+	if !ok {
+		return Result{}, fmt.Errorf("unrecognized date %q, use YYYY-MM-DD [HH:MM] or a phrase like besok jam 9, next monday, akhir bulan", input)
+	}
+
+	if hasTime {
+		day = time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+	}
+	return Result{Time: day, HasTime: hasTime}, nil
+}
+
+// splitClock separates a trailing clock time from the day phrase
+func splitClock(value string) (string, int, int, bool) {
+	match := clockPattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return value, 0, 0, false
+	}
+
+	clock := value[match[0]:]
+	dayPart := strings.TrimSpace(value[:match[0]])
+	groups := clockPattern.FindStringSubmatch(clock)
+
+	// A bare number is only a clock time when introduced by jam/pukul/at or
+	// qualified with am/pm or a part of day, otherwise "3 hari lagi" breaks
+	if !strings.HasPrefix(clock, "jam") && !strings.HasPrefix(clock, "pukul") && !strings.HasPrefix(clock, "at") &&
+		groups[2] == "" && groups[3] == "" {
+		return value, 0, 0, false
+	}
+
+	hour, _ := strconv.Atoi(groups[1])
+	minute := 0
+	if groups[2] != "" {
+		minute, _ = strconv.Atoi(groups[2])
+	}
+
+	switch groups[3] {
+	case "am", "pagi":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm", "sore", "malam":
+		if hour < 12 {
+			hour += 12
+		}
+	case "siang":
+		if hour < 11 {
+			hour += 12
+		}
+	}
+
+	if dayPart == "" {
+		dayPart = "today"
+	}
+	return dayPart, hour, minute, true
+}
+
+// parseDay resolves a relative day phrase against today
+func parseDay(value string, today time.Time) (time.Time, bool) {
+	switch value {
+	case "today", "hari ini", "sekarang":
+		return today, true
+	case "tomorrow", "besok":
+		return today.AddDate(0, 0, 1), true
+	case "lusa", "day after tomorrow":
+		return today.AddDate(0, 0, 2), true
+	case "yesterday", "kemarin":
+		return today.AddDate(0, 0, -1), true
+	case "next week", "minggu depan":
+		return today.AddDate(0, 0, 7), true
+	case "next month", "bulan depan":
+		return today.AddDate(0, 1, 0), true
+	case "end of month", "akhir bulan":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, today.Location()), true
+	case "start of month", "awal bulan":
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location()), true
+	case "end of next month", "akhir bulan depan":
+		return time.Date(today.Year(), today.Month()+2, 0, 0, 0, 0, 0, today.Location()), true
+	case "start of next month", "awal bulan depan":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), true
+	}
+
+	if match := inDaysPattern.FindStringSubmatch(value); match != nil {
+		n := match[1]
+		if n == "" {
+			n = match[2]
+		}
+		days, _ := strconv.Atoi(n)
+		return today.AddDate(0, 0, days), true
+	}
+
+	// "monday", "next monday", "senin", "senin depan", "hari senin"
+	name := strings.TrimPrefix(value, "next ")
+	name = strings.TrimPrefix(name, "hari ")
+	name = strings.TrimSuffix(name, " depan")
+	if weekday, ok := weekdays[name]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), true
+	}
+
+	return time.Time{}, false
+}
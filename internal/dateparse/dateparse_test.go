@@ -0,0 +1,75 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// Wednesday 15 January 2025, 10:30 WIB
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, jakarta)
+	day := func(y int, m time.Month, d, hour, min int) time.Time {
+		return time.Date(y, m, d, hour, min, 0, 0, jakarta)
+	}
+
+	tests := []struct {
+		input    string
+		want     time.Time
+		wantTime bool
+		wantErr  bool
+	}{
+		{input: "2025-01-10 09:00", want: day(2025, 1, 10, 9, 0), wantTime: true},
+		{input: "2025-01-10T09:00", want: day(2025, 1, 10, 9, 0), wantTime: true},
+		{input: "2025-01-10", want: day(2025, 1, 10, 0, 0)},
+		{input: "10/01/2025", want: day(2025, 1, 10, 0, 0)},
+		{input: "10/01/2025 14:15", want: day(2025, 1, 10, 14, 15), wantTime: true},
+		{input: "10-01-2025", want: day(2025, 1, 10, 0, 0)},
+		{input: "hari ini", want: day(2025, 1, 15, 0, 0)},
+		{input: "today", want: day(2025, 1, 15, 0, 0)},
+		{input: "besok", want: day(2025, 1, 16, 0, 0)},
+		{input: "Besok  jam 9", want: day(2025, 1, 16, 9, 0), wantTime: true},
+		{input: "tomorrow at 9pm", want: day(2025, 1, 16, 21, 0), wantTime: true},
+		{input: "besok jam 7 malam", want: day(2025, 1, 16, 19, 0), wantTime: true},
+		{input: "besok pukul 14:30", want: day(2025, 1, 16, 14, 30), wantTime: true},
+		{input: "lusa", want: day(2025, 1, 17, 0, 0)},
+		{input: "kemarin", want: day(2025, 1, 14, 0, 0)},
+		{input: "jam 2 siang", want: day(2025, 1, 15, 14, 0), wantTime: true},
+		{input: "12 am", want: day(2025, 1, 15, 0, 0), wantTime: true},
+		{input: "next monday", want: day(2025, 1, 20, 0, 0)},
+		{input: "senin depan", want: day(2025, 1, 20, 0, 0)},
+		{input: "hari rabu", want: day(2025, 1, 22, 0, 0)},
+		{input: "jumat jam 10", want: day(2025, 1, 17, 10, 0), wantTime: true},
+		{input: "minggu depan", want: day(2025, 1, 22, 0, 0)},
+		{input: "next month", want: day(2025, 2, 15, 0, 0)},
+		{input: "akhir bulan", want: day(2025, 1, 31, 0, 0)},
+		{input: "awal bulan", want: day(2025, 1, 1, 0, 0)},
+		{input: "akhir bulan depan", want: day(2025, 2, 28, 0, 0)},
+		{input: "start of next month", want: day(2025, 2, 1, 0, 0)},
+		{input: "in 3 days", want: day(2025, 1, 18, 0, 0)},
+		{input: "3 hari lagi", want: day(2025, 1, 18, 0, 0)},
+		{input: "dalam 10 hari", want: day(2025, 1, 25, 0, 0)},
+		{input: "", wantErr: true},
+		{input: "someday", wantErr: true},
+		{input: "besok jam 25", wantErr: true},
+		{input: "2025-13-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Time.Equal(tt.want) || got.Time.Location() != jakarta {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got.Time, tt.want)
+			}
+			if got.HasTime != tt.wantTime {
+				t.Errorf("Parse(%q) HasTime = %v, want %v", tt.input, got.HasTime, tt.wantTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"task_manager/internal/redis"
+	"time"
+)
+
+// Known feature flags
+const (
+	AIConfirmation        = "ai_confirmation"
+	CacheWarming          = "cache_warming"
+	CustomerNotifications = "customer_notifications"
+	QuietHours            = "quiet_hours"
+	SyncOrderTotal        = "sync_order_total"
+)
+
+var (
+	mu       sync.RWMutex
+	defaults = map[string]bool{
+		AIConfirmation:        true,
+		CacheWarming:          false,
+		CustomerNotifications: false,
+		QuietHours:            false,
+		SyncOrderTotal:        false,
+	}
+	store *redis.Client
+)
+
+// Init loads the configured flag values and, when redisClient is not nil,
+// lets runtime overrides stored in Redis take precedence over them
+func Init(configured map[string]bool, redisClient *redis.Client) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for name, enabled := range configured {
+		defaults[name] = enabled
+	}
+	store = redisClient
+}
+
+// Enabled reports whether the named feature is switched on
+func Enabled(name string) bool {
+	mu.RLock()
+	enabled := defaults[name]
+	rdb := store
+	mu.RUnlock()
+
+	if rdb != nil {
+		if val, err := rdb.Get(redisKey(name)).Result(); err == nil {
+			return val == "1"
+		}
+	}
+	return enabled
+}
+
+// Set overrides a flag at runtime. The override is kept in Redis when
+// available so every instance sees it, otherwise only in memory
+func Set(name string, enabled bool) error {
+	mu.Lock()
+	if _, ok := defaults[name]; !ok {
+		mu.Unlock()
+		return fmt.Errorf("unknown feature %q", name)
+	}
+	rdb := store
+	if rdb == nil {
+		defaults[name] = enabled
+	}
+	mu.Unlock()
+
+	if rdb == nil {
+		return nil
+	}
+
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return rdb.Set(redisKey(name), value, time.Duration(0)).Err()
+}
+
+// All returns the current value of every known flag
+func All() map[string]bool {
+	mu.RLock()
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+
+	flags := make(map[string]bool, len(names))
+	for _, name := range names {
+		flags[name] = Enabled(name)
+	}
+	return flags
+}
+
+// Names returns the known flag names in alphabetical order
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func redisKey(name string) string {
+	return "feature:" + name
+}
@@ -0,0 +1,94 @@
+package features
+
+import (
+	"task_manager/internal/redis"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// reset restores the built-in defaults when the test ends
+func reset(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	saved := make(map[string]bool, len(defaults))
+	for name, enabled := range defaults {
+		saved[name] = enabled
+	}
+	savedStore := store
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		defaults = saved
+		store = savedStore
+		mu.Unlock()
+	})
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured map[string]bool
+		flag       string
+		want       bool
+	}{
+		{name: "built-in default on", flag: AIConfirmation, want: true},
+		{name: "built-in default off", flag: QuietHours},
+		{name: "configured on", configured: map[string]bool{CustomerNotifications: true}, flag: CustomerNotifications, want: true},
+		{name: "configured off", configured: map[string]bool{AIConfirmation: false}, flag: AIConfirmation},
+		{name: "unknown flag", flag: "teleport"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset(t)
+			Init(tt.configured, nil)
+			if got := Enabled(tt.flag); got != tt.want {
+				t.Errorf("Enabled(%q) = %v, want %v", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Run("in memory", func(t *testing.T) {
+		reset(t)
+		Init(nil, nil)
+		if err := Set(QuietHours, true); err != nil {
+			t.Fatal(err)
+		}
+		if !Enabled(QuietHours) {
+			t.Error("override was not applied")
+		}
+	})
+
+	t.Run("redis override beats configuration", func(t *testing.T) {
+		reset(t)
+		server := miniredis.RunT(t)
+		client, err := redis.Initialize("redis://" + server.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { client.Close() })
+
+		Init(map[string]bool{CustomerNotifications: true}, client)
+		if err := Set(CustomerNotifications, false); err != nil {
+			t.Fatal(err)
+		}
+		if Enabled(CustomerNotifications) {
+			t.Error("Redis override was ignored")
+		}
+		if got, _ := server.Get(redisKey(CustomerNotifications)); got != "0" {
+			t.Errorf("stored override = %q, want 0", got)
+		}
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		reset(t)
+		Init(nil, nil)
+		if err := Set("teleport", true); err == nil {
+			t.Error("Set accepted an unknown flag")
+		}
+	})
+}
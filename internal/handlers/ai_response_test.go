@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/services"
+	"testing"
+)
+
+func TestParseAIResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantType    string
+		wantMessage string
+		wantData    map[string]interface{}
+	}{
+		{
+			name:     "plain JSON",
+			content:  `{"type":"create_task","data":{"title":"Stock opname"}}`,
+			wantType: "create_task",
+			wantData: map[string]interface{}{"title": "Stock opname"},
+		},
+		{
+			name:     "prose-wrapped JSON",
+			content:  "Sure! Here is the result:\n```json\n{\"type\":\"create_task\",\"data\":{\"title\":\"Cek {gudang}\"}}\n```\nLet me know if you need more.",
+			wantType: "create_task",
+			wantData: map[string]interface{}{"title": "Cek {gudang}"},
+		},
+		{
+			name:        "escaped quote inside a string",
+			content:     `Result: {"type":"general","message":"say \"hi\" }"} thanks`,
+			wantType:    "general",
+			wantMessage: `say "hi" }`,
+			wantData:    map[string]interface{}{},
+		},
+		{
+			name:        "pure prose",
+			content:     "  I can help you create tasks and orders.  ",
+			wantType:    "general",
+			wantMessage: "I can help you create tasks and orders.",
+			wantData:    map[string]interface{}{},
+		},
+		{
+			name:        "unbalanced braces",
+			content:     "Here you go: {\"type\":\"create_task\"",
+			wantType:    "general",
+			wantMessage: "Here you go: {\"type\":\"create_task\"",
+			wantData:    map[string]interface{}{},
+		},
+		{
+			name:        "empty content",
+			content:     "   ",
+			wantType:    "general",
+			wantMessage: "",
+			wantData:    map[string]interface{}{},
+		},
+		{
+			name:     "missing data",
+			content:  `{"type":"view_tasks"}`,
+			wantType: "view_tasks",
+			wantData: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			got, err := h.parseAIResponse(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", got.Type, tt.wantType)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if len(got.Data) != len(tt.wantData) {
+				t.Fatalf("Data = %v, want %v", got.Data, tt.wantData)
+			}
+			for k, v := range tt.wantData {
+				if got.Data[k] != v {
+					t.Errorf("Data[%q] = %v, want %v", k, got.Data[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "no braces", content: "hello", want: ""},
+		{name: "first block only", content: `a {"x":1} b {"y":2}`, want: `{"x":1}`},
+		{name: "nested", content: `-> {"a":{"b":{}}} <-`, want: `{"a":{"b":{}}}`},
+		{name: "brace inside string", content: `{"a":"}"}`, want: `{"a":"}"}`},
+		{name: "unterminated", content: `{"a":1`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractJSONObject(tt.content); got != tt.want {
+				t.Errorf("extractJSONObject() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "quota", err: &services.OpenAIError{StatusCode: 429, Type: "insufficient_quota"}, want: "usage quota is exhausted"},
+		{name: "rate limit", err: &services.OpenAIError{StatusCode: 429, Code: "rate_limit_exceeded"}, want: "busy right now"},
+		{name: "bad key", err: &services.OpenAIError{StatusCode: 401, Code: "invalid_api_key"}, want: "not configured correctly"},
+		{name: "bad request", err: &services.OpenAIError{StatusCode: 400, Type: "invalid_request_error"}, want: "could not process that message"},
+		{name: "wrapped", err: fmt.Errorf("ai: %w", &services.OpenAIError{StatusCode: 429, Type: "insufficient_quota"}), want: "usage quota is exhausted"},
+		{name: "other failure", err: errors.New("dial tcp: timeout"), want: "I'm having trouble understanding your message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aiErrorMessage("en", tt.err); !strings.Contains(got, tt.want) {
+				t.Errorf("aiErrorMessage() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("reply from processAICommand", func(t *testing.T) {
+		h := newTestHandler(testNow)
+		h.aiProcessor = &fakeAIProcessor{err: &services.OpenAIError{StatusCode: 429, Type: "insufficient_quota"}}
+		if got := h.processAICommand(&models.User{ID: 1, Language: "en"}, "halo"); !strings.Contains(got, "usage quota is exhausted") {
+			t.Errorf("reply = %q", got)
+		}
+	})
+}
+
+func TestValidateAIInput(t *testing.T) {
+	lang := models.DefaultLanguage
+
+	tests := []struct {
+		name     string
+		message  string
+		limit    int
+		want     string
+		wantSent string
+		wantNoAI bool
+	}{
+		{name: "ordinary request", message: "lihat task saya", limit: 20, want: "🤖 ok", wantSent: "lihat task saya"},
+		{name: "exactly at the limit", message: strings.Repeat("a", 20), limit: 20, want: "🤖 ok", wantSent: strings.Repeat("a", 20)},
+		{name: "over the limit", message: strings.Repeat("a", 21), limit: 20, want: fmt.Sprintf(messages["message_too_long"][lang], 20), wantNoAI: true},
+		{name: "limit counts characters, not bytes", message: strings.Repeat("é", 20), limit: 20, want: "🤖 ok", wantSent: strings.Repeat("é", 20)},
+		{name: "no limit configured", message: strings.Repeat("a", 5000), want: "🤖 ok", wantSent: strings.Repeat("a", 5000)},
+		{name: "control characters stripped", message: "lihat\x00 task\x1b[31m saya\x7f", limit: 50, want: "🤖 ok", wantSent: "lihat task[31m saya"},
+		{name: "newlines and tabs kept", message: "order:\n\tSiti 150000", limit: 50, want: "🤖 ok", wantSent: "order:\n\tSiti 150000"},
+		{name: "control characters only", message: "\x00\x01\x02", limit: 50, want: messages["empty_message"][lang], wantNoAI: true},
+		{name: "punctuation only", message: "?!... 🙂", limit: 50, want: messages["not_a_command"][lang], wantNoAI: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := &fakeAIProcessor{reply: "ok"}
+			h := newTestHandler(testNow)
+			h.cfg.AIMaxMessageLength = tt.limit
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = ai
+
+			if got := h.processCommand(&models.User{ID: 1, Role: string(models.Users)}, tt.message); got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			if tt.wantNoAI {
+				if len(ai.messages) != 0 {
+					t.Errorf("sent %q to the AI, want nothing sent", ai.messages)
+				}
+				return
+			}
+			if len(ai.messages) != 1 || ai.messages[0] != tt.wantSent {
+				t.Errorf("AI got %q, want %q", ai.messages, tt.wantSent)
+			}
+		})
+	}
+}
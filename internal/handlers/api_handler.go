@@ -1,28 +1,52 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
 	"task_manager/internal/redis"
+	"task_manager/internal/repository"
 	"task_manager/internal/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type APIHandler struct {
-	userService  services.UserService
-	taskService  services.TaskService
-	orderService services.OrderService
+	userService   services.UserService
+	taskService   services.TaskService
+	orderService  services.OrderService
+	tenantService services.TenantService
+	logger        *slog.Logger
+	clock         clock.Clock
 }
 
 func NewAPIHandler(
 	userService services.UserService,
 	taskService services.TaskService,
 	orderService services.OrderService,
+	tenantService services.TenantService,
+	logger *slog.Logger,
+	clk clock.Clock,
 ) *APIHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &APIHandler{
-		userService:  userService,
-		taskService:  taskService,
-		orderService: orderService,
+		userService:   userService,
+		taskService:   taskService,
+		orderService:  orderService,
+		tenantService: tenantService,
+		logger:        logger,
+		clock:         clock.OrReal(clk),
 	}
 }
 
@@ -122,3 +146,395 @@ func (h *APIHandler) DeleteTempData(c *gin.Context) {
 		"status": "deleted",
 	})
 }
+
+// Task endpoints
+
+// CreateTaskRequest is the body of POST /api/tasks. assigned_to and
+// created_by take a username or a user ID
+type CreateTaskRequest struct {
+	Title       string      `json:"title" binding:"required"`
+	Description string      `json:"description"`
+	AssignedTo  interface{} `json:"assigned_to" binding:"required"`
+	CreatedBy   interface{} `json:"created_by"`
+	Priority    string      `json:"priority"`
+	DueDate     string      `json:"due_date"`
+}
+
+// CreateTask creates a task for systems that integrate without WhatsApp.
+// Without created_by the task is recorded as created by its assignee
+func (h *APIHandler) CreateTask(c *gin.Context) {
+	var req CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: title and assigned_to are required"})
+		return
+	}
+
+	assignee, err := findAssignee(h.userService, userIdentifier(req.AssignedTo))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	creatorID := assignee.ID
+	if identifier := userIdentifier(req.CreatedBy); identifier != "" {
+		creator, err := findUser(h.userService, identifier)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Creator not found: " + identifier})
+			return
+		}
+		creatorID = creator.ID
+	}
+
+	priority, err := parseTaskPriority(req.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var dueDate *time.Time
+	if strings.TrimSpace(req.DueDate) != "" {
+		dueDate, err = parseDueDate(req.DueDate, h.clock.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due date: " + err.Error()})
+			return
+		}
+	}
+
+	task := &models.Task{
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		AssignedTo:  assignee.ID,
+		DueDate:     dueDate,
+		Status:      string(models.Pending),
+		Priority:    priority,
+		TaskType:    string(models.Custom),
+		CreatedBy:   creatorID,
+	}
+	if err := h.taskService.CreateTask(task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// userIdentifier turns a JSON username or numeric ID into the form findUser takes
+func userIdentifier(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatUint(uint64(v), 10)
+	}
+	return ""
+}
+
+// Tenant endpoints
+
+// CreateTenantRequest is the body of POST /api/tenants. The password is
+// stored encrypted and never returned
+type CreateTenantRequest struct {
+	Name           string `json:"name" binding:"required"`
+	WhatsAppNumber string `json:"whatsapp_number" binding:"required"`
+	APIURL         string `json:"api_url"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	Path           string `json:"path"`
+}
+
+// CreateTenant adds a business served through its own gateway number
+func (h *APIHandler) CreateTenant(c *gin.Context) {
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: name and whatsapp_number are required"})
+		return
+	}
+
+	tenant := &models.Tenant{
+		Name:           req.Name,
+		WhatsAppNumber: req.WhatsAppNumber,
+		APIURL:         strings.TrimSpace(req.APIURL),
+		Username:       req.Username,
+		Path:           req.Path,
+	}
+	if err := h.tenantService.CreateTenant(tenant, req.Password); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTenant):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNoTenantKey):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Tenants are disabled: TENANT_SECRET_KEY is not set"})
+		default:
+			h.logger.Error("Failed to create tenant", "whatsapp_number", tenant.WhatsAppNumber, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tenant"})
+		}
+		return
+	}
+
+	h.logger.Info("audit: tenant created", "tenant_id", tenant.ID, "name", tenant.Name, "whatsapp_number", tenant.WhatsAppNumber)
+	c.JSON(http.StatusCreated, tenant)
+}
+
+// Order endpoints
+
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+)
+
+// ListOrders returns orders as JSON, optionally filtered by status and an
+// order date range (from/to as YYYY-MM-DD, both inclusive), a page at a time
+func (h *APIHandler) ListOrders(c *gin.Context) {
+	var filter repository.OrderFilter
+
+	if status := strings.ToLower(c.Query("status")); status != "" {
+		if !services.IsValidOrderStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, valid statuses: pending, processing, completed, cancelled"})
+			return
+		}
+		filter.Status = status
+	}
+
+	for _, param := range []string{"from", "to"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s date, expected YYYY-MM-DD", param)})
+			return
+		}
+		if param == "from" {
+			filter.From = &day
+		} else {
+			end := day.AddDate(0, 0, 1)
+			filter.To = &end
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultOrderPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxOrderPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid page_size, must be 1-%d", maxOrderPageSize)})
+		return
+	}
+	filter.Offset = (page - 1) * pageSize
+	filter.Limit = pageSize
+
+	orders, total, err := h.orderService.SearchOrders(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"orders":    orders,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetOrder returns one order with its financials, and its items when called
+// with ?include=items
+func (h *APIHandler) GetOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.orderService.GetOrderByID(uint(orderID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	response := gin.H{"order": order}
+	if c.Query("include") == "items" {
+		items, err := h.orderService.GetOrderItems(order.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get order items"})
+			return
+		}
+		response["items"] = items
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Order document endpoints
+func (h *APIHandler) GetOrderInvoice(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.orderService.GetOrderByID(uint(orderID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	pdfData, err := h.orderService.GenerateInvoicePDF(order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invoice"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"invoice-%s.pdf\"", order.OrderNumber))
+	c.Data(http.StatusOK, "application/pdf", pdfData)
+}
+
+// ImportOrders creates orders from an uploaded CSV (multipart field "file"
+// or the raw request body) on behalf of the created_by user. Each row is
+// created on its own, with its items in one transaction, so bad rows are
+// reported without stopping the rest
+func (h *APIHandler) ImportOrders(c *gin.Context) {
+	identifier := strings.TrimSpace(c.Query("created_by"))
+	if identifier == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "created_by (username or user ID) is required"})
+		return
+	}
+	creator, err := findUser(h.userService, identifier)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Creator not found: " + identifier})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var body io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			if isTooLarge(err) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Import exceeds %d bytes", maxImportSize)})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Multipart upload needs a \"file\" part"})
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+		defer opened.Close()
+		body = opened
+	}
+
+	rows, results, err := parseOrderCSV(body, creator.ID)
+	if err != nil {
+		if isTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Import exceeds %d bytes", maxImportSize)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV: " + err.Error()})
+		return
+	}
+
+	created := 0
+	for _, row := range rows {
+		if len(row.Items) > 0 {
+			err = h.orderService.CreateOrderWithItems(row.Order, row.Items)
+		} else {
+			err = h.orderService.CreateOrder(row.Order)
+		}
+		if err != nil {
+			results = append(results, orderImportResult{Line: row.Line, Status: "error", Error: err.Error()})
+			continue
+		}
+		created++
+		results = append(results, orderImportResult{Line: row.Line, Status: "created", OrderID: row.Order.ID, OrderNumber: row.Order.OrderNumber})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+
+	c.JSON(http.StatusOK, gin.H{
+		"created": created,
+		"failed":  len(results) - created,
+		"rows":    results,
+	})
+}
+
+// ExportTasksCSV streams every task as CSV
+func (h *APIHandler) ExportTasksCSV(c *gin.Context) {
+	filename := fmt.Sprintf("tasks-%s.csv", h.clock.Now().Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Status(http.StatusOK)
+
+	if err := writeTasksCSV(c.Writer, h.userService, h.taskService); err != nil {
+		// Headers are already sent, so the truncated body is all we can do
+		h.logger.Error("Failed to export tasks", "error", err)
+	}
+}
+
+// exportBatchSize is the number of tasks read and written per batch
+const exportBatchSize = 500
+
+var taskCSVHeader = []string{
+	"id", "title", "assignee", "status", "priority", "task_type",
+	"completion_percentage", "due_date", "completed_at", "created_at", "updated_at",
+}
+
+// writeTasksCSV writes all tasks with their assignee username to w, flushing
+// after each batch so the output is streamed rather than buffered
+func writeTasksCSV(w io.Writer, userService services.UserService, taskService services.TaskService) error {
+	users, err := userService.GetAllUsers()
+	if err != nil {
+		return err
+	}
+	usernames := make(map[uint]string, len(users))
+	for _, u := range users {
+		usernames[u.ID] = u.Username
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taskCSVHeader); err != nil {
+		return err
+	}
+
+	err = taskService.StreamAllTasks(exportBatchSize, func(tasks []models.Task) error {
+		for _, task := range tasks {
+			record := []string{
+				strconv.FormatUint(uint64(task.ID), 10),
+				task.Title,
+				usernames[task.AssignedTo],
+				task.Status,
+				task.Priority,
+				task.TaskType,
+				strconv.Itoa(task.CompletionPercentage),
+				formatCSVTime(task.DueDate, "2006-01-02"),
+				formatCSVTime(task.CompletedAt, time.RFC3339),
+				task.CreatedAt.Format(time.RFC3339),
+				task.UpdatedAt.Format(time.RFC3339),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCSVTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"task_manager/internal/repository"
+	"task_manager/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const valid = `{"name":"Toko Kue","whatsapp_number":"081234567890","username":"toko","password":"gateway-pass","path":"toko"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "created", body: valid, wantStatus: http.StatusCreated},
+		{name: "missing number", body: `{"name":"Toko Kue"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid tenant", body: valid, serviceErr: fmt.Errorf("%w: name and whatsapp_number are required", services.ErrInvalidTenant), wantStatus: http.StatusBadRequest},
+		{name: "no secret key", body: valid, serviceErr: services.ErrNoTenantKey, wantStatus: http.StatusServiceUnavailable},
+		{name: "database error", body: valid, serviceErr: errors.New("duplicate key"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenants := &fakeTenantService{err: tt.serviceErr}
+			h := NewAPIHandler(nil, nil, nil, tenants, nil, nil)
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/tenants", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.CreateTenant(c)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			if len(tenants.passwords) != 1 || tenants.passwords[0] != "gateway-pass" {
+				t.Errorf("password passed to the service = %q", tenants.passwords)
+			}
+			if strings.Contains(rec.Body.String(), "gateway-pass") || strings.Contains(rec.Body.String(), "enc:v1") {
+				t.Errorf("response leaks the password: %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateTaskAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := []*models.User{
+		{ID: 1, Username: "admin", IsActive: true},
+		{ID: 3, Username: "budi", IsActive: true},
+		{ID: 4, Username: "citra"},
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantError    string
+		wantAssignee uint
+		wantCreator  uint
+		wantPriority string
+		wantDue      string
+	}{
+		{
+			name:         "by username",
+			body:         `{"title":" Stock opname ","description":"Gudang A","assigned_to":"budi","created_by":"admin","priority":"High","due_date":"2025-02-01"}`,
+			wantStatus:   http.StatusCreated,
+			wantAssignee: 3,
+			wantCreator:  1,
+			wantPriority: "high",
+			wantDue:      "2025-02-01",
+		},
+		{
+			name:         "by ID with defaults",
+			body:         `{"title":"Pack boxes","assigned_to":3}`,
+			wantStatus:   http.StatusCreated,
+			wantAssignee: 3,
+			wantCreator:  3,
+			wantPriority: "medium",
+		},
+		{
+			name:         "relative due date",
+			body:         `{"title":"Pack boxes","assigned_to":"budi","due_date":"besok"}`,
+			wantStatus:   http.StatusCreated,
+			wantAssignee: 3,
+			wantCreator:  3,
+			wantPriority: "medium",
+			wantDue:      "2025-01-16",
+		},
+		{name: "missing title", body: `{"assigned_to":"budi"}`, wantStatus: http.StatusBadRequest, wantError: "title and assigned_to are required"},
+		{name: "missing assignee", body: `{"title":"Pack boxes"}`, wantStatus: http.StatusBadRequest, wantError: "title and assigned_to are required"},
+		{name: "unknown assignee", body: `{"title":"Pack boxes","assigned_to":"ani"}`, wantStatus: http.StatusBadRequest, wantError: "user not found: ani"},
+		{name: "inactive assignee", body: `{"title":"Pack boxes","assigned_to":4}`, wantStatus: http.StatusBadRequest, wantError: "user is inactive: citra"},
+		{name: "unknown creator", body: `{"title":"Pack boxes","assigned_to":"budi","created_by":99}`, wantStatus: http.StatusBadRequest, wantError: "Creator not found: 99"},
+		{name: "invalid priority", body: `{"title":"Pack boxes","assigned_to":"budi","priority":"asap"}`, wantStatus: http.StatusBadRequest, wantError: "invalid priority"},
+		{name: "invalid due date", body: `{"title":"Pack boxes","assigned_to":"budi","due_date":"someday"}`, wantStatus: http.StatusBadRequest, wantError: "Invalid due date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{}
+			h := NewAPIHandler(&fakeUserService{users: users}, tasks, nil, nil, nil, clock.NewFake(testNow))
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.CreateTask(c)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if !strings.Contains(rec.Body.String(), tt.wantError) {
+					t.Errorf("body %s does not mention %q", rec.Body.String(), tt.wantError)
+				}
+				if len(tasks.created) != 0 {
+					t.Errorf("created %+v despite the error", tasks.created[0])
+				}
+				return
+			}
+
+			if len(tasks.created) != 1 {
+				t.Fatalf("created %d tasks, want 1", len(tasks.created))
+			}
+			task := tasks.created[0]
+			if task.AssignedTo != tt.wantAssignee || task.CreatedBy != tt.wantCreator || task.Priority != tt.wantPriority {
+				t.Errorf("task = %+v, want assignee %d, creator %d, priority %s", task, tt.wantAssignee, tt.wantCreator, tt.wantPriority)
+			}
+			if task.Status != string(models.Pending) || strings.TrimSpace(task.Title) != task.Title {
+				t.Errorf("task = %+v, want a pending task with a trimmed title", task)
+			}
+			if due := formatCSVTime(task.DueDate, "2006-01-02"); due != tt.wantDue {
+				t.Errorf("due date = %q, want %q", due, tt.wantDue)
+			}
+
+			var got models.Task
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != task.ID || got.Title != task.Title {
+				t.Errorf("response = %+v, want the created task %+v", got, task)
+			}
+		})
+	}
+}
+
+// newOrdersAPI routes the order endpoints the way main does, behind the key
+// "secret"
+func newOrdersAPI(orders services.OrderService) *gin.Engine {
+	h := NewAPIHandler(nil, nil, orders, nil, nil, nil)
+	router := gin.New()
+	api := router.Group("/api", RequireAPIKey("secret"))
+	api.GET("/orders", h.ListOrders)
+	api.GET("/orders/:id", h.GetOrder)
+	return router
+}
+
+func TestListOrdersAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	day := func(d int) *time.Time {
+		at := time.Date(2025, 1, d, 0, 0, 0, 0, time.Local)
+		return &at
+	}
+	orders := []models.Order{
+		{ID: 1, CustomerName: "Siti", TotalAmount: 150000, NetProfit: 90000, Status: "pending"},
+		{ID: 2, CustomerName: "Budi", TotalAmount: 50000, Status: "completed"},
+		{ID: 3, CustomerName: "Rina", TotalAmount: 75000, Status: "completed"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		key        string
+		wantStatus int
+		wantFilter repository.OrderFilter
+		wantIDs    []uint
+	}{
+		{name: "defaults", query: "", key: "secret", wantStatus: http.StatusOK, wantFilter: repository.OrderFilter{Limit: 20}, wantIDs: []uint{1, 2, 3}},
+		{name: "status", query: "?status=Completed", key: "secret", wantStatus: http.StatusOK, wantFilter: repository.OrderFilter{Status: "completed", Limit: 20}, wantIDs: []uint{1, 2, 3}},
+		{
+			// to is inclusive, so the filter ends at the start of the next day
+			name:       "date range",
+			query:      "?from=2025-01-01&to=2025-01-31",
+			key:        "secret",
+			wantStatus: http.StatusOK,
+			wantFilter: repository.OrderFilter{From: day(1), To: day(32), Limit: 20},
+			wantIDs:    []uint{1, 2, 3},
+		},
+		{name: "second page", query: "?page=2&page_size=2", key: "secret", wantStatus: http.StatusOK, wantFilter: repository.OrderFilter{Offset: 2, Limit: 2}, wantIDs: []uint{3}},
+		{name: "bad status", query: "?status=shipped", key: "secret", wantStatus: http.StatusBadRequest},
+		{name: "bad date", query: "?from=01-01-2025", key: "secret", wantStatus: http.StatusBadRequest},
+		{name: "bad page", query: "?page=0", key: "secret", wantStatus: http.StatusBadRequest},
+		{name: "page too large", query: "?page_size=101", key: "secret", wantStatus: http.StatusBadRequest},
+		{name: "no API key", query: "", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeOrderService{orders: orders}
+			req := httptest.NewRequest(http.MethodGet, "/api/orders"+tt.query, nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			newOrdersAPI(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(svc.filters) != 0 {
+					t.Errorf("searched %+v despite the error", svc.filters)
+				}
+				return
+			}
+			if len(svc.filters) != 1 || !reflect.DeepEqual(svc.filters[0], tt.wantFilter) {
+				t.Errorf("filters = %+v, want %+v", svc.filters, tt.wantFilter)
+			}
+
+			var got struct {
+				Orders []models.Order `json:"orders"`
+				Total  int64          `json:"total"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			var ids []uint
+			for _, o := range got.Orders {
+				ids = append(ids, o.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) || got.Total != int64(len(orders)) {
+				t.Errorf("orders %v of %d, want %v of %d", ids, got.Total, tt.wantIDs, len(orders))
+			}
+		})
+	}
+}
+
+func TestGetOrderAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orders := []models.Order{{ID: 1, CustomerName: "Siti", TotalAmount: 150000, NetProfit: 90000, Status: "pending"}}
+	items := map[uint][]models.OrderItem{1: {{ID: 1, OrderID: 1, ItemName: "Kue Lapis", Quantity: 3, UnitPrice: 50000, TotalPrice: 150000}}}
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+		wantItems  bool
+	}{
+		{name: "order only", path: "/api/orders/1", key: "secret", wantStatus: http.StatusOK},
+		{name: "with items", path: "/api/orders/1?include=items", key: "secret", wantStatus: http.StatusOK, wantItems: true},
+		{name: "unknown include", path: "/api/orders/1?include=notes", key: "secret", wantStatus: http.StatusOK},
+		{name: "missing order", path: "/api/orders/9", key: "secret", wantStatus: http.StatusNotFound},
+		{name: "bad ID", path: "/api/orders/abc", key: "secret", wantStatus: http.StatusBadRequest},
+		{name: "wrong API key", path: "/api/orders/1", key: "guess", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(APIKeyHeader, tt.key)
+			rec := httptest.NewRecorder()
+			newOrdersAPI(&fakeOrderService{orders: orders, items: items}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Order *models.Order       `json:"order"`
+				Items []*models.OrderItem `json:"items"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Order == nil || got.Order.ID != 1 || got.Order.NetProfit != 90000 {
+				t.Errorf("order = %+v, want order 1 with its financials", got.Order)
+			}
+			if hasItems := strings.Contains(rec.Body.String(), `"items"`); hasItems != tt.wantItems {
+				t.Errorf("items included = %v, want %v: %s", hasItems, tt.wantItems, rec.Body.String())
+			}
+			if tt.wantItems && (len(got.Items) != 1 || got.Items[0].ItemName != "Kue Lapis") {
+				t.Errorf("items = %+v, want the Kue Lapis line", got.Items)
+			}
+		})
+	}
+}
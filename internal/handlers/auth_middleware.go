@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the key RequireAPIKey checks
+const APIKeyHeader = "X-API-Key"
+
+// RequireAPIKey rejects requests whose X-API-Key header does not match key.
+// With no key configured every request is rejected rather than left open
+func RequireAPIKey(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(APIKeyHeader)
+		if key == "" || provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing API key"})
+			return
+		}
+		c.Next()
+	}
+}
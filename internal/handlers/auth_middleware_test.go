@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		configured string
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{name: "matching key", configured: "s3cret-key", path: "/api/cache/temp-data/x", header: "s3cret-key", wantStatus: http.StatusOK},
+		{name: "absent key", configured: "s3cret-key", path: "/api/cache/temp-data/x", wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", configured: "s3cret-key", path: "/api/cache/temp-data/x", header: "s3cret-kez", wantStatus: http.StatusUnauthorized},
+		{name: "key prefix", configured: "s3cret-key", path: "/api/cache/temp-data/x", header: "s3cret", wantStatus: http.StatusUnauthorized},
+		// An unset API_KEY closes the group instead of opening it
+		{name: "nothing configured", path: "/api/cache/temp-data/x", wantStatus: http.StatusUnauthorized},
+		// The webhook sits outside the group and checks its own secret
+		{name: "webhook needs no API key", configured: "s3cret-key", path: "/api/whatsapp/webhook", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router := gin.New()
+			router.GET("/api/whatsapp/webhook", ok)
+			api := router.Group("/api", RequireAPIKey(tt.configured))
+			api.GET("/cache/temp-data/:key", ok)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(APIKeyHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/services"
+	"testing"
+	"time"
+)
+
+func TestShowChatHistoryHeader(t *testing.T) {
+	history := []services.ChatMessage{
+		{Role: "user", Content: "create a task", Time: testNow.Unix()},
+		{Role: "assistant", Content: "done", Time: testNow.Unix()},
+	}
+
+	tests := []struct {
+		name    string
+		history []services.ChatMessage
+		config  services.HistoryConfig
+		want    string
+	}{
+		{name: "defaults", history: history, config: services.DefaultHistoryConfig(), want: "Last 3 messages, expires in 10 minutes"},
+		{name: "configured", history: history, config: services.HistoryConfig{Size: 8, TTL: 45 * time.Minute}, want: "Last 8 messages, expires in 45 minutes"},
+		{name: "empty", config: services.HistoryConfig{Size: 8, TTL: 45 * time.Minute}, want: "No chat history found."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.aiProcessor = &fakeAIProcessor{history: tt.history, config: tt.config}
+
+			got := h.showChatHistory(&models.User{ID: 1, Language: "en"})
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("showChatHistory() = %q, want it to contain %q", got, tt.want)
+			}
+			if len(tt.history) > 0 && !strings.Contains(got, "🤖 AI: done") {
+				t.Errorf("showChatHistory() = %q, missing the assistant reply", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"task_manager/internal/models"
+)
+
+var (
+	superAdminOnly = []string{string(models.SuperAdmin)}
+	managersOnly   = []string{string(models.Admin), string(models.SuperAdmin)}
+	everyone       = []string{string(models.Users), string(models.Admin), string(models.SuperAdmin)}
+)
+
+// commandRoles lists the roles allowed to run each command, keyed by the
+// command name without its slash. Slash commands and AI intents share the
+// table so a command cannot be reached through a less guarded path, and
+// every command processCommand or executeAIResponse dispatches has an entry.
+// Handlers that open a command to everyone may still limit what a user sees,
+// such as their own orders only
+var commandRoles = map[string][]string{
+	// Users and system
+	"add_user":       superAdminOnly,
+	"delete_user":    superAdminOnly,
+	"update_user":    superAdminOnly,
+	"set_role":       superAdminOnly,
+	"reset_password": superAdminOnly,
+	"list_tasks":     superAdminOnly,
+	"export_tasks":   superAdminOnly,
+	"cleanup":        superAdminOnly,
+	"features":       superAdminOnly,
+	"system_config":  superAdminOnly,
+	"list_users":     managersOnly,
+	"help":           everyone,
+	"whoami":         everyone,
+	"set_language":   everyone,
+	"set_timezone":   everyone,
+	"clear_history":  everyone,
+	"show_history":   everyone,
+	"undo":           everyone,
+	"general":        everyone,
+
+	// Tasks
+	"assign_task":            managersOnly,
+	"assign_task_bulk":       managersOnly,
+	"create_daily_task":      managersOnly,
+	"create_weekly_task":     managersOnly,
+	"create_monthly_task":    managersOnly,
+	"clone_task":             managersOnly,
+	"transfer_tasks":         managersOnly,
+	"user_tasks":             managersOnly,
+	"view_user_tasks":        managersOnly,
+	"my_assigned_tasks":      managersOnly,
+	"task_report":            managersOnly,
+	"my_tasks":               everyone,
+	"view_tasks":             everyone,
+	"my_weekly_tasks":        everyone,
+	"tasks_by_status":        everyone,
+	"tasks_by_priority":      everyone,
+	"view_tasks_by_status":   everyone,
+	"view_tasks_by_priority": everyone,
+	"search_tasks":           everyone,
+	"start_task":             everyone,
+	"block_task":             everyone,
+	"update_progress":        everyone,
+	"mark_complete":          everyone,
+	"delete_task":            everyone,
+	"task_progress_history":  everyone,
+	"my_stats":               everyone,
+	"create_reminder":        everyone,
+	"view_reminders":         everyone,
+
+	// Orders
+	"create_order":           managersOnly,
+	"all_orders":             managersOnly,
+	"create_order_with_item": managersOnly,
+	"add_order_item":         managersOnly,
+	"update_order_status":    managersOnly,
+	"cancel_order":           managersOnly,
+	"orders_by_status":       managersOnly,
+	"orders_for_customer":    managersOnly,
+	"upcoming_deliveries":    managersOnly,
+	"order_history":          managersOnly,
+	"customer_summary":       managersOnly,
+	"restore_order":          superAdminOnly,
+	"merge_customer":         superAdminOnly,
+	"search_orders":          everyone,
+	"view_orders":            everyone,
+	"my_orders":              everyone,
+	"order_detail":           everyone,
+	"order_note":             everyone,
+	"view_order_items":       everyone,
+	"invoice":                everyone,
+
+	// Finance and reports
+	"set_tax_rate":       managersOnly,
+	"set_marketing_rate": managersOnly,
+	"set_rental_rate":    managersOnly,
+	"generate_report":    managersOnly,
+	"daily_report":       managersOnly,
+	"monthly_report":     managersOnly,
+	"report_by_date":     managersOnly,
+	"report_history":     everyone,
+	"my_report":          everyone,
+}
+
+var roleLabels = map[string]string{
+	string(models.SuperAdmin): "Super Admin",
+	string(models.Admin):      "Admin",
+	string(models.Users):      "User",
+}
+
+// roleLabel is the display name of a role
+func roleLabel(role string) string {
+	if label, ok := roleLabels[models.NormalizeRole(role)]; ok {
+		return label
+	}
+	return role
+}
+
+// restrictedCommandsFor returns the commands role may run that are closed to
+// plain users, sorted
+func restrictedCommandsFor(role string) []string {
+	role = models.NormalizeRole(role)
+	var commands []string
+	for command, roles := range commandRoles {
+		if slices.Contains(roles, string(models.Users)) {
+			continue
+		}
+		for _, allowed := range roles {
+			if allowed == role {
+				commands = append(commands, "/"+command)
+				break
+			}
+		}
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// roleError is returned when a user lacks the role a command needs
+type roleError struct {
+	roles []string
+}
+
+func (e *roleError) Error() string {
+	return "only " + e.labels(" or ") + " can use this command"
+}
+
+func (e *roleError) labels(sep string) string {
+	labels := make([]string, 0, len(e.roles))
+	for _, role := range e.roles {
+		labels = append(labels, roleLabel(role))
+	}
+	return strings.Join(labels, sep)
+}
+
+// requireRole returns an error unless the user holds one of roles
+func requireRole(user *models.User, roles ...string) error {
+	if user != nil {
+		userRole := models.NormalizeRole(user.Role)
+		for _, role := range roles {
+			if userRole == models.NormalizeRole(role) {
+				return nil
+			}
+		}
+	}
+
+	return &roleError{roles: roles}
+}
+
+// authorizeCommand checks the user against commandRoles before a command or
+// AI intent is dispatched. command may carry its leading slash. Anything
+// missing from the table is allowed: an unknown slash command only reaches the
+// AI, and an unknown intent only gets a general reply
+func authorizeCommand(user *models.User, command string) error {
+	roles, restricted := commandRoles[strings.TrimPrefix(command, "/")]
+	if !restricted {
+		return nil
+	}
+	return requireRole(user, roles...)
+}
+
+// permissionDenied renders an authorization error as a chat reply in lang
+func permissionDenied(lang string, err error) string {
+	var roleErr *roleError
+	if errors.As(err, &roleErr) {
+		return t(lang, "permission_denied", roleErr.labels(t(lang, "or")))
+	}
+	return "❌ " + err.Error()
+}
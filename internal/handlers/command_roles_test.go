@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    *models.User
+		roles   []string
+		wantErr string
+	}{
+		{name: "matching role", user: &models.User{Role: "admin", Language: "en"}, roles: managersOnly},
+		{name: "friendly spelling", user: &models.User{Role: "SuperAdmin", Language: "en"}, roles: superAdminOnly},
+		{name: "wrong role", user: &models.User{Role: "admin", Language: "en"}, roles: superAdminOnly, wantErr: "only Super Admin can use this command"},
+		{name: "plain user", user: &models.User{Role: "user", Language: "en"}, roles: managersOnly, wantErr: "only Admin or Super Admin can use this command"},
+		{name: "no role", user: &models.User{}, roles: managersOnly, wantErr: "only Admin or Super Admin can use this command"},
+		{name: "no user", roles: managersOnly, wantErr: "only Admin or Super Admin can use this command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireRole(tt.user, tt.roles...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("requireRole() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("requireRole() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthorizeCommandTable(t *testing.T) {
+	roles := []string{string(models.SuperAdmin), string(models.Admin), string(models.Users)}
+
+	for command, allowed := range commandRoles {
+		for _, role := range roles {
+			user := &models.User{Role: role, Language: "en"}
+			for _, name := range []string{command, "/" + command} {
+				err := authorizeCommand(user, name)
+				if want := slices.Contains(allowed, role); (err == nil) != want {
+					t.Errorf("%s running %s: err = %v, want allowed %v", role, name, err, want)
+				}
+			}
+		}
+	}
+
+	// Commands outside the table are left to the AI, open to everyone
+	if err := authorizeCommand(&models.User{Role: string(models.Users), Language: "en"}, "/no_such_command"); err != nil {
+		t.Errorf("/no_such_command denied: %v", err)
+	}
+}
+
+func TestRestrictedCommandsDeniedOnEveryPath(t *testing.T) {
+	admin := &models.User{ID: 2, Role: string(models.Admin), Language: "en"}
+	staff := &models.User{ID: 3, Role: string(models.Users), Language: "en"}
+	const (
+		superAdminDenied = "❌ Only Super Admin can use this command."
+		managersDenied   = "❌ Only Admin or Super Admin can use this command."
+	)
+
+	type path int
+	const (
+		slash path = iota
+		aiIntent
+	)
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		command string
+		aiReply string
+		want    string
+	}{
+		{name: "user adds a user", user: staff, command: "add_user", aiReply: `{"type":"add_user","data":{"username":"x","role":"SuperAdmin"}}`, want: superAdminDenied},
+		{name: "user assigns a task", user: staff, command: "assign_task", aiReply: `{"type":"assign_task","data":{"username":"x","title":"t"}}`, want: managersDenied},
+		{name: "user creates an order", user: staff, command: "create_order", aiReply: `{"type":"create_order","data":{"customer_name":"x","total_amount":1}}`, want: managersDenied},
+		{name: "admin adds a user", user: admin, command: "add_user", aiReply: `{"type":"add_user","data":{"username":"x","role":"SuperAdmin"}}`, want: superAdminDenied},
+		{name: "admin deletes a user", user: admin, command: "delete_user", aiReply: `{"type":"delete_user","data":{"username":"x"}}`, want: superAdminDenied},
+		{name: "admin promotes", user: admin, command: "set_role", aiReply: `{"type":"set_role","data":{"username":"x","role":"SuperAdmin"}}`, want: superAdminDenied},
+		{name: "admin lists all tasks", user: admin, command: "list_tasks", aiReply: `{"type":"list_tasks","data":{}}`, want: superAdminDenied},
+		{name: "user creates a daily task", user: staff, command: "create_daily_task", aiReply: `{"type":"create_daily_task","data":{}}`, want: managersDenied},
+		{name: "user reads the financial report", user: staff, command: "report_by_date", aiReply: `{"type":"report_by_date","data":{}}`, want: managersDenied},
+		{name: "user lists users", user: staff, command: "list_users", aiReply: `{"type":"list_users","data":{}}`, want: managersDenied},
+		{name: "user views a task report", user: staff, command: "task_report", aiReply: `{"type":"task_report","data":{}}`, want: managersDenied},
+	}
+
+	for _, tt := range tests {
+		for _, p := range []path{slash, aiIntent} {
+			t.Run(tt.name, func(t *testing.T) {
+				// The services are left nil: reaching one fails the test
+				h := newTestHandler(testNow)
+				h.whatsappService = &fakeWhatsAppService{}
+				h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+				var got string
+				switch p {
+				case slash:
+					got = h.processCommand(tt.user, "/"+tt.command+" x y z")
+				case aiIntent:
+					got = h.processCommand(tt.user, "please "+tt.command)
+				}
+				if got != tt.want {
+					t.Errorf("path %d: got %q, want %q", p, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+// TestEveryDispatchedCommandHasRoles reads the command and intent switches
+// so a new case cannot skip the role table
+func TestEveryDispatchedCommandHasRoles(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "whatsapp_handler.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatchers := map[string]bool{"processCommand": true, "executeAIResponse": true}
+	found := 0
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !dispatchers[fn.Name.Name] {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			clause, ok := n.(*ast.CaseClause)
+			if !ok {
+				return true
+			}
+			for _, expr := range clause.List {
+				lit, ok := expr.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				command, _ := strconv.Unquote(lit.Value)
+				found++
+				if _, ok := commandRoles[strings.TrimPrefix(command, "/")]; !ok {
+					t.Errorf("%s dispatches %q, which has no commandRoles entry", fn.Name.Name, command)
+				}
+			}
+			return true
+		})
+	}
+	if found == 0 {
+		t.Fatal("no dispatched commands found")
+	}
+}
+
+func TestAdminCommandsDispatched(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "add user", message: "/add_user citra citra@example.com 08123456789 User", want: "✅ User created successfully"},
+		{name: "daily task", message: "/create_daily_task budi Opname hitung stok", want: "✅ Daily task created successfully"},
+		{name: "monthly task", message: "/create_monthly_task budi Audit cek kas", want: "✅ Monthly task created successfully"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.userService = &fakeUserService{users: []*models.User{owner, {ID: 2, Username: "budi", IsActive: true}}}
+			h.taskService = &fakeTaskService{tasks: map[uint]*models.Task{}}
+			// The AI processor is left nil: reaching it fails the test
+			h.whatsappService = &fakeWhatsAppService{}
+
+			if got := h.processCommand(owner, tt.message); got != tt.want {
+				t.Errorf("%q = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+)
+
+func TestOrderRepliesUseConfiguredCurrency(t *testing.T) {
+	tests := []struct {
+		currency   string
+		wantOrder  string
+		wantReport []string
+	}{
+		{currency: "IDR", wantOrder: "Total: Rp 1.500.000", wantReport: []string{"Total Amount: Rp 100.000", "Net Profit: Rp 80.000"}},
+		{currency: "USD", wantOrder: "Total: $1,500,000.00", wantReport: []string{"Total Amount: $100,000.00", "Net Profit: $80,000.00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.cfg.Currency = tt.currency
+			h.orderService = &fakeOrderService{orders: []models.Order{
+				{ID: 1, OrderNumber: "ORD-0001", CustomerName: "Siti", TotalAmount: 1500000, Status: "pending", CreatedBy: 1, OrderDate: testNow},
+			}}
+
+			if got := h.viewMyOrders(&models.User{ID: 1, Language: "en"}); !strings.Contains(got, tt.wantOrder) {
+				t.Errorf("viewMyOrders() = %q, want it to contain %q", got, tt.wantOrder)
+			}
+			report := h.getReportByDate(&models.User{ID: 1, Language: "en"}, []string{"2025-01-01", "2025-01-31"})
+			for _, want := range tt.wantReport {
+				if !strings.Contains(report, want) {
+					t.Errorf("getReportByDate() = %q, want it to contain %q", report, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFixture returns services holding two users and enough tasks to span
+// several export batches
+func exportFixture() (*fakeUserService, *fakeTaskService) {
+	users := &fakeUserService{users: []*models.User{{ID: 1, Username: "andi"}, {ID: 2, Username: "budi"}}}
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	due := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	tasks := &fakeTaskService{tasks: map[uint]*models.Task{}}
+	for id := uint(1); id <= exportBatchSize+2; id++ {
+		tasks.tasks[id] = &models.Task{
+			ID: id, Title: "Task, with comma", AssignedTo: 1 + id%2, Status: "pending", Priority: "high",
+			TaskType: "custom", CompletionPercentage: 40, CreatedAt: created, UpdatedAt: created,
+		}
+	}
+	tasks.tasks[1].DueDate = &due
+	return users, tasks
+}
+
+func TestExportTasksCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users, tasks := exportFixture()
+	h := NewAPIHandler(users, tasks, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), clock.NewFake(testNow))
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/tasks/export.csv", nil)
+	h.ExportTasksCSV(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="tasks-20250115.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if !rec.Flushed {
+		t.Errorf("export was buffered instead of flushed per batch")
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != exportBatchSize+3 {
+		t.Fatalf("got %d rows, want a header and %d tasks", len(records), exportBatchSize+2)
+	}
+	if strings.Join(records[0], ",") != strings.Join(taskCSVHeader, ",") {
+		t.Errorf("header = %v", records[0])
+	}
+	want := []string{"1", "Task, with comma", "budi", "pending", "high", "custom", "40", "2025-01-20", "", "2025-01-02T03:04:05Z", "2025-01-02T03:04:05Z"}
+	if strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Errorf("first row = %q, want %q", records[1], want)
+	}
+	if records[2][2] != "andi" {
+		t.Errorf("second row assignee = %q, want andi", records[2][2])
+	}
+}
+
+func TestExportTasksCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		want     string
+		wantSent bool
+	}{
+		{name: "super admin", role: string(models.SuperAdmin), want: "✅ Task export sent", wantSent: true},
+		{name: "admin", role: string(models.Admin), want: "❌ Only Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, tasks := exportFixture()
+			wa := &fakeWhatsAppService{}
+			h := newTestHandler(testNow)
+			h.userService, h.taskService, h.whatsappService = users, tasks, wa
+
+			if got := h.processCommand(&models.User{ID: 1, Role: tt.role, WhatsAppNumber: "628111", Language: "en"}, "/export_tasks"); got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			data, sent := wa.documents["tasks-20250115.csv"]
+			if sent != tt.wantSent {
+				t.Fatalf("document sent = %v, want %v (%v)", sent, tt.wantSent, wa.documents)
+			}
+			if sent && strings.Count(string(data), "\n") != exportBatchSize+3 {
+				t.Errorf("document has %d lines, want %d", strings.Count(string(data), "\n"), exportBatchSize+3)
+			}
+		})
+	}
+}
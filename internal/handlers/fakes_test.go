@@ -0,0 +1,831 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"task_manager/internal/repository"
+	"task_manager/internal/services"
+	"task_manager/pkg/whatsapp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeUserService serves users from memory; methods a test does not need
+// panic through the embedded nil interface
+type fakeUserService struct {
+	services.UserService
+	users   []*models.User
+	deleted []uint
+	updated []models.User
+	lookups [][]uint
+	resets  []uint
+}
+
+func (f *fakeUserService) GetUserByID(id uint) (*models.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (f *fakeUserService) GetAllUsers() ([]models.User, error) {
+	var users []models.User
+	for _, u := range f.users {
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+func (f *fakeUserService) GetUserByUsername(username string) (*models.User, error) {
+	for _, u := range f.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (f *fakeUserService) GetUsersByIDs(ids []uint) ([]models.User, error) {
+	f.lookups = append(f.lookups, ids)
+	var users []models.User
+	for _, id := range ids {
+		if u, err := f.GetUserByID(id); err == nil {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeUserService) CreateUser(user *models.User, password string) error {
+	user.ID = uint(len(f.users) + 1)
+	f.users = append(f.users, user)
+	return nil
+}
+
+// ResetPassword hands out a predictable password for the user
+func (f *fakeUserService) ResetPassword(userID uint) (string, error) {
+	if _, err := f.GetUserByID(userID); err != nil {
+		return "", err
+	}
+	f.resets = append(f.resets, userID)
+	return fmt.Sprintf("Pw%dxyzKLMNP", userID), nil
+}
+
+func (f *fakeUserService) CountUsersByRole(role string) (int, error) {
+	var count int
+	for _, u := range f.users {
+		if models.NormalizeRole(u.Role) == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeUserService) DeleteUser(id uint) error {
+	for i, u := range f.users {
+		if u.ID == id {
+			f.users = append(f.users[:i], f.users[i+1:]...)
+			f.deleted = append(f.deleted, id)
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
+
+func (f *fakeUserService) UpdateUser(user *models.User) error {
+	f.updated = append(f.updated, *user)
+	return nil
+}
+
+func (f *fakeUserService) GetUserByWhatsAppNumber(number string) (*models.User, error) {
+	for _, u := range f.users {
+		if u.WhatsAppNumber == number {
+			return u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// fakeOrderService records created orders and assigns sequential IDs
+type fakeOrderService struct {
+	services.OrderService
+	orders        []models.Order
+	created       []*models.Order
+	items         map[uint][]models.OrderItem
+	summaryRanges [][2]time.Time
+	// reportOrders, when set, are summed by GetFinancialSummary
+	reportOrders []models.Order
+	filters      []repository.OrderFilter
+	merged       [][2]string
+	summary      *services.CustomerSummary
+	deleted      []models.Order
+	notes        []models.OrderNote
+	history      []models.CalculationHistory
+	reports      []models.ReportQuery
+	// windows records the look-ahead of each GetUpcomingDeliveries
+	windows []time.Duration
+	// statusErr is returned by UpdateStatus
+	statusErr error
+}
+
+func (f *fakeOrderService) UpdateStatus(orderID uint, status string) error {
+	return f.statusErr
+}
+
+func (f *fakeOrderService) CreateOrder(order *models.Order) error {
+	order.ID = uint(len(f.created) + 1)
+	order.OrderNumber = fmt.Sprintf("ORD-%04d", order.ID)
+	f.created = append(f.created, order)
+	return nil
+}
+
+// GetUpcomingDeliveries hands back the orders with a delivery date; the
+// window itself is the repository's business
+func (f *fakeOrderService) GetUpcomingDeliveries(within time.Duration) ([]models.Order, error) {
+	f.windows = append(f.windows, within)
+	var matched []models.Order
+	for _, o := range f.orders {
+		if o.DeliveryDate != nil {
+			matched = append(matched, o)
+		}
+	}
+	return matched, nil
+}
+
+// GetOrdersForCustomer matches the customer name ignoring case, or the stored
+// phone against the query normalized
+func (f *fakeOrderService) GetOrdersForCustomer(nameOrPhone string) ([]models.Order, error) {
+	var matched []models.Order
+	for _, o := range f.orders {
+		if strings.EqualFold(o.CustomerName, nameOrPhone) || (o.CustomerPhone != "" && o.CustomerPhone == whatsapp.NormalizePhone(nameOrPhone)) {
+			matched = append(matched, o)
+		}
+	}
+	return matched, nil
+}
+
+func (f *fakeOrderService) CreateOrderWithItems(order *models.Order, items []models.OrderItem) error {
+	if err := f.CreateOrder(order); err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].ID = uint(i + 1)
+		items[i].OrderID = order.ID
+		items[i].TotalPrice = float64(items[i].Quantity) * items[i].UnitPrice
+	}
+	if f.items == nil {
+		f.items = make(map[uint][]models.OrderItem)
+	}
+	f.items[order.ID] = items
+	return nil
+}
+
+func (f *fakeOrderService) GetOrderByID(id uint) (*models.Order, error) {
+	for _, order := range f.created {
+		if order.ID == id {
+			return order, nil
+		}
+	}
+	for i := range f.orders {
+		if f.orders[i].ID == id {
+			return &f.orders[i], nil
+		}
+	}
+	return nil, errors.New("order not found")
+}
+
+func (f *fakeOrderService) GetOrderItems(orderID uint) ([]*models.OrderItem, error) {
+	var items []*models.OrderItem
+	for i := range f.items[orderID] {
+		items = append(items, &f.items[orderID][i])
+	}
+	return items, nil
+}
+
+func (f *fakeOrderService) AddItemToOrder(orderID uint, itemName string, quantity int, price float64, description string) error {
+	if _, err := f.GetOrderByID(orderID); err != nil {
+		return err
+	}
+	if f.items == nil {
+		f.items = make(map[uint][]models.OrderItem)
+	}
+	f.items[orderID] = append(f.items[orderID], models.OrderItem{
+		ID:          uint(len(f.items[orderID]) + 1),
+		OrderID:     orderID,
+		ItemName:    itemName,
+		Quantity:    quantity,
+		UnitPrice:   price,
+		TotalPrice:  float64(quantity) * price,
+		Description: description,
+		Status:      string(models.ItemPending),
+	})
+	return nil
+}
+
+func (f *fakeOrderService) GetAllOrdersPaginated(offset, limit int) ([]models.Order, int64, error) {
+	total := int64(len(f.orders))
+	if offset >= len(f.orders) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(f.orders) {
+		end = len(f.orders)
+	}
+	return f.orders[offset:end], total, nil
+}
+
+// SearchOrders records the filter and pages through orders, which a test
+// fills with the matching orders
+func (f *fakeOrderService) SearchOrders(filter repository.OrderFilter) ([]models.Order, int64, error) {
+	f.filters = append(f.filters, filter)
+	return f.GetAllOrdersPaginated(filter.Offset, filter.Limit)
+}
+
+func (f *fakeOrderService) GetOrdersByStatus(status string) ([]models.Order, error) {
+	if !services.IsValidOrderStatus(status) {
+		return nil, fmt.Errorf("invalid order status %q", status)
+	}
+	var matched []models.Order
+	for _, order := range f.orders {
+		if order.Status == status {
+			matched = append(matched, order)
+		}
+	}
+	return matched, nil
+}
+
+// GetCustomerSummary returns summary, or an empty one for the name
+func (f *fakeOrderService) GetCustomerSummary(name string) (*services.CustomerSummary, error) {
+	if f.summary == nil {
+		return &services.CustomerSummary{Customer: name}, nil
+	}
+	return f.summary, nil
+}
+
+func (f *fakeOrderService) AddNote(orderID, userID uint, content string) (*models.OrderNote, error) {
+	note := models.OrderNote{ID: uint(len(f.notes) + 1), OrderID: orderID, UserID: userID, Content: content, CreatedAt: testNow}
+	f.notes = append(f.notes, note)
+	return &note, nil
+}
+
+func (f *fakeOrderService) GetNotes(orderID uint) ([]models.OrderNote, error) {
+	var notes []models.OrderNote
+	for _, note := range f.notes {
+		if note.OrderID == orderID {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+func (f *fakeOrderService) GetCalculationHistory(orderID uint) ([]models.CalculationHistory, error) {
+	var history []models.CalculationHistory
+	for _, entry := range f.history {
+		if entry.OrderID == orderID {
+			history = append(history, entry)
+		}
+	}
+	return history, nil
+}
+
+func (f *fakeOrderService) GetDeletedOrders() ([]models.Order, error) {
+	return f.deleted, nil
+}
+
+// RestoreOrder moves a deleted order back into f.orders
+func (f *fakeOrderService) RestoreOrder(id uint) error {
+	for i, order := range f.deleted {
+		if order.ID == id {
+			f.deleted = append(f.deleted[:i], f.deleted[i+1:]...)
+			f.orders = append(f.orders, order)
+			return nil
+		}
+	}
+	return services.ErrOrderNotDeleted
+}
+
+// MergeCustomer renames the matching orders in f.orders
+func (f *fakeOrderService) MergeCustomer(from, to string) (int64, error) {
+	f.merged = append(f.merged, [2]string{from, to})
+	var count int64
+	for i := range f.orders {
+		if f.orders[i].CustomerName == from {
+			f.orders[i].CustomerName = to
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeOrderService) GetOrdersByUser(userID uint) ([]models.Order, error) {
+	var matched []models.Order
+	for _, order := range f.orders {
+		if order.CreatedBy == userID {
+			matched = append(matched, order)
+		}
+	}
+	return matched, nil
+}
+
+func (f *fakeOrderService) GetFinancialSummary(startDate, endDate time.Time, includeCancelled bool) (*services.FinancialSummary, error) {
+	f.summaryRanges = append(f.summaryRanges, [2]time.Time{startDate, endDate})
+	if f.reportOrders == nil {
+		return &services.FinancialSummary{OrderCount: 1, TotalRevenue: 100000, TotalNetProfit: 80000}, nil
+	}
+
+	// Sum the report orders as the service does
+	summary := &services.FinancialSummary{}
+	for _, order := range f.reportOrders {
+		cancelled := order.Status == string(models.OrderCancelled)
+		if order.OrderDate.Before(startDate) || order.OrderDate.After(endDate) || (cancelled && !includeCancelled) {
+			continue
+		}
+		if cancelled {
+			summary.CancelledCount++
+		}
+		summary.OrderCount++
+		summary.TotalRevenue += order.TotalAmount
+		summary.TotalNetProfit += order.NetProfit
+	}
+	return summary, nil
+}
+
+func (f *fakeOrderService) RecordReportQuery(userID uint, queryType string, startDate, endDate *time.Time, reportData interface{}) error {
+	data, err := json.Marshal(reportData)
+	if err != nil {
+		return err
+	}
+	f.reports = append(f.reports, models.ReportQuery{
+		ID: uint(len(f.reports) + 1), UserID: userID, QueryType: queryType,
+		StartDate: startDate, EndDate: endDate, ReportData: string(data), GeneratedAt: testNow,
+	})
+	return nil
+}
+
+// GetReportHistory returns the user's reports newest first
+func (f *fakeOrderService) GetReportHistory(userID uint, limit int) ([]models.ReportQuery, error) {
+	var reports []models.ReportQuery
+	for i := len(f.reports) - 1; i >= 0 && len(reports) < limit; i-- {
+		if f.reports[i].UserID == userID {
+			reports = append(reports, f.reports[i])
+		}
+	}
+	return reports, nil
+}
+
+// fakeTaskService serves tasks from memory
+type fakeTaskService struct {
+	services.TaskService
+	tasks   map[uint]*models.Task
+	created []*models.Task
+	// bulkErr fails CreateTasksBulk before any task is kept
+	bulkErr  error
+	progress map[uint][]models.TaskProgress
+	// admins may update any task; others only the tasks assigned to them
+	admins  []uint
+	updates []uint
+}
+
+func (f *fakeTaskService) GetTasksByUser(userID uint) ([]models.Task, error) {
+	var matched []models.Task
+	for _, task := range f.tasks {
+		if task.AssignedTo == userID {
+			matched = append(matched, *task)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+func (f *fakeTaskService) GetTasksByUserAndStatus(userID uint, status string) ([]models.Task, error) {
+	if !services.IsValidTaskStatus(status) {
+		return nil, fmt.Errorf("invalid task status %q", status)
+	}
+	return f.userTasksWhere(userID, func(task *models.Task) bool { return task.Status == status }), nil
+}
+
+func (f *fakeTaskService) GetTasksByUserAndPriority(userID uint, priority string) ([]models.Task, error) {
+	if !services.IsValidTaskPriority(priority) {
+		return nil, fmt.Errorf("invalid task priority %q", priority)
+	}
+	return f.userTasksWhere(userID, func(task *models.Task) bool { return task.Priority == priority }), nil
+}
+
+func (f *fakeTaskService) userTasksWhere(userID uint, match func(task *models.Task) bool) []models.Task {
+	tasks, _ := f.GetTasksByUser(userID)
+	var matched []models.Task
+	for i := range tasks {
+		if match(&tasks[i]) {
+			matched = append(matched, tasks[i])
+		}
+	}
+	return matched
+}
+
+// SearchTasks matches keyword against title and description ignoring case,
+// as ILIKE does, in ID order
+func (f *fakeTaskService) SearchTasks(userID uint, keyword string, allUsers bool) ([]models.Task, error) {
+	keyword = strings.ToLower(keyword)
+	var matched []models.Task
+	for _, task := range f.tasks {
+		if !allUsers && task.AssignedTo != userID {
+			continue
+		}
+		if strings.Contains(strings.ToLower(task.Title), keyword) || strings.Contains(strings.ToLower(task.Description), keyword) {
+			matched = append(matched, *task)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+// GetCompletedTasks returns tasks completed between the start and end days,
+// both inclusive
+func (f *fakeTaskService) GetCompletedTasks(start, end time.Time) ([]models.Task, error) {
+	to := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, end.Location())
+	var matched []models.Task
+	for _, task := range f.tasks {
+		if task.CompletedAt != nil && !task.CompletedAt.Before(start) && task.CompletedAt.Before(to) {
+			matched = append(matched, *task)
+		}
+	}
+	return matched, nil
+}
+
+// GetAllTasksPaginated pages through the tasks in ID order
+func (f *fakeTaskService) GetAllTasksPaginated(offset, limit int) ([]models.Task, int64, error) {
+	all := make([]models.Task, 0, len(f.tasks))
+	for _, task := range f.tasks {
+		all = append(all, *task)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	if offset >= len(all) {
+		return nil, int64(len(all)), nil
+	}
+	return all[offset:min(offset+limit, len(all))], int64(len(all)), nil
+}
+
+// GetTasksByCreator returns creatorID's tasks by assignee, then ID, as the
+// repository orders them
+func (f *fakeTaskService) GetTasksByCreator(creatorID uint) ([]models.Task, error) {
+	var matched []models.Task
+	for _, task := range f.tasks {
+		if task.CreatedBy == creatorID {
+			matched = append(matched, *task)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].AssignedTo != matched[j].AssignedTo {
+			return matched[i].AssignedTo < matched[j].AssignedTo
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return matched, nil
+}
+
+// StreamAllTasks hands out the tasks in ID order, batchSize at a time
+func (f *fakeTaskService) StreamAllTasks(batchSize int, fn func(tasks []models.Task) error) error {
+	var all []models.Task
+	for _, task := range f.tasks {
+		all = append(all, *task)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	for start := 0; start < len(all); start += batchSize {
+		end := start + batchSize
+		if end > len(all) {
+			end = len(all)
+		}
+		if err := fn(all[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeTaskService) CreateDailyTask(task *models.Task) error {
+	task.TaskType = string(models.Daily)
+	return f.CreateTask(task)
+}
+
+func (f *fakeTaskService) CreateWeeklyTask(task *models.Task) error {
+	task.TaskType = string(models.Weekly)
+	return f.CreateTask(task)
+}
+
+func (f *fakeTaskService) CreateMonthlyTask(task *models.Task) error {
+	task.TaskType = string(models.Monthly)
+	return f.CreateTask(task)
+}
+
+func (f *fakeTaskService) CreateTask(task *models.Task) error {
+	if f.tasks == nil {
+		f.tasks = make(map[uint]*models.Task)
+	}
+	task.ID = uint(len(f.tasks) + 1)
+	f.tasks[task.ID] = task
+	f.created = append(f.created, task)
+	return nil
+}
+
+func (f *fakeTaskService) GetProgressHistory(taskID uint) ([]models.TaskProgress, error) {
+	return f.progress[taskID], nil
+}
+
+func (f *fakeTaskService) UpdateTaskProgress(taskID uint, progress int, isImplemented bool, notes string, updatedBy uint) error {
+	if task := f.tasks[taskID]; task.AssignedTo != updatedBy && !slices.Contains(f.admins, updatedBy) {
+		return services.ErrTaskProgressForbidden
+	}
+	f.updates = append(f.updates, taskID)
+	return nil
+}
+
+func (f *fakeTaskService) UpdateStatus(taskID uint, status string, actor uint) error {
+	if task := f.tasks[taskID]; task.AssignedTo != actor && !slices.Contains(f.admins, actor) {
+		return services.ErrTaskStatusForbidden
+	}
+	f.tasks[taskID].Status = status
+	return nil
+}
+
+// CreateTasksBulk keeps all tasks or, with bulkErr set, none of them
+func (f *fakeTaskService) CreateTasksBulk(tasks []*models.Task) error {
+	if f.bulkErr != nil {
+		return f.bulkErr
+	}
+	for _, task := range tasks {
+		f.CreateTask(task)
+	}
+	return nil
+}
+
+func (f *fakeTaskService) GetTaskByID(id uint) (*models.Task, error) {
+	task, ok := f.tasks[id]
+	if !ok {
+		return nil, errors.New("task not found")
+	}
+	return task, nil
+}
+
+func (f *fakeTaskService) DeleteTask(id uint) error {
+	if _, ok := f.tasks[id]; !ok {
+		return errors.New("task not found")
+	}
+	delete(f.tasks, id)
+	return nil
+}
+
+// fakeReminderService records the reminders it is asked to create
+type fakeReminderService struct {
+	services.ReminderService
+	scheduled []time.Time
+	reminders []models.Reminder
+	lookups   []uint
+}
+
+func (f *fakeReminderService) GetRemindersByUser(userID uint) ([]models.Reminder, error) {
+	f.lookups = append(f.lookups, userID)
+	return f.reminders, nil
+}
+
+func (f *fakeReminderService) CreateTaskReminder(taskID uint, reminderType string, scheduledTime time.Time) error {
+	f.scheduled = append(f.scheduled, scheduledTime)
+	return nil
+}
+
+func (f *fakeReminderService) CreateRecurringReminder(taskID uint, reminderType string, firstTime time.Time, pattern string) error {
+	f.scheduled = append(f.scheduled, firstTime)
+	return nil
+}
+
+// RecordSeen reports a first sighting for users without LastSeenAt, as the
+// repository does
+func (f *fakeUserService) RecordSeen(userID uint) (bool, error) {
+	user, err := f.GetUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+	first := user.LastSeenAt == nil
+	now := time.Now()
+	user.LastSeenAt = &now
+	return first, nil
+}
+
+// fakeRateLimiter lets every message through
+type fakeRateLimiter struct{}
+
+func (fakeRateLimiter) Allow(phone string) (bool, bool, error) {
+	return true, false, nil
+}
+
+// fakeWhatsAppService records outgoing messages and documents and keeps temp
+// data as JSON in memory; no interactive session is ever open
+type fakeWhatsAppService struct {
+	services.WhatsAppService
+	sent      []string
+	documents map[string][]byte
+	temp      map[string][]byte
+	// recipients holds the phone of each sent message
+	recipients []string
+	sendErr    error
+}
+
+func (f *fakeWhatsAppService) SendDocument(phone, filename string, data []byte, caption string) error {
+	if f.documents == nil {
+		f.documents = make(map[string][]byte)
+	}
+	f.documents[filename] = data
+	return nil
+}
+
+func (f *fakeWhatsAppService) SendMessage(phone, message string) error {
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.sent = append(f.sent, message)
+	f.recipients = append(f.recipients, phone)
+	return nil
+}
+
+func (f *fakeWhatsAppService) SendLongMessage(phone, message string) error {
+	return f.SendMessage(phone, message)
+}
+
+func (f *fakeWhatsAppService) MessageLimit() int {
+	return services.DefaultMessageLimit
+}
+
+func (f *fakeWhatsAppService) AdvanceSession(phone, input string) (*redis.SessionData, error) {
+	return nil, services.ErrNoActiveSession
+}
+
+func (f *fakeWhatsAppService) SetTempData(key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if f.temp == nil {
+		f.temp = make(map[string][]byte)
+	}
+	f.temp[key] = data
+	return nil
+}
+
+func (f *fakeWhatsAppService) GetTempData(key string, dest interface{}) error {
+	data, ok := f.temp[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (f *fakeWhatsAppService) DeleteTempData(key string) error {
+	delete(f.temp, key)
+	return nil
+}
+
+// fakeTenantService records created tenants; err, when set, fails creation
+type fakeTenantService struct {
+	services.TenantService
+	created   []*models.Tenant
+	passwords []string
+	err       error
+}
+
+func (f *fakeTenantService) CreateTenant(tenant *models.Tenant, password string) error {
+	if f.err != nil {
+		return f.err
+	}
+	tenant.ID = uint(len(f.created) + 1)
+	tenant.Password = "enc:v1:sealed"
+	f.created = append(f.created, tenant)
+	f.passwords = append(f.passwords, password)
+	return nil
+}
+
+// fakeAIProcessor answers every message with reply, or fails with err
+type fakeAIProcessor struct {
+	services.AIProcessor
+	reply    string
+	err      error
+	messages []string
+	history  []services.ChatMessage
+	config   services.HistoryConfig
+}
+
+func (f *fakeAIProcessor) ProcessWithOpenAI(message string, userID string) (string, interface{}, error) {
+	f.messages = append(f.messages, message)
+	if f.err != nil {
+		return "", nil, f.err
+	}
+	return f.reply, f.reply, nil
+}
+
+func (f *fakeAIProcessor) GetChatHistory(userID string) ([]services.ChatMessage, error) {
+	return f.history, nil
+}
+
+func (f *fakeAIProcessor) HistoryConfig() services.HistoryConfig {
+	return f.config
+}
+
+// fakeUndoService accepts every recorded action
+type fakeUndoService struct {
+	services.UndoService
+	orders []*models.Order
+}
+
+func (f *fakeUndoService) RecordOrderCreated(userID uint, order *models.Order) error {
+	f.orders = append(f.orders, order)
+	return nil
+}
+
+func (f *fakeUndoService) RecordOrderStatus(userID uint, order *models.Order, items []*models.OrderItem) error {
+	return nil
+}
+
+func (f *fakeUndoService) RecordTaskChange(userID uint, actionType string, task *models.Task) error {
+	return nil
+}
+
+// fakeCleanupService records when it was run and reports result
+type fakeCleanupService struct {
+	runs   []time.Time
+	result services.CleanupResult
+}
+
+func (f *fakeCleanupService) Run(now time.Time) (*services.CleanupResult, error) {
+	f.runs = append(f.runs, now)
+	result := f.result
+	return &result, nil
+}
+
+// fakeOrderRepo stores orders in memory so a real order service can run
+// against it
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders map[uint]*models.Order
+}
+
+func (r *fakeOrderRepo) Create(order *models.Order) error {
+	if r.orders == nil {
+		r.orders = make(map[uint]*models.Order)
+	}
+	order.ID = uint(len(r.orders) + 1)
+	copied := *order
+	r.orders[order.ID] = &copied
+	return nil
+}
+
+func (r *fakeOrderRepo) CreateWithItems(order *models.Order, items []models.OrderItem) error {
+	return r.Create(order)
+}
+
+func (r *fakeOrderRepo) GetByID(id uint) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// fakeFinancialRepo serves fixed settings and keeps calculation history in
+// memory
+type fakeFinancialRepo struct {
+	repository.FinancialRepository
+	settings map[string]*models.FinancialSettings
+	history  []models.CalculationHistory
+}
+
+func (r *fakeFinancialRepo) GetSettings(name string) (*models.FinancialSettings, error) {
+	settings, ok := r.settings[name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *settings
+	return &copied, nil
+}
+
+func (r *fakeFinancialRepo) CreateCalculationHistory(history *models.CalculationHistory) error {
+	r.history = append(r.history, *history)
+	return nil
+}
+
+func (r *fakeFinancialRepo) GetCalculationHistory(orderID uint) ([]models.CalculationHistory, error) {
+	var history []models.CalculationHistory
+	for _, entry := range r.history {
+		if entry.OrderID == orderID {
+			history = append(history, entry)
+		}
+	}
+	return history, nil
+}
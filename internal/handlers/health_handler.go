@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"task_manager/internal/redis"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout keeps a hung dependency from stalling the check
+const healthCheckTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewHealthHandler(db *gorm.DB, redis *redis.Client) *HealthHandler {
+	return &HealthHandler{db: db, redis: redis}
+}
+
+// Health reports 200 when the database and Redis both answer a ping, and 503
+// naming each dependency that did not
+func (h *HealthHandler) Health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	checks := gin.H{}
+	healthy := true
+
+	if err := h.pingDatabase(ctx); err != nil {
+		checks["database"] = err.Error()
+		healthy = false
+	} else {
+		checks["database"] = "ok"
+	}
+
+	if err := h.redis.Ping(ctx); err != nil {
+		checks["redis"] = err.Error()
+		healthy = false
+	} else {
+		checks["redis"] = "ok"
+	}
+
+	if !healthy {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unhealthy", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
+
+func (h *HealthHandler) pingDatabase(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"task_manager/internal/redis"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		dbErr      error
+		redisDown  bool
+		wantStatus int
+		wantBody   map[string]interface{}
+	}{
+		{
+			name:       "all up",
+			wantStatus: http.StatusOK,
+			wantBody:   map[string]interface{}{"status": "ok", "checks": map[string]interface{}{"database": "ok", "redis": "ok"}},
+		},
+		{
+			name:       "redis down",
+			redisDown:  true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]interface{}{"status": "unhealthy", "checks": map[string]interface{}{"database": "ok", "redis": "down"}},
+		},
+		{
+			name:       "database down",
+			dbErr:      errors.New("connection refused"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]interface{}{"status": "unhealthy", "checks": map[string]interface{}{"database": "connection refused", "redis": "ok"}},
+		},
+		{
+			name:       "both down",
+			dbErr:      errors.New("connection refused"),
+			redisDown:  true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]interface{}{"status": "unhealthy", "checks": map[string]interface{}{"database": "connection refused", "redis": "down"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sqlDB.Close()
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+				DisableAutomaticPing: true,
+				Logger:               logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mock.ExpectPing().WillReturnError(tt.dbErr)
+
+			server := miniredis.RunT(t)
+			client, err := redis.Initialize("redis://" + server.Addr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			if tt.redisDown {
+				server.Close()
+			}
+
+			router := gin.New()
+			router.GET("/health", NewHealthHandler(db, client).Health)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			// The Redis error text depends on the OS; only its presence matters
+			if checks, ok := body["checks"].(map[string]interface{}); ok && checks["redis"] != "ok" && checks["redis"] != nil {
+				checks["redis"] = "down"
+			}
+			if !reflect.DeepEqual(body, tt.wantBody) {
+				t.Errorf("body = %v, want %v", body, tt.wantBody)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,1551 @@
+package handlers
+
+import (
+	"fmt"
+
+	"task_manager/internal/models"
+)
+
+// messages is the reply catalog, keyed by message ID and then language.
+// Every entry has an Indonesian text, which is also the fallback
+var messages = map[string]map[string]string{
+	"or": {
+		"en": " or ",
+		"id": " atau ",
+	},
+	"empty_message": {
+		"en": "❌ Empty message. Please send a message or use /help for available commands.",
+		"id": "❌ Pesan kosong. Silakan kirim pesan atau gunakan /help untuk melihat perintah yang tersedia.",
+	},
+	"message_too_long": {
+		"en": "❌ Your message is too long (max %d characters). Please shorten it or split it into several messages.",
+		"id": "❌ Pesan terlalu panjang (maksimal %d karakter). Silakan persingkat atau pecah menjadi beberapa pesan.",
+	},
+	"not_a_command": {
+		"en": "🤔 I couldn't find a request in that message. Tell me what you need, or use /help for available commands.",
+		"id": "🤔 Saya tidak menemukan permintaan dalam pesan itu. Sampaikan kebutuhan Anda, atau gunakan /help untuk melihat perintah yang tersedia.",
+	},
+	"user_not_found": {
+		"en": "❌ User not found. Please contact administrator.",
+		"id": "❌ User tidak ditemukan. Silakan hubungi administrator.",
+	},
+	"rate_limited": {
+		"en": "⏳ You're sending messages too quickly. Please wait a moment and try again.",
+		"id": "⏳ Anda mengirim pesan terlalu cepat. Mohon tunggu sebentar lalu coba lagi.",
+	},
+	"permission_denied": {
+		"en": "❌ Only %s can use this command.",
+		"id": "❌ Hanya %s yang dapat menggunakan perintah ini.",
+	},
+	"add_user_incomplete": {
+		"en": "❌ Incomplete data. Make sure username, email, phone and role are given.",
+		"id": "❌ Data tidak lengkap. Pastikan username, email, phone, dan role tersedia.",
+	},
+	"add_user_format": {
+		"en": "❌ Invalid format. Use: 'add user [username] [email] [phone] [role]'\nExample: 'add user ega egatryagung@gmail.com 08123456789 SuperAdmin'",
+		"id": "❌ Format tidak valid. Gunakan: 'tambahkan user [username] [email] [phone] [role]'\nContoh: 'tambahkan user ega egatryagung@gmail.com 08123456789 SuperAdmin'",
+	},
+	"invalid_role": {
+		"en": "❌ Invalid role. Use: SuperAdmin, Admin or User",
+		"id": "❌ Role tidak valid. Gunakan: SuperAdmin, Admin, atau User",
+	},
+	"add_user_failed": {
+		"en": "❌ Failed to add user: %s",
+		"id": "❌ Gagal menambah user: %s",
+	},
+	"user_added": {
+		"en": "✅ User added!\n👤 Username: %s\n📧 Email: %s\n📱 Phone: %s\n🔑 Role: %s\n🔐 Password: default123",
+		"id": "✅ User berhasil ditambahkan!\n👤 Username: %s\n📧 Email: %s\n📱 Phone: %s\n🔑 Role: %s\n🔐 Password: default123",
+	},
+	"whoami": {
+		"en": "👤 **Who Am I**\n\nUsername: %s\nRole: %s\nWhatsApp: %s\nStatus: %s\nLanguage: %s\n",
+		"id": "👤 **Siapa Saya**\n\nUsername: %s\nRole: %s\nWhatsApp: %s\nStatus: %s\nBahasa: %s\n",
+	},
+	"status_active": {
+		"en": "Active",
+		"id": "Aktif",
+	},
+	"status_inactive": {
+		"en": "Inactive",
+		"id": "Tidak aktif",
+	},
+	"whoami_general_only": {
+		"en": "\nYou can use the general commands; see /help.",
+		"id": "\nAnda dapat menggunakan perintah umum; lihat /help.",
+	},
+	"whoami_commands": {
+		"en": "\nBesides the general commands you can use:\n%s",
+		"id": "\nSelain perintah umum, Anda dapat menggunakan:\n%s",
+	},
+	"set_language_usage": {
+		"en": "❌ Usage: /set_language [id|en]",
+		"id": "❌ Penggunaan: /set_language [id|en]",
+	},
+	"set_language_failed": {
+		"en": "❌ Failed to save language: %s",
+		"id": "❌ Gagal menyimpan bahasa: %s",
+	},
+	"language_set": {
+		"en": "✅ Replies will now be in English.",
+		"id": "✅ Balasan sekarang dalam Bahasa Indonesia.",
+	},
+	"set_timezone_usage": {
+		"en": "❌ Usage: /set_timezone [zone], e.g. /set_timezone Asia/Makassar",
+		"id": "❌ Penggunaan: /set_timezone [zona], contoh: /set_timezone Asia/Makassar",
+	},
+	"set_timezone_unknown": {
+		"en": "❌ Unknown time zone %q. Use a name like Asia/Jakarta, Asia/Makassar or Asia/Jayapura.",
+		"id": "❌ Zona waktu %q tidak dikenal. Gunakan nama seperti Asia/Jakarta, Asia/Makassar atau Asia/Jayapura.",
+	},
+	"set_timezone_failed": {
+		"en": "❌ Failed to save time zone: %s",
+		"id": "❌ Gagal menyimpan zona waktu: %s",
+	},
+	"timezone_set": {
+		"en": "✅ Dates you send are now read in %s.",
+		"id": "✅ Tanggal yang Anda kirim sekarang dibaca dalam zona %s.",
+	},
+	"customer_order_status": {
+		"en": "Hi %s, your order %s is now %s.",
+		"id": "Halo %s, pesanan Anda %s sekarang berstatus %s.",
+	},
+
+	// Welcome
+	"welcome": {
+		"en": "👋 Welcome {username}! You are registered as {role}.\n\nHere are a few commands to get started:\n{commands}\n\nSend /help for the full list, or just tell me what you need.",
+		"id": "👋 Selamat datang {username}! Anda terdaftar sebagai {role}.\n\nBeberapa perintah untuk memulai:\n{commands}\n\nKirim /help untuk daftar lengkap, atau sampaikan saja kebutuhan Anda.",
+	},
+	"welcome_commands_super_admin": {
+		"en": "/list_users - View all users\n/add_user - Add a new user\n/assign_task - Assign a task\n/features - Toggle features",
+		"id": "/list_users - Lihat semua user\n/add_user - Tambah user baru\n/assign_task - Tugaskan task\n/features - Atur fitur",
+	},
+	"welcome_commands_admin": {
+		"en": "/assign_task - Assign a task\n/create_order - Create an order\n/view_orders - List orders\n/user_tasks - Check a user's tasks",
+		"id": "/assign_task - Tugaskan task\n/create_order - Buat order\n/view_orders - Lihat daftar order\n/user_tasks - Cek task seorang user",
+	},
+	"welcome_commands_user": {
+		"en": "/my_tasks - View your tasks\n/update_progress - Report progress\n/mark_complete - Finish a task\n/my_stats - View your stats",
+		"id": "/my_tasks - Lihat task Anda\n/update_progress - Laporkan progress\n/mark_complete - Selesaikan task\n/my_stats - Lihat statistik Anda",
+	},
+
+	// AI dispatch, confirmations and the order wizard
+	"and": {
+		"en": " and ",
+		"id": " dan ",
+	},
+	"ai_missing_fields": {
+		"en": "❌ Incomplete data. Make sure %s are provided.",
+		"id": "❌ Data tidak lengkap. Pastikan %s tersedia.",
+	},
+	"ai_not_understood": {
+		"en": "I don't understand that message. Please use /help to see available commands.",
+		"id": "Saya tidak memahami pesan itu. Gunakan /help untuk melihat perintah yang tersedia.",
+	},
+	"order_wizard_failed": {
+		"en": "❌ Failed to start order wizard: %s",
+		"id": "❌ Gagal memulai wizard order: %s",
+	},
+	"order_wizard_cancelled": {
+		"en": "❌ Order wizard cancelled.",
+		"id": "❌ Wizard order dibatalkan.",
+	},
+	"session_error": {
+		"en": "❌ Session error: %s",
+		"id": "❌ Kesalahan sesi: %s",
+	},
+	"order_create_failed": {
+		"en": "❌ Failed to create order: %s",
+		"id": "❌ Gagal membuat order: %s",
+	},
+	"order_created": {
+		"en": "✅ Order created successfully\nOrder #: %s\nCustomer: %s\nTotal: %s",
+		"id": "✅ Order berhasil dibuat\nNo. Order: %s\nCustomer: %s\nTotal: %s",
+	},
+	"confirmation_save_failed": {
+		"en": "❌ Failed to save the confirmation: %s",
+		"id": "❌ Gagal menyimpan konfirmasi: %s",
+	},
+	"confirmation_prompt": {
+		"en": "⚠️ Confirm: %s\n\nReply YES to confirm or NO to cancel (expires in %d minutes).",
+		"id": "⚠️ Konfirmasi: %s\n\nBalas YA untuk melanjutkan atau TIDAK untuk membatalkan (berlaku %d menit).",
+	},
+	"confirm_add_user": {
+		"en": "add user '%s' as %s",
+		"id": "tambah user '%s' sebagai %s",
+	},
+	"confirm_delete_user": {
+		"en": "delete user '%s'",
+		"id": "hapus user '%s'",
+	},
+	"confirm_delete_task": {
+		"en": "delete task #%d",
+		"id": "hapus task #%d",
+	},
+	"confirmation_cancelled": {
+		"en": "❌ Cancelled.",
+		"id": "❌ Dibatalkan.",
+	},
+	"ai_quota_exceeded": {
+		"en": "🤖 The AI assistant is unavailable because its usage quota is exhausted. Please contact the administrator, or use /help for commands.",
+		"id": "🤖 Asisten AI tidak tersedia karena kuota penggunaannya habis. Silakan hubungi administrator, atau gunakan /help untuk melihat perintah.",
+	},
+	"ai_rate_limited": {
+		"en": "🤖 The AI assistant is busy right now. Please try again in a minute, or use /help for commands.",
+		"id": "🤖 Asisten AI sedang sibuk. Silakan coba lagi sebentar lagi, atau gunakan /help untuk melihat perintah.",
+	},
+	"ai_misconfigured": {
+		"en": "🤖 The AI assistant is not configured correctly. Please contact the administrator, or use /help for commands.",
+		"id": "🤖 Asisten AI belum dikonfigurasi dengan benar. Silakan hubungi administrator, atau gunakan /help untuk melihat perintah.",
+	},
+	"ai_bad_request": {
+		"en": "🤖 The AI assistant could not process that message. Please rephrase it, or use /help for commands.",
+		"id": "🤖 Asisten AI tidak dapat memproses pesan itu. Silakan ulangi dengan kalimat lain, atau gunakan /help untuk melihat perintah.",
+	},
+	"ai_failed": {
+		"en": "🤖 I'm having trouble understanding your message. Please try using a command like /help for available options.",
+		"id": "🤖 Saya kesulitan memahami pesan Anda. Silakan coba perintah seperti /help untuk melihat pilihan yang tersedia.",
+	},
+
+	// Help
+	"help_general": {
+		"en": "\n📱 **Available Commands:**\n\n**General Commands:**\n/my_tasks [page] - View assigned tasks\n/tasks_by_status [status] - View your tasks with a status\n/tasks_by_priority [priority] - View your tasks with a priority\n/my_daily_tasks - View today's daily tasks\n/my_weekly_tasks [YYYY-Www] - View this week's weekly tasks\n/search_tasks [keyword] - Search tasks by title or description\n/my_monthly_tasks - View this month's tasks\n/my_stats - View your task statistics and daily streak\n/update_progress [task_id] [percentage] - Update task progress\n/mark_complete [task_id] - Mark task as implemented\n/undo - Undo your last order or task change\n/start_task [task_id] - Mark task as in progress\n/block_task [task_id] - Mark task as blocked\n/delete_task [task_id] - Delete a task you created\n/view_orders - View related orders\n/my_orders - View the orders you created\n/invoice [order_id] - Receive an order invoice as PDF\n/order_detail [order_id] - View an order with its items and notes\n/order_note [order_id] [text] - Add an internal note to an order\n/search_orders [status:] [customer:] [min:] [max:] [from:] [to:] - Search orders\n/my_report - View personal financial reports\n/report_by_date [start_date] [end_date] [all] - Generate reports by date range ('all' includes cancelled orders)\n/report_history - List the reports you generated recently\n/clear_history - Clear AI chat history\n/show_history - Show AI chat history\n/view_reminders - View the reminders of your tasks\n/task_progress_history [task_id] - See every progress update of a task\n/whoami - Show who the bot thinks you are and your role\n/set_language [id|en] - Reply in Indonesian or English\n/set_timezone [zone] - Read your dates in a time zone, e.g. Asia/Makassar\n/help - Show this help message\n",
+		"id": "\n📱 **Perintah yang Tersedia:**\n\n**Perintah Umum:**\n/my_tasks [halaman] - Lihat task yang ditugaskan\n/tasks_by_status [status] - Lihat task Anda dengan status tertentu\n/tasks_by_priority [prioritas] - Lihat task Anda dengan prioritas tertentu\n/my_daily_tasks - Lihat task harian hari ini\n/my_weekly_tasks [YYYY-Www] - Lihat task mingguan minggu ini\n/search_tasks [kata_kunci] - Cari task berdasarkan judul atau deskripsi\n/my_monthly_tasks - Lihat task bulan ini\n/my_stats - Lihat statistik task dan streak harian Anda\n/update_progress [task_id] [persentase] - Perbarui progres task\n/mark_complete [task_id] - Tandai task sudah dikerjakan\n/undo - Batalkan perubahan order atau task terakhir Anda\n/start_task [task_id] - Tandai task sedang dikerjakan\n/block_task [task_id] - Tandai task terhambat\n/delete_task [task_id] - Hapus task yang Anda buat\n/view_orders - Lihat order terkait\n/my_orders - Lihat order yang Anda buat\n/invoice [order_id] - Terima invoice order dalam bentuk PDF\n/order_detail [order_id] - Lihat order beserta item dan catatannya\n/order_note [order_id] [teks] - Tambahkan catatan internal ke order\n/search_orders [status:] [customer:] [min:] [max:] [from:] [to:] - Cari order\n/my_report - Lihat laporan keuangan pribadi\n/report_by_date [tanggal_mulai] [tanggal_akhir] [all] - Buat laporan berdasarkan rentang tanggal ('all' menyertakan order yang dibatalkan)\n/report_history - Daftar laporan yang baru Anda buat\n/clear_history - Hapus riwayat chat AI\n/show_history - Tampilkan riwayat chat AI\n/view_reminders - Lihat pengingat task Anda\n/task_progress_history [task_id] - Lihat setiap pembaruan progres task\n/whoami - Tampilkan siapa Anda menurut bot beserta role Anda\n/set_language [id|en] - Balas dalam bahasa Indonesia atau Inggris\n/set_timezone [zona] - Baca tanggal Anda dalam zona waktu tertentu, mis. Asia/Makassar\n/help - Tampilkan pesan bantuan ini\n",
+	},
+	"help_admin": {
+		"en": "\n**Admin Commands:**\n/create_order [customer_name] [total_amount] [tax=%] [marketing=%] [rental=%] - Create new order (no arguments starts a step-by-step wizard)\n/view_orders [page] - List all orders\n/all_orders [page] - List every user's orders\n/orders_by_status [status] - List orders in a status\n/customer_summary [customer_name] - Order totals for a customer\n/orders_for_customer [name_or_phone] - List a customer's orders\n/upcoming_deliveries [days] - Orders to deliver in the next days (default 7)\n/order_history [order_id] - Show how an order's financials were calculated\n/update_order_status [order_id] [status] - Change order status\n/cancel_order [order_id] [reason] - Cancel an order and remove it from revenue\n/assign_task [username_or_id] [title] [description] [due:YYYY-MM-DD] [priority:high] - Assign task to user\n/my_assigned_tasks - View the tasks you assigned, by assignee\n/assign_task_bulk [title] | [description] | [user1,user2] - Assign the same task to several users\n/task_report [start_date] [end_date] - Summarize tasks completed in a date range\n/create_daily_task [username_or_id] [title] [description] - Create daily recurring task\n/create_weekly_task [username_or_id] [title] [description] - Create weekly recurring task\n/create_monthly_task [username_or_id] [title] [description] - Create monthly recurring task\n/transfer_tasks [from_user] [to_user] - Move all open tasks to another user\n/clone_task [task_id] [username_or_id] - Copy an existing task\n/user_tasks [username_or_id] - View tasks assigned to a user\n/set_tax_rate [percentage] - Set tax percentage\n/set_marketing_rate [percentage] - Set marketing cost percentage\n/set_rental_rate [percentage] - Set rental cost percentage\n/generate_report - Generate financial reports\n/daily_report - Generate daily report\n/monthly_report - Generate monthly report\n",
+		"id": "\n**Perintah Admin:**\n/create_order [nama_customer] [total] [tax=%] [marketing=%] [rental=%] - Buat order baru (tanpa argumen memulai wizard langkah demi langkah)\n/view_orders [halaman] - Daftar semua order\n/all_orders [halaman] - Daftar order semua user\n/orders_by_status [status] - Daftar order dengan status tertentu\n/customer_summary [nama_customer] - Total order seorang customer\n/orders_for_customer [nama_atau_telepon] - Daftar order seorang customer\n/upcoming_deliveries [hari] - Order yang dikirim dalam beberapa hari ke depan (default 7)\n/order_history [order_id] - Tampilkan cara keuangan order dihitung\n/update_order_status [order_id] [status] - Ubah status order\n/cancel_order [order_id] [alasan] - Batalkan order dan keluarkan dari pendapatan\n/assign_task [username_atau_id] [judul] [deskripsi] [due:YYYY-MM-DD] [priority:high] - Tugaskan task ke user\n/my_assigned_tasks - Lihat task yang Anda tugaskan, per penerima\n/assign_task_bulk [judul] | [deskripsi] | [user1,user2] - Tugaskan task yang sama ke beberapa user\n/task_report [tanggal_mulai] [tanggal_akhir] - Ringkasan task yang selesai dalam rentang tanggal\n/create_daily_task [username_atau_id] [judul] [deskripsi] - Buat task harian berulang\n/create_weekly_task [username_atau_id] [judul] [deskripsi] - Buat task mingguan berulang\n/create_monthly_task [username_atau_id] [judul] [deskripsi] - Buat task bulanan berulang\n/transfer_tasks [dari_user] [ke_user] - Pindahkan semua task terbuka ke user lain\n/clone_task [task_id] [username_atau_id] - Salin task yang sudah ada\n/user_tasks [username_atau_id] - Lihat task yang ditugaskan ke seorang user\n/set_tax_rate [persentase] - Atur persentase pajak\n/set_marketing_rate [persentase] - Atur persentase biaya marketing\n/set_rental_rate [persentase] - Atur persentase biaya sewa\n/generate_report - Buat laporan keuangan\n/daily_report - Buat laporan harian\n/monthly_report - Buat laporan bulanan\n",
+	},
+	"help_super_admin": {
+		"en": "\n**Super Admin Commands:**\n/add_user [username] [email] [phone] [role] - Add new user\n/list_users [page] - View all users (shows User ID for reference)\n/list_tasks [page] - View all tasks in the system\n/export_tasks - Receive all tasks as a CSV file\n/update_user [username_or_id] [email|phone|whatsapp] [value] - Update user information\n/delete_user [username_or_id] - Delete user\n/reset_password [username_or_id] - Send a user a new random password\n/set_role [username_or_id] [role] - Change user role\n/system_config - System configuration\n/features [name] [on|off] - View or toggle feature flags\n/cleanup - Remove history older than the retention period\n/merge_customer [from_name] [to_name] - Merge a duplicate customer's orders\n/restore_order [order_id] - Restore a deleted order (no ID lists deleted orders)\n\n",
+		"id": "\n**Perintah Super Admin:**\n/add_user [username] [email] [telepon] [role] - Tambah user baru\n/list_users [halaman] - Lihat semua user (menampilkan User ID sebagai referensi)\n/list_tasks [halaman] - Lihat semua task di sistem\n/export_tasks - Terima semua task sebagai file CSV\n/update_user [username_atau_id] [email|phone|whatsapp] [nilai] - Perbarui informasi user\n/delete_user [username_atau_id] - Hapus user\n/reset_password [username_atau_id] - Kirim password acak baru ke user\n/set_role [username_atau_id] [role] - Ubah role user\n/system_config - Konfigurasi sistem\n/features [nama] [on|off] - Lihat atau ubah feature flag\n/cleanup - Hapus riwayat yang lebih lama dari masa retensi\n/merge_customer [dari_nama] [ke_nama] - Gabungkan order customer duplikat\n/restore_order [order_id] - Pulihkan order yang dihapus (tanpa ID menampilkan order yang dihapus)\n",
+	},
+
+	// Task lists
+	"chat_history_clear_failed": {
+		"en": "❌ Failed to clear chat history: %s",
+		"id": "❌ Gagal menghapus riwayat chat: %s",
+	},
+	"chat_history_cleared": {
+		"en": "✅ Chat history cleared successfully",
+		"id": "✅ Riwayat chat berhasil dihapus",
+	},
+	"chat_history_failed": {
+		"en": "❌ Failed to get chat history: %s",
+		"id": "❌ Gagal mengambil riwayat chat: %s",
+	},
+	"chat_history_empty": {
+		"en": "📝 **Chat History:**\n\nNo chat history found.",
+		"id": "📝 **Riwayat Chat:**\n\nTidak ada riwayat chat.",
+	},
+	"chat_history_header": {
+		"en": "📝 **Chat History (Last %d messages, expires in %d minutes):**\n\n",
+		"id": "📝 **Riwayat Chat (%d pesan terakhir, kedaluwarsa dalam %d menit):**\n\n",
+	},
+	"chat_history_user": {
+		"en": "👤 User",
+		"id": "👤 Anda",
+	},
+	"chat_history_time": {
+		"en": "   Time: %s\n\n",
+		"id": "   Waktu: %s\n\n",
+	},
+	"greeting": {
+		"en": "👋 Hello %s! I'm the AI assistant for Task Manager.\n\nI can help you with:\n• Adding users (Super Admin)\n• Creating orders (Admin)\n• Assigning tasks (Admin)\n• Viewing tasks and orders\n\nTry saying: 'show my tasks' or 'create order John 1000000'",
+		"id": "👋 Halo %s! Saya AI assistant untuk Task Manager.\n\nSaya dapat membantu Anda dengan:\n• Menambah user (Super Admin)\n• Membuat order (Admin)\n• Menugaskan task (Admin)\n• Melihat tasks dan orders\n\nCoba katakan: 'lihat tasks saya' atau 'buat order John 1000000'",
+	},
+	"tasks_failed": {
+		"en": "❌ Failed to get tasks: %s",
+		"id": "❌ Gagal mengambil task: %s",
+	},
+	"my_tasks_empty": {
+		"en": "📝 No tasks assigned to you.",
+		"id": "📝 Belum ada task yang ditugaskan kepada Anda.",
+	},
+	"page_not_found": {
+		"en": "❌ Page %d does not exist (last page is %d)",
+		"id": "❌ Halaman %d tidak ada (halaman terakhir %d)",
+	},
+	"my_tasks_header": {
+		"en": "📝 **Your Tasks:**",
+		"id": "📝 **Task Anda:**",
+	},
+	"tasks_by_priority_usage": {
+		"en": "❌ Usage: /tasks_by_priority [low|medium|high|urgent]",
+		"id": "❌ Penggunaan: /tasks_by_priority [low|medium|high|urgent]",
+	},
+	"tasks_by_status_usage": {
+		"en": "❌ Usage: /tasks_by_status [pending|in_progress|completed|overdue|blocked]",
+		"id": "❌ Penggunaan: /tasks_by_status [pending|in_progress|completed|overdue|blocked]",
+	},
+	"error": {
+		"en": "❌ %s",
+		"id": "❌ %s",
+	},
+	"my_tasks_filter_empty": {
+		"en": "📝 You have no %s tasks.",
+		"id": "📝 Anda tidak punya task %s.",
+	},
+	"my_tasks_filter_header": {
+		"en": "📝 **Your %s Tasks (%d):**",
+		"id": "📝 **Task %s Anda (%d):**",
+	},
+	"search_tasks_usage": {
+		"en": "❌ Usage: /search_tasks [keyword]",
+		"id": "❌ Penggunaan: /search_tasks [kata_kunci]",
+	},
+	"search_tasks_failed": {
+		"en": "❌ Failed to search tasks: %s",
+		"id": "❌ Gagal mencari task: %s",
+	},
+	"search_tasks_empty": {
+		"en": "🔍 No tasks match '%s'.",
+		"id": "🔍 Tidak ada task yang cocok dengan '%s'.",
+	},
+	"search_tasks_header": {
+		"en": "🔍 **Tasks matching '%s' (%d):**",
+		"id": "🔍 **Task yang cocok dengan '%s' (%d):**",
+	},
+	"task_status_pending": {
+		"en": "⏳ Pending",
+		"id": "⏳ Menunggu",
+	},
+	"task_status_in_progress": {
+		"en": "🔄 In Progress",
+		"id": "🔄 Sedang Dikerjakan",
+	},
+	"task_status_completed": {
+		"en": "✅ Completed",
+		"id": "✅ Selesai",
+	},
+	"task_overdue_suffix": {
+		"en": " (⚠️ Overdue)",
+		"id": " (⚠️ Terlambat)",
+	},
+	"task_line_status": {
+		"en": "Status: %s\n",
+		"id": "Status: %s\n",
+	},
+	"task_line_progress": {
+		"en": "Progress: %d%%\n",
+		"id": "Progres: %d%%\n",
+	},
+	"task_line_priority": {
+		"en": "Priority: %s\n",
+		"id": "Prioritas: %s\n",
+	},
+	"task_line_due": {
+		"en": "Due: %s\n",
+		"id": "Tenggat: %s\n",
+	},
+	"task_line_implemented": {
+		"en": "Implemented: %t\n",
+		"id": "Dikerjakan: %t\n",
+	},
+	"assigned_tasks_empty": {
+		"en": "📋 You haven't assigned any tasks yet.",
+		"id": "📋 Anda belum menugaskan task apa pun.",
+	},
+	"assigned_tasks_header": {
+		"en": "📋 **Tasks You Assigned:**\n",
+		"id": "📋 **Task yang Anda Tugaskan:**\n",
+	},
+	"user_tasks_usage": {
+		"en": "❌ Usage: /user_tasks [username_or_id]",
+		"id": "❌ Penggunaan: /user_tasks [username_atau_id]",
+	},
+	"user_not_found_named": {
+		"en": "❌ User not found: %s",
+		"id": "❌ User tidak ditemukan: %s",
+	},
+	"user_tasks_empty": {
+		"en": "📝 No tasks assigned to %s.",
+		"id": "📝 Belum ada task yang ditugaskan kepada %s.",
+	},
+	"user_tasks_header": {
+		"en": "📝 **Tasks for %s:**",
+		"id": "📝 **Task untuk %s:**",
+	},
+	"stats_header": {
+		"en": "📊 **Your Stats:**\n\n",
+		"id": "📊 **Statistik Anda:**\n\n",
+	},
+	"stats_total": {
+		"en": "Total Tasks: %d\n",
+		"id": "Total Task: %d\n",
+	},
+	"stats_completed": {
+		"en": "✅ Completed: %d\n",
+		"id": "✅ Selesai: %d\n",
+	},
+	"stats_in_progress": {
+		"en": "🔄 In Progress: %d\n",
+		"id": "🔄 Sedang Dikerjakan: %d\n",
+	},
+	"stats_pending": {
+		"en": "⏳ Pending: %d\n",
+		"id": "⏳ Menunggu: %d\n",
+	},
+	"stats_average": {
+		"en": "Average Progress: %d%%\n",
+		"id": "Rata-rata Progres: %d%%\n",
+	},
+	"stats_streak": {
+		"en": "\n🔥 %d-day streak!",
+		"id": "\n🔥 Streak %d hari!",
+	},
+	"daily_tasks_failed": {
+		"en": "❌ Failed to get daily tasks: %s",
+		"id": "❌ Gagal mengambil task harian: %s",
+	},
+	"daily_tasks_empty": {
+		"en": "📅 No daily tasks for today.",
+		"id": "📅 Tidak ada task harian untuk hari ini.",
+	},
+	"daily_tasks_header": {
+		"en": "📅 **Today's Daily Tasks:**\n\n",
+		"id": "📅 **Task Harian Hari Ini:**\n\n",
+	},
+	"monthly_tasks_failed": {
+		"en": "❌ Failed to get monthly tasks: %s",
+		"id": "❌ Gagal mengambil task bulanan: %s",
+	},
+	"monthly_tasks_empty": {
+		"en": "📅 No monthly tasks for this month.",
+		"id": "📅 Tidak ada task bulanan untuk bulan ini.",
+	},
+	"monthly_tasks_header": {
+		"en": "📅 **This Month's Tasks:**\n\n",
+		"id": "📅 **Task Bulan Ini:**\n\n",
+	},
+	"weekly_tasks_failed": {
+		"en": "❌ Failed to get weekly tasks: %s",
+		"id": "❌ Gagal mengambil task mingguan: %s",
+	},
+	"weekly_tasks_empty": {
+		"en": "📅 No weekly tasks for %s.",
+		"id": "📅 Tidak ada task mingguan untuk %s.",
+	},
+	"weekly_tasks_header": {
+		"en": "📅 **Weekly Tasks (%s):**\n\n",
+		"id": "📅 **Task Mingguan (%s):**\n\n",
+	},
+	"more_items": {
+		"en": "...and %d more, use pagination",
+		"id": "...dan %d lainnya, gunakan halaman berikutnya",
+	},
+	"page_footer_next": {
+		"en": "Page %d/%d — send '%s %d' for next",
+		"id": "Halaman %d/%d — kirim '%s %d' untuk berikutnya",
+	},
+	"page_footer": {
+		"en": "Page %d/%d",
+		"id": "Halaman %d/%d",
+	},
+
+	// AI create and assign, order search
+	"order_created_ai": {
+		"en": "✅ Order #%d created!\n📦 Order Number: %s\n👤 Customer: %s\n💰 Total: %s\n📅 Date: %s",
+		"id": "✅ Order #%d berhasil dibuat!\n📦 Order Number: %s\n👤 Customer: %s\n💰 Total: %s\n📅 Tanggal: %s",
+	},
+	"order_delivery_line": {
+		"en": "\n🚚 Delivery: %s",
+		"id": "\n🚚 Pengiriman: %s",
+	},
+	"invalid_delivery_date": {
+		"en": "❌ Invalid delivery date: %s",
+		"id": "❌ Delivery date tidak valid: %s",
+	},
+	"rate_out_of_range": {
+		"en": "❌ %s must be between 0 and 100",
+		"id": "❌ %s harus antara 0 dan 100",
+	},
+	"custom_rates": {
+		"en": "\n⚙️ Custom rates: %s",
+		"id": "\n⚙️ Tarif khusus: %s",
+	},
+	"invalid_due_date": {
+		"en": "❌ Invalid due date: %s",
+		"id": "❌ Due date tidak valid: %s",
+	},
+	"assignee_not_found": {
+		"en": "❌ User '%s' not found. Make sure the username is correct.",
+		"id": "❌ User '%s' tidak ditemukan. Pastikan username benar.",
+	},
+	"task_create_failed": {
+		"en": "❌ Failed to create task: %s",
+		"id": "❌ Gagal membuat task: %s",
+	},
+	"task_assigned_ai": {
+		"en": "✅ Task #%d assigned!\n📝 Title: %s\n📄 Description: %s\n👤 Assigned to: %s",
+		"id": "✅ Task #%d berhasil ditugaskan!\n📝 Title: %s\n📄 Description: %s\n👤 Assigned to: %s",
+	},
+	"task_due_line": {
+		"en": "\n📅 Due: %s",
+		"id": "\n📅 Tenggat: %s",
+	},
+	"task_priority_line": {
+		"en": "\n⚡ Priority: %s",
+		"id": "\n⚡ Prioritas: %s",
+	},
+	"create_order_format": {
+		"en": "❌ Invalid format. Use: 'create order [customer_name] [total_amount]'\nExample: 'create order John Doe 1000000'",
+		"id": "❌ Format tidak valid. Gunakan: 'buat order [customer_name] [total_amount]'\nContoh: 'buat order John Doe 1000000'",
+	},
+	"invalid_total_amount": {
+		"en": "❌ Invalid total amount. Use a valid number.",
+		"id": "❌ Total amount tidak valid. Gunakan angka yang benar.",
+	},
+	"order_created_short": {
+		"en": "✅ Order #%d created!\n📦 Customer: %s\n💰 Total: %s\n📅 Date: %s",
+		"id": "✅ Order #%d berhasil dibuat!\n📦 Customer: %s\n💰 Total: %s\n📅 Tanggal: %s",
+	},
+	"assign_task_format": {
+		"en": "❌ Invalid format. Use: 'assign task [title] [description] to [username]'\nExample: 'assign task Update Website Update homepage design to john'",
+		"id": "❌ Format tidak valid. Gunakan: 'tugaskan task [title] [description] to [username]'\nContoh: 'tugaskan task Update Website Update homepage design to john'",
+	},
+	"search_orders_usage": {
+		"en": "❌ Usage: /search_orders [status:pending] [customer:john] [min:100000] [max:500000] [from:2025-01-01] [to:2025-01-31] [page:2]",
+		"id": "❌ Penggunaan: /search_orders [status:pending] [customer:john] [min:100000] [max:500000] [from:2025-01-01] [to:2025-01-31] [page:2]",
+	},
+	"search_orders_failed": {
+		"en": "❌ Failed to search orders: %s",
+		"id": "❌ Gagal mencari orders: %s",
+	},
+	"search_ignored": {
+		"en": "ℹ️ Ignored: %s\n\n",
+		"id": "ℹ️ Diabaikan: %s\n\n",
+	},
+	"search_orders_empty": {
+		"en": "📦 No matching orders.",
+		"id": "📦 Tidak ada order yang cocok.",
+	},
+	"search_orders_header": {
+		"en": "🔎 **Search Results (%d):**",
+		"id": "🔎 **Hasil Pencarian (%d):**",
+	},
+	"search_page_next": {
+		"en": "Page %d/%d — add 'page:%d' for next",
+		"id": "Halaman %d/%d — tambahkan 'page:%d' untuk berikutnya",
+	},
+	"orders_by_status_usage": {
+		"en": "❌ Usage: /orders_by_status [pending|processing|completed|cancelled]",
+		"id": "❌ Penggunaan: /orders_by_status [pending|processing|completed|cancelled]",
+	},
+	"orders_by_status_empty": {
+		"en": "📦 No %s orders found.",
+		"id": "📦 Tidak ada order %s.",
+	},
+	"orders_by_status_header": {
+		"en": "📦 **Orders with status %s (%d):**",
+		"id": "📦 **Order dengan status %s (%d):**",
+	},
+
+	// Order commands
+	"orders_for_customer_usage": {
+		"en": "❌ Usage: /orders_for_customer [name_or_phone]",
+		"id": "❌ Penggunaan: /orders_for_customer [nama_atau_telepon]",
+	},
+	"orders_failed": {
+		"en": "❌ Failed to get orders: %s",
+		"id": "❌ Gagal mengambil orders: %s",
+	},
+	"orders_for_customer_empty": {
+		"en": "📦 No orders found for %s.",
+		"id": "📦 Tidak ada order untuk %s.",
+	},
+	"orders_for_customer_header": {
+		"en": "📦 **Orders for %s (%d):**",
+		"id": "📦 **Order untuk %s (%d):**",
+	},
+	"upcoming_deliveries_usage": {
+		"en": "❌ Usage: /upcoming_deliveries [days]",
+		"id": "❌ Penggunaan: /upcoming_deliveries [hari]",
+	},
+	"deliveries_failed": {
+		"en": "❌ Failed to get deliveries: %s",
+		"id": "❌ Gagal mengambil pengiriman: %s",
+	},
+	"deliveries_empty": {
+		"en": "🚚 No deliveries in the next %d days.",
+		"id": "🚚 Tidak ada pengiriman dalam %d hari ke depan.",
+	},
+	"deliveries_header": {
+		"en": "🚚 **Deliveries in the next %d days (%d):**\n\n",
+		"id": "🚚 **Pengiriman dalam %d hari ke depan (%d):**\n\n",
+	},
+	"order_line_customer": {
+		"en": "Customer: %s\n",
+		"id": "Customer: %s\n",
+	},
+	"order_line_phone": {
+		"en": "Phone: %s\n",
+		"id": "Telepon: %s\n",
+	},
+	"order_line_total": {
+		"en": "Total: %s\n",
+		"id": "Total: %s\n",
+	},
+	"order_line_status": {
+		"en": "Status: %s\n",
+		"id": "Status: %s\n",
+	},
+	"update_order_status_usage": {
+		"en": "❌ Usage: /update_order_status [order_id] [pending|processing|completed]",
+		"id": "❌ Penggunaan: /update_order_status [order_id] [pending|processing|completed]",
+	},
+	"invalid_order_id": {
+		"en": "❌ Invalid order ID",
+		"id": "❌ Order ID tidak valid",
+	},
+	"order_not_found": {
+		"en": "❌ Order #%d not found",
+		"id": "❌ Order #%d tidak ditemukan",
+	},
+	"order_items_failed": {
+		"en": "❌ Failed to load order items: %s",
+		"id": "❌ Gagal memuat item order: %s",
+	},
+	"use_cancel_order": {
+		"en": "❌ Use /cancel_order %d [reason] to cancel an order so its revenue is reversed",
+		"id": "❌ Gunakan /cancel_order %d [alasan] untuk membatalkan order agar pendapatannya dikembalikan",
+	},
+	"order_is_cancelled": {
+		"en": "❌ Order %s is cancelled; its status can no longer change",
+		"id": "❌ Order %s sudah dibatalkan; statusnya tidak dapat diubah lagi",
+	},
+	"order_status_failed": {
+		"en": "❌ Failed to update order status: %s",
+		"id": "❌ Gagal memperbarui status order: %s",
+	},
+	"order_status_updated": {
+		"en": "✅ Order %s status: %s → %s",
+		"id": "✅ Status order %s: %s → %s",
+	},
+	"order_items_completed": {
+		"en": "\n📦 All items marked as done",
+		"id": "\n📦 Semua item ditandai selesai",
+	},
+	"order_no_terminal_item_status": {
+		"en": "❌ The order cannot be completed: no item status is set as done (ORDER_ITEM_TERMINAL_STATUSES).",
+		"id": "❌ Order tidak dapat diselesaikan: belum ada status item yang ditetapkan sebagai selesai (ORDER_ITEM_TERMINAL_STATUSES).",
+	},
+	"cancel_order_usage": {
+		"en": "❌ Usage: /cancel_order [order_id] [reason]",
+		"id": "❌ Penggunaan: /cancel_order [order_id] [alasan]",
+	},
+	"order_already_cancelled": {
+		"en": "ℹ️ Order %s is already cancelled",
+		"id": "ℹ️ Order %s sudah dibatalkan",
+	},
+	"cancel_order_failed": {
+		"en": "❌ Failed to cancel order: %s",
+		"id": "❌ Gagal membatalkan order: %s",
+	},
+	"order_cancelled": {
+		"en": "✅ Order %s cancelled; %s removed from revenue",
+		"id": "✅ Order %s dibatalkan; %s dikeluarkan dari pendapatan",
+	},
+	"order_cancel_reason": {
+		"en": "\n📝 Reason: %s",
+		"id": "\n📝 Alasan: %s",
+	},
+	"merge_customer_usage": {
+		"en": "❌ Usage: /merge_customer [from_name] [to_name] (use 'from name -> to name' for names with spaces)",
+		"id": "❌ Penggunaan: /merge_customer [dari_nama] [ke_nama] (gunakan 'nama asal -> nama tujuan' untuk nama yang mengandung spasi)",
+	},
+	"merge_customer_failed": {
+		"en": "❌ Failed to merge customer: %s",
+		"id": "❌ Gagal menggabungkan customer: %s",
+	},
+	"customer_no_orders": {
+		"en": "ℹ️ No orders found for customer '%s'",
+		"id": "ℹ️ Tidak ada order untuk customer '%s'",
+	},
+	"customer_merged": {
+		"en": "✅ Merged customer '%s' into '%s' (%d order(s) updated)",
+		"id": "✅ Customer '%s' digabungkan ke '%s' (%d order diperbarui)",
+	},
+	"order_access_denied": {
+		"en": "❌ You don't have access to this order.",
+		"id": "❌ Anda tidak memiliki akses ke order ini.",
+	},
+	"order_detail_usage": {
+		"en": "❌ Usage: /order_detail [order_id]",
+		"id": "❌ Penggunaan: /order_detail [order_id]",
+	},
+	"order_detail_date": {
+		"en": "Date: %s\n",
+		"id": "Tanggal: %s\n",
+	},
+	"order_detail_delivery": {
+		"en": "Delivery: %s\n",
+		"id": "Pengiriman: %s\n",
+	},
+	"order_detail_net_profit": {
+		"en": "Net Profit: %s\n",
+		"id": "Laba Bersih: %s\n",
+	},
+	"order_items_get_failed": {
+		"en": "❌ Failed to get order items: %s",
+		"id": "❌ Gagal mengambil item order: %s",
+	},
+	"order_detail_items": {
+		"en": "\n🛒 **Items:**\n",
+		"id": "\n🛒 **Item:**\n",
+	},
+	"order_notes_failed": {
+		"en": "❌ Failed to get order notes: %s",
+		"id": "❌ Gagal mengambil catatan order: %s",
+	},
+	"order_detail_notes": {
+		"en": "\n📝 **Notes:**\n",
+		"id": "\n📝 **Catatan:**\n",
+	},
+	"order_note_usage": {
+		"en": "❌ Usage: /order_note [order_id] [text]",
+		"id": "❌ Penggunaan: /order_note [order_id] [teks]",
+	},
+	"order_note_failed": {
+		"en": "❌ Failed to add note: %s",
+		"id": "❌ Gagal menambah catatan: %s",
+	},
+	"order_note_added": {
+		"en": "📝 Note added to order #%d. Use /order_detail %d to see all notes.",
+		"id": "📝 Catatan ditambahkan ke order #%d. Gunakan /order_detail %d untuk melihat semua catatan.",
+	},
+	"order_history_usage": {
+		"en": "❌ Usage: /order_history [order_id]",
+		"id": "❌ Penggunaan: /order_history [order_id]",
+	},
+	"order_not_found_dot": {
+		"en": "❌ Order #%d not found.",
+		"id": "❌ Order #%d tidak ditemukan.",
+	},
+	"calculation_history_failed": {
+		"en": "❌ Failed to get calculation history: %s",
+		"id": "❌ Gagal mengambil riwayat kalkulasi: %s",
+	},
+	"calculation_history_empty": {
+		"en": "🧮 Order #%d has no calculation history.",
+		"id": "🧮 Order #%d belum memiliki riwayat kalkulasi.",
+	},
+	"calculation_history_header": {
+		"en": "🧮 **Calculation History - Order #%d (%s)**\n\n",
+		"id": "🧮 **Riwayat Kalkulasi - Order #%d (%s)**\n\n",
+	},
+	"calculation_fixed": {
+		"en": " + %s fixed",
+		"id": " + %s tetap",
+	},
+	"calculation_line": {
+		"en": "Input: %s | Rate: %s | Result: %s\n",
+		"id": "Input: %s | Tarif: %s | Hasil: %s\n",
+	},
+	"calculation_previous": {
+		"en": "Previous net profit: %s\n",
+		"id": "Laba bersih sebelumnya: %s\n",
+	},
+	"task_progress_history_usage": {
+		"en": "❌ Usage: /task_progress_history [task_id]",
+		"id": "❌ Penggunaan: /task_progress_history [task_id]",
+	},
+	"invalid_task_id": {
+		"en": "❌ Invalid task ID",
+		"id": "❌ Task ID tidak valid",
+	},
+	"task_not_found_dot": {
+		"en": "❌ Task #%d not found.",
+		"id": "❌ Task #%d tidak ditemukan.",
+	},
+	"progress_history_denied": {
+		"en": "❌ You can only view the progress history of your own tasks.",
+		"id": "❌ Anda hanya dapat melihat riwayat progres task Anda sendiri.",
+	},
+	"progress_history_failed": {
+		"en": "❌ Failed to get progress history: %s",
+		"id": "❌ Gagal mengambil riwayat progres: %s",
+	},
+	"progress_history_empty": {
+		"en": "📈 Task #%d has no progress updates yet.",
+		"id": "📈 Task #%d belum memiliki pembaruan progres.",
+	},
+	"progress_history_header": {
+		"en": "📈 **Progress History - Task #%d (%s)**\n\n",
+		"id": "📈 **Riwayat Progres - Task #%d (%s)**\n\n",
+	},
+	"progress_history_line": {
+		"en": "[%s] %d%% by %s",
+		"id": "[%s] %d%% oleh %s",
+	},
+	"customer_summary_usage": {
+		"en": "❌ Usage: /customer_summary [customer_name]",
+		"id": "❌ Penggunaan: /customer_summary [nama_customer]",
+	},
+	"customer_summary_failed": {
+		"en": "❌ Failed to get customer summary: %s",
+		"id": "❌ Gagal mengambil ringkasan customer: %s",
+	},
+	"customer_summary_empty": {
+		"en": "📦 No orders found for customer '%s'",
+		"id": "📦 Tidak ada order untuk customer '%s'",
+	},
+	"customer_summary": {
+		"en": "👤 **Customer Summary: %s**\n\nOrders: %d\n",
+		"id": "👤 **Ringkasan Customer: %s**\n\nOrder: %d\n",
+	},
+	"customer_summary_cancelled": {
+		"en": "Cancelled: %d (not included in totals)\n",
+		"id": "Dibatalkan: %d (tidak termasuk dalam total)\n",
+	},
+	"customer_summary_totals": {
+		"en": "Total Revenue: %s\nTotal Net Profit: %s\nAverage Order Value: %s",
+		"id": "Total Pendapatan: %s\nTotal Laba Bersih: %s\nRata-rata Nilai Order: %s",
+	},
+	"deleted_orders_failed": {
+		"en": "❌ Failed to get deleted orders: %s",
+		"id": "❌ Gagal mengambil order yang dihapus: %s",
+	},
+	"deleted_orders_empty": {
+		"en": "🗑️ No deleted orders.",
+		"id": "🗑️ Tidak ada order yang dihapus.",
+	},
+	"deleted_orders_header": {
+		"en": "🗑️ **Deleted Orders:**",
+		"id": "🗑️ **Order yang Dihapus:**",
+	},
+	"deleted_orders_hint": {
+		"en": "Use /restore_order [order_id] to restore one.",
+		"id": "Gunakan /restore_order [order_id] untuk memulihkan salah satunya.",
+	},
+	"order_not_deleted": {
+		"en": "❌ No deleted order #%d",
+		"id": "❌ Tidak ada order terhapus #%d",
+	},
+	"restore_order_failed": {
+		"en": "❌ Failed to restore order: %s",
+		"id": "❌ Gagal memulihkan order: %s",
+	},
+	"order_restored": {
+		"en": "♻️ Order #%d restored",
+		"id": "♻️ Order #%d dipulihkan",
+	},
+	"my_orders_header": {
+		"en": "📦 **My Orders** (created by you):",
+		"id": "📦 **Order Saya** (dibuat oleh Anda):",
+	},
+	"all_orders_header": {
+		"en": "📦 **All Orders** (every user):",
+		"id": "📦 **Semua Order** (semua user):",
+	},
+	"my_orders_empty": {
+		"en": "📦 No orders related to you.",
+		"id": "📦 Tidak ada order yang terkait dengan Anda.",
+	},
+
+	// Task updates, undo and reports
+	"update_progress_usage": {
+		"en": "❌ Usage: /update_progress [task_id] [percentage]",
+		"id": "❌ Penggunaan: /update_progress [task_id] [persentase]",
+	},
+	"invalid_progress": {
+		"en": "❌ Invalid progress percentage (0-100)",
+		"id": "❌ Persentase progres tidak valid (0-100)",
+	},
+	"task_not_found": {
+		"en": "❌ Task #%d not found",
+		"id": "❌ Task #%d tidak ditemukan",
+	},
+	"task_not_yours": {
+		"en": "❌ You can only update tasks assigned to you",
+		"id": "❌ Anda hanya dapat memperbarui task yang ditugaskan kepada Anda",
+	},
+	"update_progress_failed": {
+		"en": "❌ Failed to update progress: %s",
+		"id": "❌ Gagal memperbarui progres: %s",
+	},
+	"progress_updated": {
+		"en": "✅ Task progress updated to %d%%",
+		"id": "✅ Progres task diperbarui menjadi %d%%",
+	},
+	"mark_complete_usage": {
+		"en": "❌ Usage: /mark_complete [task_id]",
+		"id": "❌ Penggunaan: /mark_complete [task_id]",
+	},
+	"mark_complete_failed": {
+		"en": "❌ Failed to mark task as complete: %s",
+		"id": "❌ Gagal menandai task selesai: %s",
+	},
+	"task_implemented": {
+		"en": "✅ Task marked as implemented",
+		"id": "✅ Task ditandai sudah dikerjakan",
+	},
+	"nothing_to_undo": {
+		"en": "ℹ️ Nothing to undo. Only your last action from the past %d minutes can be undone.",
+		"id": "ℹ️ Tidak ada yang dapat dibatalkan. Hanya aksi terakhir Anda dalam %d menit terakhir yang dapat dibatalkan.",
+	},
+	"undo_failed": {
+		"en": "❌ Failed to undo: %s",
+		"id": "❌ Gagal membatalkan: %s",
+	},
+	"undo_order_created": {
+		"en": "↩️ Order #%d creation undone, the order was deleted",
+		"id": "↩️ Pembuatan order #%d dibatalkan, order telah dihapus",
+	},
+	"undo_order_status": {
+		"en": "↩️ Order #%d status restored to %s",
+		"id": "↩️ Status order #%d dikembalikan ke %s",
+	},
+	"undo_task_progress": {
+		"en": "↩️ Task #%d progress restored to %d%%",
+		"id": "↩️ Progres task #%d dikembalikan ke %d%%",
+	},
+	"undo_task_status": {
+		"en": "↩️ Task #%d restored to %s (%d%%)",
+		"id": "↩️ Task #%d dikembalikan ke %s (%d%%)",
+	},
+	"delete_task_usage": {
+		"en": "❌ Usage: /delete_task [task_id]",
+		"id": "❌ Penggunaan: /delete_task [task_id]",
+	},
+	"delete_task_denied": {
+		"en": "❌ You don't have permission to delete this task. Only its creator or an Admin can delete it.",
+		"id": "❌ Anda tidak memiliki izin untuk menghapus task ini. Hanya pembuatnya atau Admin yang dapat menghapusnya.",
+	},
+	"delete_task_failed": {
+		"en": "❌ Failed to delete task: %s",
+		"id": "❌ Gagal menghapus task: %s",
+	},
+	"task_deleted": {
+		"en": "🗑️ Task #%d '%s' deleted",
+		"id": "🗑️ Task #%d '%s' dihapus",
+	},
+	"task_id_usage": {
+		"en": "❌ Usage: %s [task_id]",
+		"id": "❌ Penggunaan: %s [task_id]",
+	},
+	"task_status_failed": {
+		"en": "❌ Failed to update task status: %s",
+		"id": "❌ Gagal memperbarui status task: %s",
+	},
+	"task_started": {
+		"en": "🔄 Task #%d is now in progress",
+		"id": "🔄 Task #%d sekarang sedang dikerjakan",
+	},
+	"task_blocked": {
+		"en": "⛔ Task #%d is marked as blocked",
+		"id": "⛔ Task #%d ditandai terhambat",
+	},
+	"task_status_set": {
+		"en": "✅ Task #%d status set to %s",
+		"id": "✅ Status task #%d diubah menjadi %s",
+	},
+	"report_by_date_usage": {
+		"en": "❌ Usage: /report_by_date [start_date] [end_date] [all] (format: YYYY-MM-DD, or e.g. 'awal bulan sampai hari ini'; 'all' includes cancelled orders)",
+		"id": "❌ Penggunaan: /report_by_date [tanggal_mulai] [tanggal_akhir] [all] (format: YYYY-MM-DD, atau mis. 'awal bulan sampai hari ini'; 'all' menyertakan order yang dibatalkan)",
+	},
+	"report_empty": {
+		"en": "📊 No orders found for the specified date range.",
+		"id": "📊 Tidak ada order dalam rentang tanggal tersebut.",
+	},
+	"report_header": {
+		"en": "📊 **Report for %s to %s:**\n\n",
+		"id": "📊 **Laporan %s sampai %s:**\n\n",
+	},
+	"report_totals": {
+		"en": "Total Orders: %d\nTotal Amount: %s\nNet Profit: %s\n",
+		"id": "Total Order: %d\nTotal Nilai: %s\nLaba Bersih: %s\n",
+	},
+	"report_including_cancelled": {
+		"en": "Including Cancelled Orders: %d\n",
+		"id": "Termasuk Order Dibatalkan: %d\n",
+	},
+	"report_excluding_cancelled": {
+		"en": "Cancelled orders excluded (add 'all' to include them)\n",
+		"id": "Order yang dibatalkan tidak dihitung (tambahkan 'all' untuk menyertakannya)\n",
+	},
+	"task_report_usage": {
+		"en": "❌ Usage: /task_report [start_date] [end_date] (format: YYYY-MM-DD, or e.g. 'awal bulan sampai hari ini')",
+		"id": "❌ Penggunaan: /task_report [tanggal_mulai] [tanggal_akhir] (format: YYYY-MM-DD, atau mis. 'awal bulan sampai hari ini')",
+	},
+	"period": {
+		"en": "%s to %s",
+		"id": "%s sampai %s",
+	},
+	"task_report_empty": {
+		"en": "📊 No tasks were completed from %s.",
+		"id": "📊 Tidak ada task yang selesai pada %s.",
+	},
+	"task_report_header": {
+		"en": "📊 **Task Report for %s:**\n\nCompleted Tasks: %d\n",
+		"id": "📊 **Laporan Task %s:**\n\nTask Selesai: %d\n",
+	},
+	"task_report_average": {
+		"en": "Average Time to Complete: %s\n",
+		"id": "Rata-rata Waktu Penyelesaian: %s\n",
+	},
+	"elapsed_days": {
+		"en": "%dd %dh",
+		"id": "%dh %dj",
+	},
+	"elapsed_hours": {
+		"en": "%dh %dm",
+		"id": "%dj %dm",
+	},
+	"report_history_failed": {
+		"en": "❌ Failed to get report history: %s",
+		"id": "❌ Gagal mengambil riwayat laporan: %s",
+	},
+	"report_history_empty": {
+		"en": "📊 You haven't generated any reports yet. Try /report_by_date.",
+		"id": "📊 Anda belum membuat laporan apa pun. Coba /report_by_date.",
+	},
+	"report_history_header": {
+		"en": "📊 **Your Recent Reports:**\n\n",
+		"id": "📊 **Laporan Terbaru Anda:**\n\n",
+	},
+	"report_history_period": {
+		"en": " %s to %s",
+		"id": " %s sampai %s",
+	},
+	"report_history_line": {
+		"en": "Orders: %d | Total: %s\n",
+		"id": "Order: %d | Total: %s\n",
+	},
+
+	// Admin lists, invoices and exports
+	"add_user_usage": {
+		"en": "❌ Usage: /add_user [username] [email] [phone] [role]",
+		"id": "❌ Penggunaan: /add_user [username] [email] [telepon] [role]",
+	},
+	"invalid_role_named": {
+		"en": "❌ Invalid role: %s. Use SuperAdmin, Admin or User.",
+		"id": "❌ Role tidak valid: %s. Gunakan SuperAdmin, Admin, atau User.",
+	},
+	"create_user_failed": {
+		"en": "❌ Failed to create user: %s",
+		"id": "❌ Gagal membuat user: %s",
+	},
+	"user_created": {
+		"en": "✅ User created successfully",
+		"id": "✅ User berhasil dibuat",
+	},
+	"users_failed": {
+		"en": "❌ Failed to get users: %s",
+		"id": "❌ Gagal mengambil user: %s",
+	},
+	"users_empty": {
+		"en": "👥 No users found.",
+		"id": "👥 Tidak ada user.",
+	},
+	"users_header": {
+		"en": "👥 **All Users:**\n\n",
+		"id": "👥 **Semua User:**\n\n",
+	},
+	"user_line": {
+		"en": "📱 Phone: %s\nRole: %s\nStatus: %s\n",
+		"id": "📱 Telepon: %s\nRole: %s\nStatus: %s\n",
+	},
+	"user_status_active": {
+		"en": "✅ Active",
+		"id": "✅ Aktif",
+	},
+	"user_status_inactive": {
+		"en": "❌ Inactive",
+		"id": "❌ Tidak aktif",
+	},
+	"all_tasks_header": {
+		"en": "📝 **All Tasks:**\n\n",
+		"id": "📝 **Semua Task:**\n\n",
+	},
+	"all_tasks_empty": {
+		"en": "📝 **All Tasks:**\n\nNo tasks found.",
+		"id": "📝 **Semua Task:**\n\nTidak ada task.",
+	},
+	"task_status_pending_marked": {
+		"en": "❌ Pending",
+		"id": "❌ Menunggu",
+	},
+	"task_status_overdue": {
+		"en": "⚠️ Overdue",
+		"id": "⚠️ Terlambat",
+	},
+	"priority_medium": {
+		"en": "🟡 Medium",
+		"id": "🟡 Sedang",
+	},
+	"priority_high": {
+		"en": "🔴 High",
+		"id": "🔴 Tinggi",
+	},
+	"priority_low": {
+		"en": "🟢 Low",
+		"id": "🟢 Rendah",
+	},
+	"priority_urgent": {
+		"en": "🚨 Urgent",
+		"id": "🚨 Mendesak",
+	},
+	"task_not_implemented": {
+		"en": "❌ Not Implemented",
+		"id": "❌ Belum Dikerjakan",
+	},
+	"task_is_implemented": {
+		"en": "✅ Implemented",
+		"id": "✅ Dikerjakan",
+	},
+	"task_line_description": {
+		"en": "Description: %s\n",
+		"id": "Deskripsi: %s\n",
+	},
+	"task_line_assigned_to": {
+		"en": "Assigned To: %s\n",
+		"id": "Ditugaskan ke: %s\n",
+	},
+	"task_line_implemented_label": {
+		"en": "Implemented: %s\n",
+		"id": "Dikerjakan: %s\n",
+	},
+	"task_line_due_date": {
+		"en": "Due Date: %s\n",
+		"id": "Tenggat: %s\n",
+	},
+	"task_line_completed": {
+		"en": "Completed: %s\n",
+		"id": "Selesai: %s\n",
+	},
+	"create_order_usage": {
+		"en": "❌ Usage: /create_order [customer_name] [total_amount] [customer_phone] [tax=%] [marketing=%] [rental=%]",
+		"id": "❌ Penggunaan: /create_order [nama_customer] [total] [telepon_customer] [tax=%] [marketing=%] [rental=%]",
+	},
+	"invalid_total": {
+		"en": "❌ Invalid total amount",
+		"id": "❌ Total amount tidak valid",
+	},
+	"orders_empty": {
+		"en": "📦 No orders found.",
+		"id": "📦 Tidak ada order.",
+	},
+	"invoice_usage": {
+		"en": "❌ Usage: /invoice [order_id]",
+		"id": "❌ Penggunaan: /invoice [order_id]",
+	},
+	"invoice_failed": {
+		"en": "❌ Failed to generate invoice: %s",
+		"id": "❌ Gagal membuat invoice: %s",
+	},
+	"invoice_send_failed": {
+		"en": "❌ Failed to send invoice: %s",
+		"id": "❌ Gagal mengirim invoice: %s",
+	},
+	"invoice_sent": {
+		"en": "✅ Invoice for order %s sent",
+		"id": "✅ Invoice untuk order %s terkirim",
+	},
+	"export_failed": {
+		"en": "❌ Failed to export tasks: %s",
+		"id": "❌ Gagal mengekspor task: %s",
+	},
+	"export_send_failed": {
+		"en": "❌ Failed to send export: %s",
+		"id": "❌ Gagal mengirim ekspor: %s",
+	},
+	"export_sent": {
+		"en": "✅ Task export sent",
+		"id": "✅ Ekspor task terkirim",
+	},
+	"export_caption": {
+		"en": "📊 Task export",
+		"id": "📊 Ekspor task",
+	},
+
+	// Task assignment
+	"assign_task_usage": {
+		"en": "❌ Usage: /assign_task [username_or_id] [title] [description] [due:YYYY-MM-DD] [priority:low|medium|high|urgent]",
+		"id": "❌ Penggunaan: /assign_task [username_atau_id] [judul] [deskripsi] [due:YYYY-MM-DD] [priority:low|medium|high|urgent]",
+	},
+	"task_assigned": {
+		"en": "✅ Task #%d assigned successfully",
+		"id": "✅ Task #%d berhasil ditugaskan",
+	},
+	"assign_task_bulk_usage": {
+		"en": "❌ Usage: /assign_task_bulk [title] | [description] | [user1,user2,user3]",
+		"id": "❌ Penggunaan: /assign_task_bulk [judul] | [deskripsi] | [user1,user2,user3]",
+	},
+	"bulk_none_assigned": {
+		"en": "❌ No task created, none of the users could be assigned:\n- %s",
+		"id": "❌ Tidak ada task yang dibuat, tidak ada user yang dapat ditugaskan:\n- %s",
+	},
+	"bulk_create_failed": {
+		"en": "❌ Failed to create tasks, nothing was assigned: %s",
+		"id": "❌ Gagal membuat task, tidak ada yang ditugaskan: %s",
+	},
+	"bulk_assigned": {
+		"en": "✅ Task '%s' assigned to %d user(s): %s",
+		"id": "✅ Task '%s' ditugaskan ke %d user: %s",
+	},
+	"bulk_skipped": {
+		"en": "\n⚠️ Skipped:\n- %s",
+		"id": "\n⚠️ Dilewati:\n- %s",
+	},
+	"create_daily_task_usage": {
+		"en": "❌ Usage: /create_daily_task [username_or_id] [title] [description]",
+		"id": "❌ Penggunaan: /create_daily_task [username_atau_id] [judul] [deskripsi]",
+	},
+	"create_daily_task_failed": {
+		"en": "❌ Failed to create daily task: %s",
+		"id": "❌ Gagal membuat task harian: %s",
+	},
+	"daily_task_created": {
+		"en": "✅ Daily task created successfully",
+		"id": "✅ Task harian berhasil dibuat",
+	},
+	"create_weekly_task_usage": {
+		"en": "❌ Usage: /create_weekly_task [username_or_id] [title] [description]",
+		"id": "❌ Penggunaan: /create_weekly_task [username_atau_id] [judul] [deskripsi]",
+	},
+	"create_weekly_task_failed": {
+		"en": "❌ Failed to create weekly task: %s",
+		"id": "❌ Gagal membuat task mingguan: %s",
+	},
+	"weekly_task_created": {
+		"en": "✅ Weekly task '%s' created for %s",
+		"id": "✅ Task mingguan '%s' dibuat untuk %s",
+	},
+	"create_monthly_task_usage": {
+		"en": "❌ Usage: /create_monthly_task [username_or_id] [title] [description]",
+		"id": "❌ Penggunaan: /create_monthly_task [username_atau_id] [judul] [deskripsi]",
+	},
+	"create_monthly_task_failed": {
+		"en": "❌ Failed to create monthly task: %s",
+		"id": "❌ Gagal membuat task bulanan: %s",
+	},
+	"monthly_task_created": {
+		"en": "✅ Monthly task created successfully",
+		"id": "✅ Task bulanan berhasil dibuat",
+	},
+
+	// User administration
+	"delete_user_usage": {
+		"en": "❌ Usage: /delete_user [username_or_id]",
+		"id": "❌ Penggunaan: /delete_user [username_atau_id]",
+	},
+	"delete_self": {
+		"en": "❌ You cannot delete yourself.",
+		"id": "❌ Anda tidak dapat menghapus diri sendiri.",
+	},
+	"super_admin_check_failed": {
+		"en": "❌ Failed to check Super Admins: %s",
+		"id": "❌ Gagal memeriksa Super Admin: %s",
+	},
+	"delete_last_super_admin": {
+		"en": "❌ Cannot delete the last Super Admin.",
+		"id": "❌ Tidak dapat menghapus Super Admin terakhir.",
+	},
+	"delete_user_failed": {
+		"en": "❌ Failed to delete user: %s",
+		"id": "❌ Gagal menghapus user: %s",
+	},
+	"user_deleted": {
+		"en": "✅ User %s (ID: %d) deleted",
+		"id": "✅ User %s (ID: %d) dihapus",
+	},
+	"reset_password_usage": {
+		"en": "❌ Usage: /reset_password [username_or_id]",
+		"id": "❌ Penggunaan: /reset_password [username_atau_id]",
+	},
+	"reset_password_no_whatsapp": {
+		"en": "❌ %s has no WhatsApp number to send the new password to.",
+		"id": "❌ %s tidak memiliki nomor WhatsApp untuk menerima password baru.",
+	},
+	"reset_password_failed": {
+		"en": "❌ Failed to reset password: %s",
+		"id": "❌ Gagal mereset password: %s",
+	},
+	"password_reset_message": {
+		"en": "🔐 Your password has been reset.\n👤 Username: %s\n🔑 New password: %s",
+		"id": "🔐 Password Anda telah direset.\n👤 Username: %s\n🔑 Password baru: %s",
+	},
+	"password_not_delivered": {
+		"en": "⚠️ Password for %s was reset but could not be delivered. Run /reset_password again.",
+		"id": "⚠️ Password %s sudah direset tetapi gagal dikirim. Jalankan /reset_password lagi.",
+	},
+	"password_sent": {
+		"en": "✅ New password sent to %s via WhatsApp",
+		"id": "✅ Password baru dikirim ke %s melalui WhatsApp",
+	},
+	"update_user_usage": {
+		"en": "❌ Usage: /update_user [username_or_id] [email|phone|whatsapp] [value]",
+		"id": "❌ Penggunaan: /update_user [username_atau_id] [email|phone|whatsapp] [nilai]",
+	},
+	"invalid_email": {
+		"en": "❌ Invalid email address: %s",
+		"id": "❌ Alamat email tidak valid: %s",
+	},
+	"unknown_user_field": {
+		"en": "❌ Unknown field: %s. Use email, phone or whatsapp.",
+		"id": "❌ Field tidak dikenal: %s. Gunakan email, phone, atau whatsapp.",
+	},
+	"update_user_failed": {
+		"en": "❌ Failed to update user: %s",
+		"id": "❌ Gagal memperbarui user: %s",
+	},
+	"user_updated": {
+		"en": "✅ Updated %s of %s\nBefore: %s\nAfter: %s",
+		"id": "✅ %s milik %s diperbarui\nSebelum: %s\nSesudah: %s",
+	},
+	"set_role_usage": {
+		"en": "❌ Usage: /set_role [username_or_id] [SuperAdmin|Admin|User]",
+		"id": "❌ Penggunaan: /set_role [username_atau_id] [SuperAdmin|Admin|User]",
+	},
+	"role_unchanged": {
+		"en": "ℹ️ %s already has role %s",
+		"id": "ℹ️ %s sudah memiliki role %s",
+	},
+	"demote_last_super_admin": {
+		"en": "❌ Cannot demote the last Super Admin.",
+		"id": "❌ Tidak dapat menurunkan Super Admin terakhir.",
+	},
+	"update_role_failed": {
+		"en": "❌ Failed to update role: %s",
+		"id": "❌ Gagal memperbarui role: %s",
+	},
+	"role_changed": {
+		"en": "✅ Role of %s changed: %s → %s",
+		"id": "✅ Role %s diubah: %s → %s",
+	},
+	"transfer_tasks_usage": {
+		"en": "❌ Usage: /transfer_tasks [from_username_or_id] [to_username_or_id]",
+		"id": "❌ Penggunaan: /transfer_tasks [dari_username_atau_id] [ke_username_atau_id]",
+	},
+	"transfer_same_user": {
+		"en": "❌ Source and target user must be different.",
+		"id": "❌ User asal dan tujuan harus berbeda.",
+	},
+	"transfer_failed": {
+		"en": "❌ Failed to transfer tasks: %s",
+		"id": "❌ Gagal memindahkan task: %s",
+	},
+	"tasks_transferred_notice": {
+		"en": "📋 %d task(s) from %s have been transferred to you by %s. Use /my_tasks to see them.",
+		"id": "📋 %d task dari %s telah dipindahkan kepada Anda oleh %s. Gunakan /my_tasks untuk melihatnya.",
+	},
+	"tasks_transferred": {
+		"en": "✅ Transferred %d open task(s) from %s to %s",
+		"id": "✅ %d task terbuka dipindahkan dari %s ke %s",
+	},
+	"clone_task_usage": {
+		"en": "❌ Usage: /clone_task [task_id] [username_or_id]",
+		"id": "❌ Penggunaan: /clone_task [task_id] [username_atau_id]",
+	},
+	"clone_task_failed": {
+		"en": "❌ Failed to clone task: %s",
+		"id": "❌ Gagal menyalin task: %s",
+	},
+	"task_cloned": {
+		"en": "✅ Task #%d cloned as task #%d\n📝 Title: %s\n👤 Assigned to user #%d",
+		"id": "✅ Task #%d disalin sebagai task #%d\n📝 Judul: %s\n👤 Ditugaskan ke user #%d",
+	},
+	"cleanup_failed": {
+		"en": "❌ Cleanup failed: %s",
+		"id": "❌ Pembersihan gagal: %s",
+	},
+	"cleanup_done": {
+		"en": "🧹 Cleanup complete\nTask progress: %d removed\nCalculation history: %d removed\nReport queries: %d removed",
+		"id": "🧹 Pembersihan selesai\nProgres task: %d dihapus\nRiwayat kalkulasi: %d dihapus\nQuery laporan: %d dihapus",
+	},
+
+	// Order items and reminders
+	"features_usage": {
+		"en": "❌ Usage: /features [name] [on|off]",
+		"id": "❌ Penggunaan: /features [nama] [on|off]",
+	},
+	"feature_update_failed": {
+		"en": "❌ Failed to update feature: %s",
+		"id": "❌ Gagal memperbarui fitur: %s",
+	},
+	"features_header": {
+		"en": "🚩 **Feature Flags:**\n\n",
+		"id": "🚩 **Feature Flag:**\n\n",
+	},
+	"feature_on": {
+		"en": "✅ On",
+		"id": "✅ Aktif",
+	},
+	"feature_off": {
+		"en": "❌ Off",
+		"id": "❌ Nonaktif",
+	},
+	"features_hint": {
+		"en": "\nToggle with /features [name] [on|off]",
+		"id": "\nUbah dengan /features [nama] [on|off]",
+	},
+	"order_with_item_failed": {
+		"en": "❌ Failed to create order with item: %s",
+		"id": "❌ Gagal membuat order dengan item: %s",
+	},
+	"order_with_item_created": {
+		"en": "✅ Order #%d with item created!\n📦 Order Number: %s\n👤 Customer: %s\n💰 Total: %s\n🛒 Item: %s\n   Qty: %d x %s = %s\n📅 Date: %s",
+		"id": "✅ Order #%d dengan item berhasil dibuat!\n📦 Order Number: %s\n👤 Customer: %s\n💰 Total: %s\n🛒 Item: %s\n   Qty: %d x %s = %s\n📅 Tanggal: %s",
+	},
+	"add_item_failed": {
+		"en": "❌ Failed to add item: %s",
+		"id": "❌ Gagal menambah item: %s",
+	},
+	"item_added": {
+		"en": "✅ Item added to order #%d!\n🛒 Item: %s\n   Qty: %d x %s = %s",
+		"id": "✅ Item berhasil ditambahkan ke order #%d!\n🛒 Item: %s\n   Qty: %d x %s = %s",
+	},
+	"order_items_access_denied": {
+		"en": "❌ You don't have access to view this order.",
+		"id": "❌ Anda tidak memiliki akses untuk melihat order ini.",
+	},
+	"items_failed": {
+		"en": "❌ Failed to get items: %s",
+		"id": "❌ Gagal mengambil items: %s",
+	},
+	"order_items_empty": {
+		"en": "🛒 Order #%d has no items yet.",
+		"id": "🛒 Order #%d belum memiliki item.",
+	},
+	"order_items_header": {
+		"en": "🛒 **Items of Order #%d (%s):**\n\n",
+		"id": "🛒 **Items Order #%d (%s):**\n\n",
+	},
+	"item_line_description": {
+		"en": "Description: %s\n",
+		"id": "Deskripsi: %s\n",
+	},
+	"order_items_total": {
+		"en": "💰 Total Items: %s",
+		"id": "💰 Total Item: %s",
+	},
+	"order_items_mismatch": {
+		"en": "\n⚠️ Order total is %s, which does not match its items",
+		"id": "\n⚠️ Total order %s tidak sesuai dengan item-itemnya",
+	},
+	"invalid_reminder_time": {
+		"en": "❌ Invalid time format. Use: YYYY-MM-DD HH:MM (e.g. 2025-10-05 10:00) or 'tomorrow at 9'",
+		"id": "❌ Format waktu tidak valid. Gunakan format: YYYY-MM-DD HH:MM (contoh: 2025-10-05 10:00) atau 'besok jam 9'",
+	},
+	"reminder_in_past": {
+		"en": "❌ The reminder time has already passed. Use a time in the future.",
+		"id": "❌ Waktu reminder sudah lewat. Gunakan waktu di masa depan.",
+	},
+	"reminder_not_your_task": {
+		"en": "❌ You can only create reminders for tasks assigned to you.",
+		"id": "❌ Anda hanya dapat membuat reminder untuk task yang ditugaskan kepada Anda.",
+	},
+	"reminder_create_failed": {
+		"en": "❌ Failed to create reminder: %s",
+		"id": "❌ Gagal membuat reminder: %s",
+	},
+	"reminder_created": {
+		"en": "✅ Reminder created!\n📝 Task: #%d %s\n🔔 Type: %s\n⏰ Scheduled: %s",
+		"id": "✅ Reminder berhasil dibuat!\n📝 Task: #%d %s\n🔔 Type: %s\n⏰ Scheduled: %s",
+	},
+	"reminder_repeats": {
+		"en": "\n🔁 Repeats: %s",
+		"id": "\n🔁 Berulang: %s",
+	},
+	"reminders_failed": {
+		"en": "❌ Failed to get reminders: %s",
+		"id": "❌ Gagal mengambil daftar reminders: %s",
+	},
+	"reminders_empty": {
+		"en": "🔔 No reminders for your tasks.",
+		"id": "🔔 Tidak ada reminder untuk task Anda.",
+	},
+	"reminders_header": {
+		"en": "🔔 **Reminders:**\n\n",
+		"id": "🔔 **Daftar Reminders:**\n\n",
+	},
+	"reminder_sent": {
+		"en": "✅ Sent",
+		"id": "✅ Terkirim",
+	},
+	"reminder_not_sent": {
+		"en": "❌ Not Sent",
+		"id": "❌ Belum Terkirim",
+	},
+	"reminder_line": {
+		"en": "🔔 Type: %s\n⏰ Scheduled: %s\n",
+		"id": "🔔 Type: %s\n⏰ Scheduled: %s\n",
+	},
+	"reminder_repeats_line": {
+		"en": "🔁 Repeats: %s\n",
+		"id": "🔁 Berulang: %s\n",
+	},
+	"update_progress_hint": {
+		"en": "🔄 To update a task's progress, use:\n/update_progress [task_id] [percentage]\n\nExample: /update_progress 1 75",
+		"id": "🔄 Untuk mengupdate progress task, gunakan format:\n/update_progress [task_id] [percentage]\n\nContoh: /update_progress 1 75",
+	},
+	"mark_complete_hint": {
+		"en": "✅ To mark a task as done, use:\n/mark_complete [task_id]\n\nExample: /mark_complete 1",
+		"id": "✅ Untuk menandai task sebagai selesai, gunakan format:\n/mark_complete [task_id]\n\nContoh: /mark_complete 1",
+	},
+	"my_report_hint": {
+		"en": "📊 To see your personal report, use:\n/my_report\n\nOr for a report by date:\n/report_by_date [start_date] [end_date]\n\nExample: /report_by_date 2025-01-01 2025-01-31",
+		"id": "📊 Untuk melihat laporan personal, gunakan format:\n/my_report\n\nAtau untuk laporan berdasarkan tanggal:\n/report_by_date [start_date] [end_date]\n\nContoh: /report_by_date 2025-01-01 2025-01-31",
+	},
+	"report_by_date_hint": {
+		"en": "📅 To generate a report by date, use:\n/report_by_date [start_date] [end_date]\n\nExample: /report_by_date 2025-01-01 2025-01-31\n\nDate format: YYYY-MM-DD",
+		"id": "📅 Untuk generate laporan berdasarkan tanggal, gunakan format:\n/report_by_date [start_date] [end_date]\n\nContoh: /report_by_date 2025-01-01 2025-01-31\n\nFormat tanggal: YYYY-MM-DD",
+	},
+
+	// Order details, chat history and invoices
+	"order_detail_header": {
+		"en": "📦 **Order #%d (%s)**\n\n",
+		"id": "📦 **Pesanan #%d (%s)**\n\n",
+	},
+	"chat_history_ai": {
+		"en": "🤖 AI",
+		"id": "🤖 AI",
+	},
+	"invoice_caption": {
+		"en": "📄 Invoice %s - %s",
+		"id": "📄 Invoice %s - %s",
+	},
+	"order_item_qty": {
+		"en": "Qty: %d x %s = %s\n",
+		"id": "Jumlah: %d x %s = %s\n",
+	},
+
+	// Assignees
+	"assignee_inactive": {
+		"en": "❌ User %s is inactive and cannot be assigned tasks.",
+		"id": "❌ User %s tidak aktif dan tidak dapat diberi task.",
+	},
+	"assignee_skipped_not_found": {
+		"en": "%s (not found)",
+		"id": "%s (tidak ditemukan)",
+	},
+	"assignee_skipped_inactive": {
+		"en": "%s (inactive)",
+		"id": "%s (tidak aktif)",
+	},
+}
+
+// t looks up a catalog message in lang, falling back to the default language,
+// and fills in args. Unknown keys are returned as is so gaps are visible
+func t(lang, key string, args ...interface{}) string {
+	entry, ok := messages[key]
+	if !ok {
+		return key
+	}
+	format, ok := entry[lang]
+	if !ok {
+		format = entry[models.DefaultLanguage]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// userLanguage is the language replies to user are written in
+func userLanguage(user *models.User) string {
+	if user == nil || !models.IsSupportedLanguage(user.Language) {
+		return models.DefaultLanguage
+	}
+	return user.Language
+}
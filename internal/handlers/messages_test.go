@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+)
+
+func TestMessageCatalog(t *testing.T) {
+	for key, entry := range messages {
+		for _, lang := range []string{models.LanguageIndonesian, models.LanguageEnglish} {
+			if entry[lang] == "" {
+				t.Errorf("%q has no %q text", key, lang)
+			}
+		}
+		// Every translation takes the same arguments as the default
+		want := strings.Count(entry[models.DefaultLanguage], "%")
+		for lang, text := range entry {
+			if got := strings.Count(text, "%"); got != want {
+				t.Errorf("%q in %q has %d verbs, want %d", key, lang, got, want)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []interface{}
+		want string
+	}{
+		{name: "english", lang: "en", key: "language_set", want: "✅ Replies will now be in English."},
+		{name: "indonesian", lang: "id", key: "language_set", want: "✅ Balasan sekarang dalam Bahasa Indonesia."},
+		{name: "unknown language falls back", lang: "fr", key: "language_set", want: "✅ Balasan sekarang dalam Bahasa Indonesia."},
+		{name: "no language falls back", lang: "", key: "empty_message", want: messages["empty_message"][models.DefaultLanguage]},
+		{name: "args filled in", lang: "en", key: "permission_denied", args: []interface{}{"Super Admin"}, want: "❌ Only Super Admin can use this command."},
+		{name: "unknown key shown as is", lang: "en", key: "no_such_key", want: "no_such_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translate(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("t(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// translate is t under a name the tests' *testing.T does not shadow
+var translate = t
+
+func TestRepliesFollowUserLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		aiReply string
+		want    func(lang string) string
+	}{
+		{
+			name:    "empty message",
+			message: "   ",
+			want:    func(lang string) string { return translate(lang, "empty_message") },
+		},
+		{
+			name:    "restricted command",
+			message: "/add_user 62812 budi",
+			want:    func(lang string) string { return translate(lang, "permission_denied", "Super Admin") },
+		},
+		{
+			name:    "bad language code",
+			message: "/set_language fr",
+			want:    func(lang string) string { return translate(lang, "set_language_usage") },
+		},
+		{
+			name:    "command usage",
+			message: "/mark_complete",
+			want:    func(lang string) string { return translate(lang, "mark_complete_usage") },
+		},
+		{
+			name:    "invalid task id",
+			message: "/mark_complete abc",
+			want:    func(lang string) string { return translate(lang, "invalid_task_id") },
+		},
+		{
+			name:    "empty order list",
+			message: "/my_orders",
+			want:    func(lang string) string { return translate(lang, "my_orders_empty") },
+		},
+		{
+			name:    "empty reminder list",
+			message: "/view_reminders",
+			want:    func(lang string) string { return translate(lang, "reminders_empty") },
+		},
+		{
+			name:    "help",
+			message: "/help",
+			want:    func(lang string) string { return translate(lang, "help_general") },
+		},
+		{
+			name:    "AI reply without a message",
+			message: "hmm",
+			aiReply: "   ",
+			want:    func(lang string) string { return "🤖 " + translate(lang, "ai_not_understood") },
+		},
+	}
+
+	for _, tt := range tests {
+		for _, lang := range []string{"en", "id", ""} {
+			t.Run(tt.name+"/"+lang, func(t *testing.T) {
+				user := &models.User{ID: 2, Role: string(models.Users), Language: lang}
+				h := newTestHandler(testNow)
+				h.whatsappService = &fakeWhatsAppService{}
+				h.orderService = &fakeOrderService{}
+				h.reminderService = &fakeReminderService{}
+				h.taskService = &fakeTaskService{}
+				h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+				want := tt.want(lang)
+				if lang == "" {
+					want = tt.want(models.DefaultLanguage)
+				}
+				if got := h.processCommand(user, tt.message); !strings.HasPrefix(got, want) {
+					t.Errorf("got %q, want it to start with %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		args     []string
+		want     string
+		wantSave string
+	}{
+		{name: "to english", current: "id", args: []string{"en"}, want: messages["language_set"]["en"], wantSave: "en"},
+		{name: "to indonesian", current: "en", args: []string{"ID"}, want: messages["language_set"]["id"], wantSave: "id"},
+		{name: "unsupported keeps current language", current: "en", args: []string{"fr"}, want: messages["set_language_usage"]["en"]},
+		{name: "missing code", current: "id", want: messages["set_language_usage"]["id"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{ID: 2, Username: "budi", Role: string(models.Users), Language: tt.current}
+			users := &fakeUserService{users: []*models.User{{ID: 2, Username: "budi", Role: string(models.Users), Language: tt.current}}}
+			h := newTestHandler(testNow)
+			h.userService = users
+
+			if got := h.setLanguage(user, tt.args); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.wantSave == "" {
+				if len(users.updated) != 0 {
+					t.Errorf("saved %+v, want nothing saved", users.updated)
+				}
+				return
+			}
+			if len(users.updated) != 1 || users.updated[0].Language != tt.wantSave || users.updated[0].Username != "budi" {
+				t.Errorf("saved %+v, want budi with language %q", users.updated, tt.wantSave)
+			}
+		})
+	}
+}
+
+func TestMessageKeysInUse(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used := map[string]bool{}
+	for _, pkg := range pkgs {
+		ast.Inspect(pkg, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) < 2 {
+				return true
+			}
+			if fn, ok := call.Fun.(*ast.Ident); !ok || fn.Name != "t" {
+				return true
+			}
+			lit, ok := call.Args[1].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("%s: t() called with a non-literal key", fset.Position(call.Pos()))
+				return true
+			}
+			key, _ := strconv.Unquote(lit.Value)
+			if _, ok := messages[key]; !ok {
+				t.Errorf("%s: key %q is not in the catalog", fset.Position(call.Pos()), key)
+			}
+			used[key] = true
+			return true
+		})
+	}
+	for key := range messages {
+		if !used[key] {
+			t.Errorf("key %q is never used", key)
+		}
+	}
+}
@@ -0,0 +1,1023 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/repository"
+	"task_manager/internal/services"
+	"testing"
+	"time"
+)
+
+func TestAICreateOrderWithItem(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		reply      string
+		wantReply  []string
+		wantOrders int
+	}{
+		{
+			name:  "order and item created together",
+			user:  admin,
+			reply: `{"type":"create_order_with_item","data":{"customer_name":"Siti","item_name":"Kue Lapis","quantity":2,"price":50000}}`,
+			wantReply: []string{
+				"✅ Order #1 with item created!",
+				"Order Number: ORD-0001",
+				"💰 Total: Rp 100.000",
+				"🛒 Item: Kue Lapis",
+				"Qty: 2 x Rp 50.000 = Rp 100.000",
+			},
+			wantOrders: 1,
+		},
+		{
+			name:       "explicit total is kept",
+			user:       admin,
+			reply:      `{"type":"create_order_with_item","data":{"customer_name":"Siti","total_amount":120000,"item_name":"Kue Lapis","quantity":2,"price":50000}}`,
+			wantReply:  []string{"💰 Total: Rp 120.000", "Qty: 2 x Rp 50.000 = Rp 100.000"},
+			wantOrders: 1,
+		},
+		{
+			name:      "missing quantity",
+			user:      admin,
+			reply:     `{"type":"create_order_with_item","data":{"customer_name":"Siti","item_name":"Kue Lapis","price":50000}}`,
+			wantReply: []string{"❌ Incomplete data"},
+		},
+		{
+			name:      "regular user",
+			user:      &models.User{ID: 2, Role: string(models.Users), Language: "en"},
+			reply:     `{"type":"create_order_with_item","data":{"customer_name":"Siti","item_name":"Kue Lapis","quantity":2,"price":50000}}`,
+			wantReply: []string{"❌"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{}
+			undo := &fakeUndoService{}
+			h := newTestHandler(testNow)
+			h.orderService = orders
+			h.undoService = undo
+			h.aiProcessor = &fakeAIProcessor{reply: tt.reply}
+
+			reply := h.processAICommand(tt.user, "buat order Siti 2 kue lapis 50rb")
+			for _, want := range tt.wantReply {
+				if !strings.Contains(reply, want) {
+					t.Errorf("reply %q does not contain %q", reply, want)
+				}
+			}
+			if len(orders.created) != tt.wantOrders {
+				t.Fatalf("created %d orders, want %d", len(orders.created), tt.wantOrders)
+			}
+			if tt.wantOrders == 0 {
+				return
+			}
+
+			items := orders.items[1]
+			if len(items) != 1 || items[0].ItemName != "Kue Lapis" || items[0].Quantity != 2 || items[0].UnitPrice != 50000 {
+				t.Errorf("items = %+v, want one Kue Lapis 2 x 50000", items)
+			}
+			if len(undo.orders) != 1 {
+				t.Errorf("order creation not recorded for /undo")
+			}
+		})
+	}
+}
+
+func TestAIAddAndViewOrderItems(t *testing.T) {
+	orders := &fakeOrderService{orders: []models.Order{{ID: 7, CustomerName: "Siti", TotalAmount: 130000, CreatedBy: 1}}}
+	h := newTestHandler(testNow)
+	h.orderService = orders
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	added := h.handleAIAddOrderItem(admin, &AIResponse{Data: map[string]interface{}{
+		"order_id": float64(7), "item_name": "Bolu", "quantity": float64(3), "price": float64(10000),
+	}})
+	if want := "Qty: 3 x Rp 10.000 = Rp 30.000"; !strings.Contains(added, want) {
+		t.Errorf("add reply %q does not contain %q", added, want)
+	}
+
+	viewed := h.handleAIViewOrderItems(admin, &AIResponse{Data: map[string]interface{}{"order_id": float64(7)}})
+	for _, want := range []string{"Items of Order #7 (Siti)", "Bolu", "💰 Total Items: Rp 30.000", "does not match its items"} {
+		if !strings.Contains(viewed, want) {
+			t.Errorf("view reply %q does not contain %q", viewed, want)
+		}
+	}
+
+	other := h.handleAIViewOrderItems(&models.User{ID: 2, Role: string(models.Users), Language: "en"}, &AIResponse{Data: map[string]interface{}{"order_id": float64(7)}})
+	if !strings.HasPrefix(other, "❌") {
+		t.Errorf("another user's order items were shown: %q", other)
+	}
+}
+
+func TestAIViewOrdersScope(t *testing.T) {
+	orders := []models.Order{
+		{ID: 1, OrderNumber: "ORD-0001", CustomerName: "Siti", CreatedBy: 1},
+		{ID: 2, OrderNumber: "ORD-0002", CustomerName: "Budi", CreatedBy: 2},
+	}
+
+	tests := []struct {
+		name       string
+		role       string
+		wantHeader string
+		want       []string
+		notWant    []string
+	}{
+		{name: "admin", role: string(models.Admin), wantHeader: translate("en", "all_orders_header"), want: []string{"Siti", "Budi"}},
+		{name: "admin in display casing", role: "Admin", wantHeader: translate("en", "all_orders_header"), want: []string{"Siti", "Budi"}},
+		{name: "super admin", role: "SuperAdmin", wantHeader: translate("en", "all_orders_header"), want: []string{"Siti", "Budi"}},
+		{name: "regular user", role: string(models.Users), wantHeader: translate("en", "my_orders_header"), want: []string{"Budi"}, notWant: []string{"Siti"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = &fakeOrderService{orders: orders}
+
+			reply := h.handleAIViewOrders(&models.User{ID: 2, Role: tt.role, Language: "en"}, &AIResponse{Type: "view_orders", Data: map[string]interface{}{}})
+			if !strings.HasPrefix(reply, tt.wantHeader) {
+				t.Errorf("reply %q does not start with %q", reply, tt.wantHeader)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(reply, want) {
+					t.Errorf("reply %q does not list %s", reply, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(reply, notWant) {
+					t.Errorf("reply %q lists another user's order for %s", reply, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOrderFilter(t *testing.T) {
+	jakarta := testNow.Location()
+	day := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 0, 0, 0, 0, jakarta)
+		return &t
+	}
+	amount := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name        string
+		args        []string
+		want        repository.OrderFilter
+		wantPage    int
+		wantIgnored []string
+	}{
+		{name: "status", args: []string{"status:Pending"}, want: repository.OrderFilter{Status: "pending"}, wantPage: 1},
+		{name: "customer with several words", args: []string{"customer:john", "doe", "status:completed"}, want: repository.OrderFilter{Customer: "john doe", Status: "completed"}, wantPage: 1},
+		{name: "amount range", args: []string{"min:100000", "max:500.000"}, want: repository.OrderFilter{MinAmount: amount(100000), MaxAmount: amount(500000)}, wantPage: 1},
+		{
+			name:     "date range includes the whole last day",
+			args:     []string{"from:2025-01-01", "to:2025-01-31", "page:2"},
+			want:     repository.OrderFilter{From: day(2025, 1, 1), To: day(2025, 2, 1)},
+			wantPage: 2,
+		},
+		{
+			name:        "unknown and invalid tokens are ignored",
+			args:        []string{"colour:red", "min:lots", "from:someday", "page:0", "stray"},
+			wantPage:    1,
+			wantIgnored: []string{"colour:red", "min:lots", "from:someday", "page:0", "stray"},
+		},
+		{name: "negative amount", args: []string{"min:-5"}, wantPage: 1, wantIgnored: []string{"min:-5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, page, ignored := parseOrderFilter(tt.args, testNow)
+			if !reflect.DeepEqual(filter, tt.want) {
+				t.Errorf("filter = %+v, want %+v", filter, tt.want)
+			}
+			if page != tt.wantPage {
+				t.Errorf("page = %d, want %d", page, tt.wantPage)
+			}
+			if !reflect.DeepEqual(ignored, tt.wantIgnored) {
+				t.Errorf("ignored = %q, want %q", ignored, tt.wantIgnored)
+			}
+		})
+	}
+}
+
+func TestSearchOrders(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	staff := &models.User{ID: 2, Role: string(models.Users), Language: "en"}
+	matches := make([]models.Order, 12)
+	for i := range matches {
+		matches[i] = models.Order{ID: uint(i + 1), OrderNumber: fmt.Sprintf("ORD-%04d", i+1), CustomerName: "John", Status: "pending"}
+	}
+
+	tests := []struct {
+		name          string
+		user          *models.User
+		args          []string
+		orders        []models.Order
+		want          []string
+		wantAbsent    string
+		wantCreatedBy *uint
+		wantOffset    int
+	}{
+		{name: "admin searches all orders", user: admin, args: []string{"customer:john"}, orders: matches, want: []string{"🔎 **Search Results (12):**", "Page 1/2 — add 'page:2' for next"}},
+		{name: "second page", user: admin, args: []string{"customer:john", "page:2"}, orders: matches, want: []string{"**Order #11**", "**Order #12**", "Page 2/2"}, wantAbsent: "for next", wantOffset: 10},
+		{name: "user only searches own orders", user: staff, args: []string{"status:pending"}, orders: matches[:1], want: []string{"🔎 **Search Results (1):**"}, wantCreatedBy: &staff.ID},
+		{name: "ignored tokens are reported", user: admin, args: []string{"colour:red", "status:pending"}, want: []string{"ℹ️ Ignored: colour:red", "📦 No matching orders."}},
+		{name: "page past the end", user: admin, args: []string{"page:5"}, orders: matches, want: []string{"❌ Page 5 does not exist (last page is 2)"}, wantOffset: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{orders: tt.orders}
+			h := newTestHandler(testNow)
+			h.orderService = orders
+
+			got := h.searchOrders(tt.user, tt.args)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("searchOrders() = %q, want it to contain %q", got, want)
+				}
+			}
+			if tt.wantAbsent != "" && strings.Contains(got, tt.wantAbsent) {
+				t.Errorf("searchOrders() = %q, should not contain %q", got, tt.wantAbsent)
+			}
+			filter := orders.filters[0]
+			if !reflect.DeepEqual(filter.CreatedBy, tt.wantCreatedBy) {
+				t.Errorf("CreatedBy = %v, want %v", filter.CreatedBy, tt.wantCreatedBy)
+			}
+			if filter.Offset != tt.wantOffset || filter.Limit != listPageSize {
+				t.Errorf("offset/limit = %d/%d, want %d/%d", filter.Offset, filter.Limit, tt.wantOffset, listPageSize)
+			}
+		})
+	}
+}
+
+func TestMergeCustomerCommand(t *testing.T) {
+	superAdmin := &models.User{ID: 1, Role: string(models.SuperAdmin), Language: "en"}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		args       []string
+		want       string
+		wantMerged [2]string
+	}{
+		{name: "two single-word names", user: superAdmin, args: []string{"Jon", "John"}, want: "✅ Merged customer 'Jon' into 'John' (2 order(s) updated)", wantMerged: [2]string{"Jon", "John"}},
+		{name: "names with spaces", user: superAdmin, args: []string{"Jon", "Doe", "->", "John", "Doe"}, want: "✅ Merged customer 'Jon Doe' into 'John Doe' (1 order(s) updated)", wantMerged: [2]string{"Jon Doe", "John Doe"}},
+		{name: "no matching orders", user: superAdmin, args: []string{"Ann", "Anne"}, want: "ℹ️ No orders found for customer 'Ann'", wantMerged: [2]string{"Ann", "Anne"}},
+		{name: "ambiguous without an arrow", user: superAdmin, args: []string{"Jon", "Doe", "John"}, want: "❌ Usage: /merge_customer"},
+		{name: "admin is not allowed", user: &models.User{ID: 2, Role: string(models.Admin), Language: "en"}, args: []string{"Jon", "John"}, want: "❌ Only Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{orders: []models.Order{
+				{ID: 1, CustomerName: "Jon"},
+				{ID: 2, CustomerName: "Jon"},
+				{ID: 3, CustomerName: "Jon Doe"},
+			}}
+			h := newTestHandler(testNow)
+			h.orderService = orders
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(tt.user, strings.Join(append([]string{"/merge_customer"}, tt.args...), " "))
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("mergeCustomer() = %q, want %q", got, tt.want)
+			}
+			if tt.wantMerged == ([2]string{}) {
+				if len(orders.merged) != 0 {
+					t.Errorf("merged %q, want no merge", orders.merged)
+				}
+				return
+			}
+			if len(orders.merged) != 1 || orders.merged[0] != tt.wantMerged {
+				t.Errorf("merged %q, want %q", orders.merged, tt.wantMerged)
+			}
+		})
+	}
+}
+
+func TestOrdersByStatus(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name string
+		user *models.User
+		args []string
+		want []string
+	}{
+		{name: "pending orders", user: admin, args: []string{"Pending"}, want: []string{"📦 **Orders with status pending (2):**", "**Order #1**", "**Order #3**"}},
+		{name: "none in status", user: admin, args: []string{"processing"}, want: []string{"📦 No processing orders found."}},
+		{name: "unknown status", user: admin, args: []string{"shipped"}, want: []string{"❌ invalid order status \"shipped\""}},
+		{name: "missing status", user: admin, want: []string{"❌ Usage: /orders_by_status"}},
+		{name: "regular user", user: &models.User{ID: 2, Role: string(models.Users), Language: "en"}, args: []string{"pending"}, want: []string{"❌ Only Admin or Super Admin can use this command."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = &fakeOrderService{orders: []models.Order{
+				{ID: 1, OrderNumber: "ORD-0001", Status: "pending"},
+				{ID: 2, OrderNumber: "ORD-0002", Status: "completed"},
+				{ID: 3, OrderNumber: "ORD-0003", Status: "pending"},
+			}}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(tt.user, strings.Join(append([]string{"/orders_by_status"}, tt.args...), " "))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("ordersByStatus() = %q, want it to contain %q", got, want)
+				}
+			}
+			if strings.Contains(got, "**Order #2**") {
+				t.Errorf("ordersByStatus() = %q, lists a completed order", got)
+			}
+		})
+	}
+}
+
+func TestCustomerSummary(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		args    []string
+		summary *services.CustomerSummary
+		want    []string
+	}{
+		{
+			name:    "totals",
+			user:    admin,
+			args:    []string{"siti"},
+			summary: &services.CustomerSummary{Customer: "Siti", OrderCount: 2, TotalRevenue: 400000, TotalNetProfit: 320000, AverageOrderValue: 200000},
+			want:    []string{"👤 **Customer Summary: Siti**", "Orders: 2\n", "Total Revenue: Rp 400.000", "Total Net Profit: Rp 320.000", "Average Order Value: Rp 200.000"},
+		},
+		{
+			name:    "cancelled orders are called out",
+			user:    admin,
+			args:    []string{"siti"},
+			summary: &services.CustomerSummary{Customer: "Siti", OrderCount: 1, CancelledCount: 1, TotalRevenue: 100000, AverageOrderValue: 100000},
+			want:    []string{"Cancelled: 1 (not included in totals)"},
+		},
+		{name: "name with spaces and no orders", user: admin, args: []string{"Budi", "Santoso"}, want: []string{"📦 No orders found for customer 'Budi Santoso'"}},
+		{name: "missing name", user: admin, want: []string{"❌ Usage: /customer_summary"}},
+		{name: "regular user", user: &models.User{ID: 2, Role: string(models.Users), Language: "en"}, args: []string{"siti"}, want: []string{"❌ Only Admin or Super Admin can use this command."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = &fakeOrderService{summary: tt.summary}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(tt.user, strings.Join(append([]string{"/customer_summary"}, tt.args...), " "))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("customerSummary() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreOrder(t *testing.T) {
+	superAdmin := &models.User{ID: 1, Role: string(models.SuperAdmin), Language: "en"}
+	orders := &fakeOrderService{
+		orders:  []models.Order{{ID: 1, CustomerName: "Budi"}},
+		deleted: []models.Order{{ID: 5, CustomerName: "Siti", Status: "pending"}},
+	}
+	h := newTestHandler(testNow)
+	h.orderService = orders
+	h.whatsappService = &fakeWhatsAppService{}
+
+	steps := []struct {
+		name string
+		user *models.User
+		args []string
+		want string
+	}{
+		{name: "admin cannot restore", user: &models.User{ID: 2, Role: string(models.Admin), Language: "en"}, args: []string{"5"}, want: "❌ Only Super Admin can use this command."},
+		{name: "list deleted", user: superAdmin, want: "🗑️ **Deleted Orders:**\n\n**Order #5**\nCustomer: Siti"},
+		{name: "restore", user: superAdmin, args: []string{"5"}, want: "♻️ Order #5 restored"},
+		{name: "nothing left to list", user: superAdmin, want: "🗑️ No deleted orders."},
+		{name: "restore twice", user: superAdmin, args: []string{"5"}, want: "❌ No deleted order #5"},
+		{name: "never deleted", user: superAdmin, args: []string{"1"}, want: "❌ No deleted order #1"},
+		{name: "invalid id", user: superAdmin, args: []string{"five"}, want: "❌ Invalid order ID"},
+	}
+
+	for _, step := range steps {
+		if got := h.processCommand(step.user, strings.Join(append([]string{"/restore_order"}, step.args...), " ")); !strings.HasPrefix(got, step.want) {
+			t.Errorf("%s: restoreOrder() = %q, want %q", step.name, got, step.want)
+		}
+	}
+	if len(orders.orders) != 2 || orders.orders[1].ID != 5 {
+		t.Errorf("orders after restore = %+v, want order 5 back", orders.orders)
+	}
+}
+
+func TestOrderNotes(t *testing.T) {
+	admin := &models.User{ID: 1, Username: "admin", Role: string(models.Admin), Language: "en"}
+	owner := &models.User{ID: 2, Username: "siti", Role: string(models.Users), Language: "en"}
+	other := &models.User{ID: 3, Username: "budi", Role: string(models.Users), Language: "en"}
+
+	orders := &fakeOrderService{orders: []models.Order{
+		{ID: 1, OrderNumber: "ORD-0001", CustomerName: "Rina", CreatedBy: owner.ID, OrderDate: testNow},
+	}}
+	h := newTestHandler(testNow)
+	h.orderService = orders
+	h.userService = &fakeUserService{users: []*models.User{admin, owner, other}}
+
+	steps := []struct {
+		name string
+		user *models.User
+		args []string
+		want string
+	}{
+		{name: "owner adds a note", user: owner, args: []string{"1", "customer", "requested", "gift", "wrap"}, want: "📝 Note added to order #1."},
+		{name: "admin adds a note", user: admin, args: []string{"1", "deliver", "after", "5pm"}, want: "📝 Note added to order #1."},
+		{name: "other user is refused", user: other, args: []string{"1", "hello"}, want: "❌ You don't have access to this order."},
+		{name: "unknown order", user: admin, args: []string{"9", "hello"}, want: "❌ Order #9 not found"},
+		{name: "missing text", user: admin, args: []string{"1"}, want: "❌ Usage: /order_note [order_id] [text]"},
+	}
+	for _, step := range steps {
+		if got := h.addOrderNote(step.user, step.args); !strings.HasPrefix(got, step.want) {
+			t.Errorf("%s: addOrderNote() = %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	detail := h.orderDetail(owner, []string{"1"})
+	wantNotes := "\n📝 **Notes:**\n" +
+		"- [2025-01-15 10:30] siti: customer requested gift wrap\n" +
+		"- [2025-01-15 10:30] admin: deliver after 5pm\n"
+	if !strings.HasSuffix(detail, wantNotes) {
+		t.Errorf("orderDetail() = %q, want it to end with %q", detail, wantNotes)
+	}
+}
+
+func TestOrderHistory(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	first := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	second := first.Add(26 * time.Hour)
+	previous := 90000.0
+	orders := &fakeOrderService{
+		orders: []models.Order{{ID: 1, CustomerName: "Siti"}, {ID: 2, CustomerName: "Budi"}},
+		history: []models.CalculationHistory{
+			{OrderID: 1, CalculationType: "tax", InputValue: 100000, PercentageUsed: 10, CalculatedAmount: 10000, CalculationTimestamp: first},
+			{OrderID: 1, CalculationType: "net_profit", InputValue: 100000, PercentageUsed: 10, CalculatedAmount: 90000, CalculationTimestamp: first},
+			{OrderID: 1, CalculationType: "net_profit", InputValue: 200000, PercentageUsed: 10, FixedAmountUsed: 5000, CalculatedAmount: 175000, PreviousNetProfit: &previous, CalculationTimestamp: second},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		message string
+		aiReply string
+		want    string
+	}{
+		{
+			name:    "entries in order",
+			user:    admin,
+			message: "/order_history 1",
+			want: "🧮 **Calculation History - Order #1 (Siti)**\n\n" +
+				"[2025-01-10 09:00] tax\nInput: Rp 100.000 | Rate: 10.00% | Result: Rp 10.000\n\n" +
+				"[2025-01-10 09:00] net_profit\nInput: Rp 100.000 | Rate: 10.00% | Result: Rp 90.000\n\n" +
+				"[2025-01-11 11:00] net_profit\nInput: Rp 200.000 | Rate: 10.00% + Rp 5.000 fixed | Result: Rp 175.000\nPrevious net profit: Rp 90.000",
+		},
+		{name: "no history", user: admin, message: "/order_history 2", want: "🧮 Order #2 has no calculation history."},
+		{name: "unknown order", user: admin, message: "/order_history 9", want: "❌ Order #9 not found."},
+		{name: "usage", user: admin, message: "/order_history", want: "❌ Usage: /order_history [order_id]"},
+		{name: "regular user", user: &models.User{ID: 5, Role: string(models.Users), Language: "en"}, message: "/order_history 1", want: "❌ Only Admin or Super Admin can use this command."},
+		{
+			name:    "AI intent",
+			user:    admin,
+			message: "gimana hitungan order 2?",
+			aiReply: `{"type":"order_history","data":{"order_id":2},"message":""}`,
+			want:    "🧮 Order #2 has no calculation history.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = orders
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			if got := h.processCommand(tt.user, tt.message); got != tt.want {
+				t.Errorf("%q =\n%q\nwant\n%q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomerPhoneCapture(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name   string
+		create func(h *WhatsAppHandler) string
+		want   string
+	}{
+		{
+			name: "command with local phone",
+			create: func(h *WhatsAppHandler) string {
+				return h.createOrder(admin, []string{"Siti", "150000", "0812-3456-7890"})
+			},
+			want: "6281234567890",
+		},
+		{
+			name:   "command without phone",
+			create: func(h *WhatsAppHandler) string { return h.createOrder(admin, []string{"Siti", "150000"}) },
+		},
+		{
+			name: "AI order",
+			create: func(h *WhatsAppHandler) string {
+				h.aiProcessor = &fakeAIProcessor{reply: `{"type":"create_order","data":{"customer_name":"Siti","total_amount":150000,"customer_phone":"+62 812 3456 7890"}}`}
+				return h.processAICommand(admin, "buat order Siti 150rb 081234567890")
+			},
+			want: "6281234567890",
+		},
+		{
+			name: "AI order with item",
+			create: func(h *WhatsAppHandler) string {
+				h.aiProcessor = &fakeAIProcessor{reply: `{"type":"create_order_with_item","data":{"customer_name":"Siti","customer_phone":"081234567890","item_name":"Kue Lapis","quantity":2,"price":50000}}`}
+				return h.processAICommand(admin, "buat order Siti 2 kue lapis 50rb 081234567890")
+			},
+			want: "6281234567890",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{}
+			h := newTestHandler(testNow)
+			h.orderService = orders
+			h.undoService = &fakeUndoService{}
+
+			reply := tt.create(h)
+			if len(orders.created) != 1 {
+				t.Fatalf("created %d orders (%q), want 1", len(orders.created), reply)
+			}
+			if got := orders.created[0].CustomerPhone; got != tt.want {
+				t.Errorf("CustomerPhone = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdersForCustomer(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	orders := []models.Order{
+		{ID: 1, CustomerName: "Siti", CustomerPhone: "6281234567890", TotalAmount: 150000, Status: "pending"},
+		{ID: 2, CustomerName: "Budi", TotalAmount: 50000, Status: "completed"},
+		{ID: 3, CustomerName: "siti", TotalAmount: 75000, Status: "completed"},
+	}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		message string
+		aiReply string
+		wantIDs []uint
+		want    string
+	}{
+		{name: "by name ignoring case", user: admin, message: "/orders_for_customer SITI", wantIDs: []uint{1, 3}},
+		{name: "by local phone", user: admin, message: "/orders_for_customer 0812-3456-7890", wantIDs: []uint{1}},
+		{name: "by international phone", user: admin, message: "/orders_for_customer +62 812 3456 7890", wantIDs: []uint{1}},
+		{
+			name:    "AI intent by phone",
+			user:    admin,
+			message: "order dari 081234567890",
+			aiReply: `{"type":"orders_for_customer","data":{"customer_phone":"081234567890"},"message":""}`,
+			wantIDs: []uint{1},
+		},
+		{name: "unknown customer", user: admin, message: "/orders_for_customer Ani", want: "📦 No orders found for Ani."},
+		{name: "no customer", user: admin, message: "/orders_for_customer", want: "❌ Usage: /orders_for_customer [name_or_phone]"},
+		{
+			name:    "regular user",
+			user:    &models.User{ID: 2, Role: string(models.Users), Language: "en"},
+			message: "/orders_for_customer Siti",
+			want:    "❌ Only Admin or Super Admin can use this command.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = &fakeOrderService{orders: orders}
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			got := h.processCommand(tt.user, tt.message)
+			if tt.want != "" {
+				if got != tt.want {
+					t.Errorf("%q = %q, want %q", tt.message, got, tt.want)
+				}
+				return
+			}
+			for _, o := range orders {
+				listed := strings.Contains(got, fmt.Sprintf("**Order #%d**", o.ID))
+				if want := slices.Contains(tt.wantIDs, o.ID); listed != want {
+					t.Errorf("order #%d listed = %v, want %v:\n%s", o.ID, listed, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAICreateOrderDeliveryDate(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	jan := func(d int) *time.Time {
+		at := time.Date(2025, 1, d, 0, 0, 0, 0, testNow.Location())
+		return &at
+	}
+
+	tests := []struct {
+		name      string
+		reply     string
+		want      *time.Time
+		wantReply string
+	}{
+		{
+			name:      "absolute date",
+			reply:     `{"type":"create_order","data":{"customer_name":"Rina","total_amount":500000,"delivery_date":"2025-01-20"}}`,
+			want:      jan(20),
+			wantReply: "🚚 Delivery: 2025-01-20",
+		},
+		{
+			name:      "relative date",
+			reply:     `{"type":"create_order","data":{"customer_name":"Rina","total_amount":500000,"delivery_date":"lusa"}}`,
+			want:      jan(17),
+			wantReply: "🚚 Delivery: 2025-01-17",
+		},
+		{
+			name:  "no date",
+			reply: `{"type":"create_order","data":{"customer_name":"Rina","total_amount":500000}}`,
+		},
+		{
+			name:      "with item",
+			reply:     `{"type":"create_order_with_item","data":{"customer_name":"Rina","item_name":"Tumpeng","quantity":1,"price":500000,"delivery_date":"besok"}}`,
+			want:      jan(16),
+			wantReply: "🚚 Delivery: 2025-01-16",
+		},
+		{
+			name:      "unreadable date",
+			reply:     `{"type":"create_order","data":{"customer_name":"Rina","total_amount":500000,"delivery_date":"someday"}}`,
+			wantReply: "❌ Invalid delivery date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{}
+			h := newTestHandler(testNow)
+			h.orderService = orders
+			h.undoService = &fakeUndoService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.reply}
+
+			reply := h.processAICommand(admin, "buat order Rina 500rb")
+			if !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply %q does not contain %q", reply, tt.wantReply)
+			}
+			if strings.HasPrefix(tt.wantReply, "❌") {
+				if len(orders.created) != 0 {
+					t.Errorf("created %+v despite the bad date", orders.created[0])
+				}
+				return
+			}
+			if len(orders.created) != 1 {
+				t.Fatalf("created %d orders, want 1", len(orders.created))
+			}
+			got := orders.created[0].DeliveryDate
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("DeliveryDate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpcomingDeliveries(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	delivery := time.Date(2025, 1, 17, 0, 0, 0, 0, testNow.Location())
+	orders := []models.Order{
+		{ID: 4, CustomerName: "Rina", CustomerPhone: "6281234567890", TotalAmount: 500000, Status: "pending", DeliveryDate: &delivery},
+		{ID: 5, CustomerName: "Budi", TotalAmount: 50000, Status: "pending"},
+	}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		message    string
+		aiReply    string
+		orders     []models.Order
+		wantWindow time.Duration
+		want       string
+	}{
+		{
+			name:       "default week",
+			user:       admin,
+			message:    "/upcoming_deliveries",
+			orders:     orders,
+			wantWindow: 7 * 24 * time.Hour,
+			want: "🚚 **Deliveries in the next 7 days (1):**\n\n" +
+				"**2025-01-17** - Order #4\nCustomer: Rina\nPhone: 6281234567890\nTotal: Rp 500.000\nStatus: pending\n\n",
+		},
+		{
+			name:       "custom window",
+			user:       admin,
+			message:    "/upcoming_deliveries 3",
+			wantWindow: 3 * 24 * time.Hour,
+			want:       "🚚 No deliveries in the next 3 days.",
+		},
+		{
+			name:       "AI intent",
+			user:       admin,
+			message:    "pengiriman 2 hari ke depan",
+			aiReply:    `{"type":"upcoming_deliveries","data":{"days":2},"message":""}`,
+			wantWindow: 2 * 24 * time.Hour,
+			want:       "🚚 No deliveries in the next 2 days.",
+		},
+		{name: "bad window", user: admin, message: "/upcoming_deliveries soon", want: "❌ Usage: /upcoming_deliveries [days]"},
+		{
+			name:    "regular user",
+			user:    &models.User{ID: 2, Role: string(models.Users), Language: "en"},
+			message: "/upcoming_deliveries",
+			want:    "❌ Only Admin or Super Admin can use this command.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeOrderService{orders: tt.orders}
+			h := newTestHandler(testNow)
+			h.orderService = svc
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			if got := h.processCommand(tt.user, tt.message); got != tt.want {
+				t.Errorf("%q =\n%q\nwant\n%q", tt.message, got, tt.want)
+			}
+			var wantWindows []time.Duration
+			if tt.wantWindow != 0 {
+				wantWindows = []time.Duration{tt.wantWindow}
+			}
+			if !slices.Equal(svc.windows, wantWindows) {
+				t.Errorf("windows = %v, want %v", svc.windows, wantWindows)
+			}
+		})
+	}
+}
+
+func TestOrderScopeCommands(t *testing.T) {
+	orders := []models.Order{
+		{ID: 1, OrderNumber: "ORD-0001", CustomerName: "Siti", CreatedBy: 1},
+		{ID: 2, OrderNumber: "ORD-0002", CustomerName: "Budi", CreatedBy: 2},
+	}
+	denied := "❌ Only Admin or Super Admin can use this command."
+	ai := func(intent string) string { return `{"type":"` + intent + `","data":{}}` }
+
+	tests := []struct {
+		name    string
+		role    models.UserRole
+		message string
+		aiReply string
+		want    string
+		listed  []string
+	}{
+		{name: "user, /my_orders", role: models.Users, message: "/my_orders", want: translate("en", "my_orders_header"), listed: []string{"Budi"}},
+		{name: "admin, /my_orders is still only their own", role: models.Admin, message: "/my_orders", want: translate("en", "my_orders_header"), listed: []string{"Budi"}},
+		{name: "user, /all_orders", role: models.Users, message: "/all_orders", want: denied},
+		{name: "admin, /all_orders", role: models.Admin, message: "/all_orders", want: translate("en", "all_orders_header"), listed: []string{"Siti", "Budi"}},
+		{name: "super admin, /all_orders", role: models.SuperAdmin, message: "/all_orders", want: translate("en", "all_orders_header"), listed: []string{"Siti", "Budi"}},
+		{name: "user, my_orders intent", role: models.Users, message: "pesanan saya", aiReply: ai("my_orders"), want: translate("en", "my_orders_header"), listed: []string{"Budi"}},
+		{name: "admin, my_orders intent", role: models.Admin, message: "pesanan saya", aiReply: ai("my_orders"), want: translate("en", "my_orders_header"), listed: []string{"Budi"}},
+		{name: "user, all_orders intent", role: models.Users, message: "semua pesanan", aiReply: ai("all_orders"), want: denied},
+		{name: "admin, all_orders intent", role: models.Admin, message: "semua pesanan", aiReply: ai("all_orders"), want: translate("en", "all_orders_header"), listed: []string{"Siti", "Budi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.whatsappService = &fakeWhatsAppService{}
+			h.orderService = &fakeOrderService{orders: orders}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			got := h.processCommand(&models.User{ID: 2, Role: string(tt.role), Language: "en"}, tt.message)
+			if !strings.HasPrefix(got, tt.want) {
+				t.Fatalf("reply = %q, want it to start with %q", got, tt.want)
+			}
+			for _, o := range orders {
+				if listed := strings.Contains(got, o.CustomerName); listed != slices.Contains(tt.listed, o.CustomerName) {
+					t.Errorf("%s listed = %v in %q", o.CustomerName, listed, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateOrderRateOverrides(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	rate := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name      string
+		create    func(h *WhatsAppHandler) string
+		want      string
+		wantTax   *float64
+		wantMkt   *float64
+		wantRent  *float64
+		wantNoNew bool
+	}{
+		{
+			name:   "command without overrides",
+			create: func(h *WhatsAppHandler) string { return h.createOrder(admin, strings.Fields("Siti 150000")) },
+			want:   "✅ Order created successfully\nOrder #: ORD-0001\nCustomer: Siti\nTotal: Rp 150.000",
+		},
+		{
+			name: "command with overrides",
+			create: func(h *WhatsAppHandler) string {
+				return h.createOrder(admin, strings.Fields("Siti 150000 tax=5 rental=0"))
+			},
+			want:    "✅ Order created successfully\nOrder #: ORD-0001\nCustomer: Siti\nTotal: Rp 150.000\n⚙️ Custom rates: tax 5.00%, rental 0.00%",
+			wantTax: rate(5), wantRent: rate(0),
+		},
+		{
+			name: "command override after phone",
+			create: func(h *WhatsAppHandler) string {
+				return h.createOrder(admin, strings.Fields("Siti 150000 0812345 MARKETING=2.5"))
+			},
+			want:    "✅ Order created successfully\nOrder #: ORD-0001\nCustomer: Siti\nTotal: Rp 150.000\n⚙️ Custom rates: marketing 2.50%",
+			wantMkt: rate(2.5),
+		},
+		{
+			name:      "command override out of range",
+			create:    func(h *WhatsAppHandler) string { return h.createOrder(admin, strings.Fields("Siti 150000 tax=120")) },
+			want:      "❌ tax must be between 0 and 100",
+			wantNoNew: true,
+		},
+		{
+			name: "command override not a number",
+			create: func(h *WhatsAppHandler) string {
+				return h.createOrder(admin, strings.Fields("Siti 150000 rental=free"))
+			},
+			want:      "❌ rental must be between 0 and 100",
+			wantNoNew: true,
+		},
+		{
+			name: "AI without overrides",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleStructuredAICreateOrder(admin, &AIResponse{Type: "create_order", Data: map[string]interface{}{"customer_name": "Siti", "total_amount": 150000.0}})
+			},
+			want: "✅ Order #1 created!",
+		},
+		{
+			name: "AI with overrides",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleStructuredAICreateOrder(admin, &AIResponse{Type: "create_order", Data: map[string]interface{}{"customer_name": "Siti", "total_amount": 150000.0, "tax_rate": 5.0, "marketing_rate": "0"}})
+			},
+			want:    "\n⚙️ Custom rates: tax 5.00%, marketing 0.00%",
+			wantTax: rate(5), wantMkt: rate(0),
+		},
+		{
+			name: "AI order with item overrides",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleAICreateOrderWithItem(admin, &AIResponse{Type: "create_order_with_item", Data: map[string]interface{}{"customer_name": "Siti", "item_name": "Kue Lapis", "quantity": 2.0, "price": 50000.0, "rental_rate": 1.0}})
+			},
+			want:     "\n⚙️ Custom rates: rental 1.00%",
+			wantRent: rate(1),
+		},
+		{
+			name: "AI override out of range",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleStructuredAICreateOrder(admin, &AIResponse{Type: "create_order", Data: map[string]interface{}{"customer_name": "Siti", "total_amount": 150000.0, "marketing_rate": -1.0}})
+			},
+			want:      "❌ marketing_rate must be between 0 and 100",
+			wantNoNew: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{}
+			h := newTestHandler(testNow)
+			h.orderService = orders
+			h.undoService = &fakeUndoService{}
+
+			got := tt.create(h)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("reply = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.wantNoNew {
+				if len(orders.created) != 0 {
+					t.Errorf("created %d orders, want none", len(orders.created))
+				}
+				return
+			}
+			if len(orders.created) != 1 {
+				t.Fatalf("created %d orders, want 1", len(orders.created))
+			}
+			order := orders.created[0]
+			for _, check := range []struct {
+				name      string
+				got, want *float64
+			}{
+				{"tax", order.TaxRateOverride, tt.wantTax},
+				{"marketing", order.MarketingRateOverride, tt.wantMkt},
+				{"rental", order.RentalRateOverride, tt.wantRent},
+			} {
+				if (check.got == nil) != (check.want == nil) || (check.got != nil && *check.got != *check.want) {
+					t.Errorf("%s override = %v, want %v", check.name, check.got, check.want)
+				}
+			}
+			if tt.wantTax == nil && tt.wantMkt == nil && tt.wantRent == nil && strings.Contains(got, "Custom rates") {
+				t.Errorf("reply %q lists custom rates for an order without overrides", got)
+			}
+		})
+	}
+}
+
+func TestOrderHistoryOfCreatedOrder(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name    string
+		aiReply string
+		want    string
+	}{
+		{
+			name:    "AI order",
+			aiReply: `{"type":"create_order","data":{"customer_name":"Siti","total_amount":100000}}`,
+			want: "🧮 **Calculation History - Order #1 (Siti)**\n\n" +
+				"[2025-01-15 10:30] net_profit\nInput: Rp 100.000 | Rate: 10.00% + Rp 5.000 fixed | Result: Rp 85.000",
+		},
+		{
+			name:    "AI order with item",
+			aiReply: `{"type":"create_order_with_item","data":{"customer_name":"Siti","item_name":"Kue Lapis","quantity":2,"price":50000}}`,
+			want: "🧮 **Calculation History - Order #1 (Siti)**\n\n" +
+				"[2025-01-15 10:30] net_profit\nInput: Rp 100.000 | Rate: 10.00% + Rp 5.000 fixed | Result: Rp 85.000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			financial := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+				"tax_rate":    {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+				"rental_rate": {SettingName: "rental_rate", FixedAmount: 5000},
+			}}
+			h := newTestHandler(testNow)
+			h.orderService = services.NewOrderService(&fakeOrderRepo{}, nil, nil, financial, nil, services.ItemStatusConfig{}, "IDR", h.clock)
+			h.undoService = &fakeUndoService{}
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			if got := h.processCommand(admin, "buat order Siti 100rb"); !strings.Contains(got, "Order #1") {
+				t.Fatalf("create reply = %q, want order #1", got)
+			}
+			if got := h.processCommand(admin, "/order_history 1"); got != tt.want {
+				t.Errorf("/order_history 1 =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatusReplies(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		status    string
+		statusErr error
+		want      string
+		notWant   string
+	}{
+		{name: "completed", from: "processing", status: "completed", want: "✅ Order ORD-0007 status: processing → completed\n📦 All items marked as done"},
+		{name: "processing", from: "pending", status: "processing", want: "✅ Order ORD-0007 status: pending → processing", notWant: "All items"},
+		{name: "no terminal item status", from: "processing", status: "completed", statusErr: services.ErrNoTerminalItemStatus, want: translate("en", "order_no_terminal_item_status")},
+		{name: "cancelled order", from: "cancelled", status: "completed", statusErr: services.ErrOrderCancelled, want: translate("en", "order_is_cancelled", "ORD-0007")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.undoService = &fakeUndoService{}
+			h.orderService = &fakeOrderService{
+				orders:    []models.Order{{ID: 7, OrderNumber: "ORD-0007", CustomerName: "Siti", Status: tt.from}},
+				statusErr: tt.statusErr,
+			}
+
+			got := h.updateOrderStatus(&models.User{ID: 1, Role: string(models.Admin), Language: "en"}, []string{"7", tt.status})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("reply = %q, want it to start with %q", got, tt.want)
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Errorf("reply = %q, want no %q", got, tt.notWant)
+			}
+		})
+	}
+}
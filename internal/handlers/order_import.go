@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"task_manager/internal/currency"
+	"task_manager/internal/models"
+	"time"
+)
+
+// maxImportSize bounds the CSV accepted by POST /api/orders/import
+const maxImportSize = 5 << 20
+
+// orderImportRow is one valid CSV row, ready to be created
+type orderImportRow struct {
+	Line  int
+	Order *models.Order
+	Items []models.OrderItem
+}
+
+// orderImportResult reports what happened to one CSV row
+type orderImportResult struct {
+	Line        int    `json:"line"`
+	Status      string `json:"status"` // created or error
+	OrderID     uint   `json:"order_id,omitempty"`
+	OrderNumber string `json:"order_number,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// parseOrderCSV reads an order import. The header names the columns:
+// customer_name, total_amount and order_date (YYYY-MM-DD) are required,
+// customer_phone and items are optional. items holds "name:qty:price" entries
+// separated by ";". Rows that cannot be used are reported as errors rather
+// than failing the import. An unreadable header, or a read error that is not
+// a CSV parse error (such as the body exceeding maxImportSize), stops the
+// import and is returned
+func parseOrderCSV(r io.Reader, createdBy uint) ([]orderImportRow, []orderImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"customer_name", "total_amount", "order_date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing column %s", required)
+		}
+	}
+
+	var rows []orderImportRow
+	var failed []orderImportResult
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("failed to read line %d: %w", line, err)
+			}
+			failed = append(failed, orderImportResult{Line: line, Status: "error", Error: err.Error()})
+			continue
+		}
+
+		row, err := parseOrderRecord(record, columns, createdBy)
+		if err != nil {
+			failed = append(failed, orderImportResult{Line: line, Status: "error", Error: err.Error()})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	return rows, failed, nil
+}
+
+func parseOrderRecord(record []string, columns map[string]int, createdBy uint) (orderImportRow, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	customerName := field("customer_name")
+	if customerName == "" {
+		return orderImportRow{}, errors.New("customer_name is required")
+	}
+
+	orderDate, err := time.ParseInLocation("2006-01-02", field("order_date"), time.Local)
+	if err != nil {
+		return orderImportRow{}, fmt.Errorf("invalid order_date %q, use YYYY-MM-DD", field("order_date"))
+	}
+
+	items, err := parseImportItems(field("items"))
+	if err != nil {
+		return orderImportRow{}, err
+	}
+
+	// The total may be left out when the items add up to it
+	var totalAmount float64
+	if raw := field("total_amount"); raw != "" {
+		totalAmount, err = currency.Parse(raw)
+		if err != nil || totalAmount <= 0 {
+			return orderImportRow{}, fmt.Errorf("invalid total_amount %q", raw)
+		}
+	} else if len(items) == 0 {
+		return orderImportRow{}, errors.New("total_amount is required for orders without items")
+	}
+
+	return orderImportRow{
+		Order: &models.Order{
+			CustomerName:  customerName,
+			CustomerPhone: normalizeCustomerPhone(field("customer_phone")),
+			TotalAmount:   totalAmount,
+			Status:        string(models.OrderPending),
+			OrderDate:     orderDate,
+			CreatedBy:     createdBy,
+		},
+		Items: items,
+	}, nil
+}
+
+// parseImportItems reads "name:qty:price; name:qty:price"
+func parseImportItems(raw string) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid item %q, use name:qty:price", entry)
+		}
+		name := strings.TrimSpace(parts[0])
+		quantity, qtyErr := strconv.Atoi(strings.TrimSpace(parts[1]))
+		price, priceErr := currency.Parse(parts[2])
+		if name == "" || qtyErr != nil || priceErr != nil || quantity <= 0 || price <= 0 {
+			return nil, fmt.Errorf("invalid item %q, use name:qty:price with a positive quantity and price", entry)
+		}
+		items = append(items, models.OrderItem{ItemName: name, Quantity: quantity, UnitPrice: price})
+	}
+	return items, nil
+}
+
+// isTooLarge reports whether err came from http.MaxBytesReader
+func isTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseOrderCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		csv        string
+		wantRows   int
+		wantFailed []int // lines reported as errors
+		wantErr    bool
+	}{
+		{
+			name: "good rows",
+			csv: "customer_name,total_amount,order_date,customer_phone,items\n" +
+				"Budi,150000,2024-05-01,08123456789,\n" +
+				"Sari,,2024-05-02,,Kopi:2:25000;Teh:1:10000\n",
+			wantRows: 2,
+		},
+		{
+			name: "bad rows are reported and skipped",
+			csv: "customer_name,total_amount,order_date,items\n" +
+				",100,2024-05-01,\n" +
+				"Budi,100,01/05/2024,\n" +
+				"Sari,abc,2024-05-01,\n" +
+				"Tono,,2024-05-01,\n" +
+				"Dewi,100,2024-05-01,Kopi:zero:100\n" +
+				"Andi,100,2024-05-01,\n",
+			wantRows:   1,
+			wantFailed: []int{2, 3, 4, 5, 6},
+		},
+		{
+			name: "parse errors do not stop the import",
+			csv: "customer_name,total_amount,order_date\n" +
+				"Budi,\"100,2024-05-01\n",
+			// the unterminated quote swallows the rest of the input
+			wantFailed: []int{2},
+		},
+		{
+			name:    "missing required column",
+			csv:     "customer_name,order_date\nBudi,2024-05-01\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty input",
+			csv:     "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, failed, err := parseOrderCSV(strings.NewReader(tt.csv), 7)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(rows) != tt.wantRows {
+				t.Errorf("got %d rows, want %d", len(rows), tt.wantRows)
+			}
+			var lines []int
+			for _, f := range failed {
+				lines = append(lines, f.Line)
+			}
+			if len(lines) != len(tt.wantFailed) {
+				t.Fatalf("failed lines = %v, want %v", lines, tt.wantFailed)
+			}
+			for i := range lines {
+				if lines[i] != tt.wantFailed[i] {
+					t.Errorf("failed lines = %v, want %v", lines, tt.wantFailed)
+				}
+			}
+			for _, row := range rows {
+				if row.Order.CreatedBy != 7 {
+					t.Errorf("CreatedBy = %d, want 7", row.Order.CreatedBy)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOrderCSVStopsOnOversizeInput(t *testing.T) {
+	var body strings.Builder
+	body.WriteString("customer_name,total_amount,order_date\n")
+	for body.Len() < 4096 {
+		body.WriteString("Budi,100,2024-05-01\n")
+	}
+	limited := http.MaxBytesReader(httptest.NewRecorder(), io.NopCloser(strings.NewReader(body.String())), 1024)
+
+	_, _, err := parseOrderCSV(limited, 1)
+	if err == nil {
+		t.Fatal("expected an error for oversize input")
+	}
+	if !isTooLarge(err) {
+		t.Errorf("err = %v, want a MaxBytesError", err)
+	}
+}
+
+func TestImportOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const goodCSV = "customer_name,total_amount,order_date,items\n" +
+		"Budi,150000,2024-05-01,\n" +
+		"Sari,,2024-05-02,Kopi:2:25000\n" +
+		",100,2024-05-01,\n"
+
+	multipartBody := func(field, content string) (string, *bytes.Buffer) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		part, _ := w.CreateFormFile(field, "orders.csv")
+		part.Write([]byte(content))
+		w.Close()
+		return w.FormDataContentType(), &buf
+	}
+
+	tests := []struct {
+		name        string
+		body        func() (string, io.Reader)
+		wantStatus  int
+		wantCreated int
+		wantFailed  int
+	}{
+		{
+			name: "raw csv body",
+			body: func() (string, io.Reader) {
+				return "text/csv", strings.NewReader(goodCSV)
+			},
+			wantStatus:  http.StatusOK,
+			wantCreated: 2,
+			wantFailed:  1,
+		},
+		{
+			name: "multipart file",
+			body: func() (string, io.Reader) {
+				return multipartBody("file", goodCSV)
+			},
+			wantStatus:  http.StatusOK,
+			wantCreated: 2,
+			wantFailed:  1,
+		},
+		{
+			name: "multipart without a file part",
+			body: func() (string, io.Reader) {
+				return multipartBody("upload", goodCSV)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "oversize raw body",
+			body: func() (string, io.Reader) {
+				row := "Budi,100,2024-05-01,\n"
+				return "text/csv", strings.NewReader("customer_name,total_amount,order_date,items\n" +
+					strings.Repeat(row, maxImportSize/len(row)+1))
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "oversize multipart body",
+			body: func() (string, io.Reader) {
+				return multipartBody("file", strings.Repeat("x", maxImportSize+1))
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{}
+			h := NewAPIHandler(&fakeUserService{users: []*models.User{{ID: 1, Username: "admin"}}}, nil, orders, nil, nil, nil)
+
+			contentType, body := tt.body()
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/orders/import?created_by=admin", body)
+			c.Request.Header.Set("Content-Type", contentType)
+
+			h.ImportOrders(c)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Created int `json:"created"`
+				Failed  int `json:"failed"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Created != tt.wantCreated || resp.Failed != tt.wantFailed {
+				t.Errorf("created/failed = %d/%d, want %d/%d", resp.Created, resp.Failed, tt.wantCreated, tt.wantFailed)
+			}
+			if len(orders.created) != tt.wantCreated {
+				t.Errorf("orders created = %d, want %d", len(orders.created), tt.wantCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"task_manager/internal/services"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestOrderWizardRouting(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), WhatsAppNumber: "628111", Language: "en"}
+
+	type step struct {
+		input string
+		want  string
+	}
+	tests := []struct {
+		name      string
+		steps     []step
+		wantOrder *models.Order
+		wantItems int
+	}{
+		{
+			name: "each step in turn",
+			steps: []step{
+				{input: "Siti", want: services.SessionPrompt("create_order", services.OrderWizardTotal)},
+				{input: "lots", want: "❌ "},
+				{input: "Rp 150.000", want: services.SessionPrompt("create_order", services.OrderWizardItems)},
+				{input: "Kue Lapis 3 50.000", want: "✅ Order created successfully\nOrder #: ORD-0001\nCustomer: Siti"},
+			},
+			wantOrder: &models.Order{CustomerName: "Siti", TotalAmount: 150000},
+			wantItems: 1,
+		},
+		{
+			name: "no items",
+			steps: []step{
+				{input: "Budi", want: services.SessionPrompt("create_order", services.OrderWizardTotal)},
+				{input: "75000", want: services.SessionPrompt("create_order", services.OrderWizardItems)},
+				{input: "skip", want: "✅ Order created successfully"},
+			},
+			wantOrder: &models.Order{CustomerName: "Budi", TotalAmount: 75000},
+		},
+		{
+			name: "abandoned",
+			steps: []step{
+				{input: "Siti", want: services.SessionPrompt("create_order", services.OrderWizardTotal)},
+				{input: "/cancel", want: "❌ Order wizard cancelled."},
+			},
+		},
+		{
+			name: "other commands still work mid-wizard",
+			steps: []step{
+				{input: "/help", want: "\n📱 **Available Commands:**"},
+				{input: "Siti", want: services.SessionPrompt("create_order", services.OrderWizardTotal)},
+				{input: "cancel", want: "❌ Order wizard cancelled."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			client, err := redis.Initialize("redis://" + server.Addr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { client.Close() })
+
+			wa := services.NewWhatsAppService(nil, client, 0)
+			orders := &fakeOrderService{}
+			ai := &fakeAIProcessor{reply: "Hello"}
+			h := newTestHandler(testNow)
+			h.whatsappService = wa
+			h.orderService = orders
+			h.undoService = &fakeUndoService{}
+			h.aiProcessor = ai
+
+			if _, err := wa.StartInteractiveSession(admin.ID, admin.WhatsAppNumber, "create_order"); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.steps {
+				if got := h.processCommand(admin, s.input); !strings.HasPrefix(got, s.want) {
+					t.Fatalf("%q: got %q, want %q", s.input, got, s.want)
+				}
+			}
+			if len(ai.messages) != 0 {
+				t.Errorf("wizard answers reached the AI: %q", ai.messages)
+			}
+
+			if tt.wantOrder == nil {
+				if len(orders.created) != 0 {
+					t.Errorf("created %+v, want no order", orders.created[0])
+				}
+			} else {
+				if len(orders.created) != 1 {
+					t.Fatalf("created %d orders, want 1", len(orders.created))
+				}
+				got := orders.created[0]
+				if got.CustomerName != tt.wantOrder.CustomerName || got.TotalAmount != tt.wantOrder.TotalAmount || got.CreatedBy != admin.ID {
+					t.Errorf("order = %+v, want %+v created by %d", got, tt.wantOrder, admin.ID)
+				}
+				if len(orders.items[got.ID]) != tt.wantItems {
+					t.Errorf("items = %+v, want %d", orders.items[got.ID], tt.wantItems)
+				}
+			}
+
+			// Once the wizard is over the next message goes to the AI again
+			if got := h.processCommand(admin, "thanks"); got != "🤖 Hello" || len(ai.messages) != 1 {
+				t.Errorf("after the wizard got %q, AI saw %q", got, ai.messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: nil, want: 1},
+		{args: []string{"3"}, want: 3},
+		{args: []string{"0"}, want: 1},
+		{args: []string{"-2"}, want: 1},
+		{args: []string{"next"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			if got := parsePage(tt.args); got != tt.want {
+				t.Errorf("parsePage(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalPages(t *testing.T) {
+	tests := []struct {
+		total int64
+		size  int
+		want  int
+	}{
+		{total: 0, size: 10, want: 1},
+		{total: 1, size: 10, want: 1},
+		{total: 10, size: 10, want: 1},
+		{total: 11, size: 10, want: 2},
+		{total: 40, size: 10, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d by %d", tt.total, tt.size), func(t *testing.T) {
+			if got := totalPages(tt.total, tt.size); got != tt.want {
+				t.Errorf("totalPages(%d, %d) = %d, want %d", tt.total, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageFooter(t *testing.T) {
+	tests := []struct {
+		page, pages int
+		want        string
+	}{
+		{page: 1, pages: 4, want: "Page 1/4 — send '/list_tasks 2' for next"},
+		{page: 3, pages: 4, want: "Page 3/4 — send '/list_tasks 4' for next"},
+		{page: 4, pages: 4, want: "Page 4/4"},
+		{page: 1, pages: 1, want: "Page 1/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := pageFooter("en", "/list_tasks", tt.page, tt.pages); got != tt.want {
+				t.Errorf("pageFooter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAllOrdersPages(t *testing.T) {
+	var orders []models.Order
+	for i := 1; i <= 25; i++ {
+		orders = append(orders, models.Order{ID: uint(i), OrderNumber: fmt.Sprintf("ORD-%04d", i), CustomerName: "Siti"})
+	}
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantFirst string
+		wantLast  string
+		wantTail  string
+	}{
+		{name: "first page", wantFirst: "ORD-0001", wantLast: "ORD-0010", wantTail: "Page 1/3 — send '/all_orders 2' for next"},
+		{name: "middle page", args: []string{"2"}, wantFirst: "ORD-0011", wantLast: "ORD-0020", wantTail: "Page 2/3 — send '/all_orders 3' for next"},
+		{name: "last page", args: []string{"3"}, wantFirst: "ORD-0021", wantLast: "ORD-0025", wantTail: "Page 3/3"},
+		{name: "past the end", args: []string{"4"}, wantTail: "❌ Page 4 does not exist (last page is 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = &fakeOrderService{orders: orders}
+
+			reply := h.getAllOrders(admin, tt.args)
+			if !strings.HasSuffix(reply, tt.wantTail) {
+				t.Errorf("reply ends %q, want %q", reply[strings.LastIndex(reply, "\n")+1:], tt.wantTail)
+			}
+			if tt.wantFirst == "" {
+				return
+			}
+			if got := strings.Count(reply, "**Order #"); got > listPageSize {
+				t.Errorf("page lists %d orders, want at most %d", got, listPageSize)
+			}
+			for _, want := range []string{tt.wantFirst, tt.wantLast} {
+				if !strings.Contains(reply, want) {
+					t.Errorf("page does not list %s", want)
+				}
+			}
+		})
+	}
+}
+
+func TestListMaxItemsTruncation(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	listings := []struct {
+		name   string
+		marker string
+		run    func(h *WhatsAppHandler, count int) string
+	}{
+		{
+			name:   "/my_tasks",
+			marker: "Status: ",
+			run: func(h *WhatsAppHandler, count int) string {
+				tasks := map[uint]*models.Task{}
+				for i := 1; i <= count; i++ {
+					tasks[uint(i)] = &models.Task{ID: uint(i), Title: fmt.Sprintf("Task %d", i), AssignedTo: admin.ID}
+				}
+				h.taskService = &fakeTaskService{tasks: tasks}
+				return h.myTasks(admin, nil)
+			},
+		},
+		{
+			name:   "/view_orders",
+			marker: "**Order #",
+			run: func(h *WhatsAppHandler, count int) string {
+				h.orderService = &fakeOrderService{orders: ordersCreatedBy(admin.ID, count)}
+				return h.viewMyOrders(admin)
+			},
+		},
+		{
+			name:   "/all_orders",
+			marker: "**Order #",
+			run: func(h *WhatsAppHandler, count int) string {
+				h.orderService = &fakeOrderService{orders: ordersCreatedBy(admin.ID, count)}
+				return h.getAllOrders(admin, nil)
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		count      int
+		wantShown  int
+		wantNotice string
+	}{
+		{name: "under the limit", limit: 3, count: 2, wantShown: 2},
+		{name: "at the limit", limit: 3, count: 3, wantShown: 3},
+		{name: "over the limit", limit: 3, count: 7, wantShown: 3, wantNotice: "...and 4 more, use pagination"},
+		{name: "unset limit uses the page size", count: 12, wantShown: 10, wantNotice: "...and 2 more, use pagination"},
+	}
+
+	for _, listing := range listings {
+		for _, tt := range tests {
+			t.Run(listing.name+"/"+tt.name, func(t *testing.T) {
+				h := newTestHandler(testNow)
+				h.cfg.ListMaxItems = tt.limit
+
+				got := listing.run(h, tt.count)
+				if shown := strings.Count(got, listing.marker); shown != tt.wantShown {
+					t.Errorf("showed %d items, want %d:\n%s", shown, tt.wantShown, got)
+				}
+				if tt.wantNotice == "" && strings.Contains(got, "more, use pagination") {
+					t.Errorf("unexpected truncation notice:\n%s", got)
+				}
+				if tt.wantNotice != "" && !strings.Contains(got, tt.wantNotice) {
+					t.Errorf("missing %q:\n%s", tt.wantNotice, got)
+				}
+			})
+		}
+	}
+}
+
+// ordersCreatedBy returns count pending orders created by userID
+func ordersCreatedBy(userID uint, count int) []models.Order {
+	orders := make([]models.Order, count)
+	for i := range orders {
+		orders[i] = models.Order{ID: uint(i + 1), OrderNumber: fmt.Sprintf("ORD-%04d", i+1), CreatedBy: userID, Status: "pending"}
+	}
+	return orders
+}
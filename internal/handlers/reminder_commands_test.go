@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestHandleAICreateReminderValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      *models.User
+		data      map[string]interface{}
+		wantReply string
+		wantCount int
+	}{
+		{
+			name:      "own task",
+			user:      &models.User{ID: 1, Role: string(models.Users), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(3), "reminder_type": "deadline", "scheduled_time": "2025-01-20 09:00"},
+			wantReply: "✅ Reminder created!\n📝 Task: #3 Stock opname\n🔔 Type: deadline\n⏰ Scheduled: 2025-01-20 09:00",
+			wantCount: 1,
+		},
+		{
+			name:      "admin on someone else's task",
+			user:      &models.User{ID: 9, Role: string(models.Admin), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(3), "reminder_type": "deadline", "scheduled_time": "2025-01-20 09:00"},
+			wantReply: "✅ Reminder created!",
+			wantCount: 1,
+		},
+		{
+			name:      "recurring",
+			user:      &models.User{ID: 1, Role: string(models.Users), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(3), "reminder_type": "daily", "scheduled_time": "besok jam 8", "recurrence": "Daily"},
+			wantReply: "🔁 Repeats: daily",
+			wantCount: 1,
+		},
+		{
+			name:      "someone else's task",
+			user:      &models.User{ID: 2, Role: string(models.Users), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(3), "reminder_type": "deadline", "scheduled_time": "2025-01-20 09:00"},
+			wantReply: "❌ You can only create reminders for tasks assigned to you.",
+		},
+		{
+			name:      "unknown task",
+			user:      &models.User{ID: 1, Role: string(models.Users), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(4), "reminder_type": "deadline", "scheduled_time": "2025-01-20 09:00"},
+			wantReply: "❌ Task #4 not found.",
+		},
+		{
+			name:      "bad date",
+			user:      &models.User{ID: 1, Role: string(models.Users), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(3), "reminder_type": "deadline", "scheduled_time": "20/13/2025 25:00"},
+			wantReply: "❌ Invalid time format.",
+		},
+		{
+			name:      "missing fields",
+			user:      &models.User{ID: 1, Role: string(models.Users), Language: "en"},
+			data:      map[string]interface{}{"task_id": float64(3)},
+			wantReply: "❌ Incomplete data.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminders := &fakeReminderService{}
+			h := newTestHandler(testNow)
+			h.reminderService = reminders
+			h.taskService = &fakeTaskService{tasks: map[uint]*models.Task{3: {ID: 3, Title: "Stock opname", AssignedTo: 1}}}
+
+			reply := h.handleAICreateReminder(tt.user, &AIResponse{Type: "create_reminder", Data: tt.data})
+			if !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if len(reminders.scheduled) != tt.wantCount {
+				t.Errorf("created %d reminders, want %d", len(reminders.scheduled), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestViewReminders(t *testing.T) {
+	at := time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)
+	h := newTestHandler(testNow)
+	h.taskService = &fakeTaskService{tasks: map[uint]*models.Task{3: {ID: 3, Title: "Stock opname", AssignedTo: 1}}}
+	h.reminderService = &fakeReminderService{reminders: []models.Reminder{
+		{ID: 1, TaskID: 3, ReminderType: "deadline", ScheduledTime: at, WhatsAppSent: true},
+		{ID: 2, TaskID: 3, ReminderType: "daily", ScheduledTime: at.Add(time.Hour), RecurrencePattern: "daily"},
+	}}
+
+	reply := h.handleAIViewReminders(&models.User{ID: 1, Language: "en"}, &AIResponse{Type: "view_reminders"})
+	for _, want := range []string{
+		"**ID: 1** - **Task #3: Stock opname**\n🔔 Type: deadline\n⏰ Scheduled: 2025-01-20 09:00\nStatus: ✅ Sent",
+		"**ID: 2** - **Task #3: Stock opname**\n🔔 Type: daily\n⏰ Scheduled: 2025-01-20 10:00\n🔁 Repeats: daily\nStatus: ❌ Not Sent",
+	} {
+		if !strings.Contains(reply, want) {
+			t.Errorf("reply = %q, want it to contain %q", reply, want)
+		}
+	}
+
+	h.reminderService = &fakeReminderService{}
+	if reply := h.viewReminders(&models.User{ID: 1, Language: "en"}); reply != "🔔 No reminders for your tasks." {
+		t.Errorf("empty reply = %q", reply)
+	}
+}
+
+func TestViewRemindersRouting(t *testing.T) {
+	at := time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		message string
+		ai      *fakeAIProcessor
+	}{
+		{name: "slash command", message: "/view_reminders"},
+		{name: "AI intent", message: "what are my reminders?", ai: &fakeAIProcessor{reply: `{"type":"view_reminders","data":{}}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminders := &fakeReminderService{reminders: []models.Reminder{{ID: 1, TaskID: 3, ReminderType: "deadline", ScheduledTime: at}}}
+			h := newTestHandler(testNow)
+			h.whatsappService = &fakeWhatsAppService{}
+			h.taskService = &fakeTaskService{tasks: map[uint]*models.Task{3: {ID: 3, Title: "Stock opname", AssignedTo: 5}}}
+			h.reminderService = reminders
+			if tt.ai != nil {
+				h.aiProcessor = tt.ai
+			}
+
+			reply := h.processCommand(&models.User{ID: 5, Role: string(models.Users), Language: "en"}, tt.message)
+			if !strings.Contains(reply, "**Task #3: Stock opname**") {
+				t.Errorf("reply = %q, want the caller's reminder", reply)
+			}
+			if len(reminders.lookups) != 1 || reminders.lookups[0] != 5 {
+				t.Errorf("looked up reminders of %v, want user 5 only", reminders.lookups)
+			}
+		})
+	}
+}
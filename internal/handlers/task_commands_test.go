@@ -0,0 +1,977 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestParseDueDate(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// Wednesday 15 January 2025
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, jakarta)
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, jakarta) }
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "2025-02-01", want: day(2, 1)},
+		{input: "besok", want: day(1, 16)},
+		{input: "tomorrow", want: day(1, 16)},
+		{input: "next monday", want: day(1, 20)},
+		{input: "besok jam 9", want: day(1, 16)},
+		{input: "someday", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseDueDate(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDueDate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignTaskOptions(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	due := time.Date(2025, 1, 20, 0, 0, 0, 0, jakarta)
+	tomorrow := time.Date(2025, 1, 16, 0, 0, 0, 0, jakarta)
+
+	tests := []struct {
+		name         string
+		args         string
+		wantDue      *time.Time
+		wantPriority string
+		wantReply    string
+	}{
+		{name: "no options", args: "budi Stock opname count the warehouse", wantPriority: "medium", wantReply: "✅ Task #1 assigned"},
+		{name: "due date", args: "budi Stock opname count the warehouse due:2025-01-20", wantDue: &due, wantPriority: "medium", wantReply: "✅ Task #1 assigned"},
+		{name: "relative due date", args: "budi Stock opname count due:besok", wantDue: &tomorrow, wantPriority: "medium", wantReply: "✅ Task #1 assigned"},
+		{name: "priority then due", args: "budi Stock opname count priority:HIGH due:2025-01-20", wantDue: &due, wantPriority: "high", wantReply: "✅ Task #1 assigned"},
+		{name: "bad due date", args: "budi Stock opname count due:someday", wantReply: "❌ Invalid due date"},
+		{name: "bad priority", args: "budi Stock opname count priority:asap", wantReply: "❌ invalid priority"},
+		{name: "inactive assignee", args: "citra Stock opname count", wantReply: "❌ User citra is inactive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+			h.userService = &fakeUserService{users: []*models.User{
+				{ID: 2, Username: "budi", IsActive: true},
+				{ID: 3, Username: "citra"},
+			}}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			// Sent as a chat message, the way users reach the parser
+			reply := h.processCommand(&models.User{ID: 1, Role: string(models.Admin), Language: "en"}, "/assign_task "+tt.args)
+			if !strings.HasPrefix(reply, tt.wantReply) {
+				t.Fatalf("reply = %q, want prefix %q", reply, tt.wantReply)
+			}
+			if !strings.HasPrefix(tt.wantReply, "✅") {
+				if len(tasks.created) != 0 {
+					t.Errorf("task created despite %q", reply)
+				}
+				return
+			}
+
+			task := tasks.created[0]
+			if task.AssignedTo != 2 || task.Priority != tt.wantPriority {
+				t.Errorf("task = %+v, want assigned to 2 with priority %s", task, tt.wantPriority)
+			}
+			switch {
+			case tt.wantDue == nil && task.DueDate != nil:
+				t.Errorf("DueDate = %v, want none", task.DueDate)
+			case tt.wantDue != nil && (task.DueDate == nil || !task.DueDate.Equal(*tt.wantDue)):
+				t.Errorf("DueDate = %v, want %v", task.DueDate, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestCloneTask(t *testing.T) {
+	due := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	completed := due.Add(time.Hour)
+	source := &models.Task{
+		ID: 5, Title: "Stock opname", Description: "Count the warehouse", AssignedTo: 2,
+		Status: string(models.Completed), Priority: string(models.High), TaskType: string(models.Weekly),
+		CompletionPercentage: 100, IsImplemented: true, DueDate: &due, CompletedAt: &completed,
+	}
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name           string
+		user           *models.User
+		args           []string
+		wantReply      string
+		wantAssignedTo uint
+	}{
+		{name: "same assignee", user: admin, args: []string{"5"}, wantReply: "✅ Task #5 cloned as task #2", wantAssignedTo: 2},
+		{name: "other assignee", user: admin, args: []string{"5", "budi"}, wantReply: "✅ Task #5 cloned as task #2", wantAssignedTo: 3},
+		{name: "unknown source", user: admin, args: []string{"9"}, wantReply: "❌ Task #9 not found"},
+		{name: "unknown assignee", user: admin, args: []string{"5", "nobody"}, wantReply: "❌ User not found: nobody"},
+		{name: "bad id", user: admin, args: []string{"five"}, wantReply: "❌ Invalid task ID"},
+		{name: "regular user", user: &models.User{ID: 2, Role: string(models.Users), Language: "en"}, args: []string{"5"}, wantReply: "❌ Only Admin or Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{tasks: map[uint]*models.Task{5: source}}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+			h.userService = &fakeUserService{users: []*models.User{{ID: 3, Username: "budi", IsActive: true}}}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			reply := h.processCommand(tt.user, strings.Join(append([]string{"/clone_task"}, tt.args...), " "))
+			if !strings.HasPrefix(reply, tt.wantReply) {
+				t.Fatalf("reply = %q, want prefix %q", reply, tt.wantReply)
+			}
+			if tt.wantAssignedTo == 0 {
+				if len(tasks.created) != 0 {
+					t.Errorf("task created despite %q", reply)
+				}
+				return
+			}
+
+			clone := tasks.created[0]
+			if clone.Title != source.Title || clone.Description != source.Description || clone.Priority != source.Priority || clone.TaskType != source.TaskType {
+				t.Errorf("clone = %+v does not copy the source's details", clone)
+			}
+			if clone.AssignedTo != tt.wantAssignedTo || clone.CreatedBy != admin.ID {
+				t.Errorf("clone assigned to %d by %d, want %d by %d", clone.AssignedTo, clone.CreatedBy, tt.wantAssignedTo, admin.ID)
+			}
+			if clone.Status != string(models.Pending) || clone.CompletionPercentage != 0 || clone.IsImplemented || clone.DueDate != nil || clone.CompletedAt != nil {
+				t.Errorf("clone = %+v, want progress, status and dates reset", clone)
+			}
+		})
+	}
+}
+
+func TestAssignToInactiveUserIsRejected(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	aiData := map[string]interface{}{"title": "Stock opname", "description": "Count the warehouse", "assigned_to": "citra"}
+
+	tests := []struct {
+		name   string
+		assign func(h *WhatsAppHandler) string
+	}{
+		{name: "/assign_task", assign: func(h *WhatsAppHandler) string {
+			return h.assignTask(admin, strings.Fields("citra Stock opname count"))
+		}},
+		{name: "/assign_task by id", assign: func(h *WhatsAppHandler) string {
+			return h.assignTask(admin, strings.Fields("3 Stock opname count"))
+		}},
+		{name: "/create_daily_task", assign: func(h *WhatsAppHandler) string {
+			return h.createDailyTask(admin, strings.Fields("citra Stock opname count"))
+		}},
+		{name: "/create_weekly_task", assign: func(h *WhatsAppHandler) string {
+			return h.createWeeklyTask(admin, strings.Fields("citra Stock opname count"))
+		}},
+		{name: "/create_monthly_task", assign: func(h *WhatsAppHandler) string {
+			return h.createMonthlyTask(admin, strings.Fields("citra Stock opname count"))
+		}},
+		{name: "AI assign intent", assign: func(h *WhatsAppHandler) string {
+			return h.handleStructuredAIAssignTask(admin, &AIResponse{Type: "assign_task", Data: aiData})
+		}},
+		{name: "AI assign message", assign: func(h *WhatsAppHandler) string {
+			return h.handleAIAssignTask(admin, "assign task Opname count the warehouse to citra", nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+			h.userService = &fakeUserService{users: []*models.User{{ID: 3, Username: "citra", IsActive: false}}}
+
+			reply := tt.assign(h)
+			if !strings.Contains(reply, "User citra is inactive") {
+				t.Errorf("reply = %q, want the inactive user rejected", reply)
+			}
+			if len(tasks.created) != 0 {
+				t.Errorf("task created for an inactive user: %+v", tasks.created[0])
+			}
+		})
+	}
+}
+
+func TestUserTasks(t *testing.T) {
+	admin := &models.User{ID: 1, Username: "admin", Role: string(models.Admin), Language: "en"}
+	john := &models.User{ID: 2, Username: "john", Role: string(models.Users), Language: "en"}
+	idle := &models.User{ID: 3, Username: "idle", Role: string(models.Users), Language: "en"}
+	tasks := map[uint]*models.Task{
+		1: {ID: 1, Title: "Pack boxes", AssignedTo: 2, Status: string(models.InProgress), CompletionPercentage: 40, Priority: "high"},
+		2: {ID: 2, Title: "Ship order", AssignedTo: 2, Status: string(models.Completed), CompletionPercentage: 100, Priority: "medium"},
+		3: {ID: 3, Title: "Other work", AssignedTo: 1, Status: string(models.Pending)},
+	}
+
+	tests := []struct {
+		name      string
+		caller    *models.User
+		args      []string
+		want      []string
+		wantNotIn string
+	}{
+		{name: "by username", caller: admin, args: []string{"john"}, want: []string{"📝 **Tasks for john:**", "#1 Pack boxes", "Status: 🔄 In Progress\nProgress: 40%", "#2 Ship order", "Status: ✅ Completed\nProgress: 100%"}, wantNotIn: "Other work"},
+		{name: "by id", caller: admin, args: []string{"2"}, want: []string{"📝 **Tasks for john:**"}},
+		{name: "no tasks", caller: admin, args: []string{"idle"}, want: []string{"📝 No tasks assigned to idle."}},
+		{name: "unknown user", caller: admin, args: []string{"nobody"}, want: []string{"❌ User not found: nobody"}},
+		{name: "missing argument", caller: admin, want: []string{"❌ Usage: /user_tasks"}},
+		{name: "regular user is not allowed", caller: john, args: []string{"john"}, want: []string{"❌ Only Admin or Super Admin can use this command."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.userService = &fakeUserService{users: []*models.User{admin, john, idle}}
+			h.taskService = &fakeTaskService{tasks: tasks}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(tt.caller, strings.Join(append([]string{"/user_tasks"}, tt.args...), " "))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("userTasks() = %q, want it to contain %q", got, want)
+				}
+			}
+			if tt.wantNotIn != "" && strings.Contains(got, tt.wantNotIn) {
+				t.Errorf("userTasks() = %q, lists another user's task", got)
+			}
+		})
+	}
+}
+
+func TestHandleAIViewUserTasks(t *testing.T) {
+	admin := &models.User{ID: 1, Username: "admin", Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string
+	}{
+		{name: "assigned_to", data: map[string]interface{}{"assigned_to": " john "}, want: "📝 No tasks assigned to john."},
+		{name: "unknown user", data: map[string]interface{}{"assigned_to": "nobody"}, want: "❌ User not found: nobody"},
+		{name: "missing assigned_to", data: map[string]interface{}{}, want: "❌ Incomplete data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.userService = &fakeUserService{users: []*models.User{admin, {ID: 2, Username: "john"}}}
+			h.taskService = &fakeTaskService{}
+
+			got := h.handleAIViewUserTasks(admin, &AIResponse{Type: "view_user_tasks", Data: tt.data})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("handleAIViewUserTasks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTaskListDueDates(t *testing.T) {
+	past := testNow.AddDate(0, 0, -2)
+	soon := testNow.AddDate(0, 0, 1)
+	tasks := []models.Task{
+		{ID: 1, Title: "No deadline", Status: string(models.Pending)},
+		{ID: 2, Title: "Late", Status: string(models.Pending), DueDate: &past},
+		{ID: 3, Title: "Soon", Status: string(models.InProgress), DueDate: &soon},
+	}
+
+	got := formatTaskList("en", "📝 **Tasks:**", tasks, testNow)
+
+	late, upcoming, undated := strings.Index(got, "#2 Late"), strings.Index(got, "#3 Soon"), strings.Index(got, "#1 No deadline")
+	if late < 0 || upcoming < 0 || undated < 0 || !(late < upcoming && upcoming < undated) {
+		t.Errorf("tasks not ordered by due date with undated last:\n%s", got)
+	}
+	if strings.Count(got, "Overdue") != 1 || !strings.Contains(got, "Status: ⏳ Pending (⚠️ Overdue)") {
+		t.Errorf("only the late task should be overdue:\n%s", got)
+	}
+	if strings.Count(got, "Due: ") != 2 {
+		t.Errorf("undated task shows a due line:\n%s", got)
+	}
+	if tasks[0].ID != 1 {
+		t.Errorf("formatTaskList reordered the caller's slice")
+	}
+}
+
+func TestDeleteTaskAuthorization(t *testing.T) {
+	creator := &models.User{ID: 2, Username: "creator", Role: string(models.Users), Language: "en"}
+	assignee := &models.User{ID: 3, Username: "assignee", Role: string(models.Users), Language: "en"}
+	admin := &models.User{ID: 4, Username: "admin", Role: string(models.Admin), Language: "en"}
+	superAdmin := &models.User{ID: 5, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+
+	tests := []struct {
+		name        string
+		user        *models.User
+		args        []string
+		want        string
+		wantDeleted bool
+	}{
+		{name: "creator", user: creator, args: []string{"1"}, want: "🗑️ Task #1 'Pack boxes' deleted", wantDeleted: true},
+		{name: "admin", user: admin, args: []string{"1"}, want: "🗑️ Task #1 'Pack boxes' deleted", wantDeleted: true},
+		{name: "super admin", user: superAdmin, args: []string{"1"}, want: "🗑️ Task #1 'Pack boxes' deleted", wantDeleted: true},
+		{name: "assignee who did not create it", user: assignee, args: []string{"1"}, want: "❌ You don't have permission to delete this task."},
+		{name: "unknown task", user: admin, args: []string{"9"}, want: "❌ Task #9 not found"},
+		{name: "invalid id", user: admin, args: []string{"one"}, want: "❌ Invalid task ID"},
+		{name: "missing id", user: admin, want: "❌ Usage: /delete_task"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{tasks: map[uint]*models.Task{
+				1: {ID: 1, Title: "Pack boxes", CreatedBy: creator.ID, AssignedTo: assignee.ID},
+			}}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+
+			got := h.deleteTaskCommand(tt.user, tt.args)
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("deleteTaskCommand() = %q, want %q", got, tt.want)
+			}
+			if _, kept := tasks.tasks[1]; kept == tt.wantDeleted {
+				t.Errorf("task kept = %v, want deleted %v", kept, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestHandleAIDeleteTask(t *testing.T) {
+	creator := &models.User{ID: 2, Role: string(models.Users), Language: "en"}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string
+	}{
+		{name: "task id", data: map[string]interface{}{"task_id": float64(1)}, want: "🗑️ Task #1 'Pack boxes' deleted"},
+		{name: "missing task id", data: map[string]interface{}{}, want: "❌ Incomplete data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{tasks: map[uint]*models.Task{1: {ID: 1, Title: "Pack boxes", CreatedBy: creator.ID}}}
+
+			got := h.handleAIDeleteTask(creator, &AIResponse{Type: "delete_task", Data: tt.data})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("handleAIDeleteTask() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTasksByFilter(t *testing.T) {
+	john := &models.User{ID: 2, Username: "john", Role: string(models.Users), Language: "en"}
+	tasks := map[uint]*models.Task{
+		1: {ID: 1, Title: "Pack boxes", AssignedTo: 2, Status: string(models.Pending), Priority: "urgent"},
+		2: {ID: 2, Title: "Ship order", AssignedTo: 2, Status: string(models.Blocked), Priority: "low"},
+		3: {ID: 3, Title: "Someone else's", AssignedTo: 1, Status: string(models.Pending), Priority: "urgent"},
+	}
+
+	tests := []struct {
+		name      string
+		message   string
+		aiReply   string
+		want      []string
+		wantNotIn []string
+	}{
+		{name: "status command", message: "/tasks_by_status pending", want: []string{"📝 **Your pending Tasks (1):**", "#1 Pack boxes"}, wantNotIn: []string{"Ship order", "Someone else's"}},
+		{name: "status command ignores case", message: "/tasks_by_status BLOCKED", want: []string{"📝 **Your blocked Tasks (1):**", "#2 Ship order"}},
+		{name: "status with no tasks", message: "/tasks_by_status overdue", want: []string{"📝 You have no overdue tasks."}},
+		{name: "invalid status", message: "/tasks_by_status done", want: []string{"❌ invalid task status \"done\""}},
+		{name: "status usage", message: "/tasks_by_status", want: []string{"❌ Usage: /tasks_by_status [pending|in_progress|completed|overdue|blocked]"}},
+		{name: "priority command", message: "/tasks_by_priority urgent", want: []string{"📝 **Your urgent Tasks (1):**", "#1 Pack boxes"}, wantNotIn: []string{"Someone else's"}},
+		{name: "invalid priority", message: "/tasks_by_priority critical", want: []string{"❌ invalid task priority \"critical\""}},
+		{name: "priority usage", message: "/tasks_by_priority", want: []string{"❌ Usage: /tasks_by_priority [low|medium|high|urgent]"}},
+		{
+			name:    "AI status intent",
+			message: "lihat task saya yang blocked",
+			aiReply: `{"type":"view_tasks_by_status","data":{"status":"blocked"},"message":"Here are your blocked tasks"}`,
+			want:    []string{"📝 **Your blocked Tasks (1):**", "#2 Ship order"},
+		},
+		{
+			name:    "AI priority intent",
+			message: "lihat task urgent saya",
+			aiReply: `{"type":"view_tasks_by_priority","data":{"priority":"urgent"},"message":"Here are your urgent tasks"}`,
+			want:    []string{"📝 **Your urgent Tasks (1):**", "#1 Pack boxes"},
+		},
+		{
+			name:    "AI intent without a filter",
+			message: "lihat task saya berdasarkan prioritas",
+			aiReply: `{"type":"view_tasks_by_priority","data":{},"message":""}`,
+			want:    []string{"❌ Usage: /tasks_by_priority"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{tasks: tasks}
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			got := h.processCommand(john, tt.message)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("%q = %q, want it to contain %q", tt.message, got, want)
+				}
+			}
+			for _, notIn := range tt.wantNotIn {
+				if strings.Contains(got, notIn) {
+					t.Errorf("%q = %q, should not contain %q", tt.message, got, notIn)
+				}
+			}
+		})
+	}
+}
+
+func TestAssignTaskPriorityFromAI(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	structured := func(priority interface{}) func(h *WhatsAppHandler) string {
+		data := map[string]interface{}{"title": "Stock opname", "description": "Count the warehouse", "assigned_to": "budi"}
+		if priority != nil {
+			data["priority"] = priority
+		}
+		return func(h *WhatsAppHandler) string {
+			return h.handleStructuredAIAssignTask(admin, &AIResponse{Type: "assign_task", Data: data})
+		}
+	}
+	message := func(text string) func(h *WhatsAppHandler) string {
+		return func(h *WhatsAppHandler) string { return h.handleAIAssignTask(admin, text, nil) }
+	}
+
+	tests := []struct {
+		name         string
+		assign       func(h *WhatsAppHandler) string
+		wantPriority string
+		wantReply    string
+	}{
+		{name: "intent with urgent", assign: structured("urgent"), wantPriority: "urgent", wantReply: "⚡ Priority: urgent"},
+		{name: "intent priority ignores case", assign: structured(" High "), wantPriority: "high"},
+		{name: "intent without priority", assign: structured(nil), wantPriority: "medium"},
+		{name: "intent with unknown priority", assign: structured("asap"), wantReply: "❌ invalid priority \"asap\""},
+		{name: "assign urgent task message", assign: message("assign urgent task Opname count the warehouse to budi"), wantPriority: "urgent", wantReply: "⚡ Priority: urgent"},
+		{name: "priority token in message", assign: message("assign task Opname count the warehouse to budi priority:low"), wantPriority: "low"},
+		{name: "message without priority", assign: message("tugaskan task Opname count the warehouse to budi"), wantPriority: "medium"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+			h.userService = &fakeUserService{users: []*models.User{{ID: 2, Username: "budi", IsActive: true}}}
+
+			reply := tt.assign(h)
+			if !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if tt.wantPriority == "" {
+				if len(tasks.created) != 0 {
+					t.Errorf("task created despite %q", reply)
+				}
+				return
+			}
+			if len(tasks.created) != 1 || tasks.created[0].Priority != tt.wantPriority {
+				t.Fatalf("created %+v, want one task with priority %s", tasks.created, tt.wantPriority)
+			}
+			if tt.wantPriority == "medium" && strings.Contains(reply, "⚡ Priority") {
+				t.Errorf("reply = %q, the default priority is not worth mentioning", reply)
+			}
+		})
+	}
+}
+
+func TestMyAssignedTasks(t *testing.T) {
+	admin := &models.User{ID: 1, Username: "admin", Role: string(models.Admin), Language: "en"}
+	other := &models.User{ID: 2, Username: "lead", Role: string(models.Admin), Language: "en"}
+	users := []*models.User{admin, other, {ID: 3, Username: "budi"}, {ID: 4, Username: "citra"}}
+	tasks := map[uint]*models.Task{
+		1: {ID: 1, Title: "Pack boxes", CreatedBy: 1, AssignedTo: 4, Status: string(models.InProgress), CompletionPercentage: 40},
+		2: {ID: 2, Title: "Stock opname", CreatedBy: 1, AssignedTo: 3, Status: string(models.Pending)},
+		3: {ID: 3, Title: "Not mine", CreatedBy: 2, AssignedTo: 3, Status: string(models.Pending)},
+		4: {ID: 4, Title: "Ship order", CreatedBy: 1, AssignedTo: 3, Status: string(models.Completed), CompletionPercentage: 100},
+		5: {ID: 5, Title: "Left the team", CreatedBy: 1, AssignedTo: 9, Status: string(models.Pending)},
+	}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		message string
+		aiReply string
+		want    string
+	}{
+		{
+			name:    "grouped by assignee",
+			user:    admin,
+			message: "/my_assigned_tasks",
+			want: "📋 **Tasks You Assigned:**\n" +
+				"\n👤 **budi**\n- #2 Stock opname — pending, 0%\n- #4 Ship order — completed, 100%\n" +
+				"\n👤 **citra**\n- #1 Pack boxes — in_progress, 40%\n" +
+				"\n👤 **User #9**\n- #5 Left the team — pending, 0%\n",
+		},
+		{
+			name:    "AI intent",
+			user:    other,
+			message: "task yang saya berikan",
+			aiReply: `{"type":"my_assigned_tasks","data":{},"message":""}`,
+			want:    "📋 **Tasks You Assigned:**\n\n👤 **budi**\n- #3 Not mine — pending, 0%\n",
+		},
+		{name: "nothing assigned", user: &models.User{ID: 5, Role: string(models.SuperAdmin), Language: "en"}, message: "/my_assigned_tasks", want: "📋 You haven't assigned any tasks yet."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{tasks: tasks}
+			h.userService = &fakeUserService{users: users}
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			if got := h.processCommand(tt.user, tt.message); got != tt.want {
+				t.Errorf("%q =\n%q\nwant\n%q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAllTasksShowsUsernames(t *testing.T) {
+	superAdmin := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+
+	tests := []struct {
+		name  string
+		tasks map[uint]*models.Task
+		want  []string
+		avoid []string
+	}{
+		{
+			name: "usernames resolved",
+			tasks: map[uint]*models.Task{
+				1: {ID: 1, Title: "Pack boxes", AssignedTo: 3},
+				2: {ID: 2, Title: "Ship order", AssignedTo: 4},
+				3: {ID: 3, Title: "Stock opname", AssignedTo: 3},
+			},
+			want:  []string{"Assigned To: budi\n", "Assigned To: citra\n"},
+			avoid: []string{"User ID", "User #"},
+		},
+		{
+			name: "deleted assignee",
+			tasks: map[uint]*models.Task{
+				1: {ID: 1, Title: "Pack boxes", AssignedTo: 3},
+				2: {ID: 2, Title: "Left over", AssignedTo: 9},
+			},
+			want:  []string{"Assigned To: budi\n", "Assigned To: User #9\n"},
+			avoid: []string{"User ID"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserService{users: []*models.User{superAdmin, {ID: 3, Username: "budi"}, {ID: 4, Username: "citra"}}}
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{tasks: tt.tasks}
+			h.userService = users
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(superAdmin, "/list_tasks")
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("listing missing %q:\n%s", want, got)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(got, avoid) {
+					t.Errorf("listing contains %q:\n%s", avoid, got)
+				}
+			}
+			// One batched lookup, not one query per task
+			if len(users.lookups) != 1 {
+				t.Errorf("looked users up %d times, want 1: %v", len(users.lookups), users.lookups)
+			}
+		})
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	staff := &models.User{ID: 3, Username: "budi", Role: string(models.Users), Language: "en"}
+	admin := &models.User{ID: 1, Username: "admin", Role: string(models.Admin), Language: "en"}
+	tasks := map[uint]*models.Task{
+		1: {ID: 1, Title: "Laporan harian", Description: "Kirim ke owner", AssignedTo: 3, Priority: "medium"},
+		2: {ID: 2, Title: "Stock opname", Description: "Sertakan LAPORAN gudang", AssignedTo: 3, Priority: "high"},
+		3: {ID: 3, Title: "Pack boxes", Description: "Order ORD-0001", AssignedTo: 3, Priority: "low"},
+		4: {ID: 4, Title: "Laporan mingguan", AssignedTo: 4, Priority: "medium"},
+	}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		message string
+		aiReply string
+		wantIDs []uint
+		want    string
+	}{
+		{name: "title match", user: staff, message: "/search_tasks opname", wantIDs: []uint{2}},
+		{name: "description match", user: staff, message: "/search_tasks ORD-0001", wantIDs: []uint{3}},
+		{name: "case-insensitive across fields", user: staff, message: "/search_tasks laporan", wantIDs: []uint{1, 2}},
+		{name: "admins search everyone", user: admin, message: "/search_tasks Laporan", wantIDs: []uint{1, 2, 4}},
+		{
+			name:    "AI intent",
+			user:    staff,
+			message: "cari task gudang",
+			aiReply: `{"type":"search_tasks","data":{"keyword":"gudang"},"message":""}`,
+			wantIDs: []uint{2},
+		},
+		{name: "no matches", user: staff, message: "/search_tasks invoice", want: "🔍 No tasks match 'invoice'."},
+		{name: "no keyword", user: staff, message: "/search_tasks", want: "❌ Usage: /search_tasks [keyword]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{tasks: tasks}
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			got := h.processCommand(tt.user, tt.message)
+			if tt.want != "" {
+				if got != tt.want {
+					t.Errorf("%q = %q, want %q", tt.message, got, tt.want)
+				}
+				return
+			}
+			if !strings.Contains(got, fmt.Sprintf("(%d):**", len(tt.wantIDs))) {
+				t.Errorf("header does not count %d matches:\n%s", len(tt.wantIDs), got)
+			}
+			for id, task := range tasks {
+				listed := strings.Contains(got, fmt.Sprintf("**#%d %s**", id, task.Title))
+				if want := slices.Contains(tt.wantIDs, id); listed != want {
+					t.Errorf("task #%d listed = %v, want %v:\n%s", id, listed, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskReport(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	at := func(d, hour int) *time.Time {
+		t := time.Date(2025, 1, d, hour, 0, 0, 0, testNow.Location())
+		return &t
+	}
+	tasks := map[uint]*models.Task{
+		// Inside the range: 2 days, 12 hours and 1 day 6 hours to complete
+		1: {ID: 1, Priority: "high", CreatedAt: *at(8, 9), CompletedAt: at(10, 9)},
+		2: {ID: 2, Priority: "high", CreatedAt: *at(11, 8), CompletedAt: at(11, 20)},
+		3: {ID: 3, Priority: "low", CreatedAt: *at(12, 8), CompletedAt: at(13, 14)},
+		// Outside the range
+		4: {ID: 4, Priority: "urgent", CreatedAt: *at(1, 8), CompletedAt: at(9, 23)},
+		5: {ID: 5, Priority: "urgent", CreatedAt: *at(13, 8), CompletedAt: at(16, 0)},
+		6: {ID: 6, Priority: "medium", CreatedAt: *at(13, 8)},
+	}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		message string
+		want    string
+	}{
+		{
+			name:    "inside the range",
+			user:    admin,
+			message: "/task_report 2025-01-10 2025-01-15",
+			want: "📊 **Task Report for 2025-01-10 to 2025-01-15:**\n\n" +
+				"Completed Tasks: 3\n- high: 2\n- low: 1\nAverage Time to Complete: 1d 6h\n",
+		},
+		{
+			name:    "relative range",
+			user:    admin,
+			message: "/task_report awal bulan sampai hari ini",
+			want: "📊 **Task Report for 2025-01-01 to 2025-01-15:**\n\n" +
+				"Completed Tasks: 4\n- urgent: 1\n- high: 2\n- low: 1\nAverage Time to Complete: 3d 2h\n",
+		},
+		{name: "nothing completed", user: admin, message: "/task_report 2025-01-20 2025-01-25", want: "📊 No tasks were completed from 2025-01-20 to 2025-01-25."},
+		{name: "no range", user: admin, message: "/task_report", want: "❌ Usage: /task_report [start_date] [end_date] (format: YYYY-MM-DD, or e.g. 'awal bulan sampai hari ini')"},
+		{
+			name:    "regular user",
+			user:    &models.User{ID: 2, Role: string(models.Users), Language: "en"},
+			message: "/task_report 2025-01-10 2025-01-15",
+			want:    "❌ Only Admin or Super Admin can use this command.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{tasks: tasks}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			if got := h.processCommand(tt.user, tt.message); got != tt.want {
+				t.Errorf("%q =\n%q\nwant\n%q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignTaskBulk(t *testing.T) {
+	admin := &models.User{ID: 1, Username: "owner", Role: string(models.Admin), Language: "en"}
+
+	tests := []struct {
+		name         string
+		user         *models.User
+		args         string
+		bulkErr      error
+		want         string
+		wantAssigned []uint
+	}{
+		{
+			name:         "all assigned",
+			user:         admin,
+			args:         "Stock opname | Hitung stok gudang | andi, budi,4",
+			want:         "✅ Task 'Stock opname' assigned to 3 user(s): andi, budi, citra",
+			wantAssigned: []uint{2, 3, 4},
+		},
+		{
+			name:         "unknown user is reported",
+			user:         admin,
+			args:         "Stock opname | | andi,zaki,budi",
+			want:         "✅ Task 'Stock opname' assigned to 2 user(s): andi, budi\n⚠️ Skipped:\n- zaki (not found)",
+			wantAssigned: []uint{2, 3},
+		},
+		{
+			name:         "inactive and repeated users",
+			user:         admin,
+			args:         "Stock opname | | andi,dedi,andi",
+			want:         "✅ Task 'Stock opname' assigned to 1 user(s): andi\n⚠️ Skipped:\n- dedi (inactive)",
+			wantAssigned: []uint{2},
+		},
+		{
+			name: "nobody assignable",
+			user: admin,
+			args: "Stock opname | | zaki",
+			want: "❌ No task created, none of the users could be assigned:\n- zaki (not found)",
+		},
+		{
+			name:    "failure keeps nothing",
+			user:    admin,
+			args:    "Stock opname | | andi,budi",
+			bulkErr: errors.New("connection reset"),
+			want:    "❌ Failed to create tasks, nothing was assigned: connection reset",
+		},
+		{name: "missing users", user: admin, args: "Stock opname | gudang", want: "❌ Usage: /assign_task_bulk"},
+		{name: "regular user", user: &models.User{ID: 2, Role: string(models.Users), Language: "en"}, args: "Stock opname | | andi", want: "❌ Only Admin or Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{bulkErr: tt.bulkErr}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+			h.userService = &fakeUserService{users: []*models.User{
+				{ID: 2, Username: "andi", IsActive: true},
+				{ID: 3, Username: "budi", IsActive: true},
+				{ID: 4, Username: "citra", IsActive: true},
+				{ID: 5, Username: "dedi"},
+			}}
+			h.whatsappService = &fakeWhatsAppService{}
+
+			if got := h.processCommand(tt.user, "/assign_task_bulk "+tt.args); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			var assigned []uint
+			for _, task := range tasks.created {
+				assigned = append(assigned, task.AssignedTo)
+				if task.Title != "Stock opname" || task.CreatedBy != tt.user.ID || task.Status != string(models.Pending) {
+					t.Errorf("task = %+v, want a pending Stock opname created by %d", task, tt.user.ID)
+				}
+			}
+			if !slices.Equal(assigned, tt.wantAssigned) {
+				t.Errorf("assigned to %v, want %v", assigned, tt.wantAssigned)
+			}
+		})
+	}
+}
+
+func TestAssignTaskBulkAI(t *testing.T) {
+	tests := []struct {
+		name    string
+		aiReply string
+		want    string
+	}{
+		{
+			name:    "assignees resolved",
+			aiReply: `{"type":"assign_task_bulk","data":{"title":"Stock opname","description":"Hitung stok","assignees":"andi, zaki,budi"}}`,
+			want:    "✅ Task 'Stock opname' assigned to 2 user(s): andi, budi\n⚠️ Skipped:\n- zaki (not found)",
+		},
+		{
+			name:    "no assignees",
+			aiReply: `{"type":"assign_task_bulk","data":{"title":"Stock opname"}}`,
+			want:    "❌ Incomplete data. Make sure title and assignees are provided.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+			h.taskService = &fakeTaskService{}
+			h.userService = &fakeUserService{users: []*models.User{
+				{ID: 2, Username: "andi", IsActive: true},
+				{ID: 3, Username: "budi", IsActive: true},
+			}}
+
+			admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+			if got := h.processCommand(admin, "tugaskan stock opname ke andi, zaki dan budi"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskProgressHistory(t *testing.T) {
+	at := time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)
+	history := []models.TaskProgress{
+		{ID: 1, TaskID: 3, CompletionPercentage: 20, ImplementationNotes: "Mulai hitung", UpdatedBy: 2, UpdatedAt: at},
+		{ID: 2, TaskID: 3, CompletionPercentage: 60, UpdatedBy: 1, UpdatedAt: at.Add(26 * time.Hour)},
+		{ID: 3, TaskID: 3, CompletionPercentage: 100, IsImplemented: true, ImplementationNotes: "Selesai", UpdatedBy: 9, UpdatedAt: at.Add(50 * time.Hour)},
+	}
+
+	tests := []struct {
+		name string
+		user *models.User
+		args []string
+		want string
+	}{
+		{
+			name: "assignee sees every update in order",
+			user: &models.User{ID: 2, Role: string(models.Users), Language: "en"},
+			args: []string{"3"},
+			want: "📈 **Progress History - Task #3 (Stock opname)**\n\n" +
+				"[2025-01-13 09:00] 20% by andi\n📝 Mulai hitung\n" +
+				"[2025-01-14 11:00] 60% by owner\n" +
+				"[2025-01-15 11:00] 100% by User #9 ✅\n📝 Selesai",
+		},
+		{name: "no updates yet", user: &models.User{ID: 1, Role: string(models.Admin), Language: "en"}, args: []string{"4"}, want: "📈 Task #4 has no progress updates yet."},
+		{name: "someone else's task", user: &models.User{ID: 5, Role: string(models.Users), Language: "en"}, args: []string{"3"}, want: "❌ You can only view the progress history of your own tasks."},
+		{name: "unknown task", user: &models.User{ID: 1, Role: string(models.Admin), Language: "en"}, args: []string{"99"}, want: "❌ Task #99 not found."},
+		{name: "missing ID", user: &models.User{ID: 1, Role: string(models.Admin), Language: "en"}, want: "❌ Usage: /task_progress_history [task_id]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.taskService = &fakeTaskService{
+				tasks: map[uint]*models.Task{
+					3: {ID: 3, Title: "Stock opname", AssignedTo: 2, CreatedBy: 1},
+					4: {ID: 4, Title: "Laporan", AssignedTo: 2, CreatedBy: 1},
+				},
+				progress: map[uint][]models.TaskProgress{3: history},
+			}
+			h.userService = &fakeUserService{users: []*models.User{{ID: 1, Username: "owner"}, {ID: 2, Username: "andi"}}}
+
+			if got := h.taskProgressHistory(tt.user, tt.args); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskUpdatesRejectNonAssignee(t *testing.T) {
+	forbidden := "❌ You can only update tasks assigned to you"
+
+	tests := []struct {
+		name        string
+		command     string
+		actor       uint
+		want        string
+		wantUpdated bool
+	}{
+		{name: "assignee updates progress", command: "/update_progress 3 40", actor: 2, want: "✅ Task progress updated to 40%", wantUpdated: true},
+		{name: "other user updates progress", command: "/update_progress 3 40", actor: 5, want: forbidden},
+		{name: "admin updates progress", command: "/update_progress 3 40", actor: 1, want: "✅ Task progress updated to 40%", wantUpdated: true},
+		{name: "assignee completes", command: "/mark_complete 3", actor: 2, want: "✅ Task marked as implemented", wantUpdated: true},
+		{name: "other user completes", command: "/mark_complete 3", actor: 5, want: forbidden},
+		{name: "admin completes", command: "/mark_complete 3", actor: 1, want: "✅ Task marked as implemented", wantUpdated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := &fakeTaskService{
+				tasks:  map[uint]*models.Task{3: {ID: 3, Title: "Stock opname", AssignedTo: 2, Status: string(models.InProgress)}},
+				admins: []uint{1},
+			}
+			h := newTestHandler(testNow)
+			h.whatsappService = &fakeWhatsAppService{}
+			h.taskService = tasks
+			h.undoService = &fakeUndoService{}
+
+			role := models.Users
+			if tt.actor == 1 {
+				role = models.Admin
+			}
+			if got := h.processCommand(&models.User{ID: tt.actor, Role: string(role), Language: "en"}, tt.command); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if updated := len(tasks.updates) > 0; updated != tt.wantUpdated {
+				t.Errorf("progress updated = %v, want %v", updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestFindAssigneeErrors(t *testing.T) {
+	users := &fakeUserService{users: []*models.User{
+		{ID: 2, Username: "andi", IsActive: true},
+		{ID: 3, Username: "citra"},
+	}}
+
+	tests := []struct {
+		name       string
+		identifier string
+		wantErr    error
+		want       map[string]string
+	}{
+		{name: "active", identifier: "andi"},
+		{
+			name:       "inactive by id",
+			identifier: "3",
+			wantErr:    errAssigneeInactive,
+			want:       map[string]string{"en": "❌ User citra is inactive and cannot be assigned tasks.", "id": "❌ User citra tidak aktif dan tidak dapat diberi task."},
+		},
+		{
+			name:       "unknown",
+			identifier: "zaki",
+			wantErr:    errAssigneeNotFound,
+			want:       map[string]string{"en": "❌ User not found: zaki", "id": "❌ User tidak ditemukan: zaki"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := findAssignee(users, tt.identifier)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			for lang, want := range tt.want {
+				if got := assigneeError(lang, tt.identifier, err); got != want {
+					t.Errorf("%s reply = %q, want %q", lang, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestDeleteUserGuards(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+	deputy := &models.User{ID: 2, Username: "deputy", Role: string(models.SuperAdmin), Language: "en"}
+	admin := &models.User{ID: 3, Username: "admin", Role: string(models.Admin), Language: "en"}
+	staff := &models.User{ID: 4, Username: "staff", Role: string(models.Users), Language: "en"}
+
+	tests := []struct {
+		name        string
+		users       []*models.User
+		caller      *models.User
+		args        []string
+		want        string
+		wantDeleted bool
+	}{
+		{name: "deletes by username", users: []*models.User{owner, staff}, caller: owner, args: []string{"staff"}, want: "✅ User staff (ID: 4) deleted", wantDeleted: true},
+		{name: "deletes by id", users: []*models.User{owner, staff}, caller: owner, args: []string{"4"}, want: "✅ User staff (ID: 4) deleted", wantDeleted: true},
+		{name: "another super admin", users: []*models.User{owner, deputy}, caller: owner, args: []string{"deputy"}, want: "✅ User deputy (ID: 2) deleted", wantDeleted: true},
+		{name: "self delete", users: []*models.User{owner, deputy}, caller: owner, args: []string{"owner"}, want: "❌ You cannot delete yourself."},
+		{name: "last super admin", users: []*models.User{deputy, admin}, caller: &models.User{ID: 9, Username: "ghost", Role: string(models.SuperAdmin), Language: "en"}, args: []string{"deputy"}, want: "❌ Cannot delete the last Super Admin."},
+		{name: "admin is not allowed", users: []*models.User{owner, staff}, caller: admin, args: []string{"staff"}, want: "❌ Only Super Admin can use this command."},
+		{name: "unknown user", users: []*models.User{owner}, caller: owner, args: []string{"nobody"}, want: "❌ User not found: nobody"},
+		{name: "missing argument", users: []*models.User{owner}, caller: owner, want: "❌ Usage: /delete_user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserService{users: append([]*models.User(nil), tt.users...)}
+			h := newTestHandler(testNow)
+			h.userService = users
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(tt.caller, strings.Join(append([]string{"/delete_user"}, tt.args...), " "))
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("deleteUser() = %q, want %q", got, tt.want)
+			}
+			if deleted := len(users.deleted) == 1; deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", users.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestHandleAIDeleteUser(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+	staff := &models.User{ID: 4, Username: "staff", Role: string(models.Users), Language: "en"}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string
+	}{
+		{name: "username", data: map[string]interface{}{"username": " staff "}, want: "✅ User staff (ID: 4) deleted"},
+		{name: "self", data: map[string]interface{}{"username": "owner"}, want: "❌ You cannot delete yourself."},
+		{name: "missing username", data: map[string]interface{}{}, want: "❌ Incomplete data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.userService = &fakeUserService{users: []*models.User{owner, staff}}
+
+			got := h.handleAIDeleteUser(owner, &AIResponse{Type: "delete_user", Data: tt.data})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("handleAIDeleteUser() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRole(t *testing.T) {
+	newUsers := func() []*models.User {
+		return []*models.User{
+			{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"},
+			{ID: 2, Username: "deputy", Role: string(models.SuperAdmin), Language: "en"},
+			{ID: 3, Username: "admin", Role: string(models.Admin), Language: "en"},
+			{ID: 4, Username: "staff", Role: string(models.Users), Language: "en"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		users       []*models.User
+		callerID    uint
+		args        []string
+		want        string
+		wantUpdated string
+	}{
+		{name: "promotes with a friendly role name", callerID: 1, args: []string{"staff", "Admin"}, want: "✅ Role of staff changed: user → admin", wantUpdated: "admin"},
+		{name: "promotes to super admin", callerID: 1, args: []string{"3", "SuperAdmin"}, want: "✅ Role of admin changed: admin → super_admin", wantUpdated: "super_admin"},
+		{name: "demotes a super admin while another remains", callerID: 1, args: []string{"deputy", "user"}, want: "✅ Role of deputy changed: super_admin → user", wantUpdated: "user"},
+		{name: "invalid role", callerID: 1, args: []string{"staff", "Manager"}, want: "❌ Invalid role: Manager"},
+		{name: "unchanged role", callerID: 1, args: []string{"staff", "USER"}, want: "ℹ️ staff already has role user"},
+		{name: "last super admin", users: []*models.User{{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}}, callerID: 1, args: []string{"owner", "Admin"}, want: "❌ Cannot demote the last Super Admin."},
+		{name: "admin is not allowed", callerID: 3, args: []string{"staff", "Admin"}, want: "❌ Only Super Admin can use this command."},
+		{name: "unknown user", callerID: 1, args: []string{"nobody", "Admin"}, want: "❌ User not found: nobody"},
+		{name: "missing role", callerID: 1, args: []string{"staff"}, want: "❌ Usage: /set_role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := tt.users
+			if users == nil {
+				users = newUsers()
+			}
+			service := &fakeUserService{users: users}
+			h := newTestHandler(testNow)
+			h.userService = service
+			h.whatsappService = &fakeWhatsAppService{}
+			caller, _ := service.GetUserByID(tt.callerID)
+
+			got := h.processCommand(caller, strings.Join(append([]string{"/set_role"}, tt.args...), " "))
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("setRole() = %q, want %q", got, tt.want)
+			}
+			switch {
+			case tt.wantUpdated == "" && len(service.updated) > 0:
+				t.Errorf("user updated to %q, want no update", service.updated[0].Role)
+			case tt.wantUpdated != "" && (len(service.updated) != 1 || service.updated[0].Role != tt.wantUpdated):
+				t.Errorf("updated = %+v, want role %q", service.updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestHandleAISetRole(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string
+	}{
+		{name: "username and role", data: map[string]interface{}{"username": "staff", "role": "admin"}, want: "✅ Role of staff changed: user → admin"},
+		{name: "missing role", data: map[string]interface{}{"username": "staff"}, want: "❌ Incomplete data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+			h := newTestHandler(testNow)
+			h.userService = &fakeUserService{users: []*models.User{owner, {ID: 4, Username: "staff", Role: string(models.Users)}}}
+
+			got := h.handleAISetRole(owner, &AIResponse{Type: "set_role", Data: tt.data})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("handleAISetRole() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), Language: "en"}
+
+	tests := []struct {
+		name         string
+		caller       *models.User
+		args         []string
+		want         string
+		wantEmail    string
+		wantPhone    string
+		wantWhatsApp string
+	}{
+		{
+			name: "email", caller: owner, args: []string{"staff", "email", "new@example.com"},
+			want:      "✅ Updated email of staff\nBefore: old@example.com\nAfter: new@example.com",
+			wantEmail: "new@example.com", wantPhone: "628111", wantWhatsApp: "628111",
+		},
+		{name: "invalid email", caller: owner, args: []string{"staff", "email", "not-an-email"}, want: "❌ Invalid email address: not-an-email"},
+		{name: "email with a display name", caller: owner, args: []string{"staff", "email", "Staff", "<staff@example.com>"}, want: "❌ Invalid email address"},
+		{
+			name: "local phone is normalized and synced", caller: owner, args: []string{"staff", "phone", "0812-3456-789"},
+			want:      "✅ Updated phone of staff\nBefore: 628111 (WhatsApp: 628111)\nAfter: 628123456789 (WhatsApp: 628123456789)",
+			wantEmail: "old@example.com", wantPhone: "628123456789", wantWhatsApp: "628123456789",
+		},
+		{
+			name: "international phone", caller: owner, args: []string{"4", "phone", "+62 812 3456 789"},
+			want:      "✅ Updated phone of staff",
+			wantEmail: "old@example.com", wantPhone: "628123456789", wantWhatsApp: "628123456789",
+		},
+		{
+			name: "whatsapp only", caller: owner, args: []string{"staff", "whatsapp", "08987654321"},
+			want:      "✅ Updated whatsapp of staff\nBefore: 628111\nAfter: 628987654321",
+			wantEmail: "old@example.com", wantPhone: "628111", wantWhatsApp: "628987654321",
+		},
+		{name: "phone with letters", caller: owner, args: []string{"staff", "phone", "0812abc4567"}, want: "❌ invalid phone number: 0812abc4567"},
+		{name: "phone too short", caller: owner, args: []string{"staff", "phone", "0812"}, want: "❌ invalid phone number: 0812"},
+		{name: "unknown field", caller: owner, args: []string{"staff", "address", "Jakarta"}, want: "❌ Unknown field: address"},
+		{name: "unknown user", caller: owner, args: []string{"nobody", "email", "a@example.com"}, want: "❌ User not found: nobody"},
+		{name: "missing value", caller: owner, args: []string{"staff", "email"}, want: "❌ Usage: /update_user"},
+		{name: "admin is not allowed", caller: &models.User{ID: 2, Role: string(models.Admin), Language: "en"}, args: []string{"staff", "email", "a@example.com"}, want: "❌ Only Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			staff := &models.User{ID: 4, Username: "staff", Email: "old@example.com", PhoneNumber: "628111", WhatsAppNumber: "628111", Language: "en"}
+			service := &fakeUserService{users: []*models.User{owner, staff}}
+			h := newTestHandler(testNow)
+			h.userService = service
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(tt.caller, strings.Join(append([]string{"/update_user"}, tt.args...), " "))
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("updateUser() = %q, want %q", got, tt.want)
+			}
+			if tt.wantEmail == "" {
+				if len(service.updated) != 0 {
+					t.Errorf("user updated despite the error: %+v", service.updated)
+				}
+				return
+			}
+			if len(service.updated) != 1 {
+				t.Fatalf("updated %d times, want 1", len(service.updated))
+			}
+			updated := service.updated[0]
+			if updated.Email != tt.wantEmail || updated.PhoneNumber != tt.wantPhone || updated.WhatsAppNumber != tt.wantWhatsApp {
+				t.Errorf("updated email=%q phone=%q whatsapp=%q, want %q %q %q",
+					updated.Email, updated.PhoneNumber, updated.WhatsAppNumber, tt.wantEmail, tt.wantPhone, tt.wantWhatsApp)
+			}
+		})
+	}
+}
+
+func TestDestructiveAIActionConfirmation(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), WhatsAppNumber: "628111", Language: "en"}
+	staff := &models.User{ID: 4, Username: "staff", Role: string(models.Users), Language: "en"}
+	deleteStaff := `{"type": "delete_user", "data": {"username": "staff"}, "message": "Deleting staff"}`
+
+	tests := []struct {
+		name        string
+		wait        time.Duration
+		reply       string
+		want        string
+		wantDeleted bool
+	}{
+		{name: "confirm", reply: "YES", want: "✅ User staff (ID: 4) deleted", wantDeleted: true},
+		{name: "confirm in indonesian", wait: time.Minute, reply: "ya!", want: "✅ User staff (ID: 4) deleted", wantDeleted: true},
+		{name: "decline", reply: "no", want: "❌ Cancelled."},
+		{name: "timeout", wait: 3 * time.Minute, reply: "yes", want: "🤖 Nothing to do"},
+		{name: "unrelated reply drops the action", reply: "what time is it", want: "🤖 Nothing to do"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserService{users: []*models.User{owner, staff}}
+			wa := &fakeWhatsAppService{}
+			ai := &fakeAIProcessor{reply: deleteStaff}
+			now := clock.NewFake(testNow)
+			h := newTestHandler(testNow)
+			h.clock = now
+			h.userService = users
+			h.whatsappService = wa
+			h.aiProcessor = ai
+
+			prompt := h.processCommand(owner, "hapus user staff")
+			if !strings.Contains(prompt, "delete user 'staff'") || !strings.Contains(prompt, "Reply YES to confirm") {
+				t.Fatalf("prompt = %q, want a confirmation request", prompt)
+			}
+			if len(users.deleted) != 0 {
+				t.Fatalf("user deleted before confirmation")
+			}
+
+			now.Advance(tt.wait)
+			ai.reply = "Nothing to do"
+			if got := h.processCommand(owner, tt.reply); got != tt.want {
+				t.Errorf("reply %q = %q, want %q", tt.reply, got, tt.want)
+			}
+			if deleted := len(users.deleted) == 1; deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", users.deleted, tt.wantDeleted)
+			}
+			if len(wa.temp) != 0 {
+				t.Errorf("pending action still stored: %v", wa.temp)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), WhatsAppNumber: "628111", Language: "en"}
+	admin := &models.User{ID: 2, Username: "admin", Role: string(models.Admin), WhatsAppNumber: "628222", Language: "en"}
+	staff := &models.User{ID: 3, Username: "budi", Role: string(models.Users), WhatsAppNumber: "628333", Language: "en"}
+	silent := &models.User{ID: 4, Username: "citra", Role: string(models.Users), Language: "en"}
+
+	tests := []struct {
+		name       string
+		caller     *models.User
+		message    string
+		sendErr    error
+		want       string
+		wantReset  bool
+		wantSentTo string
+	}{
+		{name: "by username", caller: owner, message: "/reset_password budi", want: "✅ New password sent to budi via WhatsApp", wantReset: true, wantSentTo: "628333"},
+		{name: "by ID", caller: owner, message: "/reset_password 3", want: "✅ New password sent to budi via WhatsApp", wantReset: true, wantSentTo: "628333"},
+		{name: "unknown user", caller: owner, message: "/reset_password ani", want: "❌ User not found: ani"},
+		{name: "no WhatsApp number", caller: owner, message: "/reset_password citra", want: "❌ citra has no WhatsApp number to send the new password to."},
+		{
+			name:      "delivery fails",
+			caller:    owner,
+			message:   "/reset_password budi",
+			sendErr:   errors.New("gateway down"),
+			want:      "⚠️ Password for budi was reset but could not be delivered. Run /reset_password again.",
+			wantReset: true,
+		},
+		{name: "no target", caller: owner, message: "/reset_password", want: "❌ Usage: /reset_password [username_or_id]"},
+		{name: "admin", caller: admin, message: "/reset_password budi", want: "❌ Only Super Admin can use this command."},
+		{name: "regular user", caller: staff, message: "/reset_password budi", want: "❌ Only Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserService{users: []*models.User{owner, admin, staff, silent}}
+			wa := &fakeWhatsAppService{sendErr: tt.sendErr}
+			h := newTestHandler(testNow)
+			h.userService = users
+			h.whatsappService = wa
+
+			if got := h.processCommand(tt.caller, tt.message); got != tt.want {
+				t.Errorf("%q = %q, want %q", tt.message, got, tt.want)
+			}
+			if reset := len(users.resets) == 1; reset != tt.wantReset {
+				t.Errorf("resets = %v, want reset %v", users.resets, tt.wantReset)
+			}
+
+			// The password goes only to the target, never back to the caller
+			if tt.wantSentTo == "" {
+				if len(wa.sent) != 0 {
+					t.Errorf("sent %q", wa.sent)
+				}
+				return
+			}
+			if len(wa.sent) != 1 || wa.recipients[0] != tt.wantSentTo {
+				t.Fatalf("sent %q to %q, want one message to %s", wa.sent, wa.recipients, tt.wantSentTo)
+			}
+			if !strings.Contains(wa.sent[0], "🔑 New password: Pw3xyzKLMNP") || !strings.Contains(wa.sent[0], "Username: budi") {
+				t.Errorf("message %q does not carry the new credentials", wa.sent[0])
+			}
+		})
+	}
+}
+
+func TestAIAddedUserRoleGrantsPrivileges(t *testing.T) {
+	owner := &models.User{ID: 1, Username: "owner", Role: string(models.SuperAdmin), WhatsAppNumber: "628111", Language: "en"}
+	// The created user has no language set, so their replies use the default one
+	const (
+		superAdminDenied = "❌ Hanya Super Admin yang dapat menggunakan perintah ini."
+		managersDenied   = "❌ Hanya Admin atau Super Admin yang dapat menggunakan perintah ini."
+		allTasks         = "📝 **Semua Task:**\n\nTidak ada task."
+		assignedTasks    = "📋 Anda belum menugaskan task apa pun."
+	)
+
+	tests := []struct {
+		name         string
+		role         string
+		wantRole     string
+		wantSuper    string
+		wantManagers string
+	}{
+		{name: "SuperAdmin", role: "SuperAdmin", wantRole: "super_admin", wantSuper: allTasks, wantManagers: assignedTasks},
+		{name: "Super-Admin", role: "Super-Admin", wantRole: "super_admin", wantSuper: allTasks, wantManagers: assignedTasks},
+		{name: "Admin", role: "Admin", wantRole: "admin", wantSuper: superAdminDenied, wantManagers: assignedTasks},
+		{name: "User", role: "User", wantRole: "user", wantSuper: superAdminDenied, wantManagers: managersDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserService{users: []*models.User{owner}}
+			ai := &fakeAIProcessor{reply: `{"type":"add_user","data":{"username":"ega","email":"ega@example.com","phone":"08123456789","role":"` + tt.role + `"}}`}
+			h := newTestHandler(testNow)
+			h.userService = users
+			h.taskService = &fakeTaskService{}
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = ai
+
+			h.processCommand(owner, "tambahkan user ega ega@example.com 08123456789 "+tt.role)
+			if got := h.processCommand(owner, "YES"); !strings.Contains(got, "🔑 Role: "+tt.wantRole) {
+				t.Fatalf("confirmation reply = %q, want role %s", got, tt.wantRole)
+			}
+			if len(users.users) != 2 {
+				t.Fatalf("created %d users, want 1", len(users.users)-1)
+			}
+			created := users.users[1]
+			if created.Role != tt.wantRole {
+				t.Errorf("stored role = %q, want %q", created.Role, tt.wantRole)
+			}
+
+			// The new user's privileges follow from the stored role
+			if got := h.processCommand(created, "/list_tasks"); got != tt.wantSuper {
+				t.Errorf("/list_tasks = %q, want %q", got, tt.wantSuper)
+			}
+			if got := h.processCommand(created, "/my_assigned_tasks"); got != tt.wantManagers {
+				t.Errorf("/my_assigned_tasks = %q, want %q", got, tt.wantManagers)
+			}
+		})
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *models.User
+		message  string
+		aiReply  string
+		want     []string
+		wantCmds []string
+		denyCmds []string
+	}{
+		{
+			name:     "super admin",
+			user:     &models.User{Username: "owner", Role: string(models.SuperAdmin), WhatsAppNumber: "628111", IsActive: true, Language: "en"},
+			message:  "/whoami",
+			want:     []string{"Username: owner\nRole: Super Admin\nWhatsApp: 628111\nStatus: Active\nLanguage: en\n", "Besides the general commands you can use:\n"},
+			wantCmds: []string{"/add_user", "/reset_password", "/restore_order", "/list_users", "/assign_task"},
+		},
+		{
+			// Friendly spellings stored before roles were normalized still resolve
+			name:     "legacy role spelling",
+			user:     &models.User{Username: "old", Role: "SuperAdmin", IsActive: true, Language: "en"},
+			message:  "/whoami",
+			want:     []string{"Role: Super Admin\n"},
+			wantCmds: []string{"/add_user"},
+		},
+		{
+			name:     "admin",
+			user:     &models.User{Username: "lead", Role: string(models.Admin), WhatsAppNumber: "628222", IsActive: true, Language: "en"},
+			message:  "/whoami",
+			want:     []string{"Role: Admin\n"},
+			wantCmds: []string{"/list_users", "/assign_task", "/create_order", "/task_report"},
+			denyCmds: []string{"/add_user", "/set_role", "/reset_password", "/list_tasks", "/restore_order"},
+		},
+		{
+			name:    "user via AI",
+			user:    &models.User{Username: "budi", Role: string(models.Users), WhatsAppNumber: "628333", Language: "en"},
+			message: "siapa saya?",
+			aiReply: `{"type":"whoami","data":{},"message":""}`,
+			want:    []string{"Role: User\n", "Status: Inactive\n", "\nYou can use the general commands; see /help."},
+		},
+		{
+			name:    "indonesian",
+			user:    &models.User{Username: "budi", Role: string(models.Users), IsActive: true, Language: "id"},
+			message: "/whoami",
+			want:    []string{"👤 **Siapa Saya**", "Role: User\n", "Bahasa: id\n", "Anda dapat menggunakan perintah umum"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.whatsappService = &fakeWhatsAppService{}
+			h.aiProcessor = &fakeAIProcessor{reply: tt.aiReply}
+
+			got := h.processCommand(tt.user, tt.message)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("reply does not contain %q:\n%s", want, got)
+				}
+			}
+
+			var listed []string
+			if _, commands, ok := strings.Cut(got, "you can use:\n"); ok {
+				listed = strings.Split(commands, ", ")
+			}
+			for _, cmd := range tt.wantCmds {
+				if !slices.Contains(listed, cmd) {
+					t.Errorf("%s missing from %v", cmd, listed)
+				}
+			}
+			for _, cmd := range tt.denyCmds {
+				if slices.Contains(listed, cmd) {
+					t.Errorf("%s listed for a %s", cmd, tt.user.Role)
+				}
+			}
+			if len(tt.wantCmds) == 0 && len(listed) != 0 {
+				t.Errorf("restricted commands listed: %v", listed)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"task_manager/internal/services"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	const secret = "3f9c2a7d41e0b8"
+	body := []byte(`{"from":"628123456789@s.whatsapp.net","message":{"text":"/help"}}`)
+	sign := func(key string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "hmac valid", mode: WebhookAuthHMAC, headers: map[string]string{"X-Hub-Signature-256": sign(secret)}, want: true},
+		{name: "hmac upper-case hex", mode: WebhookAuthHMAC, headers: map[string]string{"X-Hub-Signature-256": "sha256=" + strings.ToUpper(strings.TrimPrefix(sign(secret), "sha256="))}, want: true},
+		{name: "hmac wrong key", mode: WebhookAuthHMAC, headers: map[string]string{"X-Hub-Signature-256": sign("other")}},
+		{name: "hmac missing header", mode: WebhookAuthHMAC},
+		{name: "hmac ignores static header", mode: WebhookAuthHMAC, headers: map[string]string{"X-Webhook-Secret": secret}},
+		{name: "static valid", mode: WebhookAuthStatic, headers: map[string]string{"X-Webhook-Secret": secret}, want: true},
+		{name: "static wrong secret", mode: WebhookAuthStatic, headers: map[string]string{"X-Webhook-Secret": "guess"}},
+		{name: "static missing header", mode: WebhookAuthStatic},
+		{name: "none", mode: WebhookAuthNone, want: true},
+		{name: "unknown mode", mode: "basic", headers: map[string]string{"X-Webhook-Secret": secret}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.cfg.WebhookAuthMode = tt.mode
+			h.cfg.WhatsappWebhookSecret = secret
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/whatsapp/webhook", nil)
+			for k, v := range tt.headers {
+				c.Request.Header.Set(k, v)
+			}
+
+			if got := h.verifyWebhook(c, body); got != tt.want {
+				t.Errorf("verifyWebhook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "unsigned", body: `{"message":{"text":"/help"}}`, wantStatus: http.StatusUnauthorized},
+		{name: "oversize", body: `{"message":{"text":"` + strings.Repeat("a", maxWebhookSize) + `"}}`, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.cfg.WebhookAuthMode = WebhookAuthStatic
+			h.cfg.WhatsappWebhookSecret = "3f9c2a7d41e0b8"
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/whatsapp/webhook", strings.NewReader(tt.body))
+
+			h.HandleWebhook(c)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookLogsCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	wa := &fakeWhatsAppService{}
+	h := newTestHandler(testNow)
+	h.cfg.WebhookAuthMode = WebhookAuthNone
+	h.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	h.rateLimiter = fakeRateLimiter{}
+	h.whatsappService = wa
+	seen := testNow.Add(-time.Hour)
+	h.userService = &fakeUserService{users: []*models.User{
+		{ID: 1, WhatsAppNumber: "628123456789", Role: string(models.Admin), LastSeenAt: &seen},
+	}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	body := `{"from":"628123456789@s.whatsapp.net","message":{"id":"ABC123","text":"/help"}}`
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/whatsapp/webhook", strings.NewReader(body))
+
+	h.HandleWebhook(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Correlation-ID"); got != "ABC123" {
+		t.Errorf("X-Correlation-ID = %q, want ABC123", got)
+	}
+	if len(wa.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(wa.sent))
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if !strings.Contains(line, "correlation_id=ABC123") {
+			t.Errorf("log line without correlation ID: %s", line)
+		}
+	}
+	if !strings.Contains(logs.String(), "outcome=success") {
+		t.Errorf("logs do not record the outcome:\n%s", logs.String())
+	}
+}
+
+func TestHandleWebhookWelcomesOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		template string
+		role     string
+		want     []string
+	}{
+		{name: "default template", role: string(models.Users), want: []string{"👋 Welcome john! You are registered as User.", "/my_tasks - View your tasks"}},
+		{name: "admin commands", role: string(models.Admin), want: []string{"You are registered as Admin.", "/assign_task - Assign a task"}},
+		{name: "custom template", template: `Halo {username} ({role})!\n{commands}`, role: string(models.SuperAdmin), want: []string{"Halo john (Super Admin)!\n/list_users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wa := &fakeWhatsAppService{}
+			h := newTestHandler(testNow)
+			h.cfg.WebhookAuthMode = WebhookAuthNone
+			h.cfg.WelcomeMessage = tt.template
+			h.rateLimiter = fakeRateLimiter{}
+			h.whatsappService = wa
+			h.userService = &fakeUserService{users: []*models.User{
+				{ID: 1, Username: "john", WhatsAppNumber: "628123456789", Role: tt.role, Language: "en"},
+			}}
+
+			for i := 0; i < 2; i++ {
+				rec := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(rec)
+				body := `{"from":"628123456789@s.whatsapp.net","message":{"text":"/whoami"}}`
+				c.Request = httptest.NewRequest(http.MethodPost, "/api/whatsapp/webhook", strings.NewReader(body))
+				h.HandleWebhook(c)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+				}
+			}
+
+			// Welcome and reply, then just the reply
+			if len(wa.sent) != 3 {
+				t.Fatalf("sent %d messages, want 3: %q", len(wa.sent), wa.sent)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(wa.sent[0], want) {
+					t.Errorf("welcome = %q, want it to contain %q", wa.sent[0], want)
+				}
+			}
+			for _, reply := range wa.sent[1:] {
+				if strings.Contains(reply, "john (") || strings.Contains(reply, "Welcome") {
+					t.Errorf("welcome sent again: %q", reply)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	slowDown := messages["rate_limited"][models.DefaultLanguage]
+
+	tests := []struct {
+		name        string
+		limit       int
+		burst       int
+		wantReplies int
+		wantLimited int
+	}{
+		{name: "within the limit", limit: 3, burst: 3, wantReplies: 3},
+		{name: "burst", limit: 2, burst: 6, wantReplies: 2, wantLimited: 4},
+		{name: "disabled", limit: 0, burst: 6, wantReplies: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			client, err := redis.Initialize("redis://" + server.Addr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { client.Close() })
+
+			wa := &fakeWhatsAppService{}
+			h := newTestHandler(testNow)
+			h.cfg.WebhookAuthMode = WebhookAuthNone
+			h.rateLimiter = services.NewRateLimiter(client, services.RateLimitConfig{Limit: tt.limit, Window: time.Minute})
+			h.whatsappService = wa
+			seen := testNow.Add(-time.Hour)
+			h.userService = &fakeUserService{users: []*models.User{
+				{ID: 1, WhatsAppNumber: "628123456789", Role: string(models.Admin), LastSeenAt: &seen},
+			}}
+
+			send := func(from string) string {
+				rec := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(rec)
+				body := `{"from":"` + from + `@s.whatsapp.net","message":{"text":"/whoami"}}`
+				c.Request = httptest.NewRequest(http.MethodPost, "/api/whatsapp/webhook", strings.NewReader(body))
+				h.HandleWebhook(c)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+				}
+				return rec.Body.String()
+			}
+
+			// The same number in two formats shares one budget
+			var limited int
+			for i := 0; i < tt.burst; i++ {
+				from := "628123456789"
+				if i%2 == 1 {
+					from = "08123456789"
+				}
+				if strings.Contains(send(from), "rate_limited") {
+					limited++
+				}
+			}
+			if limited != tt.wantLimited {
+				t.Errorf("%d messages rate limited, want %d", limited, tt.wantLimited)
+			}
+
+			// One slow-down notice however long the burst goes on
+			var replies, notices int
+			for _, msg := range wa.sent {
+				if msg == slowDown {
+					notices++
+				} else {
+					replies++
+				}
+			}
+			wantNotices := 0
+			if tt.wantLimited > 0 {
+				wantNotices = 1
+			}
+			if replies != tt.wantReplies || notices != wantNotices {
+				t.Errorf("sent %d replies and %d notices, want %d and %d", replies, notices, tt.wantReplies, wantNotices)
+			}
+
+			// A new window lets the sender through again
+			server.FastForward(time.Minute)
+			if body := send("628123456789"); strings.Contains(body, "rate_limited") {
+				t.Errorf("still limited after the window: %s", body)
+			}
+		})
+	}
+}
@@ -1,27 +1,54 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
+	"net/mail"
 	"regexp"
 	"strconv"
 	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/config"
+	"task_manager/internal/currency"
+	"task_manager/internal/dateparse"
+	"task_manager/internal/features"
+	"task_manager/internal/logging"
 	"task_manager/internal/models"
 	"task_manager/internal/redis"
+	"task_manager/internal/repository"
 	"task_manager/internal/services"
+	"task_manager/pkg/whatsapp"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 )
 
 type WhatsAppHandler struct {
+	cfg             *config.Config
 	whatsappService services.WhatsAppService
 	userService     services.UserService
 	taskService     services.TaskService
 	orderService    services.OrderService
 	reminderService services.ReminderService
 	aiProcessor     services.AIProcessor
+	undoService     services.UndoService
+	cleanupService  services.CleanupService
+	tenantService   services.TenantService
+	rateLimiter     services.RateLimiter
+	logger          *slog.Logger
+	clock           clock.Clock
 }
 
 // AIResponse represents structured AI response
@@ -32,20 +59,37 @@ type AIResponse struct {
 }
 
 func NewWhatsAppHandler(
+	cfg *config.Config,
 	whatsappService services.WhatsAppService,
 	userService services.UserService,
 	taskService services.TaskService,
 	orderService services.OrderService,
 	reminderService services.ReminderService,
 	aiProcessor services.AIProcessor,
+	undoService services.UndoService,
+	cleanupService services.CleanupService,
+	tenantService services.TenantService,
+	rateLimiter services.RateLimiter,
+	logger *slog.Logger,
+	clk clock.Clock,
 ) *WhatsAppHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &WhatsAppHandler{
+		cfg:             cfg,
 		whatsappService: whatsappService,
 		userService:     userService,
 		taskService:     taskService,
 		orderService:    orderService,
 		reminderService: reminderService,
 		aiProcessor:     aiProcessor,
+		undoService:     undoService,
+		cleanupService:  cleanupService,
+		tenantService:   tenantService,
+		rateLimiter:     rateLimiter,
+		logger:          logger,
+		clock:           clock.OrReal(clk),
 	}
 }
 
@@ -53,6 +97,7 @@ type WebhookRequest struct {
 	SenderID  string `json:"sender_id"`
 	ChatID    string `json:"chat_id"`
 	From      string `json:"from"`
+	To        string `json:"to"`
 	Timestamp string `json:"timestamp"`
 	Pushname  string `json:"pushname"`
 	Message   struct {
@@ -68,46 +113,270 @@ type SendMessageRequest struct {
 	Message string `json:"message"`
 }
 
+// Webhook authentication modes selected by WEBHOOK_AUTH_MODE
+const (
+	WebhookAuthHMAC   = config.WebhookAuthHMAC
+	WebhookAuthStatic = config.WebhookAuthStatic
+	WebhookAuthNone   = config.WebhookAuthNone
+)
+
+// maxWebhookSize bounds the webhook body read before it is authenticated
+const maxWebhookSize = 1 << 20
+
+// verifyWebhook authenticates a webhook call against the shared secret. In
+// hmac mode the gateway signs the raw body with HMAC-SHA256 and sends
+// "sha256=<hex>" in X-Hub-Signature-256; in static mode it sends the secret
+// itself in X-Webhook-Secret
+func (h *WhatsAppHandler) verifyWebhook(c *gin.Context, body []byte) bool {
+	secret := h.cfg.WhatsappWebhookSecret
+
+	switch h.cfg.WebhookAuthMode {
+	case WebhookAuthNone:
+		return true
+	case WebhookAuthStatic:
+		provided := c.GetHeader("X-Webhook-Secret")
+		return provided != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) == 1
+	case WebhookAuthHMAC:
+		signature := strings.TrimPrefix(c.GetHeader("X-Hub-Signature-256"), "sha256=")
+		if signature == "" {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		return subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(expected)) == 1
+	default:
+		h.logger.Error("Unknown WEBHOOK_AUTH_MODE, rejecting webhook", "mode", h.cfg.WebhookAuthMode)
+		return false
+	}
+}
+
 func (h *WhatsAppHandler) HandleWebhook(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookSize))
+	if err != nil {
+		if isTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	if !h.verifyWebhook(c, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+		return
+	}
+
 	var req WebhookRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
 		return
 	}
 
+	// Everything logged while handling this message carries its correlation ID
+	correlationID := logging.CorrelationID(req.Message.ID)
+	h = h.withLogger(h.logger.With("correlation_id", correlationID))
+	c.Header("X-Correlation-ID", correlationID)
+
+	// Reply through the gateway of the tenant the message was sent to
+	h, err = h.forTenant(req.To)
+	if errors.Is(err, services.ErrTenantInactive) {
+		c.JSON(http.StatusOK, gin.H{"status": "tenant_inactive"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to resolve tenant", "to", req.To, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tenant"})
+		return
+	}
+
 	// Extract phone number from 'from' field (format: 628123456789@s.whatsapp.net)
 	phoneNumber := req.From
 	if phoneNumber == "" {
 		phoneNumber = req.SenderID
 	}
 	
-	// Remove @s.whatsapp.net suffix if present
-	if strings.Contains(phoneNumber, "@s.whatsapp.net") {
-		phoneNumber = strings.Replace(phoneNumber, "@s.whatsapp.net", "", 1)
+	// Strip the JID suffix and bring the number into canonical form
+	phoneNumber = whatsapp.NormalizePhone(phoneNumber)
+
+	// Drop floods before they reach the database or the AI
+	allowed, notify, err := h.rateLimiter.Allow(phoneNumber)
+	if err != nil {
+		h.logger.Error("Rate limit check failed", "phone", phoneNumber, "error", err)
+	}
+	if !allowed {
+		if notify {
+			if err := h.whatsappService.SendMessage(phoneNumber, t(models.DefaultLanguage, "rate_limited")); err != nil {
+				h.logger.Error("Failed to send message", "phone", phoneNumber, "error", err)
+			}
+		}
+		h.logger.Info("Webhook handled", "phone", phoneNumber, "outcome", "rate_limited")
+		c.JSON(http.StatusOK, gin.H{"status": "rate_limited"})
+		return
 	}
 
 	// Get user by WhatsApp number
 	user, err := h.userService.GetUserByWhatsAppNumber(phoneNumber)
 	if err != nil {
 		// Send error message
-		h.whatsappService.SendMessage(phoneNumber, "❌ User not found. Please contact administrator.")
+		if err := h.whatsappService.SendMessage(phoneNumber, t(models.DefaultLanguage, "user_not_found")); err != nil {
+			h.logger.Error("Failed to send message", "phone", phoneNumber, "error", err)
+		}
+		h.logger.Info("Webhook handled", "phone", phoneNumber, "outcome", "user_not_found")
 		c.JSON(http.StatusOK, gin.H{"status": "user_not_found"})
 		return
 	}
 
+	// Greet users on their very first message before handling it
+	firstSeen, err := h.userService.RecordSeen(user.ID)
+	if err != nil {
+		h.logger.Error("Failed to record last seen", "user_id", user.ID, "error", err)
+	} else if firstSeen {
+		if err := h.whatsappService.SendMessage(phoneNumber, h.welcomeMessage(user)); err != nil {
+			h.logger.Error("Failed to send welcome message", "user_id", user.ID, "error", err)
+		}
+	}
+
 	// Process command
 	response := h.processCommand(user, req.Message.Text)
 	
-	// Send response
-	err = h.whatsappService.SendMessage(phoneNumber, response)
+	// Send response, split into several messages when it is too long
+	if utf8.RuneCountInString(response) > h.whatsappService.MessageLimit() {
+		err = h.whatsappService.SendLongMessage(phoneNumber, response)
+	} else {
+		err = h.whatsappService.SendMessage(phoneNumber, response)
+	}
 	if err != nil {
+		h.logger.Error("Failed to send reply", "user_id", user.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		return
 	}
 
+	h.logger.Info("Webhook handled", "user_id", user.ID, "outcome", "success")
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
+// withLogger returns a copy of h that logs through logger
+func (h *WhatsAppHandler) withLogger(logger *slog.Logger) *WhatsAppHandler {
+	scoped := *h
+	scoped.logger = logger
+	return &scoped
+}
+
+// forTenant returns a handler that sends through the gateway of the tenant
+// owning the number to. h itself is returned when the number belongs to no
+// tenant
+func (h *WhatsAppHandler) forTenant(to string) (*WhatsAppHandler, error) {
+	if h.tenantService == nil {
+		return h, nil
+	}
+
+	tenant, err := h.tenantService.ResolveTenant(to)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return h, nil
+	}
+
+	client, err := h.tenantService.ClientFor(tenant)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *h
+	scoped.whatsappService = h.whatsappService.WithClient(client)
+	return &scoped, nil
+}
+
+// welcomeMessage fills the welcome template for user: WELCOME_MESSAGE when
+// the operator set one, the catalog text in the user's language otherwise
+func (h *WhatsAppHandler) welcomeMessage(user *models.User) string {
+	lang := userLanguage(user)
+	template := h.cfg.WelcomeMessage
+	if template == "" || template == config.DefaultWelcomeMessage {
+		template = t(lang, "welcome")
+	}
+
+	var commands string
+	switch models.NormalizeRole(user.Role) {
+	case string(models.SuperAdmin):
+		commands = t(lang, "welcome_commands_super_admin")
+	case string(models.Admin):
+		commands = t(lang, "welcome_commands_admin")
+	default:
+		commands = t(lang, "welcome_commands_user")
+	}
+
+	return strings.NewReplacer(
+		"{username}", user.Username,
+		"{role}", roleLabel(user.Role),
+		"{commands}", commands,
+		"\\n", "\n",
+	).Replace(template)
+}
+
+// whoAmI shows how the bot sees the caller: identity, role and the
+// role-restricted commands that role unlocks
+func (h *WhatsAppHandler) whoAmI(user *models.User) string {
+	lang := userLanguage(user)
+	status := t(lang, "status_active")
+	if !user.IsActive {
+		status = t(lang, "status_inactive")
+	}
+
+	response := t(lang, "whoami", user.Username, roleLabel(user.Role), user.WhatsAppNumber, status, lang)
+
+	commands := restrictedCommandsFor(user.Role)
+	if len(commands) == 0 {
+		response += t(lang, "whoami_general_only")
+	} else {
+		response += t(lang, "whoami_commands", strings.Join(commands, ", "))
+	}
+	return response
+}
+
+// setLanguage chooses the language the bot replies to the user in
+func (h *WhatsAppHandler) setLanguage(user *models.User, args []string) string {
+	if len(args) < 1 || !models.IsSupportedLanguage(strings.ToLower(args[0])) {
+		return t(userLanguage(user), "set_language_usage")
+	}
+
+	// Save onto a fresh copy; the caller may come from the lookup cache
+	lang := strings.ToLower(args[0])
+	current, err := h.userService.GetUserByID(user.ID)
+	if err != nil {
+		return t(lang, "set_language_failed", err.Error())
+	}
+	current.Language = lang
+	if err := h.userService.UpdateUser(current); err != nil {
+		return t(lang, "set_language_failed", err.Error())
+	}
+	return t(lang, "language_set")
+}
+
+// setTimezone chooses the time zone the user's dates and reports are read in
+func (h *WhatsAppHandler) setTimezone(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "set_timezone_usage")
+	}
+	zone := args[0]
+	if _, err := time.LoadLocation(zone); err != nil || zone == "" || strings.EqualFold(zone, "local") {
+		return t(lang, "set_timezone_unknown", zone)
+	}
+
+	// Save onto a fresh copy; the caller may come from the lookup cache
+	current, err := h.userService.GetUserByID(user.ID)
+	if err != nil {
+		return t(lang, "set_timezone_failed", err.Error())
+	}
+	current.Timezone = zone
+	if err := h.userService.UpdateUser(current); err != nil {
+		return t(lang, "set_timezone_failed", err.Error())
+	}
+	return t(lang, "timezone_set", zone)
+}
+
 func (h *WhatsAppHandler) SendMessage(c *gin.Context) {
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -117,7 +386,7 @@ func (h *WhatsAppHandler) SendMessage(c *gin.Context) {
 
 	err := h.whatsappService.SendMessage(req.Phone, req.Message)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send message: " + err.Error()})
 		return
 	}
 
@@ -136,12 +405,30 @@ func (h *WhatsAppHandler) StartInteractiveSession(c *gin.Context) {
 		return
 	}
 
+	if req.UserID == 0 || strings.TrimSpace(req.PhoneNumber) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id and phone_number are required"})
+		return
+	}
+
 	sessionID, err := h.whatsappService.StartInteractiveSession(req.UserID, req.PhoneNumber, req.Command)
+	if errors.Is(err, services.ErrUnknownSessionCommand) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
 		return
 	}
 
+	// Ask the first question so the user knows the flow has started
+	if session, err := h.whatsappService.GetSession(sessionID); err == nil {
+		if prompt := services.SessionPrompt(session.Command, session.Step); prompt != "" {
+			if err := h.whatsappService.SendMessage(session.PhoneNumber, prompt); err != nil {
+				h.logger.Error("Failed to send session prompt", "phone", session.PhoneNumber, "error", err)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"session_id": sessionID})
 }
 
@@ -178,7 +465,17 @@ func (h *WhatsAppHandler) EndSession(c *gin.Context) {
 func (h *WhatsAppHandler) processCommand(user *models.User, message string) string {
 	// Check if message is empty
 	if strings.TrimSpace(message) == "" {
-		return "❌ Empty message. Please send a message or use /help for available commands."
+		return t(userLanguage(user), "empty_message")
+	}
+
+	// Users in the middle of an interactive flow answer its questions
+	if reply, handled := h.handleActiveSession(user, message); handled {
+		return reply
+	}
+
+	// A pending AI action waits for a yes/no before anything else happens
+	if reply, handled := h.handlePendingConfirmation(user, message); handled {
+		return reply
 	}
 
 	// AI-First Approach: Process all messages with AI, with /commands as fallback
@@ -187,15 +484,139 @@ func (h *WhatsAppHandler) processCommand(user *models.User, message string) stri
 		// Parse command
 		parts := strings.Fields(message)
 		command := parts[0]
+
+		h.logger.Info("Command received", "user_id", user.ID, "command", command)
+		if err := authorizeCommand(user, command); err != nil {
+			h.logger.Warn("Command denied", "user_id", user.ID, "command", command, "role", user.Role)
+			return permissionDenied(userLanguage(user), err)
+		}
 		
 		// Only handle specific system commands directly
 		switch command {
 		case "/help":
-			return h.getHelpMessage(user.Role)
+			return h.getHelpMessage(user)
 		case "/clear_history":
-			return h.clearChatHistory(user.ID)
+			return h.clearChatHistory(user)
 		case "/show_history":
-			return h.showChatHistory(user.ID)
+			return h.showChatHistory(user)
+		case "/transfer_tasks":
+			return h.transferTasks(user, parts[1:])
+		case "/cleanup":
+			return h.runCleanup(user)
+		case "/features":
+			return h.manageFeatures(user, parts[1:])
+		case "/clone_task":
+			return h.cloneTaskCommand(user, parts[1:])
+		case "/delete_user":
+			return h.deleteUser(user, parts[1:])
+		case "/reset_password":
+			return h.resetPassword(user, parts[1:])
+		case "/set_role":
+			return h.setRole(user, parts[1:])
+		case "/user_tasks":
+			return h.userTasks(user, parts[1:])
+		case "/update_user":
+			return h.updateUser(user, parts[1:])
+		case "/search_orders":
+			return h.searchOrders(user, parts[1:])
+		case "/order_detail":
+			return h.orderDetail(user, parts[1:])
+		case "/order_note":
+			return h.addOrderNote(user, parts[1:])
+		case "/order_history":
+			return h.orderHistory(user, parts[1:])
+		case "/customer_summary":
+			return h.customerSummary(user, parts[1:])
+		case "/upcoming_deliveries":
+			return h.upcomingDeliveries(user, parts[1:])
+		case "/orders_for_customer":
+			return h.ordersForCustomer(user, parts[1:])
+		case "/restore_order":
+			return h.restoreOrder(user, parts[1:])
+		case "/merge_customer":
+			return h.mergeCustomer(user, parts[1:])
+		case "/orders_by_status":
+			return h.ordersByStatus(user, parts[1:])
+		case "/update_order_status":
+			return h.updateOrderStatus(user, parts[1:])
+		case "/cancel_order":
+			return h.cancelOrder(user, parts[1:])
+		case "/start_task":
+			return h.changeTaskStatus(user, "/start_task", parts[1:], models.InProgress)
+		case "/block_task":
+			return h.changeTaskStatus(user, "/block_task", parts[1:], models.Blocked)
+		case "/update_progress":
+			return h.updateTaskProgress(user, parts[1:])
+		case "/mark_complete":
+			return h.markTaskComplete(user, parts[1:])
+		case "/undo":
+			return h.undoLastAction(user)
+		case "/delete_task":
+			return h.deleteTaskCommand(user, parts[1:])
+		case "/my_stats":
+			return h.getMyStats(user)
+		case "/assign_task_bulk":
+			return h.assignTaskBulk(user, parts[1:])
+		case "/task_progress_history":
+			return h.taskProgressHistory(user, parts[1:])
+		case "/whoami":
+			return h.whoAmI(user)
+		case "/view_reminders":
+			return h.viewReminders(user)
+		case "/set_language":
+			return h.setLanguage(user, parts[1:])
+		case "/set_timezone":
+			return h.setTimezone(user, parts[1:])
+		case "/report_by_date":
+			return h.getReportByDate(user, parts[1:])
+		case "/report_history":
+			return h.reportHistory(user)
+		case "/task_report":
+			return h.taskReport(user, parts[1:])
+		case "/export_tasks":
+			return h.exportTasks(user)
+		case "/invoice":
+			return h.sendInvoice(user, parts[1:])
+		case "/create_order":
+			if len(parts) == 1 {
+				return h.startOrderWizard(user)
+			}
+			return h.createOrder(user, parts[1:])
+		case "/add_user":
+			return h.addUser(user, parts[1:])
+		case "/assign_task":
+			return h.assignTask(user, parts[1:])
+		case "/create_daily_task":
+			return h.createDailyTask(user, parts[1:])
+		case "/create_monthly_task":
+			return h.createMonthlyTask(user, parts[1:])
+		case "/my_tasks":
+			return h.myTasks(user, parts[1:])
+		case "/search_tasks":
+			return h.searchTasks(user, parts[1:])
+		case "/my_assigned_tasks":
+			return h.myAssignedTasks(user)
+		case "/my_weekly_tasks":
+			return h.getWeeklyTasks(user, parts[1:])
+		case "/create_weekly_task":
+			return h.createWeeklyTask(user, parts[1:])
+		case "/tasks_by_status":
+			return h.myTasksByFilter(user, "/tasks_by_status", parts[1:], h.taskService.GetTasksByUserAndStatus)
+		case "/tasks_by_priority":
+			return h.myTasksByFilter(user, "/tasks_by_priority", parts[1:], h.taskService.GetTasksByUserAndPriority)
+		case "/list_tasks":
+			return h.listAllTasks(user, parts[1:])
+		case "/list_users":
+			return h.listUsers(user, parts[1:])
+		case "/view_orders":
+			if h.authorize(user, models.Admin, models.SuperAdmin) {
+				return h.getAllOrders(user, parts[1:])
+			}
+			return h.viewMyOrders(user)
+		case "/my_orders":
+			return h.viewMyOrders(user)
+		case "/all_orders":
+			return h.getAllOrders(user, parts[1:])
 		default:
 			// For other /commands, try AI processing first
 			return h.processAICommand(user, message)
@@ -208,14 +629,20 @@ func (h *WhatsAppHandler) processCommand(user *models.User, message string) stri
 
 // processAICommand handles all messages with AI-first approach
 func (h *WhatsAppHandler) processAICommand(user *models.User, message string) string {
+	// Refuse input that would only cost tokens before it reaches OpenAI
+	message, reply := h.validateAIInput(user, message)
+	if reply != "" {
+		return reply
+	}
+
 	// Convert user ID to string for AI processor
 	userID := fmt.Sprintf("%d", user.ID)
 	
 	// Process message with AI
 	_, result, err := h.aiProcessor.ProcessWithOpenAI(message, userID)
 	if err != nil {
-		// Fallback to basic processing if AI fails
-		return "🤖 I'm having trouble understanding your message. Please try using a command like /help for available options."
+		h.logger.Error("AI processing failed", "user_id", user.ID, "error", err)
+		return aiErrorMessage(userLanguage(user), err)
 	}
 	
 	// Parse structured JSON response from AI
@@ -225,6 +652,50 @@ func (h *WhatsAppHandler) processAICommand(user *models.User, message string) st
 		return fmt.Sprintf("🤖 %s", result)
 	}
 	
+	if destructiveIntents[aiResponse.Type] && features.Enabled(features.AIConfirmation) {
+		return h.requestConfirmation(user, message, aiResponse)
+	}
+	
+	return h.executeAIResponse(user, message, result, aiResponse)
+}
+
+// validateAIInput cleans message for the AI. Control characters are removed;
+// a message over AI_MAX_MESSAGE_LENGTH, or one without a single letter or
+// digit, gets a reply instead and is not sent
+func (h *WhatsAppHandler) validateAIInput(user *models.User, message string) (string, string) {
+	message = strings.TrimSpace(stripControlChars(message))
+	if message == "" {
+		return "", t(userLanguage(user), "empty_message")
+	}
+
+	if limit := h.cfg.AIMaxMessageLength; limit > 0 && utf8.RuneCountInString(message) > limit {
+		return "", t(userLanguage(user), "message_too_long", limit)
+	}
+
+	if !strings.ContainsFunc(message, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return "", t(userLanguage(user), "not_a_command")
+	}
+	return message, ""
+}
+
+// stripControlChars drops control characters other than newlines and tabs
+func stripControlChars(message string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, message)
+}
+
+// executeAIResponse runs the action the AI recognised in message
+func (h *WhatsAppHandler) executeAIResponse(user *models.User, message string, result interface{}, aiResponse *AIResponse) string {
+	h.logger.Info("AI intent recognised", "user_id", user.ID, "intent", aiResponse.Type)
+	if err := authorizeCommand(user, aiResponse.Type); err != nil {
+		h.logger.Warn("AI intent denied", "user_id", user.ID, "intent", aiResponse.Type, "role", user.Role)
+		return permissionDenied(userLanguage(user), err)
+	}
+
 	// Handle different types of AI responses with actual database operations
 	switch aiResponse.Type {
 	case "add_user":
@@ -232,13 +703,27 @@ func (h *WhatsAppHandler) processAICommand(user *models.User, message string) st
 	case "create_order":
 		return h.handleStructuredAICreateOrder(user, aiResponse)
 	case "create_order_with_item":
-		return h.handleStructuredAICreateOrderWithItem(user, aiResponse)
+		return h.handleAICreateOrderWithItem(user, aiResponse)
+	case "add_order_item":
+		return h.handleAIAddOrderItem(user, aiResponse)
+	case "view_order_items":
+		return h.handleAIViewOrderItems(user, aiResponse)
 	case "assign_task":
 		return h.handleStructuredAIAssignTask(user, aiResponse)
 	case "view_tasks":
 		return h.handleAIViewTasks(user, message, result)
 	case "view_orders":
-		return h.handleAIViewOrders(user, message, result)
+		return h.handleAIViewOrders(user, aiResponse)
+	case "my_orders":
+		return h.viewMyOrders(user)
+	case "all_orders":
+		return h.getAllOrders(user, aiPageArgs(aiResponse))
+	case "view_tasks_by_status":
+		status, _ := aiResponse.Data["status"].(string)
+		return h.myTasksByFilter(user, "/tasks_by_status", strings.Fields(status), h.taskService.GetTasksByUserAndStatus)
+	case "view_tasks_by_priority":
+		priority, _ := aiResponse.Data["priority"].(string)
+		return h.myTasksByFilter(user, "/tasks_by_priority", strings.Fields(priority), h.taskService.GetTasksByUserAndPriority)
 	case "list_users":
 		return h.handleAIListUsers(user, aiResponse)
 	case "create_reminder":
@@ -247,6 +732,80 @@ func (h *WhatsAppHandler) processAICommand(user *models.User, message string) st
 		return h.handleAIViewReminders(user, aiResponse)
 	case "list_tasks":
 		return h.handleAIListTasks(user, aiResponse)
+	case "clone_task":
+		return h.handleAICloneTask(user, aiResponse)
+	case "delete_user":
+		return h.handleAIDeleteUser(user, aiResponse)
+	case "set_role":
+		return h.handleAISetRole(user, aiResponse)
+	case "view_user_tasks":
+		return h.handleAIViewUserTasks(user, aiResponse)
+	case "delete_task":
+		return h.handleAIDeleteTask(user, aiResponse)
+	case "update_order_status":
+		orderID := uint(dataFloat(aiResponse.Data, "order_id"))
+		status, _ := aiResponse.Data["status"].(string)
+		if orderID == 0 || strings.TrimSpace(status) == "" {
+			return missingFields(userLanguage(user), "order_id", "status")
+		}
+		return h.updateOrderStatus(user, []string{strconv.FormatUint(uint64(orderID), 10), status})
+	case "order_history":
+		orderID := uint(dataFloat(aiResponse.Data, "order_id"))
+		if orderID == 0 {
+			return missingFields(userLanguage(user), "order_id")
+		}
+		return h.orderHistory(user, []string{strconv.FormatUint(uint64(orderID), 10)})
+	case "create_weekly_task":
+		title, _ := aiResponse.Data["title"].(string)
+		description, _ := aiResponse.Data["description"].(string)
+		assignedTo, _ := aiResponse.Data["assigned_to"].(string)
+		if title == "" || description == "" || assignedTo == "" {
+			return missingFields(userLanguage(user), "title", "description", "assigned_to")
+		}
+		return h.saveWeeklyTask(user, assignedTo, title, description)
+	case "assign_task_bulk":
+		title, _ := aiResponse.Data["title"].(string)
+		description, _ := aiResponse.Data["description"].(string)
+		assignees, _ := aiResponse.Data["assignees"].(string)
+		if title == "" || assignees == "" {
+			return missingFields(userLanguage(user), "title", "assignees")
+		}
+		return h.assignTaskBulk(user, strings.Fields(title+" | "+description+" | "+assignees))
+	case "task_progress_history":
+		taskID := uint(dataFloat(aiResponse.Data, "task_id"))
+		if taskID == 0 {
+			return missingFields(userLanguage(user), "task_id")
+		}
+		return h.taskProgressHistory(user, []string{strconv.FormatUint(uint64(taskID), 10)})
+	case "my_assigned_tasks":
+		return h.myAssignedTasks(user)
+	case "search_tasks":
+		keyword, _ := aiResponse.Data["keyword"].(string)
+		return h.searchTasks(user, strings.Fields(keyword))
+	case "customer_summary":
+		customer, _ := aiResponse.Data["customer_name"].(string)
+		return h.customerSummary(user, strings.Fields(customer))
+	case "upcoming_deliveries":
+		return h.upcomingDeliveries(user, aiDaysArgs(aiResponse))
+	case "orders_for_customer":
+		customer, _ := aiResponse.Data["customer_phone"].(string)
+		if customer == "" {
+			customer, _ = aiResponse.Data["customer_name"].(string)
+		}
+		return h.ordersForCustomer(user, strings.Fields(customer))
+	case "cancel_order":
+		orderID := uint(dataFloat(aiResponse.Data, "order_id"))
+		if orderID == 0 {
+			return missingFields(userLanguage(user), "order_id")
+		}
+		reason, _ := aiResponse.Data["reason"].(string)
+		return h.cancelOrder(user, append([]string{strconv.FormatUint(uint64(orderID), 10)}, strings.Fields(reason)...))
+	case "orders_by_status":
+		status, _ := aiResponse.Data["status"].(string)
+		return h.ordersByStatus(user, strings.Fields(status))
+	case "search_orders":
+		filters, _ := aiResponse.Data["filters"].(string)
+		return h.searchOrders(user, strings.Fields(filters))
 	case "update_progress":
 		return h.handleAIUpdateProgress(user, aiResponse)
 	case "mark_complete":
@@ -255,18 +814,23 @@ func (h *WhatsAppHandler) processAICommand(user *models.User, message string) st
 		return h.handleAIMyReport(user, aiResponse)
 	case "report_by_date":
 		return h.handleAIReportByDate(user, aiResponse)
+	case "whoami":
+		return h.whoAmI(user)
 	case "clear_history":
-		return h.clearChatHistory(user.ID)
+		return h.clearChatHistory(user)
 	case "show_history":
-		return h.showChatHistory(user.ID)
+		return h.showChatHistory(user)
 	case "help":
-		return h.getHelpMessage(user.Role)
+		return h.getHelpMessage(user)
 	case "general":
+		if aiResponse.Message == "" {
+			return fmt.Sprintf("🤖 %s\n\n%s", t(userLanguage(user), "ai_not_understood"), h.getHelpMessage(user))
+		}
 		// Check if AI suggests help command
 		if strings.Contains(strings.ToLower(aiResponse.Message), "help") || 
 		   strings.Contains(strings.ToLower(aiResponse.Message), "don't understand") ||
 		   strings.Contains(strings.ToLower(aiResponse.Message), "unknown") {
-			return fmt.Sprintf("🤖 %s\n\n%s", aiResponse.Message, h.getHelpMessage(user.Role))
+			return fmt.Sprintf("🤖 %s\n\n%s", aiResponse.Message, h.getHelpMessage(user))
 		}
 		// General AI response
 		return fmt.Sprintf("🤖 %s", aiResponse.Message)
@@ -276,11 +840,220 @@ func (h *WhatsAppHandler) processAICommand(user *models.User, message string) st
 	}
 }
 
+// startOrderWizard walks the user through creating an order one question at
+// a time
+func (h *WhatsAppHandler) startOrderWizard(user *models.User) string {
+	if _, err := h.whatsappService.StartInteractiveSession(user.ID, user.WhatsAppNumber, "create_order"); err != nil {
+		return t(userLanguage(user), "order_wizard_failed", err.Error())
+	}
+	return services.SessionPrompt("create_order", services.OrderWizardCustomer)
+}
+
+// handleActiveSession routes a message into the user's interactive session,
+// if there is one
+func (h *WhatsAppHandler) handleActiveSession(user *models.User, message string) (string, bool) {
+	// Commands other than /cancel keep working while a flow is open
+	if trimmed := strings.TrimSpace(message); strings.HasPrefix(trimmed, "/") && !strings.EqualFold(trimmed, "/cancel") {
+		return "", false
+	}
+
+	session, err := h.whatsappService.AdvanceSession(user.WhatsAppNumber, message)
+	switch {
+	case errors.Is(err, services.ErrNoActiveSession):
+		return "", false
+	case errors.Is(err, services.ErrSessionAbandoned):
+		return t(userLanguage(user), "order_wizard_cancelled"), true
+	case errors.Is(err, services.ErrInvalidSessionInput):
+		return fmt.Sprintf("❌ %s\n\n%s", err.Error(), services.SessionPrompt(session.Command, session.Step)), true
+	case err != nil && session == nil:
+		return t(userLanguage(user), "session_error", err.Error()), true
+	}
+
+	if session.Step >= services.OrderWizardDone {
+		return h.completeOrderWizard(user, session), true
+	}
+	return services.SessionPrompt(session.Command, session.Step), true
+}
+
+// completeOrderWizard creates the order collected by a finished wizard
+func (h *WhatsAppHandler) completeOrderWizard(user *models.User, session *redis.SessionData) string {
+	// The role may have changed since /create_order opened the wizard
+	if err := authorizeCommand(user, "create_order"); err != nil {
+		return permissionDenied(userLanguage(user), err)
+	}
+
+	customerName, _ := session.Data["customer_name"].(string)
+	totalAmount := dataFloat(session.Data, "total_amount")
+	order := &models.Order{
+		CustomerName: customerName,
+		TotalAmount:  totalAmount,
+		Status:       string(models.OrderPending),
+		OrderDate:    h.clock.Now(),
+		CreatedBy:    user.ID,
+	}
+
+	var items []models.OrderItem
+	for _, item := range services.CollectedOrderItems(session) {
+		items = append(items, models.OrderItem{ItemName: item.Name, Quantity: item.Quantity, UnitPrice: item.Price})
+	}
+
+	// Order and items are saved together, or not at all
+	var err error
+	if len(items) == 0 {
+		err = h.orderService.CreateOrder(order)
+	} else {
+		err = h.orderService.CreateOrderWithItems(order, items)
+	}
+	lang := userLanguage(user)
+	if err != nil {
+		return t(lang, "order_create_failed", err.Error())
+	}
+	h.recordOrderCreated(user.ID, order)
+
+	response := t(lang, "order_created", order.OrderNumber, order.CustomerName, h.formatCurrency(order.TotalAmount))
+	for _, item := range items {
+		response += fmt.Sprintf("\n🛒 %s: %d x %s", item.ItemName, item.Quantity, h.formatCurrency(item.UnitPrice))
+	}
+	return response
+}
+
+// destructiveIntents are AI actions that create users or delete data, so a
+// misread message must be confirmed before they run
+var destructiveIntents = map[string]bool{
+	"add_user":    true,
+	"delete_user": true,
+	"delete_task": true,
+}
+
+// confirmationTTL is how long a pending AI action waits for a reply
+const confirmationTTL = 2 * time.Minute
+
+var (
+	affirmativeReplies = map[string]bool{"yes": true, "y": true, "ya": true, "iya": true, "ok": true, "oke": true, "confirm": true}
+	negativeReplies    = map[string]bool{"no": true, "n": true, "tidak": true, "gak": true, "batal": true, "cancel": true}
+)
+
+func confirmationKey(user *models.User) string {
+	return "pending_confirmation:" + whatsapp.NormalizePhone(user.WhatsAppNumber)
+}
+
+// requestConfirmation parks a destructive AI action in a session until the
+// user confirms it. A user who may not run the action is refused straight
+// away rather than asked to confirm it
+func (h *WhatsAppHandler) requestConfirmation(user *models.User, message string, aiResponse *AIResponse) string {
+	if err := authorizeCommand(user, aiResponse.Type); err != nil {
+		h.logger.Warn("AI intent denied", "user_id", user.ID, "intent", aiResponse.Type, "role", user.Role)
+		return permissionDenied(userLanguage(user), err)
+	}
+
+	now := h.clock.Now()
+	session := &redis.SessionData{
+		UserID:      user.ID,
+		PhoneNumber: whatsapp.NormalizePhone(user.WhatsAppNumber),
+		Command:     aiResponse.Type,
+		Step:        1,
+		Data: map[string]interface{}{
+			"message":    message,
+			"ai_data":    aiResponse.Data,
+			"ai_message": aiResponse.Message,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.whatsappService.SetTempData(confirmationKey(user), session, confirmationTTL); err != nil {
+		return t(userLanguage(user), "confirmation_save_failed", err.Error())
+	}
+
+	lang := userLanguage(user)
+	return t(lang, "confirmation_prompt", describeAIAction(lang, aiResponse), int(confirmationTTL.Minutes()))
+}
+
+// describeAIAction summarises a destructive AI action for the confirmation
+// prompt, in lang
+func describeAIAction(lang string, aiResponse *AIResponse) string {
+	switch aiResponse.Type {
+	case "add_user":
+		username, _ := aiResponse.Data["username"].(string)
+		role, _ := aiResponse.Data["role"].(string)
+		return t(lang, "confirm_add_user", username, role)
+	case "delete_user":
+		username, _ := aiResponse.Data["username"].(string)
+		return t(lang, "confirm_delete_user", username)
+	case "delete_task":
+		return t(lang, "confirm_delete_task", uint(dataFloat(aiResponse.Data, "task_id")))
+	}
+	return aiResponse.Type
+}
+
+// handlePendingConfirmation resolves a parked AI action. A yes runs it, a no
+// cancels it, and any other message drops it and is processed normally
+func (h *WhatsAppHandler) handlePendingConfirmation(user *models.User, message string) (string, bool) {
+	key := confirmationKey(user)
+	var session redis.SessionData
+	if err := h.whatsappService.GetTempData(key, &session); err != nil {
+		return "", false
+	}
+	if err := h.whatsappService.DeleteTempData(key); err != nil {
+		h.logger.Error("Failed to clear pending confirmation", "user_id", user.ID, "error", err)
+	}
+	if session.UserID != user.ID || h.clock.Now().Sub(session.CreatedAt) > confirmationTTL {
+		return "", false
+	}
+
+	reply := strings.ToLower(strings.Trim(strings.TrimSpace(message), ".!"))
+	if negativeReplies[reply] {
+		return t(userLanguage(user), "confirmation_cancelled"), true
+	}
+	if !affirmativeReplies[reply] {
+		return "", false
+	}
+
+	originalMessage, _ := session.Data["message"].(string)
+	aiData, _ := session.Data["ai_data"].(map[string]interface{})
+	aiMessage, _ := session.Data["ai_message"].(string)
+	aiResponse := &AIResponse{Type: session.Command, Data: aiData, Message: aiMessage}
+	if aiResponse.Data == nil {
+		aiResponse.Data = map[string]interface{}{}
+	}
+
+	return h.executeAIResponse(user, originalMessage, aiMessage, aiResponse), true
+}
+
 // processNaturalLanguageMessage - kept for backward compatibility
 func (h *WhatsAppHandler) processNaturalLanguageMessage(user *models.User, message string) string {
 	return h.processAICommand(user, message)
 }
 
+// aiErrorMessage explains an AI failure to the user in lang, pointing to
+// commands that work without the assistant
+func aiErrorMessage(lang string, err error) string {
+	var openAIErr *services.OpenAIError
+	if errors.As(err, &openAIErr) {
+		switch {
+		case openAIErr.IsQuotaExceeded():
+			return t(lang, "ai_quota_exceeded")
+		case openAIErr.IsRateLimited():
+			return t(lang, "ai_rate_limited")
+		case openAIErr.IsAuthError():
+			return t(lang, "ai_misconfigured")
+		case openAIErr.IsBadRequest():
+			return t(lang, "ai_bad_request")
+		}
+	}
+
+	// Fallback to basic processing if AI fails
+	return t(lang, "ai_failed")
+}
+
+// missingFields is the reply to an AI intent that lacks fields, in lang
+func missingFields(lang string, fields ...string) string {
+	list := fields[len(fields)-1]
+	if len(fields) > 1 {
+		list = strings.Join(fields[:len(fields)-1], ", ") + t(lang, "and") + list
+	}
+	return t(lang, "ai_missing_fields", list)
+}
+
 // parseAIResponse parses structured JSON response from AI
 func (h *WhatsAppHandler) parseAIResponse(result interface{}) (*AIResponse, error) {
 	var aiResponse AIResponse
@@ -298,22 +1071,85 @@ func (h *WhatsAppHandler) parseAIResponse(result interface{}) (*AIResponse, erro
 		jsonStr = string(jsonBytes)
 	}
 	
-	// Parse JSON
+	jsonStr = strings.TrimSpace(jsonStr)
+	if jsonStr == "" {
+		// An empty message makes the general reply say it was not understood
+		return &AIResponse{
+			Type: "general",
+			Data: map[string]interface{}{},
+		}, nil
+	}
+	
+	// Parse JSON, falling back to the first {...} block when the model
+	// wrapped its answer in prose
 	err := json.Unmarshal([]byte(jsonStr), &aiResponse)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+		if block := extractJSONObject(jsonStr); block != "" {
+			aiResponse = AIResponse{}
+			err = json.Unmarshal([]byte(block), &aiResponse)
+		}
+	}
+	
+	// Treat anything that still isn't valid JSON as a plain chat reply
+	if err != nil {
+		return &AIResponse{
+			Type:    "general",
+			Data:    map[string]interface{}{},
+			Message: jsonStr,
+		}, nil
+	}
+	
+	if aiResponse.Data == nil {
+		aiResponse.Data = map[string]interface{}{}
 	}
 	
 	return &aiResponse, nil
 }
 
+// extractJSONObject returns the first balanced {...} block in content, or an
+// empty string if there is none
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	if start == -1 {
+		return ""
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(content); i++ {
+		ch := content[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return content[start : i+1]
+			}
+		}
+	}
+
+	return ""
+}
+
 // handleStructuredAIAddUser handles structured AI add user requests
 func (h *WhatsAppHandler) handleStructuredAIAddUser(user *models.User, aiResponse *AIResponse) string {
 	// Check if user has SuperAdmin access
-	if user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk menambah user. Hanya Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Extract data from AI response
 	username, _ := aiResponse.Data["username"].(string)
 	email, _ := aiResponse.Data["email"].(string)
@@ -322,28 +1158,17 @@ func (h *WhatsAppHandler) handleStructuredAIAddUser(user *models.User, aiRespons
 	
 	// Validate required fields
 	if username == "" || email == "" || phone == "" || role == "" {
-		return "❌ Data tidak lengkap. Pastikan username, email, phone, dan role tersedia."
-	}
-	
-	// Validate role
-	validRoles := []string{"SuperAdmin", "Admin", "User"}
-	validRole := false
-	for _, r := range validRoles {
-		if strings.EqualFold(role, r) {
-			role = r
-			validRole = true
-			break
-		}
+		return t(userLanguage(user), "add_user_incomplete")
 	}
 	
-	if !validRole {
-		return "❌ Role tidak valid. Gunakan: SuperAdmin, Admin, atau User"
+	// Validate role and store it in canonical form
+	if !models.IsValidRole(role) {
+		return t(userLanguage(user), "invalid_role")
 	}
+	role = models.NormalizeRole(role)
 	
-	// Convert phone format if needed
-	if strings.HasPrefix(phone, "08") {
-		phone = "62" + phone[1:]
-	}
+	// Store the phone number in canonical form
+	phone = whatsapp.NormalizePhone(phone)
 	
 	// Create user
 	newUser := &models.User{
@@ -357,71 +1182,183 @@ func (h *WhatsAppHandler) handleStructuredAIAddUser(user *models.User, aiRespons
 	
 	err := h.userService.CreateUser(newUser, "default123")
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal menambah user: %s", err.Error())
+		return t(userLanguage(user), "add_user_failed", err.Error())
 	}
 	
-	return fmt.Sprintf("✅ User berhasil ditambahkan!\n👤 Username: %s\n📧 Email: %s\n📱 Phone: %s\n🔑 Role: %s\n🔐 Password: default123", username, email, phone, role)
+	return t(userLanguage(user), "user_added", username, email, phone, role)
 }
 
 // handleStructuredAICreateOrder handles structured AI create order requests
 func (h *WhatsAppHandler) handleStructuredAICreateOrder(user *models.User, aiResponse *AIResponse) string {
 	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk membuat order. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Extract data from AI response
 	customerName, _ := aiResponse.Data["customer_name"].(string)
 	totalAmountFloat, _ := aiResponse.Data["total_amount"].(float64)
+	customerPhone, _ := aiResponse.Data["customer_phone"].(string)
 	
+	lang := userLanguage(user)
 	// Validate required fields
 	if customerName == "" || totalAmountFloat == 0 {
-		return "❌ Data tidak lengkap. Pastikan customer_name dan total_amount tersedia."
+		return missingFields(lang, "customer_name", "total_amount")
 	}
 	
-	// Generate unique order number
-	orderNumber := fmt.Sprintf("ORD-%d", time.Now().Unix())
+	deliveryDate, errMsg := aiDeliveryDate(lang, aiResponse, h.userNow(user))
+	if errMsg != "" {
+		return errMsg
+	}
 	
 	// Create order using existing service
 	order := &models.Order{
-		OrderNumber:  orderNumber,
-		CustomerName: customerName,
-		TotalAmount:  totalAmountFloat,
-		Status:       "pending",
-		OrderDate:    time.Now(),
+		CustomerName:  customerName,
+		CustomerPhone: normalizeCustomerPhone(customerPhone),
+		DeliveryDate:  deliveryDate,
+		TotalAmount:   totalAmountFloat,
+		Status:       string(models.OrderPending),
+		OrderDate:    h.clock.Now(),
 		CreatedBy:    user.ID,
 	}
+	if errMsg := aiRateOverrides(lang, aiResponse, order); errMsg != "" {
+		return errMsg
+	}
 	
 	err := h.orderService.CreateOrder(order)
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal membuat order: %s", err.Error())
+		return t(lang, "order_create_failed", err.Error())
 	}
+	h.recordOrderCreated(user.ID, order)
 	
-	return fmt.Sprintf("✅ Order berhasil dibuat!\n📦 Order Number: %s\n👤 Customer: %s\n💰 Total: Rp %.0f\n📅 Tanggal: %s", 
-		orderNumber, customerName, totalAmountFloat, order.OrderDate.Format("2006-01-02 15:04"))
+	response := t(lang, "order_created_ai", order.ID, order.OrderNumber, customerName,
+		h.formatCurrency(totalAmountFloat), order.OrderDate.Format("2006-01-02 15:04"))
+	if order.DeliveryDate != nil {
+		response += t(lang, "order_delivery_line", order.DeliveryDate.Format("2006-01-02"))
+	}
+	response += rateOverrideSummary(lang, order)
+	return response
+}
+
+// aiDeliveryDate reads an optional delivery_date from AI data, relative to
+// now. The second return value is an error reply in lang when the date cannot
+// be understood
+func aiDeliveryDate(lang string, aiResponse *AIResponse, now time.Time) (*time.Time, string) {
+	raw, _ := aiResponse.Data["delivery_date"].(string)
+	if strings.TrimSpace(raw) == "" {
+		return nil, ""
+	}
+	parsed, err := parseDueDate(raw, now)
+	if err != nil {
+		return nil, t(lang, "invalid_delivery_date", err.Error())
+	}
+	return parsed, ""
+}
+
+// aiRateOverrides copies optional tax_rate, marketing_rate and rental_rate
+// percentages from AI data onto order. The return value is an error reply in
+// lang when one is out of range
+func aiRateOverrides(lang string, aiResponse *AIResponse, order *models.Order) string {
+	fields := []struct {
+		key    string
+		target **float64
+	}{
+		{"tax_rate", &order.TaxRateOverride},
+		{"marketing_rate", &order.MarketingRateOverride},
+		{"rental_rate", &order.RentalRateOverride},
+	}
+	for _, field := range fields {
+		if _, ok := aiResponse.Data[field.key]; !ok {
+			continue
+		}
+		rate := dataFloat(aiResponse.Data, field.key)
+		if rate < 0 || rate > 100 {
+			return t(lang, "rate_out_of_range", field.key)
+		}
+		*field.target = &rate
+	}
+	return ""
+}
+
+// rateOverrideSummary lists the rates an order overrides in lang, empty when
+// none
+func rateOverrideSummary(lang string, order *models.Order) string {
+	var rates []string
+	if order.TaxRateOverride != nil {
+		rates = append(rates, fmt.Sprintf("tax %.2f%%", *order.TaxRateOverride))
+	}
+	if order.MarketingRateOverride != nil {
+		rates = append(rates, fmt.Sprintf("marketing %.2f%%", *order.MarketingRateOverride))
+	}
+	if order.RentalRateOverride != nil {
+		rates = append(rates, fmt.Sprintf("rental %.2f%%", *order.RentalRateOverride))
+	}
+	if len(rates) == 0 {
+		return ""
+	}
+	return t(lang, "custom_rates", strings.Join(rates, ", "))
+}
+
+// commandRateOverrides copies tax=, marketing= and rental= percentages from
+// command arguments onto order and returns the other arguments. The second
+// return value is an error reply in lang when a rate is invalid
+func commandRateOverrides(lang string, args []string, order *models.Order) ([]string, string) {
+	targets := map[string]**float64{
+		"tax":       &order.TaxRateOverride,
+		"marketing": &order.MarketingRateOverride,
+		"rental":    &order.RentalRateOverride,
+	}
+	var rest []string
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		target, ok := targets[strings.ToLower(key)]
+		if !found || !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 100 {
+			return nil, t(lang, "rate_out_of_range", strings.ToLower(key))
+		}
+		*target = &rate
+	}
+	return rest, ""
 }
 
 // handleStructuredAIAssignTask handles structured AI assign task requests
 func (h *WhatsAppHandler) handleStructuredAIAssignTask(user *models.User, aiResponse *AIResponse) string {
 	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk menugaskan task. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Extract data from AI response
 	title, _ := aiResponse.Data["title"].(string)
 	description, _ := aiResponse.Data["description"].(string)
 	assignedToUsername, _ := aiResponse.Data["assigned_to"].(string)
 	
+	lang := userLanguage(user)
 	// Validate required fields
 	if title == "" || description == "" || assignedToUsername == "" {
-		return "❌ Data tidak lengkap. Pastikan title, description, dan assigned_to tersedia."
+		return missingFields(lang, "title", "description", "assigned_to")
+	}
+	
+	// Parse optional due date
+	var dueDate *time.Time
+	if dueDateStr, _ := aiResponse.Data["due_date"].(string); strings.TrimSpace(dueDateStr) != "" {
+		parsed, err := parseDueDate(dueDateStr, h.userNow(user))
+		if err != nil {
+			return t(lang, "invalid_due_date", err.Error())
+		}
+		dueDate = parsed
+	}
+	
+	// Parse optional priority
+	priorityInput, _ := aiResponse.Data["priority"].(string)
+	priority, err := parseTaskPriority(priorityInput)
+	if err != nil {
+		return "❌ " + err.Error()
 	}
 	
 	// Find user by username
 	assignedUser, err := h.userService.GetUserByUsername(assignedToUsername)
 	if err != nil {
-		return fmt.Sprintf("❌ User '%s' tidak ditemukan. Pastikan username benar.", assignedToUsername)
+		return t(lang, "assignee_not_found", assignedToUsername)
+	}
+	if !assignedUser.IsActive {
+		return t(lang, "assignee_inactive", assignedUser.Username)
 	}
 	
 	// Create task
@@ -429,35 +1366,38 @@ func (h *WhatsAppHandler) handleStructuredAIAssignTask(user *models.User, aiResp
 		Title:       title,
 		Description: description,
 		AssignedTo:  assignedUser.ID,
+		DueDate:     dueDate,
 		Status:      string(models.Pending),
-		Priority:    string(models.Medium),
+		Priority:    priority,
 		TaskType:    string(models.Custom),
 		CreatedBy:   user.ID,
 	}
 	
 	err = h.taskService.CreateTask(task)
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal membuat task: %s", err.Error())
+		return t(lang, "task_create_failed", err.Error())
 	}
 	
-	return fmt.Sprintf("✅ Task berhasil ditugaskan!\n📝 Title: %s\n📄 Description: %s\n👤 Assigned to: %s", 
-		title, description, assignedToUsername)
+	response := t(lang, "task_assigned_ai", task.ID, title, description, assignedToUsername)
+	if task.DueDate != nil {
+		response += t(lang, "task_due_line", task.DueDate.Format("2006-01-02"))
+	}
+	if task.Priority != string(models.Medium) {
+		response += t(lang, "task_priority_line", task.Priority)
+	}
+	return response
 }
 
 // handleAIAddUser processes AI-detected add user requests
 func (h *WhatsAppHandler) handleAIAddUser(user *models.User, message string, aiResult interface{}) string {
 	// Check if user has SuperAdmin access
-	if user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk menambah user. Hanya Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Parse user information from message using regex
 	// Pattern: "tambahkan user [username] [email] [phone] [role]"
 	userRegex := regexp.MustCompile(`(?i)(?:tambahkan|add|create)\s+user\s+(\w+)\s+([^\s]+@[^\s]+)\s+(\d+)\s+(\w+)`)
 	matches := userRegex.FindStringSubmatch(message)
 	
 	if len(matches) < 5 {
-		return "❌ Format tidak valid. Gunakan: 'tambahkan user [username] [email] [phone] [role]'\nContoh: 'tambahkan user ega egatryagung@gmail.com 08123456789 SuperAdmin'"
+		return t(userLanguage(user), "add_user_format")
 	}
 	
 	username := matches[1]
@@ -465,25 +1405,14 @@ func (h *WhatsAppHandler) handleAIAddUser(user *models.User, message string, aiR
 	phone := matches[3]
 	role := matches[4]
 	
-	// Validate role
-	validRoles := []string{"SuperAdmin", "Admin", "User"}
-	validRole := false
-	for _, r := range validRoles {
-		if strings.EqualFold(role, r) {
-			role = r
-			validRole = true
-			break
-		}
+	// Validate role and store it in canonical form
+	if !models.IsValidRole(role) {
+		return t(userLanguage(user), "invalid_role")
 	}
+	role = models.NormalizeRole(role)
 	
-	if !validRole {
-		return "❌ Role tidak valid. Gunakan: SuperAdmin, Admin, atau User"
-	}
-	
-	// Convert phone format if needed
-	if strings.HasPrefix(phone, "08") {
-		phone = "62" + phone[1:]
-	}
+	// Store the phone number in canonical form
+	phone = whatsapp.NormalizePhone(phone)
 	
 	// Create user
 	newUser := &models.User{
@@ -497,76 +1426,97 @@ func (h *WhatsAppHandler) handleAIAddUser(user *models.User, message string, aiR
 	
 	err := h.userService.CreateUser(newUser, "default123")
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal menambah user: %s", err.Error())
+		return t(userLanguage(user), "add_user_failed", err.Error())
 	}
 	
-	return fmt.Sprintf("✅ User berhasil ditambahkan!\n👤 Username: %s\n📧 Email: %s\n📱 Phone: %s\n🔑 Role: %s\n🔐 Password: default123", username, email, phone, role)
+	return t(userLanguage(user), "user_added", username, email, phone, role)
 }
 
 // handleAICreateOrder processes AI-detected create order requests
 func (h *WhatsAppHandler) handleAICreateOrder(user *models.User, message string, aiResult interface{}) string {
 	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk membuat order. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Parse order information from message
-	orderRegex := regexp.MustCompile(`(?i)(?:buat|create|tambah)\s+order\s+([^0-9]+)\s+(\d+(?:\.\d+)?)`)
+	orderRegex := regexp.MustCompile(`(?i)(?:buat|create|tambah)\s+order\s+([^0-9]+)\s+(\d[\d.,]*)(?:\s+(\+?\d{8,}))?`)
 	matches := orderRegex.FindStringSubmatch(message)
 	
+	lang := userLanguage(user)
 	if len(matches) < 3 {
-		return "❌ Format tidak valid. Gunakan: 'buat order [customer_name] [total_amount]'\nContoh: 'buat order John Doe 1000000'"
+		return t(lang, "create_order_format")
 	}
 	
 	customerName := strings.TrimSpace(matches[1])
 	totalAmountStr := matches[2]
 	
-	totalAmount, err := strconv.ParseFloat(totalAmountStr, 64)
+	totalAmount, err := currency.Parse(totalAmountStr)
 	if err != nil {
-		return "❌ Total amount tidak valid. Gunakan angka yang benar."
+		return t(lang, "invalid_total_amount")
 	}
 	
 	// Create order using existing service
 	order := &models.Order{
-		CustomerName: customerName,
-		TotalAmount:  totalAmount,
-		Status:       "pending",
-		OrderDate:    time.Now(),
+		CustomerName:  customerName,
+		CustomerPhone: normalizeCustomerPhone(matches[3]),
+		TotalAmount:   totalAmount,
+		Status:       string(models.OrderPending),
+		OrderDate:    h.clock.Now(),
 		CreatedBy:    user.ID,
 	}
 	
 	err = h.orderService.CreateOrder(order)
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal membuat order: %s", err.Error())
+		return t(lang, "order_create_failed", err.Error())
 	}
+	h.recordOrderCreated(user.ID, order)
 	
-	return fmt.Sprintf("✅ Order berhasil dibuat!\n📦 Customer: %s\n💰 Total: Rp %.0f\n📅 Tanggal: %s", 
-		customerName, totalAmount, order.OrderDate.Format("2006-01-02 15:04"))
+	return t(lang, "order_created_short", order.ID, customerName, h.formatCurrency(totalAmount), order.OrderDate.Format("2006-01-02 15:04"))
 }
 
 // handleAIAssignTask processes AI-detected assign task requests
 func (h *WhatsAppHandler) handleAIAssignTask(user *models.User, message string, aiResult interface{}) string {
 	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk menugaskan task. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Parse task information from message
-	taskRegex := regexp.MustCompile(`(?i)(?:assign|tugaskan|berikan)\s+task\s+(\w+)\s+(.+?)\s+to\s+(\w+)`)
+	taskRegex := regexp.MustCompile(`(?i)(?:assign|tugaskan|berikan)\s+(?:(?:low|medium|high|urgent)\s+)?task\s+(\w+)\s+(.+?)\s+to\s+(\w+)`)
 	matches := taskRegex.FindStringSubmatch(message)
 	
+	lang := userLanguage(user)
 	if len(matches) < 4 {
-		return "❌ Format tidak valid. Gunakan: 'assign task [title] [description] to [username]'\nContoh: 'assign task Update Website Update homepage design to john'"
+		return t(lang, "assign_task_format")
 	}
 	
 	title := strings.TrimSpace(matches[1])
 	description := strings.TrimSpace(matches[2])
 	assignedToUsername := strings.TrimSpace(matches[3])
 	
+	// Parse optional "due:YYYY-MM-DD" token
+	var dueDate *time.Time
+	dueRegex := regexp.MustCompile(`(?i)\bdue:(\S+)`)
+	if dueMatch := dueRegex.FindStringSubmatch(message); len(dueMatch) > 1 {
+		parsed, err := parseDueDate(dueMatch[1], h.userNow(user))
+		if err != nil {
+			return t(lang, "invalid_due_date", err.Error())
+		}
+		dueDate = parsed
+	}
+	
+	// Parse optional priority, either as a "priority:high" token or a level
+	// before "task" as in "assign urgent task ..."
+	priorityInput := ""
+	priorityRegex := regexp.MustCompile(`(?i)\bpriority:(\S+)|\b(low|medium|high|urgent)\s+task\b`)
+	if priorityMatch := priorityRegex.FindStringSubmatch(message); len(priorityMatch) > 2 {
+		priorityInput = priorityMatch[1] + priorityMatch[2]
+	}
+	priority, err := parseTaskPriority(priorityInput)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	
 	// Find user by username
 	assignedUser, err := h.userService.GetUserByUsername(assignedToUsername)
 	if err != nil {
-		return fmt.Sprintf("❌ User '%s' tidak ditemukan. Pastikan username benar.", assignedToUsername)
+		return t(lang, "assignee_not_found", assignedToUsername)
+	}
+	if !assignedUser.IsActive {
+		return t(lang, "assignee_inactive", assignedUser.Username)
 	}
 	
 	// Create task
@@ -574,590 +1524,1758 @@ func (h *WhatsAppHandler) handleAIAssignTask(user *models.User, message string,
 		Title:       title,
 		Description: description,
 		AssignedTo:  assignedUser.ID,
+		DueDate:     dueDate,
 		Status:      string(models.Pending),
-		Priority:    string(models.Medium),
+		Priority:    priority,
 		TaskType:    string(models.Custom),
 		CreatedBy:   user.ID,
 	}
 	
 	err = h.taskService.CreateTask(task)
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal membuat task: %s", err.Error())
+		return t(lang, "task_create_failed", err.Error())
 	}
 	
-	return fmt.Sprintf("✅ Task berhasil ditugaskan!\n📝 Title: %s\n📄 Description: %s\n👤 Assigned to: %s", 
-		title, description, assignedToUsername)
+	response := t(lang, "task_assigned_ai", task.ID, title, description, assignedToUsername)
+	if task.DueDate != nil {
+		response += t(lang, "task_due_line", task.DueDate.Format("2006-01-02"))
+	}
+	if task.Priority != string(models.Medium) {
+		response += t(lang, "task_priority_line", task.Priority)
+	}
+	return response
 }
 
 // handleAIViewTasks processes AI-detected view tasks requests
 func (h *WhatsAppHandler) handleAIViewTasks(user *models.User, message string, aiResult interface{}) string {
-	tasks, err := h.taskService.GetTasksByUser(user.ID)
-	if err != nil {
-		return fmt.Sprintf("❌ Gagal mengambil tasks: %s", err.Error())
-	}
-	
-	if len(tasks) == 0 {
-		return "📝 Tidak ada task yang ditugaskan kepada Anda."
-	}
-	
-	response := "📝 **Your Tasks:**\n\n"
-	for _, task := range tasks {
-		status := "❌ Pending"
-		if task.Status == string(models.InProgress) {
-			status = "🔄 In Progress"
-		} else if task.Status == string(models.Completed) {
-			status = "✅ Completed"
-		}
-		
-		response += fmt.Sprintf("**%s**\n", task.Title)
-		response += fmt.Sprintf("Description: %s\n", task.Description)
-		response += fmt.Sprintf("Status: %s\n", status)
-		response += fmt.Sprintf("Progress: %d%%\n\n", task.CompletionPercentage)
+	return h.myTasks(user, nil)
+}
+
+// handleAIViewOrders processes AI-detected view orders requests. Admins see
+// every order, everyone else only the orders they created; the reply header
+// says which
+func (h *WhatsAppHandler) handleAIViewOrders(user *models.User, aiResponse *AIResponse) string {
+	if h.authorize(user, models.Admin, models.SuperAdmin) {
+		return h.getAllOrders(user, aiPageArgs(aiResponse))
 	}
-	
-	return response
+	return h.viewMyOrders(user)
 }
 
-// handleAIViewOrders processes AI-detected view orders requests
-func (h *WhatsAppHandler) handleAIViewOrders(user *models.User, message string, aiResult interface{}) string {
-	// Check if user has Admin or SuperAdmin access for all orders
-	if user.Role == string(models.Admin) || user.Role == string(models.SuperAdmin) {
-		orders, err := h.orderService.GetAllOrders()
-		if err != nil {
-			return fmt.Sprintf("❌ Gagal mengambil orders: %s", err.Error())
-		}
-		
-		if len(orders) == 0 {
-			return "📦 Tidak ada order yang ditemukan."
-		}
-		
-		response := "📦 **All Orders:**\n\n"
-		for _, order := range orders {
-			response += fmt.Sprintf("**Order #%d**\n", order.ID)
-			response += fmt.Sprintf("Customer: %s\n", order.CustomerName)
-			response += fmt.Sprintf("Total: Rp %.0f\n", order.TotalAmount)
-			response += fmt.Sprintf("Status: %s\n\n", order.Status)
-		}
-		
-		return response
-	} else {
-		// Regular users can only see their own orders
-		orders, err := h.orderService.GetOrdersByUser(user.ID)
-		if err != nil {
-			return fmt.Sprintf("❌ Gagal mengambil orders: %s", err.Error())
-		}
-		
-		if len(orders) == 0 {
-			return "📦 Tidak ada order yang terkait dengan Anda."
+// parseOrderFilter reads "key:value" search tokens (status, customer, min,
+// max, from, to, page). Words following customer: extend the name; anything
+// else that cannot be used is returned as ignored. Dates are read relative to
+// now
+func parseOrderFilter(args []string, now time.Time) (repository.OrderFilter, int, []string) {
+	var filter repository.OrderFilter
+	var ignored []string
+	var customer []string
+	page := 1
+	lastKey := ""
+
+	for _, token := range args {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			if lastKey == "customer" && !ok {
+				customer = append(customer, token)
+			} else {
+				ignored = append(ignored, token)
+			}
+			continue
 		}
-		
-		response := "📦 **Your Orders:**\n\n"
-		for _, order := range orders {
-			response += fmt.Sprintf("**Order #%d**\n", order.ID)
-			response += fmt.Sprintf("Customer: %s\n", order.CustomerName)
-			response += fmt.Sprintf("Total: Rp %.0f\n", order.TotalAmount)
-			response += fmt.Sprintf("Status: %s\n\n", order.Status)
+
+		key = strings.ToLower(key)
+		lastKey = key
+		switch key {
+		case "status":
+			filter.Status = strings.ToLower(value)
+		case "customer":
+			customer = append(customer, value)
+		case "min", "max":
+			amount, err := currency.Parse(value)
+			if err != nil || amount < 0 {
+				ignored = append(ignored, token)
+				continue
+			}
+			if key == "min" {
+				filter.MinAmount = &amount
+			} else {
+				filter.MaxAmount = &amount
+			}
+		case "from", "to":
+			parsed, err := dateparse.Parse(value, now)
+			if err != nil {
+				ignored = append(ignored, token)
+				continue
+			}
+			day := time.Date(parsed.Time.Year(), parsed.Time.Month(), parsed.Time.Day(), 0, 0, 0, 0, parsed.Time.Location())
+			if key == "from" {
+				filter.From = &day
+			} else {
+				// "to" includes the whole day
+				end := day.AddDate(0, 0, 1)
+				filter.To = &end
+			}
+		case "page":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				page = n
+			} else {
+				ignored = append(ignored, token)
+			}
+		default:
+			lastKey = ""
+			ignored = append(ignored, token)
 		}
-		
-		return response
 	}
+
+	filter.Customer = strings.Join(customer, " ")
+	return filter, page, ignored
 }
 
-// handleAIGeneralIntent handles general AI responses
-func (h *WhatsAppHandler) handleAIGeneralIntent(user *models.User, message string, aiResult interface{}) string {
-	// Check for common intents and provide helpful responses
-	messageLower := strings.ToLower(message)
-	
-	if strings.Contains(messageLower, "halo") || strings.Contains(messageLower, "hi") || strings.Contains(messageLower, "hello") {
-		return fmt.Sprintf("👋 Halo %s! Saya AI assistant untuk Task Manager.\n\nSaya dapat membantu Anda dengan:\n• Menambah user (Super Admin)\n• Membuat order (Admin)\n• Menugaskan task (Admin)\n• Melihat tasks dan orders\n\nCoba katakan: 'lihat tasks saya' atau 'buat order John 1000000'", user.Username)
+// searchOrders lists orders matching the given filter tokens. Admins search
+// all orders, everyone else only the orders they created
+func (h *WhatsAppHandler) searchOrders(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) == 0 {
+		return t(lang, "search_orders_usage")
 	}
-	
-	if strings.Contains(messageLower, "help") || strings.Contains(messageLower, "bantuan") {
-		return h.getHelpMessage(user.Role)
+
+	filter, page, ignored := parseOrderFilter(args, h.userNow(user))
+	if !h.authorize(user, models.Admin, models.SuperAdmin) {
+		filter.CreatedBy = &user.ID
 	}
-	
-	// Default AI response with help fallback
-	return fmt.Sprintf("🤖 %s\n\n%s", aiResult, h.getHelpMessage(user.Role))
-}
-
-func (h *WhatsAppHandler) processAdminCommand(user *models.User, command string, args []string) string {
-	switch command {
-	case "/add_user":
-		return h.addUser(user, args)
-	case "/list_users":
-		return h.listUsers()
-	case "/list_tasks":
-		return h.listAllTasks()
-	case "/create_order":
-		return h.createOrder(user.ID, args)
-	case "/view_orders":
-		return h.getAllOrders()
-	case "/assign_task":
-		return h.assignTask(user.ID, args)
-	case "/create_daily_task":
-		return h.createDailyTask(user.ID, args)
-	case "/create_monthly_task":
-		return h.createMonthlyTask(user.ID, args)
-	case "/set_tax_rate":
-		return h.setTaxRate(user.ID, args)
-	case "/set_marketing_rate":
-		return h.setMarketingRate(user.ID, args)
-	case "/set_rental_rate":
-		return h.setRentalRate(user.ID, args)
-	case "/generate_report":
-		return h.generateReport()
-	case "/daily_report":
-		return h.generateDailyReport()
-	case "/monthly_report":
-		return h.generateMonthlyReport()
-	default:
-		return "❌ Unknown admin command. Type /help for available commands."
-	}
-}
-
-func (h *WhatsAppHandler) getHelpMessage(role string) string {
-	baseCommands := `
-📱 **Available Commands:**
-
-**General Commands:**
-/my_tasks - View assigned tasks
-/my_daily_tasks - View today's daily tasks
-/my_monthly_tasks - View this month's tasks
-/update_progress [task_id] [percentage] - Update task progress
-/mark_complete [task_id] - Mark task as implemented
-/view_orders - View related orders
-/my_report - View personal financial reports
-/report_by_date [start_date] [end_date] - Generate reports by date range
-/clear_history - Clear AI chat history
-/show_history - Show AI chat history
-/help - Show this help message
-`
-
-	if role == string(models.Admin) {
-		baseCommands += `
-**Admin Commands:**
-/create_order [customer_name] [total_amount] - Create new order
-/view_orders - List all orders
-/assign_task [username_or_id] [title] [description] - Assign task to user
-/create_daily_task [username_or_id] [title] [description] - Create daily recurring task
-/create_monthly_task [username_or_id] [title] [description] - Create monthly recurring task
-/set_tax_rate [percentage] - Set tax percentage
-/set_marketing_rate [percentage] - Set marketing cost percentage
-/set_rental_rate [percentage] - Set rental cost percentage
-/generate_report - Generate financial reports
-/daily_report - Generate daily report
-/monthly_report - Generate monthly report
-`
-	}
-
-	if role == string(models.SuperAdmin) {
-		baseCommands += `
-**Super Admin Commands:**
-/add_user [username] [email] [phone] [role] - Add new user
-/list_users - View all users (shows User ID for reference)
-/list_tasks - View all tasks in the system
-/update_user - Update user information
-/delete_user - Delete user
-/set_role - Change user role
-/system_config - System configuration
-
-**Admin Commands:**
-/create_order [customer_name] [total_amount] - Create new order
-/view_orders - List all orders
-/assign_task [username_or_id] [title] [description] - Assign task to user
-/create_daily_task [username_or_id] [title] [description] - Create daily recurring task
-/create_monthly_task [username_or_id] [title] [description] - Create monthly recurring task
-/set_tax_rate [percentage] - Set tax percentage
-/set_marketing_rate [percentage] - Set marketing cost percentage
-/set_rental_rate [percentage] - Set rental cost percentage
-/generate_report - Generate financial reports
-/daily_report - Generate daily report
-/monthly_report - Generate monthly report
-`
-	}
-
-	return baseCommands
-}
-
-func (h *WhatsAppHandler) clearChatHistory(userID uint) string {
-	// Clear chat history for AI memory
-	err := h.aiProcessor.ClearChatHistory(fmt.Sprintf("%d", userID))
+	filter.Offset = (page - 1) * listPageSize
+	filter.Limit = listPageSize
+
+	orders, total, err := h.orderService.SearchOrders(filter)
 	if err != nil {
-		return "❌ Failed to clear chat history: " + err.Error()
+		return t(lang, "search_orders_failed", err.Error())
 	}
-	return "✅ Chat history cleared successfully"
-}
 
-func (h *WhatsAppHandler) showChatHistory(userID uint) string {
-	// Show chat history for AI memory
-	history, err := h.aiProcessor.GetChatHistory(fmt.Sprintf("%d", userID))
-	if err != nil {
-		return "❌ Failed to get chat history: " + err.Error()
+	response := ""
+	if len(ignored) > 0 {
+		response += t(lang, "search_ignored", strings.Join(ignored, ", "))
 	}
-	
-	if len(history) == 0 {
-		return "📝 **Chat History:**\n\nNo chat history found."
+
+	if total == 0 {
+		return response + t(lang, "search_orders_empty")
 	}
-	
-	response := "📝 **Chat History (Last 3 messages, expires in 10 minutes):**\n\n"
-	for i, msg := range history {
-		role := "👤 User"
-		if msg.Role == "assistant" {
-			role = "🤖 AI"
-		}
-		response += fmt.Sprintf("%d. %s: %s\n", i+1, role, msg.Content)
-		response += fmt.Sprintf("   Time: %s\n\n", time.Unix(msg.Time, 0).Format("2006-01-02 15:04:05"))
+
+	pages := totalPages(total, listPageSize)
+	if page > pages {
+		return response + t(lang, "page_not_found", page, pages)
+	}
+
+	response += h.formatOrderList(lang, t(lang, "search_orders_header", total), orders)
+	if page < pages {
+		response += t(lang, "search_page_next", page, pages, page+1)
+	} else if pages > 1 {
+		response += t(lang, "page_footer", page, pages)
 	}
-	
 	return response
 }
 
-func (h *WhatsAppHandler) getUserTasks(userID uint) string {
-	tasks, err := h.taskService.GetTasksByUser(userID)
-	if err != nil {
-		return "❌ Failed to get tasks: " + err.Error()
+// ordersByStatus lists all orders in one status
+func (h *WhatsAppHandler) ordersByStatus(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "orders_by_status_usage")
 	}
 
-	if len(tasks) == 0 {
-		return "📝 No tasks assigned to you."
+	status := strings.ToLower(args[0])
+	orders, err := h.orderService.GetOrdersByStatus(status)
+	if err != nil {
+		return t(lang, "error", err.Error())
 	}
 
-	response := "📝 **Your Tasks:**\n\n"
-	for _, task := range tasks {
-		status := "⏳ Pending"
-		if task.Status == string(models.InProgress) {
-			status = "🔄 In Progress"
-		} else if task.Status == string(models.Completed) {
-			status = "✅ Completed"
-		}
-
-		response += fmt.Sprintf("**%s**\n", task.Title)
-		response += fmt.Sprintf("Status: %s\n", status)
-		response += fmt.Sprintf("Progress: %d%%\n", task.CompletionPercentage)
-		response += fmt.Sprintf("Priority: %s\n", task.Priority)
-		if task.DueDate != nil {
-			response += fmt.Sprintf("Due: %s\n", task.DueDate.Format("2006-01-02"))
-		}
-		response += "\n"
+	if len(orders) == 0 {
+		return t(lang, "orders_by_status_empty", status)
 	}
 
-	return response
+	return h.formatOrderList(lang, t(lang, "orders_by_status_header", status, len(orders)), orders)
 }
 
-func (h *WhatsAppHandler) getDailyTasks(userID uint) string {
-	tasks, err := h.taskService.GetDailyTasks(userID, time.Now())
-	if err != nil {
-		return "❌ Failed to get daily tasks: " + err.Error()
+// ordersForCustomer lists every order of a customer found by name or phone
+func (h *WhatsAppHandler) ordersForCustomer(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	customer := strings.TrimSpace(strings.Join(args, " "))
+	if customer == "" {
+		return t(lang, "orders_for_customer_usage")
 	}
 
-	if len(tasks) == 0 {
-		return "📅 No daily tasks for today."
+	orders, err := h.orderService.GetOrdersForCustomer(customer)
+	if err != nil {
+		return t(lang, "orders_failed", err.Error())
 	}
 
-	response := "📅 **Today's Daily Tasks:**\n\n"
-	for _, task := range tasks {
-		response += fmt.Sprintf("**%s**\n", task.Title)
-		response += fmt.Sprintf("Progress: %d%%\n", task.CompletionPercentage)
-		response += fmt.Sprintf("Implemented: %t\n", task.IsImplemented)
-		response += "\n"
+	if len(orders) == 0 {
+		return t(lang, "orders_for_customer_empty", customer)
 	}
 
-	return response
+	return h.formatOrderList(lang, t(lang, "orders_for_customer_header", customer, len(orders)), orders)
 }
 
-func (h *WhatsAppHandler) getMonthlyTasks(userID uint) string {
-	monthYear := time.Now().Format("2006-01")
-	tasks, err := h.taskService.GetMonthlyTasks(userID, monthYear)
+// defaultDeliveryDays is the window /upcoming_deliveries looks ahead by default
+const defaultDeliveryDays = 7
+
+// upcomingDeliveries lists open orders to be delivered in the next N days
+func (h *WhatsAppHandler) upcomingDeliveries(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	days := defaultDeliveryDays
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed <= 0 {
+			return t(lang, "upcoming_deliveries_usage")
+		}
+		days = parsed
+	}
+
+	orders, err := h.orderService.GetUpcomingDeliveries(time.Duration(days) * 24 * time.Hour)
 	if err != nil {
-		return "❌ Failed to get monthly tasks: " + err.Error()
+		return t(lang, "deliveries_failed", err.Error())
 	}
 
-	if len(tasks) == 0 {
-		return "📅 No monthly tasks for this month."
+	if len(orders) == 0 {
+		return t(lang, "deliveries_empty", days)
 	}
 
-	response := "📅 **This Month's Tasks:**\n\n"
-	for _, task := range tasks {
-		response += fmt.Sprintf("**%s**\n", task.Title)
-		response += fmt.Sprintf("Progress: %d%%\n", task.CompletionPercentage)
-		response += fmt.Sprintf("Implemented: %t\n", task.IsImplemented)
-		response += "\n"
+	response := t(lang, "deliveries_header", days, len(orders))
+	for _, order := range orders {
+		response += fmt.Sprintf("**%s** - Order #%d\n", order.DeliveryDate.Format("2006-01-02"), order.ID)
+		response += t(lang, "order_line_customer", order.CustomerName)
+		if order.CustomerPhone != "" {
+			response += t(lang, "order_line_phone", order.CustomerPhone)
+		}
+		response += t(lang, "order_line_total", h.formatCurrency(order.TotalAmount))
+		response += t(lang, "order_line_status", order.Status) + "\n"
 	}
 
 	return response
 }
 
-func (h *WhatsAppHandler) updateTaskProgress(userID uint, args []string) string {
+// aiDaysArgs turns an optional "days" field from AI data into command args
+func aiDaysArgs(aiResponse *AIResponse) []string {
+	if days := int(dataFloat(aiResponse.Data, "days")); days > 0 {
+		return []string{strconv.Itoa(days)}
+	}
+	return nil
+}
+
+// normalizeCustomerPhone brings an optional customer phone into the stored
+// 62... form; an empty value stays empty
+func normalizeCustomerPhone(phone string) string {
+	if strings.TrimSpace(phone) == "" {
+		return ""
+	}
+	return whatsapp.NormalizePhone(phone)
+}
+
+func (h *WhatsAppHandler) updateOrderStatus(user *models.User, args []string) string {
+	lang := userLanguage(user)
 	if len(args) < 2 {
-		return "❌ Usage: /update_progress [task_id] [percentage]"
+		return t(lang, "update_order_status_usage")
 	}
 
-	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	orderID, err := strconv.ParseUint(args[0], 10, 32)
 	if err != nil {
-		return "❌ Invalid task ID"
+		return t(lang, "invalid_order_id")
 	}
 
-	progress, err := strconv.Atoi(args[1])
-	if err != nil || progress < 0 || progress > 100 {
-		return "❌ Invalid progress percentage (0-100)"
+	order, err := h.orderService.GetOrderByID(uint(orderID))
+	if err != nil {
+		return t(lang, "order_not_found", orderID)
 	}
 
-	err = h.taskService.UpdateTaskProgress(uint(taskID), progress, false, "", userID)
+	items, err := h.orderService.GetOrderItems(order.ID)
 	if err != nil {
-		return "❌ Failed to update progress: " + err.Error()
+		return t(lang, "order_items_failed", err.Error())
+	}
+
+	status := strings.ToLower(args[1])
+	if err := h.orderService.UpdateStatus(order.ID, status); err != nil {
+		switch {
+		case errors.Is(err, services.ErrCancelWithCancelOrder):
+			return t(lang, "use_cancel_order", order.ID)
+		case errors.Is(err, services.ErrOrderCancelled):
+			return t(lang, "order_is_cancelled", order.OrderNumber)
+		case errors.Is(err, services.ErrNoTerminalItemStatus):
+			return t(lang, "order_no_terminal_item_status")
+		}
+		return t(lang, "order_status_failed", err.Error())
+	}
+	if err := h.undoService.RecordOrderStatus(user.ID, order, items); err != nil {
+		h.logger.Error("Failed to record undo", "order_id", order.ID, "error", err)
+	}
+
+	h.notifyCustomer(order, status)
+
+	response := t(lang, "order_status_updated", order.OrderNumber, order.Status, status)
+	if status == string(models.OrderCompleted) {
+		response += t(lang, "order_items_completed")
 	}
+	return response
+}
 
-	return fmt.Sprintf("✅ Task progress updated to %d%%", progress)
+// notifyCustomer tells the customer on WhatsApp that their order is now
+// status, if the customer_notifications feature is on and the order has a
+// phone number. A failed send is only logged; the status change stands
+func (h *WhatsAppHandler) notifyCustomer(order *models.Order, status string) {
+	if !features.Enabled(features.CustomerNotifications) || order.CustomerPhone == "" {
+		return
+	}
+	message := t(models.DefaultLanguage, "customer_order_status", order.CustomerName, order.OrderNumber, status)
+	if err := h.whatsappService.SendMessage(order.CustomerPhone, message); err != nil {
+		h.logger.Error("Failed to notify customer", "order_id", order.ID, "phone", order.CustomerPhone, "error", err)
+	}
 }
 
-func (h *WhatsAppHandler) markTaskComplete(userID uint, args []string) string {
+// cancelOrder cancels an order and takes it out of the financial totals
+func (h *WhatsAppHandler) cancelOrder(user *models.User, args []string) string {
+	lang := userLanguage(user)
 	if len(args) < 1 {
-		return "❌ Usage: /mark_complete [task_id]"
+		return t(lang, "cancel_order_usage")
 	}
 
-	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	orderID, err := strconv.ParseUint(args[0], 10, 32)
 	if err != nil {
-		return "❌ Invalid task ID"
+		return t(lang, "invalid_order_id")
 	}
 
-	err = h.taskService.UpdateTaskProgress(uint(taskID), 100, true, "Task completed", userID)
+	order, err := h.orderService.GetOrderByID(uint(orderID))
 	if err != nil {
-		return "❌ Failed to mark task as complete: " + err.Error()
+		return t(lang, "order_not_found", orderID)
+	}
+
+	reason := strings.Join(args[1:], " ")
+	if err := h.orderService.CancelOrder(order.ID, reason, user.ID); err != nil {
+		if errors.Is(err, services.ErrOrderAlreadyCancelled) {
+			return t(lang, "order_already_cancelled", order.OrderNumber)
+		}
+		return t(lang, "cancel_order_failed", err.Error())
 	}
 
-	return "✅ Task marked as implemented"
+	h.logger.Info("audit: order cancelled", "user_id", user.ID, "username", user.Username, "order_id", order.ID, "order_number", order.OrderNumber)
+	h.notifyCustomer(order, string(models.OrderCancelled))
+	response := t(lang, "order_cancelled", order.OrderNumber, h.formatCurrency(order.TotalAmount))
+	if reason != "" {
+		response += t(lang, "order_cancel_reason", reason)
+	}
+	return response
 }
 
-func (h *WhatsAppHandler) getUserOrders(userID uint) string {
-	orders, err := h.orderService.GetOrdersByUser(userID)
-	if err != nil {
-		return "❌ Failed to get orders: " + err.Error()
+// mergeCustomer renames a duplicate customer on all of their orders. Names
+// with spaces are separated by "->", e.g. "/merge_customer Jon Doe -> John Doe"
+func (h *WhatsAppHandler) mergeCustomer(user *models.User, args []string) string {
+	var from, to string
+	joined := strings.Join(args, " ")
+	if parts := strings.SplitN(joined, "->", 2); len(parts) == 2 {
+		from, to = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	} else if len(args) == 2 {
+		from, to = args[0], args[1]
 	}
 
-	if len(orders) == 0 {
-		return "📦 No orders found."
+	lang := userLanguage(user)
+	if from == "" || to == "" {
+		return t(lang, "merge_customer_usage")
 	}
 
-	response := "📦 **Your Orders:**\n\n"
-	for _, order := range orders {
-		response += fmt.Sprintf("**Order #%s**\n", order.OrderNumber)
-		response += fmt.Sprintf("Customer: %s\n", order.CustomerName)
-		response += fmt.Sprintf("Total: $%.2f\n", order.TotalAmount)
-		response += fmt.Sprintf("Status: %s\n", order.Status)
-		response += fmt.Sprintf("Date: %s\n", order.OrderDate.Format("2006-01-02"))
-		response += "\n"
+	count, err := h.orderService.MergeCustomer(from, to)
+	if err != nil {
+		return t(lang, "merge_customer_failed", err.Error())
 	}
 
-	return response
-}
+	if count == 0 {
+		return t(lang, "customer_no_orders", from)
+	}
 
-func (h *WhatsAppHandler) getUserReport(userID uint) string {
-	// Implementation for user report
-	return "📊 **Your Personal Report:**\n\nThis feature will show your personal financial summary."
+	h.logger.Info("audit: customer merged", "user_id", user.ID, "username", user.Username, "from", from, "to", to, "orders", count)
+	return t(lang, "customer_merged", from, to, count)
 }
 
-func (h *WhatsAppHandler) getReportByDate(userID uint, args []string) string {
-	if len(args) < 2 {
-		return "❌ Usage: /report_by_date [start_date] [end_date] (format: YYYY-MM-DD)"
+// loadVisibleOrder parses an order ID and returns the order if user may see
+// it: admins see every order, everyone else only the ones they created
+func (h *WhatsAppHandler) loadVisibleOrder(user *models.User, idArg string) (*models.Order, string) {
+	lang := userLanguage(user)
+	orderID, err := strconv.ParseUint(idArg, 10, 32)
+	if err != nil {
+		return nil, t(lang, "invalid_order_id")
 	}
 
-	startDate, err := time.Parse("2006-01-02", args[0])
+	order, err := h.orderService.GetOrderByID(uint(orderID))
 	if err != nil {
-		return "❌ Invalid start date format. Use YYYY-MM-DD"
+		return nil, t(lang, "order_not_found", orderID)
 	}
 
-	endDate, err := time.Parse("2006-01-02", args[1])
-	if err != nil {
-		return "❌ Invalid end date format. Use YYYY-MM-DD"
+	if order.CreatedBy != user.ID && !h.authorize(user, models.Admin, models.SuperAdmin) {
+		return nil, t(lang, "order_access_denied")
 	}
+	return order, ""
+}
 
-	orders, err := h.orderService.GetOrdersByDateRange(startDate, endDate)
-	if err != nil {
-		return "❌ Failed to get orders: " + err.Error()
+// orderDetail shows an order with its financials, items and notes
+func (h *WhatsAppHandler) orderDetail(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "order_detail_usage")
 	}
 
-	if len(orders) == 0 {
-		return "📊 No orders found for the specified date range."
+	order, errMsg := h.loadVisibleOrder(user, args[0])
+	if order == nil {
+		return errMsg
 	}
 
-	totalAmount := 0.0
-	for _, order := range orders {
-		totalAmount += order.TotalAmount
+	response := t(lang, "order_detail_header", order.ID, order.OrderNumber)
+	response += t(lang, "order_line_customer", order.CustomerName)
+	response += t(lang, "order_line_status", order.Status)
+	response += t(lang, "order_detail_date", order.OrderDate.Format("2006-01-02"))
+	if order.DeliveryDate != nil {
+		response += t(lang, "order_detail_delivery", order.DeliveryDate.Format("2006-01-02"))
+	}
+	response += t(lang, "order_line_total", h.formatCurrency(order.TotalAmount))
+	response += t(lang, "order_detail_net_profit", h.formatCurrency(order.NetProfit))
+
+	items, err := h.orderService.GetOrderItems(order.ID)
+	if err != nil {
+		return t(lang, "order_items_get_failed", err.Error())
+	}
+	if len(items) > 0 {
+		response += t(lang, "order_detail_items")
+		for _, item := range items {
+			response += fmt.Sprintf("- %s: %d x %s (%s)\n", item.ItemName, item.Quantity, h.formatCurrency(item.UnitPrice), item.Status)
+		}
 	}
 
-	response := fmt.Sprintf("📊 **Report for %s to %s:**\n\n", args[0], args[1])
-	response += fmt.Sprintf("Total Orders: %d\n", len(orders))
-	response += fmt.Sprintf("Total Amount: $%.2f\n", totalAmount)
+	notes, err := h.orderService.GetNotes(order.ID)
+	if err != nil {
+		return t(lang, "order_notes_failed", err.Error())
+	}
+	if len(notes) > 0 {
+		response += t(lang, "order_detail_notes")
+		authorIDs := make([]uint, 0, len(notes))
+		for _, note := range notes {
+			authorIDs = append(authorIDs, note.UserID)
+		}
+		authors := h.usernamesByID(authorIDs)
+		for _, note := range notes {
+			response += fmt.Sprintf("- [%s] %s: %s\n", note.CreatedAt.Format("2006-01-02 15:04"), authors[note.UserID], note.Content)
+		}
+	}
 
 	return response
 }
 
-// Admin command implementations
-func (h *WhatsAppHandler) addUser(user *models.User, args []string) string {
-	if len(args) < 4 {
-		return "❌ Usage: /add_user [username] [email] [phone] [role]"
+// addOrderNote attaches an internal note to an order
+func (h *WhatsAppHandler) addOrderNote(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 2 {
+		return t(lang, "order_note_usage")
 	}
 
-	newUser := &models.User{
-		Username:       args[0],
-		Email:          args[1],
-		PhoneNumber:    args[2],
-		Role:           args[3],
-		WhatsAppNumber: args[2],
-		IsActive:       true,
+	order, errMsg := h.loadVisibleOrder(user, args[0])
+	if order == nil {
+		return errMsg
 	}
 
-	err := h.userService.CreateUser(newUser, "default_password")
-	if err != nil {
-		return "❌ Failed to create user: " + err.Error()
+	if _, err := h.orderService.AddNote(order.ID, user.ID, strings.Join(args[1:], " ")); err != nil {
+		return t(lang, "order_note_failed", err.Error())
 	}
 
-	return "✅ User created successfully"
+	return t(lang, "order_note_added", order.ID, order.ID)
 }
 
-func (h *WhatsAppHandler) listUsers() string {
-	users, err := h.userService.GetAllUsers()
+// orderHistory lists how an order's financials were calculated over time so
+// admins can check how its net profit was derived
+func (h *WhatsAppHandler) orderHistory(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "order_history_usage")
+	}
+
+	orderID, err := strconv.ParseUint(args[0], 10, 32)
 	if err != nil {
-		return "❌ Failed to get users: " + err.Error()
+		return t(lang, "invalid_order_id")
+	}
+	order, err := h.orderService.GetOrderByID(uint(orderID))
+	if err != nil {
+		return t(lang, "order_not_found_dot", orderID)
+	}
+
+	history, err := h.orderService.GetCalculationHistory(order.ID)
+	if err != nil {
+		return t(lang, "calculation_history_failed", err.Error())
+	}
+	if len(history) == 0 {
+		return t(lang, "calculation_history_empty", order.ID)
 	}
 
-	response := "👥 **All Users:**\n\n"
-	for _, user := range users {
-		status := "❌ Inactive"
-		if user.IsActive {
-			status = "✅ Active"
+	response := t(lang, "calculation_history_header", order.ID, order.CustomerName)
+	for _, entry := range history {
+		response += fmt.Sprintf("[%s] %s\n", entry.CalculationTimestamp.Format("2006-01-02 15:04"), entry.CalculationType)
+		rate := fmt.Sprintf("%.2f%%", entry.PercentageUsed)
+		if entry.FixedAmountUsed != 0 {
+			rate += t(lang, "calculation_fixed", h.formatCurrency(entry.FixedAmountUsed))
+		}
+		response += t(lang, "calculation_line", h.formatCurrency(entry.InputValue), rate, h.formatCurrency(entry.CalculatedAmount))
+		if entry.PreviousNetProfit != nil {
+			response += t(lang, "calculation_previous", h.formatCurrency(*entry.PreviousNetProfit))
 		}
-		response += fmt.Sprintf("**ID: %d** - **%s** (%s)\n", user.ID, user.Username, user.Email)
-		response += fmt.Sprintf("Role: %s\n", user.Role)
-		response += fmt.Sprintf("Status: %s\n", status)
 		response += "\n"
 	}
 
-	return response
+	return strings.TrimRight(response, "\n")
 }
 
-func (h *WhatsAppHandler) listAllTasks() string {
-	tasks, err := h.taskService.GetAllTasks()
-	if err != nil {
-		return "❌ Failed to get tasks: " + err.Error()
+// taskProgressHistory lists every progress update of a task in order. The
+// assignee, the creator and managers may view it
+func (h *WhatsAppHandler) taskProgressHistory(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "task_progress_history_usage")
 	}
 
-	if len(tasks) == 0 {
-		return "📝 **All Tasks:**\n\nNo tasks found."
+	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_task_id")
+	}
+	task, err := h.taskService.GetTaskByID(uint(taskID))
+	if err != nil {
+		return t(lang, "task_not_found_dot", taskID)
+	}
+	if task.AssignedTo != user.ID && task.CreatedBy != user.ID && !h.authorize(user, models.Admin, models.SuperAdmin) {
+		return t(lang, "progress_history_denied")
 	}
 
-	response := "📝 **All Tasks:**\n\n"
-	for _, task := range tasks {
-		status := "❌ Pending"
-		if task.Status == string(models.InProgress) {
-			status = "🔄 In Progress"
-		} else if task.Status == string(models.Completed) {
-			status = "✅ Completed"
-		} else if task.Status == string(models.Overdue) {
-			status = "⚠️ Overdue"
-		}
-
-		priority := "🟡 Medium"
-		if task.Priority == string(models.High) {
-			priority = "🔴 High"
-		} else if task.Priority == string(models.Low) {
-			priority = "🟢 Low"
-		} else if task.Priority == string(models.Urgent) {
-			priority = "🚨 Urgent"
-		}
+	history, err := h.taskService.GetProgressHistory(task.ID)
+	if err != nil {
+		return t(lang, "progress_history_failed", err.Error())
+	}
+	if len(history) == 0 {
+		return t(lang, "progress_history_empty", task.ID)
+	}
 
-		implemented := "❌ Not Implemented"
-		if task.IsImplemented {
-			implemented = "✅ Implemented"
-		}
+	updaterIDs := make([]uint, 0, len(history))
+	for _, entry := range history {
+		updaterIDs = append(updaterIDs, entry.UpdatedBy)
+	}
+	usernames := h.usernamesByID(updaterIDs)
 
-		response += fmt.Sprintf("**ID: %d** - **%s**\n", task.ID, task.Title)
-		response += fmt.Sprintf("Description: %s\n", task.Description)
-		response += fmt.Sprintf("Assigned To: User ID %d\n", task.AssignedTo)
-		response += fmt.Sprintf("Status: %s\n", status)
-		response += fmt.Sprintf("Priority: %s\n", priority)
-		response += fmt.Sprintf("Progress: %d%%\n", task.CompletionPercentage)
-		response += fmt.Sprintf("Implemented: %s\n", implemented)
-		if task.DueDate != nil {
-			response += fmt.Sprintf("Due Date: %s\n", task.DueDate.Format("2006-01-02 15:04"))
-		}
-		if task.CompletedAt != nil {
-			response += fmt.Sprintf("Completed: %s\n", task.CompletedAt.Format("2006-01-02 15:04"))
+	response := t(lang, "progress_history_header", task.ID, task.Title)
+	for _, entry := range history {
+		response += t(lang, "progress_history_line", entry.UpdatedAt.Format("2006-01-02 15:04"), entry.CompletionPercentage, usernames[entry.UpdatedBy])
+		if entry.IsImplemented {
+			response += " ✅"
 		}
 		response += "\n"
+		if entry.ImplementationNotes != "" {
+			response += fmt.Sprintf("📝 %s\n", entry.ImplementationNotes)
+		}
 	}
 
-	return response
+	return strings.TrimRight(response, "\n")
 }
 
-func (h *WhatsAppHandler) createOrder(userID uint, args []string) string {
-	if len(args) < 2 {
-		return "❌ Usage: /create_order [customer_name] [total_amount]"
+// customerSummary shows order count, revenue, profit and average order value
+// for one customer
+func (h *WhatsAppHandler) customerSummary(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if name == "" {
+		return t(lang, "customer_summary_usage")
 	}
 
-	totalAmount, err := strconv.ParseFloat(args[1], 64)
+	summary, err := h.orderService.GetCustomerSummary(name)
 	if err != nil {
-		return "❌ Invalid total amount"
+		return t(lang, "customer_summary_failed", err.Error())
 	}
 
-	order := &models.Order{
-		OrderNumber:  fmt.Sprintf("ORD-%d", time.Now().Unix()),
-		CustomerName: args[0],
-		TotalAmount:  totalAmount,
-		Status:       string(models.OrderPending),
-		OrderDate:    time.Now(),
-		CreatedBy:    userID,
+	if summary.OrderCount == 0 && summary.CancelledCount == 0 {
+		return t(lang, "customer_summary_empty", name)
 	}
 
-	err = h.orderService.CreateOrder(order)
-	if err != nil {
-		return "❌ Failed to create order: " + err.Error()
+	response := t(lang, "customer_summary", summary.Customer, summary.OrderCount)
+	if summary.CancelledCount > 0 {
+		response += t(lang, "customer_summary_cancelled", summary.CancelledCount)
 	}
-
-	return fmt.Sprintf("✅ Order created successfully\nOrder #: %s\nCustomer: %s\nTotal: $%.2f", 
-		order.OrderNumber, order.CustomerName, order.TotalAmount)
+	response += t(lang, "customer_summary_totals", h.formatCurrency(summary.TotalRevenue),
+		h.formatCurrency(summary.TotalNetProfit), h.formatCurrency(summary.AverageOrderValue))
+	return response
 }
 
-func (h *WhatsAppHandler) getAllOrders() string {
-	orders, err := h.orderService.GetAllOrders()
-	if err != nil {
-		return "❌ Failed to get orders: " + err.Error()
+// restoreOrder brings back a deleted order. Without an ID it lists the
+// orders that can be restored
+func (h *WhatsAppHandler) restoreOrder(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		orders, err := h.orderService.GetDeletedOrders()
+		if err != nil {
+			return t(lang, "deleted_orders_failed", err.Error())
+		}
+		if len(orders) == 0 {
+			return t(lang, "deleted_orders_empty")
+		}
+		return h.formatOrderList(lang, t(lang, "deleted_orders_header"), orders) + t(lang, "deleted_orders_hint")
 	}
 
-	if len(orders) == 0 {
-		return "📦 No orders found."
+	orderID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_order_id")
 	}
 
-	response := "📦 **All Orders:**\n\n"
-	for _, order := range orders {
-		response += fmt.Sprintf("**Order #%s**\n", order.OrderNumber)
-		response += fmt.Sprintf("Customer: %s\n", order.CustomerName)
-		response += fmt.Sprintf("Total: $%.2f\n", order.TotalAmount)
-		response += fmt.Sprintf("Status: %s\n", order.Status)
-		response += fmt.Sprintf("Date: %s\n", order.OrderDate.Format("2006-01-02"))
-		response += "\n"
+	if err := h.orderService.RestoreOrder(uint(orderID)); err != nil {
+		if errors.Is(err, services.ErrOrderNotDeleted) {
+			return t(lang, "order_not_deleted", orderID)
+		}
+		return t(lang, "restore_order_failed", err.Error())
 	}
 
-	return response
+	h.logger.Info("audit: order restored", "user_id", user.ID, "username", user.Username, "order_id", orderID)
+	return t(lang, "order_restored", orderID)
 }
 
-func (h *WhatsAppHandler) assignTask(userID uint, args []string) string {
-	if len(args) < 3 {
-		return "❌ Usage: /assign_task [username_or_id] [title] [description]"
+// viewMyOrders lists the orders created by user
+func (h *WhatsAppHandler) viewMyOrders(user *models.User) string {
+	lang := userLanguage(user)
+	orders, err := h.orderService.GetOrdersByUser(user.ID)
+	if err != nil {
+		return t(lang, "orders_failed", err.Error())
 	}
-
-	// Try to parse as user ID first
-	var assignedTo uint
-	if userID, err := strconv.ParseUint(args[0], 10, 32); err == nil {
-		assignedTo = uint(userID)
+	
+	if len(orders) == 0 {
+		return t(lang, "my_orders_empty")
+	}
+	
+	header := t(lang, "my_orders_header")
+	limit := h.listMaxItems()
+	if len(orders) <= limit {
+		return h.formatOrderList(lang, header, orders)
+	}
+	return h.formatOrderList(lang, header, orders[:limit]) + moreItemsNotice(lang, len(orders)-limit)
+}
+
+// formatOrderList renders orders under the given header, labelled in lang
+func (h *WhatsAppHandler) formatOrderList(lang, header string, orders []models.Order) string {
+	response := header + "\n\n"
+	for _, order := range orders {
+		response += fmt.Sprintf("**Order #%d**\n", order.ID)
+		response += t(lang, "order_line_customer", order.CustomerName)
+		response += t(lang, "order_line_total", h.formatCurrency(order.TotalAmount))
+		response += t(lang, "order_line_status", order.Status) + "\n"
+	}
+	return response
+}
+
+// authorize reports whether user holds one of roles. Roles are compared in
+// their canonical form so "SuperAdmin" and "super_admin" are the same role
+func (h *WhatsAppHandler) authorize(user *models.User, roles ...models.UserRole) bool {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return requireRole(user, names...) == nil
+}
+
+// handleAIGeneralIntent handles general AI responses
+func (h *WhatsAppHandler) handleAIGeneralIntent(user *models.User, message string, aiResult interface{}) string {
+	// Check for common intents and provide helpful responses
+	messageLower := strings.ToLower(message)
+	
+	if strings.Contains(messageLower, "halo") || strings.Contains(messageLower, "hi") || strings.Contains(messageLower, "hello") {
+		return t(userLanguage(user), "greeting", user.Username)
+	}
+	
+	if strings.Contains(messageLower, "help") || strings.Contains(messageLower, "bantuan") {
+		return h.getHelpMessage(user)
+	}
+	
+	// Default AI response with help fallback
+	return fmt.Sprintf("🤖 %s\n\n%s", aiResult, h.getHelpMessage(user))
+}
+
+// parseDueDate accepts an absolute YYYY-MM-DD date or a relative phrase
+// understood by dateparse (besok, next monday, akhir bulan, ...) and returns
+// the start of that day in now's location
+func parseDueDate(input string, now time.Time) (*time.Time, error) {
+	result, err := dateparse.Parse(input, now)
+	if err != nil {
+		return nil, err
+	}
+
+	due := result.Time
+	due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, due.Location())
+	return &due, nil
+}
+
+// parseTaskPriority validates a priority given on assignment, defaulting to
+// medium when none was given
+func parseTaskPriority(input string) (string, error) {
+	priority := strings.ToLower(strings.TrimSpace(input))
+	if priority == "" {
+		return string(models.Medium), nil
+	}
+	if !services.IsValidTaskPriority(priority) {
+		return "", fmt.Errorf("invalid priority %q, valid priorities: low, medium, high, urgent", input)
+	}
+	return priority, nil
+}
+
+// parseDateRange reads a start and end date from args, either as two single
+// tokens ("2025-01-01 2025-01-31") or as phrases separated by to/sampai/s/d
+// ("awal bulan sampai hari ini"), relative to now. An end given as a day
+// without a time covers that whole day
+func parseDateRange(args []string, now time.Time) (time.Time, time.Time, error) {
+	joined := strings.ToLower(strings.Join(args, " "))
+	var startPhrase, endPhrase string
+	for _, sep := range []string{" sampai ", " hingga ", " s/d ", " to ", " - "} {
+		if parts := strings.SplitN(joined, sep, 2); len(parts) == 2 {
+			startPhrase, endPhrase = parts[0], parts[1]
+			break
+		}
+	}
+	if startPhrase == "" {
+		if len(args) != 2 {
+			return time.Time{}, time.Time{}, fmt.Errorf("expected a start and an end date")
+		}
+		startPhrase, endPhrase = args[0], args[1]
+	}
+
+	start, err := dateparse.Parse(startPhrase, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := dateparse.Parse(endPhrase, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %w", err)
+	}
+	if !end.HasTime {
+		end.Time = endOfDay(end.Time)
+	}
+	return start.Time, end.Time, nil
+}
+
+// endOfDay is the last moment of t's day that the database can store, for
+// use as an inclusive upper bound
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, int(time.Second-time.Microsecond), t.Location())
+}
+
+// userLocation is the time zone dates from user are read in: their own
+// timezone when set and known, otherwise the configured TIMEZONE
+func (h *WhatsAppHandler) userLocation(user *models.User) *time.Location {
+	if user != nil && user.Timezone != "" {
+		if loc, err := time.LoadLocation(user.Timezone); err == nil {
+			return loc
+		}
+	}
+	if loc, err := time.LoadLocation(h.cfg.Timezone); err == nil {
+		return loc
+	}
+	return time.Local
+}
+
+// userNow is the current time in user's time zone
+func (h *WhatsAppHandler) userNow(user *models.User) time.Time {
+	return h.clock.Now().In(h.userLocation(user))
+}
+
+// formatCurrency renders an amount in the configured currency
+func (h *WhatsAppHandler) formatCurrency(amount float64) string {
+	return currency.Format(amount, h.cfg.Currency)
+}
+
+// getHelpMessage lists the commands user may run, in their language
+func (h *WhatsAppHandler) getHelpMessage(user *models.User) string {
+	lang := userLanguage(user)
+	help := t(lang, "help_general")
+	switch models.NormalizeRole(user.Role) {
+	case string(models.SuperAdmin):
+		help += t(lang, "help_super_admin") + t(lang, "help_admin")
+	case string(models.Admin):
+		help += t(lang, "help_admin")
+	}
+	return help
+}
+
+func (h *WhatsAppHandler) clearChatHistory(user *models.User) string {
+	lang := userLanguage(user)
+	// Clear chat history for AI memory
+	err := h.aiProcessor.ClearChatHistory(fmt.Sprintf("%d", user.ID))
+	if err != nil {
+		return t(lang, "chat_history_clear_failed", err.Error())
+	}
+	return t(lang, "chat_history_cleared")
+}
+
+func (h *WhatsAppHandler) showChatHistory(user *models.User) string {
+	lang := userLanguage(user)
+	// Show chat history for AI memory
+	history, err := h.aiProcessor.GetChatHistory(fmt.Sprintf("%d", user.ID))
+	if err != nil {
+		return t(lang, "chat_history_failed", err.Error())
+	}
+	
+	if len(history) == 0 {
+		return t(lang, "chat_history_empty")
+	}
+	
+	historyConfig := h.aiProcessor.HistoryConfig()
+	response := t(lang, "chat_history_header", historyConfig.Size, int(historyConfig.TTL.Minutes()))
+	for i, msg := range history {
+		role := t(lang, "chat_history_user")
+		if msg.Role == "assistant" {
+			role = t(lang, "chat_history_ai")
+		}
+		response += fmt.Sprintf("%d. %s: %s\n", i+1, role, msg.Content)
+		response += t(lang, "chat_history_time", time.Unix(msg.Time, 0).Format("2006-01-02 15:04:05"))
+	}
+	
+	return response
+}
+
+// myTasks lists the caller's tasks soonest due first, at most
+// listMaxItems per message
+func (h *WhatsAppHandler) myTasks(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	tasks, err := h.taskService.GetTasksByUser(user.ID)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "my_tasks_empty")
+	}
+
+	models.SortTasksByDueDate(tasks)
+	size := h.listMaxItems()
+	page := parsePage(args)
+	pages := totalPages(int64(len(tasks)), size)
+	if page > pages {
+		return t(lang, "page_not_found", page, pages)
+	}
+
+	start := (page - 1) * size
+	end := min(start+size, len(tasks))
+	response := formatTaskList(lang, t(lang, "my_tasks_header"), tasks[start:end], h.clock.Now())
+	if hidden := len(tasks) - end; hidden > 0 {
+		response += moreItemsNotice(lang, hidden) + "\n"
+	}
+	if pages > 1 {
+		response += pageFooter(lang, "/my_tasks", page, pages)
+	}
+	return response
+}
+
+// myTasksByFilter lists the caller's tasks with one status or priority
+func (h *WhatsAppHandler) myTasksByFilter(user *models.User, command string, args []string, get func(userID uint, value string) ([]models.Task, error)) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		if command == "/tasks_by_priority" {
+			return t(lang, "tasks_by_priority_usage")
+		}
+		return t(lang, "tasks_by_status_usage")
+	}
+
+	value := strings.ToLower(args[0])
+	tasks, err := get(user.ID, value)
+	if err != nil {
+		return t(lang, "error", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "my_tasks_filter_empty", value)
+	}
+
+	limit := h.listMaxItems()
+	header := t(lang, "my_tasks_filter_header", value, len(tasks))
+	if len(tasks) <= limit {
+		return formatTaskList(lang, header, tasks, h.clock.Now())
+	}
+	models.SortTasksByDueDate(tasks)
+	return formatTaskList(lang, header, tasks[:limit], h.clock.Now()) + moreItemsNotice(lang, len(tasks)-limit)
+}
+
+// searchTasks finds tasks by a keyword in their title or description. Admins
+// search everyone's tasks, other users only their own
+func (h *WhatsAppHandler) searchTasks(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	keyword := strings.TrimSpace(strings.Join(args, " "))
+	if keyword == "" {
+		return t(lang, "search_tasks_usage")
+	}
+
+	allUsers := h.authorize(user, models.Admin, models.SuperAdmin)
+	tasks, err := h.taskService.SearchTasks(user.ID, keyword, allUsers)
+	if err != nil {
+		return t(lang, "search_tasks_failed", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "search_tasks_empty", keyword)
+	}
+
+	limit := h.listMaxItems()
+	header := t(lang, "search_tasks_header", keyword, len(tasks))
+	if len(tasks) <= limit {
+		return formatTaskList(lang, header, tasks, h.clock.Now())
+	}
+	models.SortTasksByDueDate(tasks)
+	return formatTaskList(lang, header, tasks[:limit], h.clock.Now()) + moreItemsNotice(lang, len(tasks)-limit)
+}
+
+// formatTaskList renders tasks with status, progress, priority and due date,
+// soonest due first and tasks without a due date last, labelled in lang
+func formatTaskList(lang, header string, tasks []models.Task, now time.Time) string {
+	sorted := make([]models.Task, len(tasks))
+	copy(sorted, tasks)
+	models.SortTasksByDueDate(sorted)
+
+	response := header + "\n\n"
+	for _, task := range sorted {
+		status := t(lang, "task_status_pending")
+		if task.Status == string(models.InProgress) {
+			status = t(lang, "task_status_in_progress")
+		} else if task.Status == string(models.Completed) {
+			status = t(lang, "task_status_completed")
+		}
+		if task.IsOverdue(now) {
+			status += t(lang, "task_overdue_suffix")
+		}
+
+		response += fmt.Sprintf("**#%d %s**\n", task.ID, task.Title)
+		response += t(lang, "task_line_status", status)
+		response += t(lang, "task_line_progress", task.CompletionPercentage)
+		response += t(lang, "task_line_priority", task.Priority)
+		if task.DueDate != nil {
+			response += t(lang, "task_line_due", task.DueDate.Format("2006-01-02"))
+		}
+		response += "\n"
+	}
+
+	return response
+}
+
+// myAssignedTasks lists the tasks the calling admin created, grouped by assignee
+func (h *WhatsAppHandler) myAssignedTasks(user *models.User) string {
+	lang := userLanguage(user)
+	tasks, err := h.taskService.GetTasksByCreator(user.ID)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+	if len(tasks) == 0 {
+		return t(lang, "assigned_tasks_empty")
+	}
+
+	assigneeIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		assigneeIDs = append(assigneeIDs, task.AssignedTo)
+	}
+	usernames := h.usernamesByID(assigneeIDs)
+
+	response := t(lang, "assigned_tasks_header")
+	var current uint
+	for i, task := range tasks {
+		if i == 0 || task.AssignedTo != current {
+			current = task.AssignedTo
+			response += fmt.Sprintf("\n👤 **%s**\n", usernames[current])
+		}
+		response += fmt.Sprintf("- #%d %s — %s, %d%%\n", task.ID, task.Title, task.Status, task.CompletionPercentage)
+	}
+
+	return response
+}
+
+// userTasks lists the tasks assigned to another user, for managers checking
+// on their team
+func (h *WhatsAppHandler) userTasks(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "user_tasks_usage")
+	}
+
+	target, err := h.resolveUser(args[0])
+	if err != nil {
+		return t(lang, "user_not_found_named", args[0])
+	}
+
+	tasks, err := h.taskService.GetTasksByUser(target.ID)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "user_tasks_empty", target.Username)
+	}
+
+	return formatTaskList(lang, t(lang, "user_tasks_header", target.Username), tasks, h.clock.Now())
+}
+
+func (h *WhatsAppHandler) getMyStats(user *models.User) string {
+	lang := userLanguage(user)
+	tasks, err := h.taskService.GetTasksByUser(user.ID)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+
+	completed, inProgress, pending := 0, 0, 0
+	totalProgress := 0
+	for _, task := range tasks {
+		switch task.Status {
+		case string(models.Completed):
+			completed++
+		case string(models.InProgress):
+			inProgress++
+		default:
+			pending++
+		}
+		totalProgress += task.CompletionPercentage
+	}
+
+	response := t(lang, "stats_header")
+	response += t(lang, "stats_total", len(tasks))
+	response += t(lang, "stats_completed", completed)
+	response += t(lang, "stats_in_progress", inProgress)
+	response += t(lang, "stats_pending", pending)
+	if len(tasks) > 0 {
+		response += t(lang, "stats_average", totalProgress/len(tasks))
+	}
+
+	streak, err := h.taskService.GetDailyStreak(user.ID)
+	if err == nil && streak > 0 {
+		response += t(lang, "stats_streak", streak)
+	}
+
+	return response
+}
+
+func (h *WhatsAppHandler) getDailyTasks(user *models.User) string {
+	lang := userLanguage(user)
+	tasks, err := h.taskService.GetDailyTasks(user.ID, h.clock.Now())
+	if err != nil {
+		return t(lang, "daily_tasks_failed", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "daily_tasks_empty")
+	}
+
+	response := t(lang, "daily_tasks_header")
+	for _, task := range tasks {
+		response += fmt.Sprintf("**%s**\n", task.Title)
+		response += t(lang, "task_line_progress", task.CompletionPercentage)
+		response += t(lang, "task_line_implemented", task.IsImplemented)
+		response += "\n"
+	}
+
+	return response
+}
+
+func (h *WhatsAppHandler) getMonthlyTasks(user *models.User) string {
+	lang := userLanguage(user)
+	monthYear := h.clock.Now().Format("2006-01")
+	tasks, err := h.taskService.GetMonthlyTasks(user.ID, monthYear)
+	if err != nil {
+		return t(lang, "monthly_tasks_failed", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "monthly_tasks_empty")
+	}
+
+	response := t(lang, "monthly_tasks_header")
+	for _, task := range tasks {
+		response += fmt.Sprintf("**%s**\n", task.Title)
+		response += t(lang, "task_line_progress", task.CompletionPercentage)
+		response += t(lang, "task_line_implemented", task.IsImplemented)
+		response += "\n"
+	}
+
+	return response
+}
+
+// getWeeklyTasks shows the user's weekly tasks for this ISO week or the week
+// given as YYYY-Www
+func (h *WhatsAppHandler) getWeeklyTasks(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	week := services.WeekKey(h.clock.Now())
+	if len(args) > 0 {
+		week = strings.ToUpper(args[0])
+	}
+
+	tasks, err := h.taskService.GetWeeklyTasks(user.ID, week)
+	if err != nil {
+		return t(lang, "weekly_tasks_failed", err.Error())
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "weekly_tasks_empty", week)
+	}
+
+	response := t(lang, "weekly_tasks_header", week)
+	for _, task := range tasks {
+		response += fmt.Sprintf("**%s**\n", task.Title)
+		response += t(lang, "task_line_progress", task.CompletionPercentage)
+		response += t(lang, "task_line_implemented", task.IsImplemented)
+		response += "\n"
+	}
+
+	return response
+}
+
+func (h *WhatsAppHandler) updateTaskProgress(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 2 {
+		return t(lang, "update_progress_usage")
+	}
+
+	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_task_id")
+	}
+
+	progress, err := strconv.Atoi(args[1])
+	if err != nil || progress < 0 || progress > 100 {
+		return t(lang, "invalid_progress")
+	}
+
+	task, err := h.taskService.GetTaskByID(uint(taskID))
+	if err != nil {
+		return t(lang, "task_not_found", taskID)
+	}
+
+	err = h.taskService.UpdateTaskProgress(task.ID, progress, false, "", user.ID)
+	if errors.Is(err, services.ErrTaskProgressForbidden) {
+		return t(lang, "task_not_yours")
+	}
+	if err != nil {
+		return t(lang, "update_progress_failed", err.Error())
+	}
+	h.recordTaskChange(user.ID, services.UndoTaskProgress, task)
+
+	return t(lang, "progress_updated", progress)
+}
+
+func (h *WhatsAppHandler) markTaskComplete(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "mark_complete_usage")
+	}
+
+	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_task_id")
+	}
+
+	task, err := h.taskService.GetTaskByID(uint(taskID))
+	if err != nil {
+		return t(lang, "task_not_found", taskID)
+	}
+
+	err = h.taskService.UpdateStatus(task.ID, string(models.Completed), user.ID)
+	if errors.Is(err, services.ErrTaskStatusForbidden) {
+		return t(lang, "task_not_yours")
+	}
+	if err != nil {
+		return t(lang, "mark_complete_failed", err.Error())
+	}
+
+	err = h.taskService.UpdateTaskProgress(task.ID, 100, true, "Task completed", user.ID)
+	if errors.Is(err, services.ErrTaskProgressForbidden) {
+		return t(lang, "task_not_yours")
+	}
+	if err != nil {
+		return t(lang, "mark_complete_failed", err.Error())
+	}
+	h.recordTaskChange(user.ID, services.UndoTaskStatus, task)
+
+	return t(lang, "task_implemented")
+}
+
+func (h *WhatsAppHandler) recordOrderCreated(userID uint, order *models.Order) {
+	if err := h.undoService.RecordOrderCreated(userID, order); err != nil {
+		h.logger.Error("Failed to record undo", "order_id", order.ID, "error", err)
+	}
+}
+
+// recordTaskChange remembers task's state from before the change so /undo
+// can restore it
+func (h *WhatsAppHandler) recordTaskChange(userID uint, actionType string, task *models.Task) {
+	if err := h.undoService.RecordTaskChange(userID, actionType, task); err != nil {
+		h.logger.Error("Failed to record undo", "task_id", task.ID, "error", err)
+	}
+}
+
+// undoLastAction reverses the user's most recent order creation, order
+// status change or task update
+func (h *WhatsAppHandler) undoLastAction(user *models.User) string {
+	lang := userLanguage(user)
+	action, err := h.undoService.Undo(user.ID)
+	if errors.Is(err, services.ErrNothingToUndo) {
+		return t(lang, "nothing_to_undo", int(services.UndoWindow.Minutes()))
+	}
+	if err != nil {
+		return t(lang, "undo_failed", err.Error())
+	}
+
+	h.logger.Info("audit: action undone", "user_id", user.ID, "username", user.Username, "action", action.Type, "entity_id", action.EntityID)
+	switch action.Type {
+	case services.UndoOrderCreated:
+		return t(lang, "undo_order_created", action.EntityID)
+	case services.UndoOrderStatus:
+		return t(lang, "undo_order_status", action.EntityID, action.PreviousStatus)
+	case services.UndoTaskProgress:
+		return t(lang, "undo_task_progress", action.EntityID, action.PreviousProgress)
+	}
+	return t(lang, "undo_task_status", action.EntityID, action.PreviousStatus, action.PreviousProgress)
+}
+
+func (h *WhatsAppHandler) deleteTaskCommand(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "delete_task_usage")
+	}
+
+	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_task_id")
+	}
+
+	return h.deleteTask(user, uint(taskID))
+}
+
+// deleteTask removes a task. Only its creator or an admin may delete it
+func (h *WhatsAppHandler) deleteTask(user *models.User, taskID uint) string {
+	lang := userLanguage(user)
+	task, err := h.taskService.GetTaskByID(taskID)
+	if err != nil {
+		return t(lang, "task_not_found", taskID)
+	}
+
+	if task.CreatedBy != user.ID && !h.authorize(user, models.Admin, models.SuperAdmin) {
+		return t(lang, "delete_task_denied")
+	}
+
+	if err := h.taskService.DeleteTask(task.ID); err != nil {
+		return t(lang, "delete_task_failed", err.Error())
+	}
+
+	h.logger.Info("audit: task deleted", "user_id", user.ID, "username", user.Username, "task_id", task.ID, "title", task.Title)
+	return t(lang, "task_deleted", task.ID, task.Title)
+}
+
+// changeTaskStatus handles /start_task and /block_task
+func (h *WhatsAppHandler) changeTaskStatus(user *models.User, command string, args []string, status models.TaskStatus) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "task_id_usage", command)
+	}
+
+	taskID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_task_id")
+	}
+
+	task, err := h.taskService.GetTaskByID(uint(taskID))
+	if err != nil {
+		return t(lang, "task_not_found", taskID)
+	}
+
+	if err := h.taskService.UpdateStatus(task.ID, string(status), user.ID); err != nil {
+		return t(lang, "task_status_failed", err.Error())
+	}
+	h.recordTaskChange(user.ID, services.UndoTaskStatus, task)
+
+	switch status {
+	case models.InProgress:
+		return t(lang, "task_started", taskID)
+	case models.Blocked:
+		return t(lang, "task_blocked", taskID)
+	}
+	return t(lang, "task_status_set", taskID, status)
+}
+
+func (h *WhatsAppHandler) getReportByDate(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	// A trailing "all" counts cancelled orders too
+	includeCancelled := false
+	if len(args) > 0 && strings.EqualFold(args[len(args)-1], reportIncludeCancelled) {
+		includeCancelled = true
+		args = args[:len(args)-1]
+	}
+
+	if len(args) < 2 {
+		return t(lang, "report_by_date_usage")
+	}
+
+	startDate, endDate, err := parseDateRange(args, h.userNow(user))
+	if err != nil {
+		return t(lang, "error", err.Error())
+	}
+
+	summary, err := h.orderService.GetFinancialSummary(startDate, endDate, includeCancelled)
+	if err != nil {
+		return t(lang, "orders_failed", err.Error())
+	}
+
+	if summary.OrderCount == 0 {
+		return t(lang, "report_empty")
+	}
+
+	reportData := map[string]interface{}{
+		"total_orders":      summary.OrderCount,
+		"total_amount":      summary.TotalRevenue,
+		"total_net_profit":  summary.TotalNetProfit,
+		"cancelled_orders":  summary.CancelledCount,
+		"include_cancelled": includeCancelled,
+	}
+	if err := h.orderService.RecordReportQuery(user.ID, reportTypeCustomRange, &startDate, &endDate, reportData); err != nil {
+		h.logger.Error("Failed to record report query", "user_id", user.ID, "error", err)
+	}
+
+	response := t(lang, "report_header", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	response += t(lang, "report_totals", summary.OrderCount, h.formatCurrency(summary.TotalRevenue), h.formatCurrency(summary.TotalNetProfit))
+	if includeCancelled {
+		response += t(lang, "report_including_cancelled", summary.CancelledCount)
 	} else {
-		// If not a number, treat as username
-		user, err := h.userService.GetUserByUsername(args[0])
+		response += t(lang, "report_excluding_cancelled")
+	}
+
+	return response
+}
+
+// taskReport summarizes the tasks completed in a date range: how many, how
+// they split by priority and how long they took on average
+func (h *WhatsAppHandler) taskReport(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 2 {
+		return t(lang, "task_report_usage")
+	}
+
+	startDate, endDate, err := parseDateRange(args, h.userNow(user))
+	if err != nil {
+		return t(lang, "error", err.Error())
+	}
+
+	tasks, err := h.taskService.GetCompletedTasks(startDate, endDate)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+
+	period := t(lang, "period", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if len(tasks) == 0 {
+		return t(lang, "task_report_empty", period)
+	}
+
+	byPriority := make(map[string]int)
+	var totalDuration time.Duration
+	for _, task := range tasks {
+		byPriority[task.Priority]++
+		totalDuration += task.CompletedAt.Sub(task.CreatedAt)
+	}
+	average := totalDuration / time.Duration(len(tasks))
+
+	response := t(lang, "task_report_header", period, len(tasks))
+	for _, priority := range []models.TaskPriority{models.Urgent, models.High, models.Medium, models.Low} {
+		if count := byPriority[string(priority)]; count > 0 {
+			response += fmt.Sprintf("- %s: %d\n", priority, count)
+		}
+	}
+	response += t(lang, "task_report_average", formatElapsed(lang, average))
+
+	return response
+}
+
+// formatElapsed renders a duration as days and hours, or hours and minutes
+// when it is under a day, abbreviated in lang
+func formatElapsed(lang string, d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	if days > 0 {
+		return t(lang, "elapsed_days", days, hours)
+	}
+	return t(lang, "elapsed_hours", hours, int(d%time.Hour/time.Minute))
+}
+
+// reportIncludeCancelled is the /report_by_date option that counts cancelled orders
+const reportIncludeCancelled = "all"
+
+// reportTypeCustomRange is the ReportQuery type of /report_by_date reports
+const reportTypeCustomRange = "custom_range"
+
+// reportHistoryLimit is how many past reports /report_history shows
+const reportHistoryLimit = 10
+
+// reportHistory lists the reports the user generated most recently
+func (h *WhatsAppHandler) reportHistory(user *models.User) string {
+	lang := userLanguage(user)
+	queries, err := h.orderService.GetReportHistory(user.ID, reportHistoryLimit)
+	if err != nil {
+		return t(lang, "report_history_failed", err.Error())
+	}
+	if len(queries) == 0 {
+		return t(lang, "report_history_empty")
+	}
+
+	response := t(lang, "report_history_header")
+	for _, query := range queries {
+		response += fmt.Sprintf("[%s] %s", query.GeneratedAt.Format("2006-01-02 15:04"), query.QueryType)
+		if query.StartDate != nil && query.EndDate != nil {
+			response += t(lang, "report_history_period", query.StartDate.Format("2006-01-02"), query.EndDate.Format("2006-01-02"))
+		}
+		response += "\n"
+
+		var data struct {
+			TotalOrders int     `json:"total_orders"`
+			TotalAmount float64 `json:"total_amount"`
+		}
+		if err := json.Unmarshal([]byte(query.ReportData), &data); err == nil {
+			response += t(lang, "report_history_line", data.TotalOrders, h.formatCurrency(data.TotalAmount))
+		}
+		response += "\n"
+	}
+
+	return strings.TrimRight(response, "\n")
+}
+
+// Admin command implementations
+func (h *WhatsAppHandler) addUser(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 4 {
+		return t(lang, "add_user_usage")
+	}
+
+	if !models.IsValidRole(args[3]) {
+		return t(lang, "invalid_role_named", args[3])
+	}
+
+	phone := whatsapp.NormalizePhone(args[2])
+	newUser := &models.User{
+		Username:       args[0],
+		Email:          args[1],
+		PhoneNumber:    phone,
+		Role:           models.NormalizeRole(args[3]),
+		WhatsAppNumber: phone,
+		IsActive:       true,
+	}
+
+	err := h.userService.CreateUser(newUser, "default_password")
+	if err != nil {
+		return t(lang, "create_user_failed", err.Error())
+	}
+
+	return t(lang, "user_created")
+}
+
+func (h *WhatsAppHandler) listUsers(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	page := parsePage(args)
+	users, total, err := h.userService.GetAllUsersPaginated((page-1)*listPageSize, listPageSize)
+	if err != nil {
+		return t(lang, "users_failed", err.Error())
+	}
+
+	if total == 0 {
+		return t(lang, "users_empty")
+	}
+
+	pages := totalPages(total, listPageSize)
+	if page > pages {
+		return t(lang, "page_not_found", page, pages)
+	}
+
+	response := t(lang, "users_header")
+	for _, u := range users {
+		status := t(lang, "user_status_inactive")
+		if u.IsActive {
+			status = t(lang, "user_status_active")
+		}
+		response += fmt.Sprintf("**ID: %d** - **%s** (%s)\n", u.ID, u.Username, u.Email)
+		response += t(lang, "user_line", u.PhoneNumber, u.Role, status)
+		response += "\n"
+	}
+	response += pageFooter(lang, "/list_users", page, pages)
+
+	return response
+}
+
+func (h *WhatsAppHandler) listAllTasks(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	page := parsePage(args)
+	size := h.listMaxItems()
+	tasks, total, err := h.taskService.GetAllTasksPaginated((page-1)*size, size)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+
+	if total == 0 {
+		return t(lang, "all_tasks_empty")
+	}
+
+	pages := totalPages(total, size)
+	if page > pages {
+		return t(lang, "page_not_found", page, pages)
+	}
+
+	assigneeIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		assigneeIDs = append(assigneeIDs, task.AssignedTo)
+	}
+	usernames := h.usernamesByID(assigneeIDs)
+
+	now := h.clock.Now()
+	response := t(lang, "all_tasks_header")
+	for _, task := range tasks {
+		status := t(lang, "task_status_pending_marked")
+		if task.Status == string(models.InProgress) {
+			status = t(lang, "task_status_in_progress")
+		} else if task.Status == string(models.Completed) {
+			status = t(lang, "task_status_completed")
+		} else if task.Status == string(models.Overdue) {
+			status = t(lang, "task_status_overdue")
+		}
+		if task.Status != string(models.Overdue) && task.IsOverdue(now) {
+			status += t(lang, "task_overdue_suffix")
+		}
+
+		priority := t(lang, "priority_medium")
+		if task.Priority == string(models.High) {
+			priority = t(lang, "priority_high")
+		} else if task.Priority == string(models.Low) {
+			priority = t(lang, "priority_low")
+		} else if task.Priority == string(models.Urgent) {
+			priority = t(lang, "priority_urgent")
+		}
+
+		implemented := t(lang, "task_not_implemented")
+		if task.IsImplemented {
+			implemented = t(lang, "task_is_implemented")
+		}
+
+		response += fmt.Sprintf("**ID: %d** - **%s**\n", task.ID, task.Title)
+		response += t(lang, "task_line_description", task.Description)
+		response += t(lang, "task_line_assigned_to", usernames[task.AssignedTo])
+		response += t(lang, "task_line_status", status)
+		response += t(lang, "task_line_priority", priority)
+		response += t(lang, "task_line_progress", task.CompletionPercentage)
+		response += t(lang, "task_line_implemented_label", implemented)
+		if task.DueDate != nil {
+			response += t(lang, "task_line_due_date", task.DueDate.Format("2006-01-02 15:04"))
+		}
+		if task.CompletedAt != nil {
+			response += t(lang, "task_line_completed", task.CompletedAt.Format("2006-01-02 15:04"))
+		}
+		response += "\n"
+	}
+	if hidden := int(total) - page*size; hidden > 0 {
+		response += moreItemsNotice(lang, hidden) + "\n"
+	}
+	response += pageFooter(lang, "/list_tasks", page, pages)
+
+	return response
+}
+
+func (h *WhatsAppHandler) createOrder(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	order := &models.Order{}
+	args, errMsg := commandRateOverrides(lang, args, order)
+	if errMsg != "" {
+		return errMsg
+	}
+	if len(args) < 2 {
+		return t(lang, "create_order_usage")
+	}
+
+	totalAmount, err := currency.Parse(args[1])
+	if err != nil {
+		return t(lang, "invalid_total")
+	}
+
+	var customerPhone string
+	if len(args) > 2 {
+		customerPhone = normalizeCustomerPhone(args[2])
+	}
+
+	order.CustomerName = args[0]
+	order.CustomerPhone = customerPhone
+	order.TotalAmount = totalAmount
+	order.Status = string(models.OrderPending)
+	order.OrderDate = h.clock.Now()
+	order.CreatedBy = user.ID
+
+	err = h.orderService.CreateOrder(order)
+	if err != nil {
+		return t(lang, "order_create_failed", err.Error())
+	}
+	h.recordOrderCreated(user.ID, order)
+
+	return t(lang, "order_created", order.OrderNumber, order.CustomerName,
+		h.formatCurrency(order.TotalAmount)) + rateOverrideSummary(lang, order)
+}
+
+func (h *WhatsAppHandler) getAllOrders(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	page := parsePage(args)
+	size := h.listMaxItems()
+	orders, total, err := h.orderService.GetAllOrdersPaginated((page-1)*size, size)
+	if err != nil {
+		return t(lang, "orders_failed", err.Error())
+	}
+
+	if total == 0 {
+		return t(lang, "orders_empty")
+	}
+
+	pages := totalPages(total, size)
+	if page > pages {
+		return t(lang, "page_not_found", page, pages)
+	}
+
+	response := t(lang, "all_orders_header") + "\n\n"
+	for _, order := range orders {
+		response += fmt.Sprintf("**Order #%s**\n", order.OrderNumber)
+		response += t(lang, "order_line_customer", order.CustomerName)
+		response += t(lang, "order_line_total", h.formatCurrency(order.TotalAmount))
+		response += t(lang, "order_line_status", order.Status)
+		response += t(lang, "order_detail_date", order.OrderDate.Format("2006-01-02"))
+		response += "\n"
+	}
+	if hidden := int(total) - page*size; hidden > 0 {
+		response += moreItemsNotice(lang, hidden) + "\n"
+	}
+	response += pageFooter(lang, "/all_orders", page, pages)
+
+	return response
+}
+
+// sendInvoice renders an order invoice as PDF and sends it to the caller
+func (h *WhatsAppHandler) sendInvoice(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 1 {
+		return t(lang, "invoice_usage")
+	}
+
+	orderID, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return t(lang, "invalid_order_id")
+	}
+
+	order, err := h.orderService.GetOrderByID(uint(orderID))
+	if err != nil {
+		return t(lang, "order_not_found", orderID)
+	}
+
+	if !h.authorize(user, models.Admin, models.SuperAdmin) && order.CreatedBy != user.ID {
+		return t(lang, "order_access_denied")
+	}
+
+	pdfData, err := h.orderService.GenerateInvoicePDF(order.ID)
+	if err != nil {
+		return t(lang, "invoice_failed", err.Error())
+	}
+
+	filename := fmt.Sprintf("invoice-%s.pdf", order.OrderNumber)
+	caption := t(lang, "invoice_caption", order.OrderNumber, order.CustomerName)
+	if err := h.whatsappService.SendDocument(user.WhatsAppNumber, filename, pdfData, caption); err != nil {
+		return t(lang, "invoice_send_failed", err.Error())
+	}
+
+	return t(lang, "invoice_sent", order.OrderNumber)
+}
+
+// exportTasks sends all tasks to the requesting Super Admin as a CSV file
+func (h *WhatsAppHandler) exportTasks(user *models.User) string {
+	lang := userLanguage(user)
+	var buf bytes.Buffer
+	if err := writeTasksCSV(&buf, h.userService, h.taskService); err != nil {
+		return t(lang, "export_failed", err.Error())
+	}
+
+	filename := fmt.Sprintf("tasks-%s.csv", h.clock.Now().Format("20060102"))
+	if err := h.whatsappService.SendDocument(user.WhatsAppNumber, filename, buf.Bytes(), t(lang, "export_caption")); err != nil {
+		return t(lang, "export_send_failed", err.Error())
+	}
+
+	return t(lang, "export_sent")
+}
+
+// listPageSize is the number of records shown per page in list commands
+const listPageSize = 10
+
+// listMaxItems is how many items a single listing shows inline
+func (h *WhatsAppHandler) listMaxItems() int {
+	if h.cfg != nil && h.cfg.ListMaxItems > 0 {
+		return h.cfg.ListMaxItems
+	}
+	return listPageSize
+}
+
+// moreItemsNotice tells the user how many items did not fit in a listing
+func moreItemsNotice(lang string, hidden int) string {
+	return t(lang, "more_items", hidden)
+}
+
+// parsePage reads an optional 1-based page number from the first argument
+func parsePage(args []string) int {
+	if len(args) > 0 {
+		if page, err := strconv.Atoi(args[0]); err == nil && page > 0 {
+			return page
+		}
+	}
+	return 1
+}
+
+// totalPages returns how many pages of size are needed for total records
+func totalPages(total int64, size int) int {
+	if total <= 0 {
+		return 1
+	}
+	return int((total + int64(size) - 1) / int64(size))
+}
+
+// pageFooter renders "Page x/y" and, when there is one, how to get the next page
+func pageFooter(lang, command string, page, pages int) string {
+	if page < pages {
+		return t(lang, "page_footer_next", page, pages, command, page+1)
+	}
+	return t(lang, "page_footer", page, pages)
+}
+
+func (h *WhatsAppHandler) assignTask(assigner *models.User, args []string) string {
+	lang := userLanguage(assigner)
+	// Optional trailing "due:YYYY-MM-DD" and "priority:<level>" tokens, in
+	// either order
+	var dueDate *time.Time
+	priority := string(models.Medium)
+	for len(args) > 0 {
+		last := args[len(args)-1]
+		lower := strings.ToLower(last)
+		if strings.HasPrefix(lower, "due:") {
+			parsed, err := parseDueDate(last[len("due:"):], h.userNow(assigner))
+			if err != nil {
+				return t(lang, "invalid_due_date", err.Error())
+			}
+			dueDate = parsed
+		} else if strings.HasPrefix(lower, "priority:") {
+			parsed, err := parseTaskPriority(last[len("priority:"):])
 			if err != nil {
-				return "❌ User not found: " + args[0]
+				return t(lang, "error", err.Error())
 			}
-			assignedTo = user.ID
+			priority = parsed
+		} else {
+			break
 		}
+		args = args[:len(args)-1]
+	}
+
+	if len(args) < 3 {
+		return t(lang, "assign_task_usage")
+	}
+
+	assignee, err := h.resolveAssignee(args[0])
+	if err != nil {
+		return assigneeError(lang, args[0], err)
+	}
+	assignedTo := assignee.ID
 
 		// Join all args after title as description
 		description := strings.Join(args[2:], " ")
@@ -1166,37 +3284,153 @@ func (h *WhatsAppHandler) assignTask(userID uint, args []string) string {
 			Title:       args[1],
 			Description: description,
 			AssignedTo:  uint(assignedTo),
+		DueDate:     dueDate,
 		Status:      string(models.Pending),
-		Priority:    string(models.Medium),
+		Priority:    priority,
 		TaskType:    string(models.Custom),
-		CreatedBy:   userID,
+		CreatedBy:   assigner.ID,
+	}
+
+	err = h.taskService.CreateTask(task)
+	if err != nil {
+		return t(lang, "task_create_failed", err.Error())
+	}
+
+	return t(lang, "task_assigned", task.ID)
+}
+
+// assignTaskBulk gives the same task to several users at once, e.g.
+// "/assign_task_bulk Stock opname | Count the warehouse | andi,budi,citra".
+// Unknown or inactive users are skipped and reported; the tasks for everyone
+// else are created together or not at all
+func (h *WhatsAppHandler) assignTaskBulk(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	fields := strings.Split(strings.Join(args, " "), "|")
+	if len(fields) != 3 || strings.TrimSpace(fields[0]) == "" || strings.TrimSpace(fields[2]) == "" {
+		return t(lang, "assign_task_bulk_usage")
+	}
+	title := strings.TrimSpace(fields[0])
+	description := strings.TrimSpace(fields[1])
+
+	var tasks []*models.Task
+	var assigned, skipped []string
+	seen := make(map[uint]bool)
+	for _, identifier := range strings.Split(fields[2], ",") {
+		identifier = strings.TrimSpace(identifier)
+		if identifier == "" {
+			continue
+		}
+		assignee, err := h.resolveAssignee(identifier)
+		if err != nil {
+			skipped = append(skipped, skippedAssignee(lang, identifier, err))
+			continue
+		}
+		if seen[assignee.ID] {
+			continue
+		}
+		seen[assignee.ID] = true
+
+		tasks = append(tasks, &models.Task{
+			Title:       title,
+			Description: description,
+			AssignedTo:  assignee.ID,
+			Status:      string(models.Pending),
+			Priority:    string(models.Medium),
+			TaskType:    string(models.Custom),
+			CreatedBy:   user.ID,
+		})
+		assigned = append(assigned, assignee.Username)
+	}
+
+	if len(tasks) == 0 {
+		return t(lang, "bulk_none_assigned", strings.Join(skipped, "\n- "))
+	}
+
+	if err := h.taskService.CreateTasksBulk(tasks); err != nil {
+		return t(lang, "bulk_create_failed", err.Error())
+	}
+
+	response := t(lang, "bulk_assigned", title, len(tasks), strings.Join(assigned, ", "))
+	if len(skipped) > 0 {
+		response += t(lang, "bulk_skipped", strings.Join(skipped, "\n- "))
+	}
+	return response
+}
+
+func (h *WhatsAppHandler) createDailyTask(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 3 {
+		return t(lang, "create_daily_task_usage")
+	}
+
+	assignee, err := h.resolveAssignee(args[0])
+	if err != nil {
+		return assigneeError(lang, args[0], err)
+	}
+	assignedTo := assignee.ID
+
+		// Join all args after title as description
+		description := strings.Join(args[2:], " ")
+		
+		task := &models.Task{
+			Title:       args[1],
+			Description: description,
+			AssignedTo:  uint(assignedTo),
+		Status:      string(models.Pending),
+		Priority:    string(models.Medium),
+		CreatedBy:   user.ID,
 	}
 
-	err := h.taskService.CreateTask(task)
+	err = h.taskService.CreateDailyTask(task)
 	if err != nil {
-		return "❌ Failed to create task: " + err.Error()
+		return t(lang, "create_daily_task_failed", err.Error())
 	}
 
-	return "✅ Task assigned successfully"
+	return t(lang, "daily_task_created")
 }
 
-func (h *WhatsAppHandler) createDailyTask(userID uint, args []string) string {
+func (h *WhatsAppHandler) createWeeklyTask(user *models.User, args []string) string {
 	if len(args) < 3 {
-		return "❌ Usage: /create_daily_task [username_or_id] [title] [description]"
+		return t(userLanguage(user), "create_weekly_task_usage")
+	}
+	return h.saveWeeklyTask(user, args[0], args[1], strings.Join(args[2:], " "))
+}
+
+// saveWeeklyTask creates a weekly recurring task for the user named by assigneeArg
+func (h *WhatsAppHandler) saveWeeklyTask(user *models.User, assigneeArg, title, description string) string {
+	lang := userLanguage(user)
+	assignee, err := h.resolveAssignee(assigneeArg)
+	if err != nil {
+		return assigneeError(lang, assigneeArg, err)
 	}
 
-	// Try to parse as user ID first
-	var assignedTo uint
-	if userID, err := strconv.ParseUint(args[0], 10, 32); err == nil {
-		assignedTo = uint(userID)
-	} else {
-		// If not a number, treat as username
-		user, err := h.userService.GetUserByUsername(args[0])
-			if err != nil {
-				return "❌ User not found: " + args[0]
-			}
-			assignedTo = user.ID
-		}
+	task := &models.Task{
+		Title:       title,
+		Description: description,
+		AssignedTo:  assignee.ID,
+		Status:      string(models.Pending),
+		Priority:    string(models.Medium),
+		CreatedBy:   user.ID,
+	}
+
+	if err := h.taskService.CreateWeeklyTask(task); err != nil {
+		return t(lang, "create_weekly_task_failed", err.Error())
+	}
+
+	return t(lang, "weekly_task_created", task.Title, assignee.Username)
+}
+
+func (h *WhatsAppHandler) createMonthlyTask(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 3 {
+		return t(lang, "create_monthly_task_usage")
+	}
+
+	assignee, err := h.resolveAssignee(args[0])
+	if err != nil {
+		return assigneeError(lang, args[0], err)
+	}
+	assignedTo := assignee.ID
 
 		// Join all args after title as description
 		description := strings.Join(args[2:], " ")
@@ -1207,252 +3441,694 @@ func (h *WhatsAppHandler) createDailyTask(userID uint, args []string) string {
 			AssignedTo:  uint(assignedTo),
 		Status:      string(models.Pending),
 		Priority:    string(models.Medium),
-		CreatedBy:   userID,
+		CreatedBy:   user.ID,
 	}
 
-	err := h.taskService.CreateDailyTask(task)
+	err = h.taskService.CreateMonthlyTask(task)
 	if err != nil {
-		return "❌ Failed to create daily task: " + err.Error()
+		return t(lang, "create_monthly_task_failed", err.Error())
 	}
 
-	return "✅ Daily task created successfully"
+	return t(lang, "monthly_task_created")
 }
 
-func (h *WhatsAppHandler) createMonthlyTask(userID uint, args []string) string {
-	if len(args) < 3 {
-		return "❌ Usage: /create_monthly_task [username_or_id] [title] [description]"
+// usernamesByID maps each of ids to its username in a single lookup. Users that
+// no longer exist, or all of them if the lookup fails, show as "User #<id>"
+func (h *WhatsAppHandler) usernamesByID(ids []uint) map[uint]string {
+	names := make(map[uint]string, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, seen := names[id]; !seen {
+			names[id] = fmt.Sprintf("User #%d", id)
+			unique = append(unique, id)
+		}
 	}
 
-	// Try to parse as user ID first
-	var assignedTo uint
-	if userID, err := strconv.ParseUint(args[0], 10, 32); err == nil {
-		assignedTo = uint(userID)
-	} else {
-		// If not a number, treat as username
-		user, err := h.userService.GetUserByUsername(args[0])
-			if err != nil {
-				return "❌ User not found: " + args[0]
-			}
-			assignedTo = user.ID
-		}
+	users, err := h.userService.GetUsersByIDs(unique)
+	if err != nil {
+		h.logger.Error("Failed to look up users", "user_ids", unique, "error", err)
+		return names
+	}
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names
+}
 
-		// Join all args after title as description
-		description := strings.Join(args[2:], " ")
-		
-		task := &models.Task{
-			Title:       args[1],
-			Description: description,
-			AssignedTo:  uint(assignedTo),
-		Status:      string(models.Pending),
-		Priority:    string(models.Medium),
-		CreatedBy:   userID,
+// resolveUser looks a user up by numeric ID or, failing that, by username
+func (h *WhatsAppHandler) resolveUser(identifier string) (*models.User, error) {
+	return findUser(h.userService, identifier)
+}
+
+// resolveAssignee resolves a task assignee and rejects deactivated users,
+// who would never see the task
+func (h *WhatsAppHandler) resolveAssignee(identifier string) (*models.User, error) {
+	return findAssignee(h.userService, identifier)
+}
+
+func findUser(userService services.UserService, identifier string) (*models.User, error) {
+	if id, err := strconv.ParseUint(identifier, 10, 32); err == nil {
+		return userService.GetUserByID(uint(id))
 	}
+	return userService.GetUserByUsername(identifier)
+}
+
+// Errors returned by findAssignee
+var (
+	errAssigneeNotFound = errors.New("user not found")
+	errAssigneeInactive = errors.New("user is inactive")
+)
+
+// inactiveAssigneeError names the deactivated user findAssignee rejected. It
+// matches errAssigneeInactive
+type inactiveAssigneeError struct {
+	username string
+}
+
+func (e *inactiveAssigneeError) Error() string {
+	return fmt.Sprintf("%s: %s", errAssigneeInactive, e.username)
+}
 
-	err := h.taskService.CreateMonthlyTask(task)
+func (e *inactiveAssigneeError) Is(target error) bool {
+	return target == errAssigneeInactive
+}
+
+func findAssignee(userService services.UserService, identifier string) (*models.User, error) {
+	assignee, err := findUser(userService, identifier)
 	if err != nil {
-		return "❌ Failed to create monthly task: " + err.Error()
+		return nil, fmt.Errorf("%w: %s", errAssigneeNotFound, identifier)
+	}
+	if !assignee.IsActive {
+		return nil, &inactiveAssigneeError{username: assignee.Username}
+	}
+	return assignee, nil
+}
+
+// assigneeError is the reply for a findAssignee error about identifier
+func assigneeError(lang, identifier string, err error) string {
+	var inactive *inactiveAssigneeError
+	if errors.As(err, &inactive) {
+		return t(lang, "assignee_inactive", inactive.username)
+	}
+	return t(lang, "user_not_found_named", identifier)
+}
+
+// skippedAssignee lists identifier among the users a bulk assignment skipped
+func skippedAssignee(lang, identifier string, err error) string {
+	if errors.Is(err, errAssigneeInactive) {
+		return t(lang, "assignee_skipped_inactive", identifier)
+	}
+	return t(lang, "assignee_skipped_not_found", identifier)
+}
+
+// isLastSuperAdmin reports whether target is the only remaining Super Admin
+func (h *WhatsAppHandler) isLastSuperAdmin(target *models.User) (bool, error) {
+	if models.NormalizeRole(target.Role) != string(models.SuperAdmin) {
+		return false, nil
 	}
 
-	return "✅ Monthly task created successfully"
+	count, err := h.userService.CountUsersByRole(string(models.SuperAdmin))
+	if err != nil {
+		return false, err
+	}
+	return count <= 1, nil
 }
 
-func (h *WhatsAppHandler) setTaxRate(userID uint, args []string) string {
+func (h *WhatsAppHandler) deleteUser(user *models.User, args []string) string {
+	lang := userLanguage(user)
 	if len(args) < 1 {
-		return "❌ Usage: /set_tax_rate [percentage]"
+		return t(lang, "delete_user_usage")
+	}
+
+	target, err := h.resolveUser(args[0])
+	if err != nil {
+		return t(lang, "user_not_found_named", args[0])
+	}
+
+	if target.ID == user.ID {
+		return t(lang, "delete_self")
 	}
 
-	percentage, err := strconv.ParseFloat(args[0], 64)
+	lastSuperAdmin, err := h.isLastSuperAdmin(target)
 	if err != nil {
-		return "❌ Invalid percentage"
+		return t(lang, "super_admin_check_failed", err.Error())
+	}
+	if lastSuperAdmin {
+		return t(lang, "delete_last_super_admin")
 	}
 
-	// Implementation for setting tax rate
-	return fmt.Sprintf("✅ Tax rate set to %.2f%%", percentage)
+	if err := h.userService.DeleteUser(target.ID); err != nil {
+		return t(lang, "delete_user_failed", err.Error())
+	}
+
+	h.logger.Info("audit: user deleted", "user_id", user.ID, "username", user.Username, "target_id", target.ID, "target", target.Username)
+	return t(lang, "user_deleted", target.Username, target.ID)
 }
 
-func (h *WhatsAppHandler) setMarketingRate(userID uint, args []string) string {
+// resetPassword sets a new random password for a user and sends it to their
+// WhatsApp number, so it never shows up in the admin's chat
+func (h *WhatsAppHandler) resetPassword(user *models.User, args []string) string {
+	lang := userLanguage(user)
 	if len(args) < 1 {
-		return "❌ Usage: /set_marketing_rate [percentage]"
+		return t(lang, "reset_password_usage")
+	}
+
+	target, err := h.resolveUser(args[0])
+	if err != nil {
+		return t(lang, "user_not_found_named", args[0])
+	}
+	if target.WhatsAppNumber == "" {
+		return t(lang, "reset_password_no_whatsapp", target.Username)
+	}
+
+	password, err := h.userService.ResetPassword(target.ID)
+	if err != nil {
+		return t(lang, "reset_password_failed", err.Error())
+	}
+	h.logger.Info("audit: password reset", "user_id", user.ID, "username", user.Username, "target_id", target.ID, "target", target.Username)
+
+	message := t(userLanguage(target), "password_reset_message", target.Username, password)
+	if err := h.whatsappService.SendMessage(target.WhatsAppNumber, message); err != nil {
+		h.logger.Error("Failed to send new password", "target_id", target.ID, "error", err)
+		return t(lang, "password_not_delivered", target.Username)
+	}
+
+	return t(lang, "password_sent", target.Username)
+}
+
+// updateUser edits a user's email, phone or WhatsApp number
+func (h *WhatsAppHandler) updateUser(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 3 {
+		return t(lang, "update_user_usage")
+	}
+
+	target, err := h.resolveUser(args[0])
+	if err != nil {
+		return t(lang, "user_not_found_named", args[0])
+	}
+
+	field := strings.ToLower(args[1])
+	value := strings.TrimSpace(strings.Join(args[2:], " "))
+
+	var before, after string
+	switch field {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return t(lang, "invalid_email", value)
+		}
+		before, after = target.Email, value
+		target.Email = value
+	case "phone":
+		phone, err := normalizePhoneInput(value)
+		if err != nil {
+			return t(lang, "error", err.Error())
+		}
+		// The phone number doubles as the WhatsApp number, keep them in sync
+		before = fmt.Sprintf("%s (WhatsApp: %s)", target.PhoneNumber, target.WhatsAppNumber)
+		after = fmt.Sprintf("%s (WhatsApp: %s)", phone, phone)
+		target.PhoneNumber = phone
+		target.WhatsAppNumber = phone
+	case "whatsapp":
+		phone, err := normalizePhoneInput(value)
+		if err != nil {
+			return t(lang, "error", err.Error())
+		}
+		before, after = target.WhatsAppNumber, phone
+		target.WhatsAppNumber = phone
+	default:
+		return t(lang, "unknown_user_field", args[1])
+	}
+
+	if err := h.userService.UpdateUser(target); err != nil {
+		return t(lang, "update_user_failed", err.Error())
+	}
+
+	h.logger.Info("audit: user updated", "user_id", user.ID, "username", user.Username,
+		"target_id", target.ID, "target", target.Username, "field", field, "before", before, "after", after)
+	return t(lang, "user_updated", field, target.Username, before, after)
+}
+
+// normalizePhoneInput brings a typed phone number into canonical form and
+// checks that only digits remain
+func normalizePhoneInput(raw string) (string, error) {
+	phone := whatsapp.NormalizePhone(raw)
+
+	if len(phone) < 9 || len(phone) > 15 {
+		return "", fmt.Errorf("invalid phone number: %s", raw)
+	}
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("invalid phone number: %s", raw)
+		}
+	}
+	return phone, nil
+}
+
+func (h *WhatsAppHandler) setRole(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 2 {
+		return t(lang, "set_role_usage")
+	}
+
+	if !models.IsValidRole(args[1]) {
+		return t(lang, "invalid_role_named", args[1])
+	}
+	newRole := models.NormalizeRole(args[1])
+
+	target, err := h.resolveUser(args[0])
+	if err != nil {
+		return t(lang, "user_not_found_named", args[0])
+	}
+
+	oldRole := target.Role
+	if models.NormalizeRole(oldRole) == newRole {
+		return t(lang, "role_unchanged", target.Username, newRole)
+	}
+
+	if newRole != string(models.SuperAdmin) {
+		lastSuperAdmin, err := h.isLastSuperAdmin(target)
+		if err != nil {
+			return t(lang, "super_admin_check_failed", err.Error())
+		}
+		if lastSuperAdmin {
+			return t(lang, "demote_last_super_admin")
+		}
+	}
+
+	target.Role = newRole
+	if err := h.userService.UpdateUser(target); err != nil {
+		return t(lang, "update_role_failed", err.Error())
+	}
+
+	h.logger.Info("audit: role changed", "user_id", user.ID, "username", user.Username,
+		"target_id", target.ID, "target", target.Username, "from", oldRole, "to", newRole)
+	return t(lang, "role_changed", target.Username, oldRole, newRole)
+}
+
+func (h *WhatsAppHandler) transferTasks(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) < 2 {
+		return t(lang, "transfer_tasks_usage")
 	}
 
-	percentage, err := strconv.ParseFloat(args[0], 64)
+	fromUser, err := h.resolveUser(args[0])
 	if err != nil {
-		return "❌ Invalid percentage"
+		return t(lang, "user_not_found_named", args[0])
 	}
 
-	// Implementation for setting marketing rate
-	return fmt.Sprintf("✅ Marketing rate set to %.2f%%", percentage)
+	toUser, err := h.resolveAssignee(args[1])
+	if err != nil {
+		return assigneeError(lang, args[1], err)
+	}
+
+	if fromUser.ID == toUser.ID {
+		return t(lang, "transfer_same_user")
+	}
+
+	count, err := h.taskService.TransferTasks(fromUser.ID, toUser.ID)
+	if err != nil {
+		return t(lang, "transfer_failed", err.Error())
+	}
+
+	h.logger.Info("audit: tasks transferred", "user_id", user.ID, "username", user.Username, "count", count,
+		"from_id", fromUser.ID, "from", fromUser.Username, "to_id", toUser.ID, "to", toUser.Username)
+
+	if count > 0 {
+		notification := t(userLanguage(toUser), "tasks_transferred_notice", count, fromUser.Username, user.Username)
+		if err := h.whatsappService.SendMessage(toUser.WhatsAppNumber, notification); err != nil {
+			h.logger.Error("Failed to notify about transferred tasks", "target", toUser.Username, "error", err)
+		}
+	}
+
+	return t(lang, "tasks_transferred", count, fromUser.Username, toUser.Username)
 }
 
-func (h *WhatsAppHandler) setRentalRate(userID uint, args []string) string {
+func (h *WhatsAppHandler) cloneTaskCommand(user *models.User, args []string) string {
+	lang := userLanguage(user)
 	if len(args) < 1 {
-		return "❌ Usage: /set_rental_rate [percentage]"
+		return t(lang, "clone_task_usage")
 	}
 
-	percentage, err := strconv.ParseFloat(args[0], 64)
+	taskID, err := strconv.ParseUint(args[0], 10, 32)
 	if err != nil {
-		return "❌ Invalid percentage"
+		return t(lang, "invalid_task_id")
+	}
+
+	assignee := ""
+	if len(args) > 1 {
+		assignee = args[1]
 	}
 
-	// Implementation for setting rental rate
-	return fmt.Sprintf("✅ Rental rate set to %.2f%%", percentage)
+	return h.cloneTask(user, uint(taskID), assignee)
 }
 
-func (h *WhatsAppHandler) generateReport() string {
-	return "📊 **Financial Report:**\n\nThis feature will show comprehensive financial summary."
+// cloneTask copies title, description, priority and type of an existing task
+// into a fresh pending task, assigned to the same user unless assignee is set
+func (h *WhatsAppHandler) cloneTask(user *models.User, taskID uint, assignee string) string {
+	lang := userLanguage(user)
+	source, err := h.taskService.GetTaskByID(taskID)
+	if err != nil {
+		return t(lang, "task_not_found", taskID)
+	}
+
+	assignedTo := source.AssignedTo
+	if assignee != "" {
+		assignedUser, err := h.resolveAssignee(assignee)
+		if err != nil {
+			return assigneeError(lang, assignee, err)
+		}
+		assignedTo = assignedUser.ID
+	}
+
+	clone := &models.Task{
+		Title:            source.Title,
+		Description:      source.Description,
+		AssignedTo:       assignedTo,
+		Status:           string(models.Pending),
+		Priority:         source.Priority,
+		TaskType:         source.TaskType,
+		IsRecurring:      source.IsRecurring,
+		RecurringPattern: source.RecurringPattern,
+		CreatedBy:        user.ID,
+	}
+
+	if err := h.taskService.CreateTask(clone); err != nil {
+		return t(lang, "clone_task_failed", err.Error())
+	}
+
+	return t(lang, "task_cloned", source.ID, clone.ID, clone.Title, clone.AssignedTo)
 }
 
-func (h *WhatsAppHandler) generateDailyReport() string {
-	return "📊 **Daily Report:**\n\nThis feature will show today's financial summary."
+// runCleanup removes history rows past their retention period on demand
+func (h *WhatsAppHandler) runCleanup(user *models.User) string {
+	lang := userLanguage(user)
+	result, err := h.cleanupService.Run(h.clock.Now())
+	if err != nil {
+		return t(lang, "cleanup_failed", err.Error())
+	}
+
+	h.logger.Info("audit: cleanup run", "user_id", user.ID, "username", user.Username,
+		"task_progress", result.TaskProgress, "calculation_history", result.CalculationHistory, "report_queries", result.ReportQueries)
+	return t(lang, "cleanup_done", result.TaskProgress, result.CalculationHistory, result.ReportQueries)
 }
 
-func (h *WhatsAppHandler) generateMonthlyReport() string {
-	return "📊 **Monthly Report:**\n\nThis feature will show this month's financial summary."
+func (h *WhatsAppHandler) manageFeatures(user *models.User, args []string) string {
+	lang := userLanguage(user)
+	if len(args) >= 2 {
+		var enabled bool
+		switch strings.ToLower(args[1]) {
+		case "on", "true", "enable", "1":
+			enabled = true
+		case "off", "false", "disable", "0":
+			enabled = false
+		default:
+			return t(lang, "features_usage")
+		}
+
+		if err := features.Set(args[0], enabled); err != nil {
+			return t(lang, "feature_update_failed", err.Error())
+		}
+		h.logger.Info("audit: feature set", "user_id", user.ID, "username", user.Username, "feature", args[0], "enabled", enabled)
+	} else if len(args) == 1 {
+		return t(lang, "features_usage")
+	}
+
+	flags := features.All()
+	response := t(lang, "features_header")
+	for _, name := range features.Names() {
+		status := t(lang, "feature_off")
+		if flags[name] {
+			status = t(lang, "feature_on")
+		}
+		response += fmt.Sprintf("%s: %s\n", name, status)
+	}
+	response += t(lang, "features_hint")
+
+	return response
 }
 
 // handleAIListUsers handles AI-detected list users requests
 func (h *WhatsAppHandler) handleAIListUsers(user *models.User, aiResponse *AIResponse) string {
+	return h.listUsers(user, aiPageArgs(aiResponse))
+}
+
+// aiPageArgs turns an optional "page" field from AI data into command args
+func aiPageArgs(aiResponse *AIResponse) []string {
+	if page := int(dataFloat(aiResponse.Data, "page")); page > 0 {
+		return []string{strconv.Itoa(page)}
+	}
+	return nil
+}
+
+// handleAICreateOrderWithItem handles AI-detected create order with item requests
+func (h *WhatsAppHandler) handleAICreateOrderWithItem(user *models.User, aiResponse *AIResponse) string {
 	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk melihat daftar user. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
+	// Extract data from AI response
+	customerName, _ := aiResponse.Data["customer_name"].(string)
+	totalAmountFloat := dataFloat(aiResponse.Data, "total_amount")
+	itemName, _ := aiResponse.Data["item_name"].(string)
+	quantity := int(dataFloat(aiResponse.Data, "quantity"))
+	price := dataFloat(aiResponse.Data, "price")
+	description, _ := aiResponse.Data["description"].(string)
+	customerPhone, _ := aiResponse.Data["customer_phone"].(string)
+	
+	lang := userLanguage(user)
+	// Validate required fields
+	if customerName == "" || itemName == "" || quantity <= 0 || price <= 0 {
+		return missingFields(lang, "customer_name", "item_name", "quantity", "price")
 	}
 	
-	// Get all users
-	users, err := h.userService.GetAllUsers()
-	if err != nil {
-		return fmt.Sprintf("❌ Gagal mengambil daftar user: %s", err.Error())
+	deliveryDate, errMsg := aiDeliveryDate(lang, aiResponse, h.userNow(user))
+	if errMsg != "" {
+		return errMsg
 	}
 	
-	if len(users) == 0 {
-		return "👥 Tidak ada user yang ditemukan."
+	lineTotal := float64(quantity) * price
+	if totalAmountFloat == 0 {
+		totalAmountFloat = lineTotal
 	}
 	
-	response := "👥 **Daftar User:**\n\n"
-	for _, u := range users {
-		status := "❌ Inactive"
-		if u.IsActive {
-			status = "✅ Active"
-		}
-		
-		response += fmt.Sprintf("**ID: %d** - **%s**\n", u.ID, u.Username)
-		response += fmt.Sprintf("📧 Email: %s\n", u.Email)
-		response += fmt.Sprintf("📱 Phone: %s\n", u.PhoneNumber)
-		response += fmt.Sprintf("🔑 Role: %s\n", u.Role)
-		response += fmt.Sprintf("Status: %s\n", status)
-		response += "\n"
+	// Create the order and its item together
+	order := &models.Order{
+		CustomerName:  customerName,
+		CustomerPhone: normalizeCustomerPhone(customerPhone),
+		DeliveryDate:  deliveryDate,
+		TotalAmount:   totalAmountFloat,
+		Status:       string(models.OrderPending),
+		OrderDate:    h.clock.Now(),
+		CreatedBy:    user.ID,
+	}
+	items := []models.OrderItem{{
+		ItemName:    itemName,
+		Quantity:    quantity,
+		UnitPrice:   price,
+		Description: description,
+	}}
+	if errMsg := aiRateOverrides(lang, aiResponse, order); errMsg != "" {
+		return errMsg
+	}
+	
+	if err := h.orderService.CreateOrderWithItems(order, items); err != nil {
+		return t(lang, "order_with_item_failed", err.Error())
 	}
+	h.recordOrderCreated(user.ID, order)
 	
+	response := t(lang, "order_with_item_created", order.ID, order.OrderNumber, customerName, h.formatCurrency(totalAmountFloat),
+		itemName, quantity, h.formatCurrency(price), h.formatCurrency(lineTotal), order.OrderDate.Format("2006-01-02 15:04"))
+	if order.DeliveryDate != nil {
+		response += t(lang, "order_delivery_line", order.DeliveryDate.Format("2006-01-02"))
+	}
+	response += rateOverrideSummary(lang, order)
 	return response
 }
 
-// handleStructuredAICreateOrderWithItem handles AI-detected create order with item requests
-func (h *WhatsAppHandler) handleStructuredAICreateOrderWithItem(user *models.User, aiResponse *AIResponse) string {
+// handleAIAddOrderItem handles AI-detected add order item requests
+func (h *WhatsAppHandler) handleAIAddOrderItem(user *models.User, aiResponse *AIResponse) string {
 	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk membuat order. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Extract data from AI response
-	customerName, _ := aiResponse.Data["customer_name"].(string)
-	totalAmountFloat, _ := aiResponse.Data["total_amount"].(float64)
+	orderID := uint(dataFloat(aiResponse.Data, "order_id"))
 	itemName, _ := aiResponse.Data["item_name"].(string)
-	quantityFloat, _ := aiResponse.Data["quantity"].(float64)
-	priceFloat, _ := aiResponse.Data["price"].(float64)
+	quantity := int(dataFloat(aiResponse.Data, "quantity"))
+	price := dataFloat(aiResponse.Data, "price")
+	description, _ := aiResponse.Data["description"].(string)
 	
+	lang := userLanguage(user)
 	// Validate required fields
-	if customerName == "" || totalAmountFloat == 0 || itemName == "" || quantityFloat == 0 || priceFloat == 0 {
-		return "❌ Data tidak lengkap. Pastikan customer_name, total_amount, item_name, quantity, dan price tersedia."
+	if orderID == 0 || itemName == "" || quantity <= 0 || price <= 0 {
+		return missingFields(lang, "order_id", "item_name", "quantity", "price")
 	}
 	
-	// Generate unique order number
-	orderNumber := fmt.Sprintf("ORD-%d", time.Now().Unix())
+	err := h.orderService.AddItemToOrder(orderID, itemName, quantity, price, description)
+	if err != nil {
+		return t(lang, "add_item_failed", err.Error())
+	}
 	
-	// Create order
-	order := &models.Order{
-		OrderNumber:  orderNumber,
-		CustomerName: customerName,
-		TotalAmount:  totalAmountFloat,
-		Status:       "pending",
-		OrderDate:    time.Now(),
-		CreatedBy:    user.ID,
+	return t(lang, "item_added", orderID, itemName, quantity, h.formatCurrency(price), h.formatCurrency(float64(quantity)*price))
+}
+
+// handleAIViewOrderItems handles AI-detected view order items requests
+func (h *WhatsAppHandler) handleAIViewOrderItems(user *models.User, aiResponse *AIResponse) string {
+	lang := userLanguage(user)
+	orderID := uint(dataFloat(aiResponse.Data, "order_id"))
+	if orderID == 0 {
+		return missingFields(lang, "order_id")
 	}
 	
-	err := h.orderService.CreateOrder(order)
+	order, err := h.orderService.GetOrderByID(orderID)
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal membuat order: %s", err.Error())
+		return t(lang, "order_not_found_dot", orderID)
+	}
+	
+	// Regular users can only see items of their own orders
+	if !h.authorize(user, models.Admin, models.SuperAdmin) && order.CreatedBy != user.ID {
+		return t(lang, "order_items_access_denied")
 	}
 	
-	// Add item to order
-	err = h.orderService.AddItemToOrder(order.ID, itemName, int(quantityFloat), priceFloat, "")
+	items, err := h.orderService.GetOrderItems(orderID)
 	if err != nil {
-		return fmt.Sprintf("❌ Order dibuat tapi gagal menambahkan item: %s", err.Error())
+		return t(lang, "items_failed", err.Error())
+	}
+	
+	if len(items) == 0 {
+		return t(lang, "order_items_empty", orderID)
+	}
+	
+	response := t(lang, "order_items_header", orderID, order.CustomerName)
+	itemsTotal := 0.0
+	for _, item := range items {
+		response += fmt.Sprintf("**ID: %d** - **%s**\n", item.ID, item.ItemName)
+		response += t(lang, "order_item_qty", item.Quantity, h.formatCurrency(item.UnitPrice), h.formatCurrency(item.TotalPrice))
+		if item.Description != "" {
+			response += t(lang, "item_line_description", item.Description)
+		}
+		response += t(lang, "order_line_status", item.Status) + "\n"
+		itemsTotal += item.TotalPrice
+	}
+	response += t(lang, "order_items_total", h.formatCurrency(itemsTotal))
+	if math.Abs(itemsTotal-order.TotalAmount) >= 0.005 {
+		response += t(lang, "order_items_mismatch", h.formatCurrency(order.TotalAmount))
 	}
 	
-	return fmt.Sprintf("✅ Order dengan item berhasil dibuat!\n📦 Order Number: %s\n👤 Customer: %s\n💰 Total: Rp %.0f\n🛒 Item: %s (Qty: %.0f, Harga: Rp %.0f)\n📅 Tanggal: %s", 
-		orderNumber, customerName, totalAmountFloat, itemName, quantityFloat, priceFloat, order.OrderDate.Format("2006-01-02 15:04"))
+	return response
+}
+
+// dataFloat reads a numeric field from AI data, accepting numbers or numeric strings
+func dataFloat(data map[string]interface{}, key string) float64 {
+	switch v := data[key].(type) {
+	case float64:
+		return v
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	}
+	return 0
 }
 
+// defaultReminderHour is used when a reminder names a day but no time
+const defaultReminderHour = 9
+
 // handleAICreateReminder handles AI-detected create reminder requests
 func (h *WhatsAppHandler) handleAICreateReminder(user *models.User, aiResponse *AIResponse) string {
-	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk membuat reminder. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
 	// Extract data from AI response
-	taskIDFloat, _ := aiResponse.Data["task_id"].(float64)
+	taskID := uint(dataFloat(aiResponse.Data, "task_id"))
 	reminderType, _ := aiResponse.Data["reminder_type"].(string)
 	scheduledTimeStr, _ := aiResponse.Data["scheduled_time"].(string)
+	recurrence, _ := aiResponse.Data["recurrence"].(string)
+	recurrence = strings.ToLower(strings.TrimSpace(recurrence))
 	
+	lang := userLanguage(user)
 	// Validate required fields
-	if taskIDFloat == 0 || reminderType == "" || scheduledTimeStr == "" {
-		return "❌ Data tidak lengkap. Pastikan task_id, reminder_type, dan scheduled_time tersedia."
+	if taskID == 0 || reminderType == "" || scheduledTimeStr == "" {
+		return missingFields(lang, "task_id", "reminder_type", "scheduled_time")
 	}
 	
-	// Parse scheduled time
-	scheduledTime, err := time.Parse("2006-01-02 15:04", scheduledTimeStr)
+	// Parse scheduled time, either absolute or a phrase like "besok jam 9"
+	now := h.userNow(user)
+	parsed, err := dateparse.Parse(scheduledTimeStr, now)
 	if err != nil {
-		return "❌ Format waktu tidak valid. Gunakan format: YYYY-MM-DD HH:MM (contoh: 2025-10-05 10:00)"
+		return t(lang, "invalid_reminder_time")
+	}
+	scheduledTime := parsed.Time
+	if !parsed.HasTime {
+		// A day without a time reminds at the start of the working day
+		scheduledTime = scheduledTime.Add(defaultReminderHour * time.Hour)
+	}
+	
+	if scheduledTime.Before(now) {
+		return t(lang, "reminder_in_past")
+	}
+	
+	// The task must exist and be visible to the caller
+	task, err := h.taskService.GetTaskByID(taskID)
+	if err != nil {
+		return t(lang, "task_not_found_dot", taskID)
+	}
+	
+	if !h.authorize(user, models.Admin, models.SuperAdmin) && task.AssignedTo != user.ID {
+		return t(lang, "reminder_not_your_task")
 	}
 	
-	// Create reminder
-	err = h.reminderService.CreateTaskReminder(uint(taskIDFloat), reminderType, scheduledTime)
+	// Create reminder, repeating when a recurrence was asked for
+	if recurrence == "" || recurrence == models.RecurrenceNone {
+		err = h.reminderService.CreateTaskReminder(task.ID, reminderType, scheduledTime)
+	} else {
+		err = h.reminderService.CreateRecurringReminder(task.ID, reminderType, scheduledTime, recurrence)
+	}
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal membuat reminder: %s", err.Error())
+		return t(lang, "reminder_create_failed", err.Error())
 	}
 	
-	return fmt.Sprintf("✅ Reminder berhasil dibuat!\n📝 Task ID: %.0f\n🔔 Type: %s\n⏰ Scheduled: %s", 
-		taskIDFloat, reminderType, scheduledTime.Format("2006-01-02 15:04"))
+	response := t(lang, "reminder_created", task.ID, task.Title, reminderType, scheduledTime.Format("2006-01-02 15:04"))
+	if recurrence != "" && recurrence != models.RecurrenceNone {
+		response += t(lang, "reminder_repeats", recurrence)
+	}
+	return response
 }
 
 // handleAIViewReminders handles AI-detected view reminders requests
 func (h *WhatsAppHandler) handleAIViewReminders(user *models.User, aiResponse *AIResponse) string {
-	// Check if user has Admin or SuperAdmin access
-	if user.Role != string(models.Admin) && user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk melihat reminders. Hanya Admin atau Super Admin yang dapat melakukan operasi ini."
-	}
-	
-	// Get all pending reminders
-	reminders, err := h.reminderService.GetPendingReminders()
+	return h.viewReminders(user)
+}
+
+// viewReminders lists the reminders of the caller's tasks, earliest first
+func (h *WhatsAppHandler) viewReminders(user *models.User) string {
+	lang := userLanguage(user)
+	reminders, err := h.reminderService.GetRemindersByUser(user.ID)
 	if err != nil {
-		return fmt.Sprintf("❌ Gagal mengambil daftar reminders: %s", err.Error())
+		return t(lang, "reminders_failed", err.Error())
 	}
 	
 	if len(reminders) == 0 {
-		return "🔔 Tidak ada reminder yang pending."
+		return t(lang, "reminders_empty")
+	}
+	
+	tasks, err := h.taskService.GetTasksByUser(user.ID)
+	if err != nil {
+		return t(lang, "tasks_failed", err.Error())
+	}
+	taskTitles := make(map[uint]string, len(tasks))
+	for _, task := range tasks {
+		taskTitles[task.ID] = task.Title
 	}
 	
-	response := "🔔 **Daftar Reminders:**\n\n"
+	response := t(lang, "reminders_header")
 	for _, r := range reminders {
-		status := "❌ Not Sent"
+		status := t(lang, "reminder_not_sent")
 		if r.WhatsAppSent {
-			status = "✅ Sent"
+			status = t(lang, "reminder_sent")
 		}
 		
-		response += fmt.Sprintf("**ID: %d** - **Task: %d**\n", r.ID, r.TaskID)
-		response += fmt.Sprintf("🔔 Type: %s\n", r.ReminderType)
-		response += fmt.Sprintf("⏰ Scheduled: %s\n", r.ScheduledTime.Format("2006-01-02 15:04"))
-		response += fmt.Sprintf("Status: %s\n", status)
+		response += fmt.Sprintf("**ID: %d** - **Task #%d: %s**\n", r.ID, r.TaskID, taskTitles[r.TaskID])
+		response += t(lang, "reminder_line", r.ReminderType, r.ScheduledTime.Format("2006-01-02 15:04"))
+		if r.RecurrencePattern != "" && r.RecurrencePattern != models.RecurrenceNone {
+			response += t(lang, "reminder_repeats_line", r.RecurrencePattern)
+		}
+		response += t(lang, "task_line_status", status)
 		response += "\n"
 	}
 	
@@ -1461,62 +4137,77 @@ func (h *WhatsAppHandler) handleAIViewReminders(user *models.User, aiResponse *A
 
 // handleAIListTasks handles list_tasks AI response
 func (h *WhatsAppHandler) handleAIListTasks(user *models.User, aiResponse *AIResponse) string {
-	// Check if user has SuperAdmin access
-	if user.Role != string(models.SuperAdmin) {
-		return "❌ Anda tidak memiliki akses untuk melihat semua tasks. Hanya Super Admin yang dapat melakukan operasi ini."
+	return h.listAllTasks(user, aiPageArgs(aiResponse))
+}
+
+// handleAICloneTask handles clone_task AI response
+func (h *WhatsAppHandler) handleAICloneTask(user *models.User, aiResponse *AIResponse) string {
+	taskID := uint(dataFloat(aiResponse.Data, "task_id"))
+	if taskID == 0 {
+		return missingFields(userLanguage(user), "task_id")
 	}
 	
-	// Get all tasks
-	tasks, err := h.taskService.GetAllTasks()
-	if err != nil {
-		return fmt.Sprintf("❌ Gagal mengambil data tasks: %s", err.Error())
+	assignee, _ := aiResponse.Data["assigned_to"].(string)
+	return h.cloneTask(user, taskID, strings.TrimSpace(assignee))
+}
+
+// handleAIDeleteUser handles delete_user AI response
+func (h *WhatsAppHandler) handleAIDeleteUser(user *models.User, aiResponse *AIResponse) string {
+	target, _ := aiResponse.Data["username"].(string)
+	if strings.TrimSpace(target) == "" {
+		return missingFields(userLanguage(user), "username")
 	}
 	
-	if len(tasks) == 0 {
-		return "📋 Tidak ada tasks dalam sistem."
+	return h.deleteUser(user, []string{strings.TrimSpace(target)})
+}
+
+// handleAISetRole handles set_role AI response
+func (h *WhatsAppHandler) handleAISetRole(user *models.User, aiResponse *AIResponse) string {
+	target, _ := aiResponse.Data["username"].(string)
+	role, _ := aiResponse.Data["role"].(string)
+	if strings.TrimSpace(target) == "" || strings.TrimSpace(role) == "" {
+		return missingFields(userLanguage(user), "username", "role")
 	}
 	
-	response := "📋 *Semua Tasks dalam Sistem:*\n\n"
-	for _, task := range tasks {
-		status := "⏳ Pending"
-		if task.Status == string(models.InProgress) {
-			status = "🔄 In Progress"
-		} else if task.Status == string(models.Completed) {
-			status = "✅ Completed"
-		} else if task.Status == string(models.Overdue) {
-			status = "⚠️ Overdue"
-		}
-		
-		response += fmt.Sprintf("🆔 *ID:* %d\n", task.ID)
-		response += fmt.Sprintf("📝 *Title:* %s\n", task.Title)
-		response += fmt.Sprintf("📄 *Description:* %s\n", task.Description)
-		response += fmt.Sprintf("👤 *Assigned To:* %d\n", task.AssignedTo)
-		response += fmt.Sprintf("📅 *Due Date:* %s\n", task.DueDate.Format("2006-01-02"))
-		response += fmt.Sprintf("📊 *Progress:* %d%%\n", task.CompletionPercentage)
-		response += fmt.Sprintf("🏷️ *Status:* %s\n", status)
-		response += fmt.Sprintf("🔄 *Implemented:* %t\n", task.IsImplemented)
-		response += "\n"
+	return h.setRole(user, []string{strings.TrimSpace(target), strings.TrimSpace(role)})
+}
+
+// handleAIViewUserTasks handles view_user_tasks AI response
+func (h *WhatsAppHandler) handleAIViewUserTasks(user *models.User, aiResponse *AIResponse) string {
+	target, _ := aiResponse.Data["assigned_to"].(string)
+	if strings.TrimSpace(target) == "" {
+		return missingFields(userLanguage(user), "assigned_to")
 	}
 	
-	return response
+	return h.userTasks(user, []string{strings.TrimSpace(target)})
+}
+
+// handleAIDeleteTask handles delete_task AI response
+func (h *WhatsAppHandler) handleAIDeleteTask(user *models.User, aiResponse *AIResponse) string {
+	taskID := uint(dataFloat(aiResponse.Data, "task_id"))
+	if taskID == 0 {
+		return missingFields(userLanguage(user), "task_id")
+	}
+	
+	return h.deleteTask(user, taskID)
 }
 
 // handleAIUpdateProgress handles update_progress AI response
 func (h *WhatsAppHandler) handleAIUpdateProgress(user *models.User, aiResponse *AIResponse) string {
-	return "🔄 Untuk mengupdate progress task, gunakan format:\n/update_progress [task_id] [percentage]\n\nContoh: /update_progress 1 75"
+	return t(userLanguage(user), "update_progress_hint")
 }
 
 // handleAIMarkComplete handles mark_complete AI response
 func (h *WhatsAppHandler) handleAIMarkComplete(user *models.User, aiResponse *AIResponse) string {
-	return "✅ Untuk menandai task sebagai selesai, gunakan format:\n/mark_complete [task_id]\n\nContoh: /mark_complete 1"
+	return t(userLanguage(user), "mark_complete_hint")
 }
 
 // handleAIMyReport handles my_report AI response
 func (h *WhatsAppHandler) handleAIMyReport(user *models.User, aiResponse *AIResponse) string {
-	return "📊 Untuk melihat laporan personal, gunakan format:\n/my_report\n\nAtau untuk laporan berdasarkan tanggal:\n/report_by_date [start_date] [end_date]\n\nContoh: /report_by_date 2025-01-01 2025-01-31"
+	return t(userLanguage(user), "my_report_hint")
 }
 
 // handleAIReportByDate handles report_by_date AI response
 func (h *WhatsAppHandler) handleAIReportByDate(user *models.User, aiResponse *AIResponse) string {
-	return "📅 Untuk generate laporan berdasarkan tanggal, gunakan format:\n/report_by_date [start_date] [end_date]\n\nContoh: /report_by_date 2025-01-01 2025-01-31\n\nFormat tanggal: YYYY-MM-DD"
+	return t(userLanguage(user), "report_by_date_hint")
 }
\ No newline at end of file
@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/config"
+	"task_manager/internal/features"
+	"task_manager/internal/models"
+	"task_manager/internal/services"
+	"testing"
+	"time"
+)
+
+// testNow is a Wednesday morning in Jakarta, for tests that need no
+// particular moment
+var testNow = time.Date(2025, 1, 15, 10, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+// newTestHandler returns a handler on a fake clock with a discarded log;
+// tests fill in the services they exercise
+func newTestHandler(now time.Time) *WhatsAppHandler {
+	return &WhatsAppHandler{
+		cfg:    &config.Config{Currency: "IDR", Timezone: "Asia/Jakarta"},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		clock:  clock.NewFake(now),
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, jakarta)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "two dates cover the whole end day",
+			args:      []string{"2025-01-01", "2025-01-31"},
+			wantStart: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 1, 31, 23, 59, 59, 999999000, jakarta),
+		},
+		{
+			name:      "same day",
+			args:      []string{"2025-01-10", "2025-01-10"},
+			wantStart: time.Date(2025, 1, 10, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 1, 10, 23, 59, 59, 999999000, jakarta),
+		},
+		{
+			name:      "phrases",
+			args:      strings.Fields("awal bulan sampai hari ini"),
+			wantStart: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 1, 15, 23, 59, 59, 999999000, jakarta),
+		},
+		{
+			name:      "end with a time is kept",
+			args:      strings.Fields("2025-01-01 to 2025-01-02 12:00"),
+			wantStart: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 1, 2, 12, 0, 0, 0, jakarta),
+		},
+		{name: "one date", args: []string{"2025-01-01"}, wantErr: true},
+		{name: "bad end", args: []string{"2025-01-01", "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseDateRange(tt.args, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("range = %v - %v, want %v - %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestGetReportByDate(t *testing.T) {
+	// 23:30 UTC on 31 January is already 1 February in Jakarta and Makassar
+	now := time.Date(2025, 1, 31, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		timezone   string
+		args       []string
+		wantHeader string
+		wantStart  time.Time
+		wantEnd    time.Time
+	}{
+		{
+			name:       "header shows parsed dates",
+			args:       strings.Fields("awal bulan sampai hari ini"),
+			wantHeader: "Report for 2025-02-01 to 2025-02-01",
+			wantStart:  time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC),
+			wantEnd:    time.Date(2025, 2, 1, 16, 59, 59, 999999000, time.UTC),
+		},
+		{
+			name:       "user time zone",
+			timezone:   "Asia/Makassar",
+			args:       []string{"2025-01-01", "2025-01-31"},
+			wantHeader: "Report for 2025-01-01 to 2025-01-31",
+			wantStart:  time.Date(2024, 12, 31, 16, 0, 0, 0, time.UTC),
+			wantEnd:    time.Date(2025, 1, 31, 15, 59, 59, 999999000, time.UTC),
+		},
+		{
+			name:       "unknown user time zone falls back to TIMEZONE",
+			timezone:   "Mars/Olympus",
+			args:       []string{"2025-01-01", "2025-01-31", "all"},
+			wantHeader: "Report for 2025-01-01 to 2025-01-31",
+			wantStart:  time.Date(2024, 12, 31, 17, 0, 0, 0, time.UTC),
+			wantEnd:    time.Date(2025, 1, 31, 16, 59, 59, 999999000, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderService{}
+			h := newTestHandler(now)
+			h.orderService = orders
+
+			reply := h.getReportByDate(&models.User{ID: 1, Timezone: tt.timezone, Language: "en"}, tt.args)
+			if !strings.Contains(reply, tt.wantHeader) {
+				t.Errorf("reply %q does not contain %q", reply, tt.wantHeader)
+			}
+			if len(orders.summaryRanges) != 1 {
+				t.Fatalf("GetFinancialSummary called %d times", len(orders.summaryRanges))
+			}
+			got := orders.summaryRanges[0]
+			if !got[0].Equal(tt.wantStart) || !got[1].Equal(tt.wantEnd) {
+				t.Errorf("range = %v - %v, want %v - %v", got[0].UTC(), got[1].UTC(), tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestHandleAICreateReminderUsesClock(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		when     string
+		want     time.Time
+		wantPast bool
+	}{
+		{
+			name: "later today",
+			now:  time.Date(2025, 1, 15, 8, 59, 0, 0, jakarta),
+			when: "hari ini jam 9",
+			want: time.Date(2025, 1, 15, 9, 0, 0, 0, jakarta),
+		},
+		{
+			name:     "a minute too late",
+			now:      time.Date(2025, 1, 15, 9, 1, 0, 0, jakarta),
+			when:     "hari ini jam 9",
+			wantPast: true,
+		},
+		{
+			name: "tomorrow across a month end",
+			now:  time.Date(2025, 1, 31, 23, 59, 0, 0, jakarta),
+			when: "besok",
+			want: time.Date(2025, 2, 1, defaultReminderHour, 0, 0, 0, jakarta),
+		},
+		{
+			// 20:00 UTC on 14 January is already 15 January in Jakarta
+			name: "today is the user's today",
+			now:  time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC),
+			when: "hari ini jam 10",
+			want: time.Date(2025, 1, 15, 10, 0, 0, 0, jakarta),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminders := &fakeReminderService{}
+			h := newTestHandler(tt.now)
+			h.reminderService = reminders
+			h.taskService = &fakeTaskService{tasks: map[uint]*models.Task{3: {ID: 3, Title: "Stock opname", AssignedTo: 1}}}
+
+			reply := h.handleAICreateReminder(&models.User{ID: 1, Role: string(models.Users), Language: "en"}, &AIResponse{
+				Type: "create_reminder",
+				Data: map[string]interface{}{"task_id": float64(3), "reminder_type": "deadline", "scheduled_time": tt.when},
+			})
+
+			if tt.wantPast {
+				if len(reminders.scheduled) != 0 || !strings.Contains(reply, "already passed") {
+					t.Fatalf("expected the past time to be rejected, got %q", reply)
+				}
+				return
+			}
+			if len(reminders.scheduled) != 1 {
+				t.Fatalf("no reminder created: %q", reply)
+			}
+			if got := reminders.scheduled[0]; !got.Equal(tt.want) {
+				t.Errorf("scheduled %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyCustomer(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		phone   string
+		want    []string
+	}{
+		{name: "feature on", enabled: true, phone: "6281234567890", want: []string{"Halo Siti, pesanan Anda ORD-0001 sekarang berstatus completed."}},
+		{name: "feature off", phone: "6281234567890"},
+		{name: "no customer phone", enabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := features.Set(features.CustomerNotifications, tt.enabled); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { features.Set(features.CustomerNotifications, false) })
+
+			wa := &fakeWhatsAppService{}
+			h := newTestHandler(testNow)
+			h.whatsappService = wa
+
+			h.notifyCustomer(&models.Order{ID: 1, OrderNumber: "ORD-0001", CustomerName: "Siti", CustomerPhone: tt.phone}, "completed")
+			if len(wa.sent) != len(tt.want) {
+				t.Fatalf("sent %q, want %q", wa.sent, tt.want)
+			}
+			for i := range tt.want {
+				if wa.sent[i] != tt.want[i] {
+					t.Errorf("sent %q, want %q", wa.sent[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunCleanup(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    models.UserRole
+		want    string
+		wantRun bool
+	}{
+		{name: "super admin", role: models.SuperAdmin, want: "🧹 Cleanup complete\nTask progress: 2 removed\nCalculation history: 0 removed\nReport queries: 5 removed", wantRun: true},
+		{name: "admin", role: models.Admin, want: "❌ Only Super Admin can use this command."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := &fakeCleanupService{result: services.CleanupResult{TaskProgress: 2, ReportQueries: 5}}
+			h := newTestHandler(testNow)
+			h.cleanupService = cleanup
+			h.whatsappService = &fakeWhatsAppService{}
+
+			got := h.processCommand(&models.User{ID: 1, Role: string(tt.role), Language: "en"}, "/cleanup")
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("/cleanup = %q, want %q", got, tt.want)
+			}
+			if ran := len(cleanup.runs) == 1; ran != tt.wantRun {
+				t.Fatalf("ran %d times, want run %v", len(cleanup.runs), tt.wantRun)
+			}
+			if tt.wantRun && !cleanup.runs[0].Equal(testNow) {
+				t.Errorf("run at %v, want the handler clock's %v", cleanup.runs[0], testNow)
+			}
+		})
+	}
+}
+
+func TestReportHistory(t *testing.T) {
+	user := &models.User{ID: 1, Language: "en"}
+	orders := &fakeOrderService{}
+	h := newTestHandler(testNow)
+	h.orderService = orders
+
+	if got, want := h.reportHistory(user), "📊 You haven't generated any reports yet. Try /report_by_date."; got != want {
+		t.Errorf("reportHistory() before any report = %q, want %q", got, want)
+	}
+
+	h.getReportByDate(user, []string{"2025-01-01", "2025-01-31"})
+	h.getReportByDate(&models.User{ID: 2, Language: "en"}, []string{"2025-01-01", "2025-01-15"})
+	h.getReportByDate(user, []string{"2025-01-10", "2025-01-15", "all"})
+
+	if len(orders.reports) != 3 {
+		t.Fatalf("recorded %d report queries, want one per report", len(orders.reports))
+	}
+	row := orders.reports[0]
+	if row.UserID != 1 || row.QueryType != "custom_range" || row.StartDate == nil || row.EndDate == nil ||
+		row.StartDate.Format("2006-01-02") != "2025-01-01" || row.EndDate.Format("2006-01-02") != "2025-01-31" {
+		t.Errorf("first row = %+v, want user 1's custom_range report for January", row)
+	}
+	if !strings.Contains(row.ReportData, `"total_orders":1`) || !strings.Contains(row.ReportData, `"include_cancelled":false`) {
+		t.Errorf("ReportData = %s, want the report figures", row.ReportData)
+	}
+
+	want := "📊 **Your Recent Reports:**\n\n" +
+		"[2025-01-15 10:30] custom_range 2025-01-10 to 2025-01-15\nOrders: 1 | Total: Rp 100.000\n\n" +
+		"[2025-01-15 10:30] custom_range 2025-01-01 to 2025-01-31\nOrders: 1 | Total: Rp 100.000"
+	if got := h.reportHistory(user); got != want {
+		t.Errorf("reportHistory() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestReportByDateCancelledOrders(t *testing.T) {
+	day := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	orders := []models.Order{
+		{ID: 1, Status: "completed", OrderDate: day, TotalAmount: 100000, NetProfit: 80000},
+		{ID: 2, Status: "cancelled", OrderDate: day, TotalAmount: 150000, NetProfit: 120000},
+		{ID: 3, Status: "pending", OrderDate: day.AddDate(0, 1, 0), TotalAmount: 50000, NetProfit: 40000},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "cancelled excluded by default",
+			args: []string{"2025-01-01", "2025-01-31"},
+			want: "Total Orders: 1\nTotal Amount: Rp 100.000\nNet Profit: Rp 80.000\nCancelled orders excluded (add 'all' to include them)\n",
+		},
+		{
+			name: "all includes cancelled",
+			args: []string{"2025-01-01", "2025-01-31", "ALL"},
+			want: "Total Orders: 2\nTotal Amount: Rp 250.000\nNet Profit: Rp 200.000\nIncluding Cancelled Orders: 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(testNow)
+			h.orderService = &fakeOrderService{reportOrders: orders}
+
+			if got := h.getReportByDate(&models.User{ID: 1, Language: "en"}, tt.args); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("report = %q, want it to end with %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreationRepliesIncludeID(t *testing.T) {
+	admin := &models.User{ID: 1, Role: string(models.Admin), Language: "en"}
+	assignData := map[string]interface{}{"title": "Stock opname", "description": "Hitung stok", "assigned_to": "budi"}
+
+	tests := []struct {
+		name   string
+		create func(h *WhatsAppHandler) string
+		want   string
+	}{
+		{
+			name: "AI task intent",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleStructuredAIAssignTask(admin, &AIResponse{Type: "assign_task", Data: assignData})
+			},
+			want: "✅ Task #3 assigned!",
+		},
+		{
+			name: "AI task message",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleAIAssignTask(admin, "tugaskan task Opname hitung stok to budi", nil)
+			},
+			want: "✅ Task #3 assigned!",
+		},
+		{
+			name:   "assign command",
+			create: func(h *WhatsAppHandler) string { return h.assignTask(admin, strings.Fields("budi Opname hitung stok")) },
+			want:   "✅ Task #3 assigned successfully",
+		},
+		{
+			name: "AI order intent",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleStructuredAICreateOrder(admin, &AIResponse{Type: "create_order", Data: map[string]interface{}{"customer_name": "Siti", "total_amount": 150000.0}})
+			},
+			want: "✅ Order #3 created!\n📦 Order Number: ORD-0003",
+		},
+		{
+			name: "AI order with item intent",
+			create: func(h *WhatsAppHandler) string {
+				return h.handleAICreateOrderWithItem(admin, &AIResponse{Type: "create_order_with_item", Data: map[string]interface{}{"customer_name": "Siti", "item_name": "Kue Lapis", "quantity": 2.0, "price": 50000.0}})
+			},
+			want: "✅ Order #3 with item created!",
+		},
+		{
+			name:   "AI order message",
+			create: func(h *WhatsAppHandler) string { return h.handleAICreateOrder(admin, "buat order Siti 150000", nil) },
+			want:   "✅ Order #3 created!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Two earlier records of each kind, so the new ID is not the first
+			tasks := &fakeTaskService{tasks: map[uint]*models.Task{1: {ID: 1}, 2: {ID: 2}}}
+			orders := &fakeOrderService{}
+			for i := 0; i < 2; i++ {
+				orders.CreateOrder(&models.Order{})
+			}
+			h := newTestHandler(testNow)
+			h.taskService = tasks
+			h.orderService = orders
+			h.undoService = &fakeUndoService{}
+			h.userService = &fakeUserService{users: []*models.User{{ID: 2, Username: "budi", IsActive: true}}}
+
+			if got := tt.create(h); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("reply = %q, want it to start with %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,7 +13,9 @@ import (
 func RunMigrations(db *gorm.DB) error {
 	log.Println("Running database migrations...")
 
-	// Force recreate all tables to ensure proper schema
+	// Force recreate all tables to ensure proper schema. Tenants are kept:
+	// they are only added through the API and hold gateway credentials that
+	// cannot be recreated from defaults
 	log.Println("Dropping existing tables...")
 	err := db.Migrator().DropTable(
 		&models.User{},
@@ -23,6 +25,7 @@ func RunMigrations(db *gorm.DB) error {
 		&models.MonthlyTask{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderNote{},
 		&models.Reminder{},
 		&models.FinancialSettings{},
 		&models.CalculationHistory{},
@@ -42,10 +45,12 @@ func RunMigrations(db *gorm.DB) error {
 		&models.MonthlyTask{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderNote{},
 		&models.Reminder{},
 		&models.FinancialSettings{},
 		&models.CalculationHistory{},
 		&models.ReportQuery{},
+		&models.Tenant{},
 	)
 	if err != nil {
 		return err
@@ -67,7 +72,7 @@ func createDefaultData(db *gorm.DB) error {
 
 	// Initialize repositories and services
 	userRepo := repository.NewUserRepository(db)
-	userService := services.NewUserService(userRepo)
+	userService := services.NewUserService(userRepo, nil, nil)
 	financialRepo := repository.NewFinancialRepository(db)
 
 	// Check if super admin already exists
@@ -6,16 +6,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// Reminder is a scheduled WhatsApp notification about a task. At most one
+// live, unsent reminder exists per task, type and time
 type Reminder struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
-	TaskID       uint           `json:"task_id" gorm:"not null"`
-	ReminderType string         `json:"reminder_type" gorm:"not null"`
-	ScheduledTime time.Time     `json:"scheduled_time" gorm:"not null"`
+	TaskID       uint           `json:"task_id" gorm:"not null;uniqueIndex:idx_reminder_task_type_time,where:deleted_at IS NULL AND whatsapp_sent = false"`
+	ReminderType string         `json:"reminder_type" gorm:"not null;uniqueIndex:idx_reminder_task_type_time"`
+	ScheduledTime time.Time     `json:"scheduled_time" gorm:"not null;uniqueIndex:idx_reminder_task_type_time"`
 	WhatsAppSent bool           `json:"whatsapp_sent" gorm:"default:false"`
+	RecurrencePattern string    `json:"recurrence_pattern" gorm:"default:'none'"` // none, daily, weekly
 	CreatedAt    time.Time      `json:"created_at"`
 	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
+// Reminder recurrence patterns
+const (
+	RecurrenceNone   = "none"
+	RecurrenceDaily  = "daily"
+	RecurrenceWeekly = "weekly"
+)
+
 type FinancialSettings struct {
 	ID             uint      `json:"id" gorm:"primaryKey"`
 	SettingName    string    `json:"setting_name" gorm:"not null"` // tax_rate, marketing_rate, rental_rate
@@ -34,7 +44,10 @@ type CalculationHistory struct {
 	CalculationType       string    `json:"calculation_type" gorm:"not null"` // tax, marketing, rental, net_profit
 	InputValue            float64   `json:"input_value"`
 	PercentageUsed        float64   `json:"percentage_used"`
+	FixedAmountUsed       float64   `json:"fixed_amount_used"` // flat deductions from settings with IsPercentage false
 	CalculatedAmount      float64   `json:"calculated_amount"`
+	PreviousNetProfit     *float64  `json:"previous_net_profit"` // nil for the first calculation of an order
+	UpdatedBy             uint      `json:"updated_by"`
 	CalculationTimestamp  time.Time `json:"calculation_timestamp"`
 	CreatedAt             time.Time `json:"created_at"`
 }
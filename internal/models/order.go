@@ -21,6 +21,11 @@ type Order struct {
 	MarketingCost         float64        `json:"marketing_cost"`
 	RentalPercentage      float64        `json:"rental_percentage"`
 	RentalCost            float64        `json:"rental_cost"`
+	// Percentages used for this order instead of the global settings; nil
+	// means the setting applies
+	TaxRateOverride       *float64       `json:"tax_rate_override"`
+	MarketingRateOverride *float64       `json:"marketing_rate_override"`
+	RentalRateOverride    *float64       `json:"rental_rate_override"`
 	NetProfit             float64        `json:"net_profit"`
 	CalculationTimestamp  time.Time      `json:"calculation_timestamp"`
 	CreatedBy             uint           `json:"created_by" gorm:"not null"`
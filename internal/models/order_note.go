@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OrderNote is an internal comment on an order, e.g. "customer requested gift wrap"
+type OrderNote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	OrderID   uint      `json:"order_id" gorm:"not null;index"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	Content   string    `json:"content" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
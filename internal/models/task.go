@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,14 +13,14 @@ type Task struct {
 	Description          string         `json:"description"`
 	AssignedTo           uint           `json:"assigned_to" gorm:"not null"`
 	DueDate              *time.Time    `json:"due_date"`
-	Status               string         `json:"status" gorm:"default:'pending'"` // pending, in_progress, completed, overdue
+	Status               string         `json:"status" gorm:"default:'pending'"` // pending, in_progress, completed, overdue, blocked
 	Priority             string         `json:"priority" gorm:"default:'medium'"` // low, medium, high, urgent
 	CompletionPercentage int            `json:"completion_percentage" gorm:"default:0"`
 	IsImplemented        bool           `json:"is_implemented" gorm:"default:false"`
 	ImplementationNotes  string         `json:"implementation_notes"`
-	TaskType             string         `json:"task_type" gorm:"default:'custom'"` // daily, monthly, custom
+	TaskType             string         `json:"task_type" gorm:"default:'custom'"` // daily, weekly, monthly, custom
 	IsRecurring          bool           `json:"is_recurring" gorm:"default:false"`
-	RecurringPattern     string         `json:"recurring_pattern"` // daily, monthly
+	RecurringPattern     string         `json:"recurring_pattern"` // daily, weekly, monthly
 	LastUpdatedDate      *time.Time     `json:"last_updated_date"`
 	CompletedAt          *time.Time     `json:"completed_at"`
 	CreatedBy            uint           `json:"created_by" gorm:"not null"`
@@ -28,6 +29,46 @@ type Task struct {
 	DeletedAt            gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
+// IsOverdue reports whether the task's due day has passed without it being
+// completed. A task without a due date is never overdue
+func (t *Task) IsOverdue(now time.Time) bool {
+	if t.DueDate == nil || t.Status == string(Completed) {
+		return false
+	}
+
+	due := t.DueDate.In(now.Location())
+	endOfDueDay := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	return !now.Before(endOfDueDay)
+}
+
+// IsDueWithin reports whether the task is still open and due between now and
+// now+window. A task without a due date is never due
+func (t *Task) IsDueWithin(now time.Time, window time.Duration) bool {
+	if t.DueDate == nil || t.Status == string(Completed) {
+		return false
+	}
+	return !t.DueDate.Before(now) && !t.DueDate.After(now.Add(window))
+}
+
+// SortTasksByDueDate orders tasks by due date, earliest first. Tasks without
+// a due date sort last; ties keep ID order
+func SortTasksByDueDate(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].DueDate, tasks[j].DueDate
+		switch {
+		case a == nil && b == nil:
+			return tasks[i].ID < tasks[j].ID
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
 type TaskStatus string
 
 const (
@@ -35,6 +76,7 @@ const (
 	InProgress  TaskStatus = "in_progress"
 	Completed   TaskStatus = "completed"
 	Overdue     TaskStatus = "overdue"
+	Blocked     TaskStatus = "blocked"
 )
 
 type TaskPriority string
@@ -50,6 +92,7 @@ type TaskType string
 
 const (
 	Daily   TaskType = "daily"
+	Weekly  TaskType = "weekly"
 	Monthly TaskType = "monthly"
 	Custom  TaskType = "custom"
 )
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskDueDateChecks(t *testing.T) {
+	now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+
+	tests := []struct {
+		name          string
+		task          Task
+		wantOverdue   bool
+		wantDueWithin bool
+	}{
+		{name: "no due date", task: Task{Status: string(Pending)}},
+		{name: "no due date completed", task: Task{Status: string(Completed)}},
+		{name: "past due day", task: Task{Status: string(Pending), DueDate: at(-30 * time.Hour)}, wantOverdue: true},
+		{name: "earlier on the due day", task: Task{Status: string(InProgress), DueDate: at(-2 * time.Hour)}},
+		{name: "due within the window", task: Task{Status: string(Pending), DueDate: at(12 * time.Hour)}, wantDueWithin: true},
+		{name: "due after the window", task: Task{Status: string(Pending), DueDate: at(48 * time.Hour)}},
+		{name: "completed past due", task: Task{Status: string(Completed), DueDate: at(-30 * time.Hour)}},
+		{name: "completed due soon", task: Task{Status: string(Completed), DueDate: at(time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.IsOverdue(now); got != tt.wantOverdue {
+				t.Errorf("IsOverdue() = %v, want %v", got, tt.wantOverdue)
+			}
+			if got := tt.task.IsDueWithin(now, 24*time.Hour); got != tt.wantDueWithin {
+				t.Errorf("IsDueWithin() = %v, want %v", got, tt.wantDueWithin)
+			}
+		})
+	}
+}
+
+func TestSortTasksByDueDate(t *testing.T) {
+	day := func(d int) *time.Time {
+		due := time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+		return &due
+	}
+
+	tests := []struct {
+		name  string
+		tasks []Task
+		want  []uint
+	}{
+		{name: "empty", tasks: nil, want: nil},
+		{name: "all without due dates keep ID order", tasks: []Task{{ID: 3}, {ID: 1}, {ID: 2}}, want: []uint{1, 2, 3}},
+		{
+			name:  "nil due dates sort last",
+			tasks: []Task{{ID: 1}, {ID: 2, DueDate: day(20)}, {ID: 3}, {ID: 4, DueDate: day(5)}},
+			want:  []uint{4, 2, 1, 3},
+		},
+		{
+			name:  "same due date falls back to ID",
+			tasks: []Task{{ID: 9, DueDate: day(5)}, {ID: 2, DueDate: day(5)}, {ID: 5}},
+			want:  []uint{2, 9, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SortTasksByDueDate(tt.tasks)
+			if len(tt.tasks) != len(tt.want) {
+				t.Fatalf("got %d tasks, want %d", len(tt.tasks), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if tt.tasks[i].ID != id {
+					t.Errorf("position %d = task %d, want %d", i, tt.tasks[i].ID, id)
+				}
+			}
+		})
+	}
+}
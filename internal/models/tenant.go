@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Tenant is a business served by this instance through its own WhatsApp
+// gateway number and credentials
+type Tenant struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	Name           string    `json:"name" gorm:"not null"`
+	WhatsAppNumber string    `json:"whatsapp_number" gorm:"column:whatsapp_number;uniqueIndex;not null"`
+	APIURL         string    `json:"api_url"`
+	Username       string    `json:"username"`
+	Password       string    `json:"-"` // encrypted with TENANT_SECRET_KEY
+	Path           string    `json:"path"`
+	IsActive       bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,6 +15,10 @@ type User struct {
 	Role          string         `json:"role" gorm:"default:'user'"` // super_admin, admin, user
 	WhatsAppNumber string        `json:"whatsapp_number" gorm:"column:whatsapp_number"`
 	IsActive      bool           `json:"is_active" gorm:"default:true"`
+	Language      string         `json:"language" gorm:"default:'id'"` // id, en
+	Timezone      string         `json:"timezone"`                       // IANA name; empty uses TIMEZONE
+	PasswordHash  string         `json:"-" gorm:"column:password_hash"`
+	LastSeenAt    *time.Time     `json:"last_seen_at"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -26,3 +31,42 @@ const (
 	Admin      UserRole = "admin"
     Users UserRole = "user"
 )
+
+// Reply languages
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+	DefaultLanguage    = LanguageIndonesian
+)
+
+// IsSupportedLanguage reports whether replies can be written in lang
+func IsSupportedLanguage(lang string) bool {
+	return lang == LanguageIndonesian || lang == LanguageEnglish
+}
+
+// NormalizeRole maps friendly or differently cased role names such as
+// "SuperAdmin", "Admin" or "USER" to the stored role constants. Unknown
+// roles are returned lower-cased and trimmed
+func NormalizeRole(role string) string {
+	key := strings.ToLower(strings.TrimSpace(role))
+	key = strings.NewReplacer("_", "", "-", "", " ", "").Replace(key)
+	switch key {
+	case "superadmin":
+		return string(SuperAdmin)
+	case "admin":
+		return string(Admin)
+	case "user":
+		return string(Users)
+	}
+	return strings.ToLower(strings.TrimSpace(role))
+}
+
+// IsValidRole reports whether role names one of the stored role constants,
+// in any spelling NormalizeRole understands
+func IsValidRole(role string) bool {
+	switch NormalizeRole(role) {
+	case string(SuperAdmin), string(Admin), string(Users):
+		return true
+	}
+	return false
+}
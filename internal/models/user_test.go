@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestNormalizeRole(t *testing.T) {
+	tests := []struct {
+		role      string
+		want      string
+		wantValid bool
+	}{
+		{role: "SuperAdmin", want: "super_admin", wantValid: true},
+		{role: "super_admin", want: "super_admin", wantValid: true},
+		{role: " Super-Admin ", want: "super_admin", wantValid: true},
+		{role: "ADMIN", want: "admin", wantValid: true},
+		{role: "User", want: "user", wantValid: true},
+		{role: "Manager", want: "manager"},
+		{role: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			if got := NormalizeRole(tt.role); got != tt.want {
+				t.Errorf("NormalizeRole(%q) = %q, want %q", tt.role, got, tt.want)
+			}
+			if got := IsValidRole(tt.role); got != tt.wantValid {
+				t.Errorf("IsValidRole(%q) = %v, want %v", tt.role, got, tt.wantValid)
+			}
+		})
+	}
+}
@@ -13,6 +13,9 @@ type Client struct {
 	rdb *redis.Client
 }
 
+// Nil is the error Get reports for a key that does not exist
+var Nil = redis.Nil
+
 type SessionData struct {
 	UserID      uint   `json:"user_id"`
 	PhoneNumber string `json:"phone_number"`
@@ -173,6 +176,105 @@ func (c *Client) GetMonthlyTaskProgress(taskID uint, monthYear string) (int, err
 	return val, nil
 }
 
+// Plain key/value access
+func (c *Client) Get(key string) *redis.StringCmd {
+	ctx := context.Background()
+	return c.rdb.Get(ctx, key)
+}
+
+func (c *Client) Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	ctx := context.Background()
+	return c.rdb.Set(ctx, key, value, expiration)
+}
+
+// Daily completion streaks
+type StreakData struct {
+	Count    int    `json:"count"`
+	LastDate string `json:"last_date"` // YYYY-MM-DD of the last day all daily tasks were done
+}
+
+func (c *Client) SetStreak(userID uint, streak *StreakData) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(streak)
+	if err != nil {
+		return fmt.Errorf("failed to marshal streak data: %w", err)
+	}
+
+	key := fmt.Sprintf("streak:%d", userID)
+	return c.rdb.Set(ctx, key, jsonData, 0).Err()
+}
+
+func (c *Client) GetStreak(userID uint) (*StreakData, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf("streak:%d", userID)
+	val, err := c.rdb.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return &StreakData{}, nil
+		}
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+
+	var streak StreakData
+	if err := json.Unmarshal([]byte(val), &streak); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal streak data: %w", err)
+	}
+	return &streak, nil
+}
+
+// Last mutating action per user, kept so it can be undone
+type LastAction struct {
+	Type                string          `json:"type"`
+	EntityID            uint            `json:"entity_id"`
+	PreviousStatus      string          `json:"previous_status,omitempty"`
+	PreviousProgress    int             `json:"previous_progress,omitempty"`
+	PreviousImplemented bool            `json:"previous_implemented,omitempty"`
+	PreviousNotes       string          `json:"previous_notes,omitempty"`
+	ItemStatuses        map[uint]string `json:"item_statuses,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+}
+
+func (c *Client) SetLastAction(userID uint, action *LastAction, ttl time.Duration) error {
+	ctx := context.Background()
+	jsonData, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal last action: %w", err)
+	}
+
+	key := fmt.Sprintf("last_action:%d", userID)
+	return c.rdb.Set(ctx, key, jsonData, ttl).Err()
+}
+
+// popScript reads and deletes a key in one step, so two callers can never
+// both receive the same value
+var popScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value then
+	redis.call("DEL", KEYS[1])
+end
+return value`)
+
+// PopLastAction removes and returns the user's last action atomically, so
+// concurrent undos cannot both reverse it. It returns nil when there is no
+// action left to undo
+func (c *Client) PopLastAction(userID uint) (*LastAction, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf("last_action:%d", userID)
+	val, err := popScript.Run(ctx, c.rdb, []string{key}).Text()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to pop last action: %w", err)
+	}
+
+	var action LastAction
+	if err := json.Unmarshal([]byte(val), &action); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal last action: %w", err)
+	}
+	return &action, nil
+}
+
 // Chat history management for AI
 func (c *Client) LRange(key string, start, stop int64) *redis.StringSliceCmd {
 	ctx := context.Background()
@@ -189,6 +291,17 @@ func (c *Client) LTrim(key string, start, stop int64) *redis.StatusCmd {
 	return c.rdb.LTrim(ctx, key, start, stop)
 }
 
+func (c *Client) Incr(key string) *redis.IntCmd {
+	ctx := context.Background()
+	return c.rdb.Incr(ctx, key)
+}
+
+// SetNX sets key only when it does not exist yet
+func (c *Client) SetNX(key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	ctx := context.Background()
+	return c.rdb.SetNX(ctx, key, value, expiration)
+}
+
 func (c *Client) Expire(key string, expiration time.Duration) *redis.BoolCmd {
 	ctx := context.Background()
 	return c.rdb.Expire(ctx, key, expiration)
@@ -199,6 +312,18 @@ func (c *Client) Del(keys ...string) *redis.IntCmd {
 	return c.rdb.Del(ctx, keys...)
 }
 
+// AcquireLock takes the named lock for ttl with SET NX, reporting false when
+// another holder already has it. Locks are released by expiry
+func (c *Client) AcquireLock(key string, ttl time.Duration) (bool, error) {
+	ctx := context.Background()
+	return c.rdb.SetNX(ctx, fmt.Sprintf("lock:%s", key), time.Now().Format(time.RFC3339), ttl).Result()
+}
+
+// Ping checks that Redis is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
+
 // Close Redis connection
 func (c *Client) Close() error {
 	return c.rdb.Close()
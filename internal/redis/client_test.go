@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestClient returns a Client on an in-memory Redis that is shut down
+// when the test ends
+func newTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client, err := Initialize("redis://" + server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestPopLastAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored *LastAction
+		ttl    time.Duration
+		wait   time.Duration
+		want   *LastAction
+	}{
+		{name: "nothing recorded"},
+		{
+			name:   "recorded action",
+			stored: &LastAction{Type: "order_created", EntityID: 42},
+			ttl:    time.Minute,
+			want:   &LastAction{Type: "order_created", EntityID: 42},
+		},
+		{
+			name:   "expired action",
+			stored: &LastAction{Type: "order_created", EntityID: 42},
+			ttl:    time.Minute,
+			wait:   2 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			if tt.stored != nil {
+				if err := client.SetLastAction(7, tt.stored, tt.ttl); err != nil {
+					t.Fatal(err)
+				}
+			}
+			server.FastForward(tt.wait)
+
+			got, err := client.PopLastAction(7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if got != nil && (got.Type != tt.want.Type || got.EntityID != tt.want.EntityID) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			// Popping removes the action
+			again, err := client.PopLastAction(7)
+			if err != nil || again != nil {
+				t.Errorf("second pop = %+v, %v; want nothing", again, err)
+			}
+		})
+	}
+}
+
+func TestPopLastActionIsAtomic(t *testing.T) {
+	client, _ := newTestClient(t)
+	if err := client.SetLastAction(7, &LastAction{Type: "order_created", EntityID: 1}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	const callers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	popped := 0
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			action, err := client.PopLastAction(7)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if action != nil {
+				mu.Lock()
+				popped++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if popped != 1 {
+		t.Errorf("action popped %d times, want exactly once", popped)
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		held     string
+		wait     time.Duration
+		key      string
+		wantLock bool
+	}{
+		{name: "free lock", key: "reminders", wantLock: true},
+		{name: "held lock", held: "reminders", key: "reminders"},
+		{name: "expired lock", held: "reminders", wait: 2 * time.Minute, key: "reminders", wantLock: true},
+		{name: "locks are independent", held: "reminders", key: "escalation", wantLock: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			if tt.held != "" {
+				if ok, err := client.AcquireLock(tt.held, time.Minute); err != nil || !ok {
+					t.Fatalf("first acquire = %v, %v", ok, err)
+				}
+			}
+			server.FastForward(tt.wait)
+
+			ok, err := client.AcquireLock(tt.key, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantLock {
+				t.Errorf("acquired = %v, want %v", ok, tt.wantLock)
+			}
+			if ttl := server.TTL("lock:" + tt.key); ttl <= 0 || ttl > time.Minute {
+				t.Errorf("lock TTL = %v, want it to expire within a minute", ttl)
+			}
+		})
+	}
+}
+
+func TestAcquireLockConcurrently(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	const callers = 2
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	acquired := 0
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := client.AcquireLock("daily_jobs:2025-01-15", time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				acquired++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if acquired != 1 {
+		t.Errorf("lock acquired %d times, want exactly once", acquired)
+	}
+}
@@ -14,6 +14,7 @@ type FinancialRepository interface {
 	GetCalculationHistory(orderID uint) ([]models.CalculationHistory, error)
 	CreateReportQuery(query *models.ReportQuery) error
 	GetReportQuery(id uint) (*models.ReportQuery, error)
+	GetReportQueriesByUser(userID uint, limit int) ([]models.ReportQuery, error)
 }
 
 type financialRepository struct {
@@ -45,9 +46,10 @@ func (r *financialRepository) CreateCalculationHistory(history *models.Calculati
 	return r.db.Create(history).Error
 }
 
+// GetCalculationHistory returns an order's calculations, oldest first
 func (r *financialRepository) GetCalculationHistory(orderID uint) ([]models.CalculationHistory, error) {
 	var history []models.CalculationHistory
-	err := r.db.Where("order_id = ?", orderID).Find(&history).Error
+	err := r.db.Where("order_id = ?", orderID).Order("calculation_timestamp ASC, id ASC").Find(&history).Error
 	return history, err
 }
 
@@ -63,3 +65,10 @@ func (r *financialRepository) GetReportQuery(id uint) (*models.ReportQuery, erro
 	}
 	return &query, nil
 }
+
+// GetReportQueriesByUser returns a user's most recent report queries, newest first
+func (r *financialRepository) GetReportQueriesByUser(userID uint, limit int) ([]models.ReportQuery, error) {
+	var queries []models.ReportQuery
+	err := r.db.Where("user_id = ?", userID).Order("generated_at DESC, id DESC").Limit(limit).Find(&queries).Error
+	return queries, err
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"task_manager/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OrderNoteRepository interface {
+	Create(note *models.OrderNote) error
+	GetByOrderID(orderID uint) ([]models.OrderNote, error)
+}
+
+type orderNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderNoteRepository(db *gorm.DB) OrderNoteRepository {
+	return &orderNoteRepository{db: db}
+}
+
+func (r *orderNoteRepository) Create(note *models.OrderNote) error {
+	return r.db.Create(note).Error
+}
+
+// GetByOrderID returns an order's notes, oldest first
+func (r *orderNoteRepository) GetByOrderID(orderID uint) ([]models.OrderNote, error) {
+	var notes []models.OrderNote
+	err := r.db.Where("order_id = ?", orderID).Order("created_at ASC").Find(&notes).Error
+	return notes, err
+}
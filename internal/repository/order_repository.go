@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"task_manager/internal/clock"
 	"task_manager/internal/models"
+	"task_manager/pkg/whatsapp"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,26 +11,71 @@ import (
 
 type OrderRepository interface {
 	Create(order *models.Order) error
+	CreateWithItems(order *models.Order, items []models.OrderItem) error
 	GetByID(id uint) (*models.Order, error)
 	GetByUserID(userID uint) ([]models.Order, error)
-	GetByDateRange(startDate, endDate time.Time) ([]models.Order, error)
+	GetByDateRange(startDate, endDate time.Time, excludeStatuses ...string) ([]models.Order, error)
+	GetByStatus(status string) ([]models.Order, error)
+	SearchByCustomer(name string) ([]models.Order, error)
+	GetByCustomer(nameOrPhone string) ([]models.Order, error)
+	GetUpcomingDeliveries(from time.Time, within time.Duration) ([]models.Order, error)
 	Update(order *models.Order) error
 	Delete(id uint) error
+	DeleteWithItems(id uint) error
+	GetDeleted() ([]models.Order, error)
+	Restore(id uint) error
 	GetAll() ([]models.Order, error)
+	GetAllPaginated(offset, limit int) ([]models.Order, int64, error)
+	Search(filter OrderFilter) ([]models.Order, int64, error)
+	UpdateCustomerName(from, to string) (int64, error)
+	UpdateStatus(orderID uint, status string, itemStatus string) error
+	Cancel(orderID uint, history *models.CalculationHistory, note *models.OrderNote) error
+}
+
+// OrderFilter narrows an order search. Zero values leave a criterion out;
+// To is exclusive
+type OrderFilter struct {
+	Status    string
+	Customer  string
+	MinAmount *float64
+	MaxAmount *float64
+	From      *time.Time
+	To        *time.Time
+	CreatedBy *uint
+	Offset    int
+	Limit     int
 }
 
 type orderRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	clock clock.Clock
 }
 
-func NewOrderRepository(db *gorm.DB) OrderRepository {
-	return &orderRepository{db: db}
+func NewOrderRepository(db *gorm.DB, clk clock.Clock) OrderRepository {
+	return &orderRepository{db: db, clock: clock.OrReal(clk)}
 }
 
 func (r *orderRepository) Create(order *models.Order) error {
 	return r.db.Create(order).Error
 }
 
+// CreateWithItems inserts the order and its items in one transaction, so an
+// order is never left without the items it was created with
+func (r *orderRepository) CreateWithItems(order *models.Order, items []models.OrderItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].OrderID = order.ID
+			if err := tx.Create(&items[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *orderRepository) GetByID(id uint) (*models.Order, error) {
 	var order models.Order
 	err := r.db.First(&order, id).Error
@@ -44,12 +91,69 @@ func (r *orderRepository) GetByUserID(userID uint) ([]models.Order, error) {
 	return orders, err
 }
 
-func (r *orderRepository) GetByDateRange(startDate, endDate time.Time) ([]models.Order, error) {
+// GetByDateRange returns orders dated between startDate and endDate, leaving
+// out any whose status is in excludeStatuses. Soft-deleted orders are never
+// returned
+func (r *orderRepository) GetByDateRange(startDate, endDate time.Time, excludeStatuses ...string) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Where("order_date BETWEEN ? AND ?", startDate, endDate)
+	if len(excludeStatuses) > 0 {
+		query = query.Where("status NOT IN ?", excludeStatuses)
+	}
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepository) GetByStatus(status string) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Where("status = ?", status).Order("order_date DESC").Find(&orders).Error
+	return orders, err
+}
+
+// SearchByCustomer returns the orders of the customer with exactly this name,
+// ignoring case
+func (r *orderRepository) SearchByCustomer(name string) ([]models.Order, error) {
 	var orders []models.Order
-	err := r.db.Where("order_date BETWEEN ? AND ?", startDate, endDate).Find(&orders).Error
+	err := r.db.Where("LOWER(customer_name) = LOWER(?)", name).Order("order_date DESC").Find(&orders).Error
 	return orders, err
 }
 
+// GetByCustomer returns the orders whose customer name matches nameOrPhone,
+// ignoring case, or whose customer phone is the same number in any format
+func (r *orderRepository) GetByCustomer(nameOrPhone string) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Where("LOWER(customer_name) = LOWER(?)", nameOrPhone)
+	if isPhoneNumber(whatsapp.NormalizePhone(nameOrPhone)) {
+		query = query.Or("customer_phone IN ?", whatsapp.PhoneVariants(nameOrPhone))
+	}
+	err := query.Order("order_date DESC").Find(&orders).Error
+	return orders, err
+}
+
+// GetUpcomingDeliveries returns open orders due for delivery between from and
+// from+within, soonest first
+func (r *orderRepository) GetUpcomingDeliveries(from time.Time, within time.Duration) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Where("delivery_date >= ? AND delivery_date <= ?", from, from.Add(within)).
+		Where("status NOT IN ?", []string{string(models.OrderCompleted), string(models.OrderCancelled)}).
+		Order("delivery_date ASC").Find(&orders).Error
+	return orders, err
+}
+
+// isPhoneNumber reports whether a normalized value is all digits and long
+// enough to be a phone number rather than, say, a numeric customer name
+func isPhoneNumber(value string) bool {
+	if len(value) < 8 {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *orderRepository) Update(order *models.Order) error {
 	return r.db.Save(order).Error
 }
@@ -58,8 +162,154 @@ func (r *orderRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Order{}, id).Error
 }
 
+// DeleteWithItems deletes the order and its items in one transaction, so an
+// order is never left behind without the items it was created with
+func (r *orderRepository) DeleteWithItems(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("order_id = ?", id).Delete(&models.OrderItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Order{}, id).Error
+	})
+}
+
+// GetDeleted returns soft-deleted orders, most recently deleted first
+func (r *orderRepository) GetDeleted() ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&orders).Error
+	return orders, err
+}
+
+// Restore clears deleted_at on a soft-deleted order. It returns
+// gorm.ErrRecordNotFound when no deleted order has this ID
+func (r *orderRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&models.Order{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *orderRepository) GetAll() ([]models.Order, error) {
 	var orders []models.Order
 	err := r.db.Find(&orders).Error
 	return orders, err
 }
+
+// GetAllPaginated returns one page ordered by ID together with the total row count
+func (r *orderRepository) GetAllPaginated(offset, limit int) ([]models.Order, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.Order{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []models.Order
+	err := r.db.Order("id ASC").Offset(offset).Limit(limit).Find(&orders).Error
+	return orders, total, err
+}
+
+// UpdateStatus sets the order status and, when itemStatus is not empty, the
+// status of all its items in the same transaction
+func (r *orderRepository) UpdateStatus(orderID uint, status string, itemStatus string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := r.clock.Now()
+		result := tx.Model(&models.Order{}).Where("id = ?", orderID).Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if itemStatus == "" {
+			return nil
+		}
+		return tx.Model(&models.OrderItem{}).Where("order_id = ?", orderID).Updates(map[string]interface{}{
+			"status":     itemStatus,
+			"updated_at": now,
+		}).Error
+	})
+}
+
+// Cancel marks the order cancelled and writes its reversal history row and
+// note in one transaction, so a cancelled order always has its reversal
+func (r *orderRepository) Cancel(orderID uint, history *models.CalculationHistory, note *models.OrderNote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).Where("id = ?", orderID).Updates(map[string]interface{}{
+			"status":     string(models.OrderCancelled),
+			"updated_at": r.clock.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+		return tx.Create(note).Error
+	})
+}
+
+// UpdateCustomerName renames the customer on every order placed under from
+func (r *orderRepository) UpdateCustomerName(from, to string) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).Where("customer_name = ?", from).Updates(map[string]interface{}{
+			"customer_name": to,
+			"updated_at":    r.clock.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		return nil
+	})
+	return affected, err
+}
+
+// Search returns one page of orders matching filter, newest first, together
+// with the total number of matches
+func (r *orderRepository) Search(filter OrderFilter) ([]models.Order, int64, error) {
+	query := r.db.Model(&models.Order{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Customer != "" {
+		query = query.Where("customer_name ILIKE ?", "%"+filter.Customer+"%")
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("total_amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("total_amount <= ?", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		query = query.Where("order_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("order_date < ?", *filter.To)
+	}
+	if filter.CreatedBy != nil {
+		query = query.Where("created_by = ?", *filter.CreatedBy)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []models.Order
+	err := query.Order("order_date DESC, id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&orders).Error
+	return orders, total, err
+}
@@ -0,0 +1,655 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+func TestOrderRepositoryCancel(t *testing.T) {
+	errInsert := errors.New("insert failed")
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		want   error
+	}{
+		{
+			name: "commits status, history and note together",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "calculation_histories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO "order_notes"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "missing order rolls back",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			want: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "history failure rolls back the status change",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "calculation_histories"`).WillReturnError(errInsert)
+				mock.ExpectRollback()
+			},
+			want: errInsert,
+		},
+		{
+			name: "note failure rolls back the status change and history",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "calculation_histories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO "order_notes"`).WillReturnError(errInsert)
+				mock.ExpectRollback()
+			},
+			want: errInsert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			err := NewOrderRepository(db, nil).Cancel(1,
+				&models.CalculationHistory{OrderID: 1, CalculationType: "net_profit_reversal"},
+				&models.OrderNote{OrderID: 1, UserID: 2, Content: "Order cancelled"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryDeleteWithItems(t *testing.T) {
+	errDelete := errors.New("delete failed")
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		want   error
+	}{
+		{
+			name: "deletes items and order together",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "order_items" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`UPDATE "orders" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "order failure keeps the items",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "order_items" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`UPDATE "orders" SET "deleted_at"`).WillReturnError(errDelete)
+				mock.ExpectRollback()
+			},
+			want: errDelete,
+		},
+		{
+			name: "item failure keeps the order",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "order_items" SET "deleted_at"`).WillReturnError(errDelete)
+				mock.ExpectRollback()
+			},
+			want: errDelete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			if err := NewOrderRepository(db, nil).DeleteWithItems(3); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryGetAllPaginated(t *testing.T) {
+	tests := []struct {
+		name          string
+		offset, limit int
+		wantSQL       string
+	}{
+		{name: "first page", offset: 0, limit: 10, wantSQL: `SELECT \* FROM "orders" WHERE "orders"."deleted_at" IS NULL ORDER BY id ASC LIMIT 10$`},
+		{name: "third page", offset: 20, limit: 10, wantSQL: `SELECT \* FROM "orders" WHERE "orders"."deleted_at" IS NULL ORDER BY id ASC LIMIT 10 OFFSET 20$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "orders"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+			mock.ExpectQuery(tt.wantSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(tt.offset + 1))
+
+			orders, total, err := NewOrderRepository(db, nil).GetAllPaginated(tt.offset, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 25 || len(orders) != 1 || orders[0].ID != uint(tt.offset+1) {
+				t.Errorf("got %d orders of %d, want the page starting at %d of 25", len(orders), total, tt.offset+1)
+			}
+		})
+	}
+}
+
+func TestOrderRepositorySearch(t *testing.T) {
+	minAmount, maxAmount := 100000.0, 500000.0
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	creator := uint(7)
+
+	tests := []struct {
+		name      string
+		filter    OrderFilter
+		wantWhere string
+		wantArgs  []driver.Value
+		wantPage  string
+	}{
+		{
+			name:      "no criteria",
+			filter:    OrderFilter{Limit: 10},
+			wantWhere: `WHERE "orders"."deleted_at" IS NULL`,
+			wantPage:  `LIMIT 10$`,
+		},
+		{
+			name:      "status and customer",
+			filter:    OrderFilter{Status: "pending", Customer: "john", Limit: 10},
+			wantWhere: `WHERE status = \$1 AND customer_name ILIKE \$2 AND "orders"."deleted_at" IS NULL`,
+			wantArgs:  []driver.Value{"pending", "%john%"},
+			wantPage:  `LIMIT 10$`,
+		},
+		{
+			name:      "amount range",
+			filter:    OrderFilter{MinAmount: &minAmount, MaxAmount: &maxAmount, Offset: 10, Limit: 10},
+			wantWhere: `WHERE total_amount >= \$1 AND total_amount <= \$2 AND "orders"."deleted_at" IS NULL`,
+			wantArgs:  []driver.Value{minAmount, maxAmount},
+			wantPage:  `LIMIT 10 OFFSET 10$`,
+		},
+		{
+			name:      "date range for one creator",
+			filter:    OrderFilter{From: &from, To: &to, CreatedBy: &creator, Limit: 10},
+			wantWhere: `WHERE order_date >= \$1 AND order_date < \$2 AND created_by = \$3 AND "orders"."deleted_at" IS NULL`,
+			wantArgs:  []driver.Value{from, to, int64(creator)},
+			wantPage:  `LIMIT 10$`,
+		},
+		{
+			name:      "every criterion",
+			filter:    OrderFilter{Status: "completed", Customer: "ann", MinAmount: &minAmount, MaxAmount: &maxAmount, From: &from, To: &to, CreatedBy: &creator, Limit: 5},
+			wantWhere: `WHERE status = \$1 AND customer_name ILIKE \$2 AND total_amount >= \$3 AND total_amount <= \$4 AND order_date >= \$5 AND order_date < \$6 AND created_by = \$7 AND "orders"."deleted_at" IS NULL`,
+			wantArgs:  []driver.Value{"completed", "%ann%", minAmount, maxAmount, from, to, int64(creator)},
+			wantPage:  `LIMIT 5$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			count := mock.ExpectQuery(`SELECT count\(\*\) FROM "orders" ` + tt.wantWhere + `$`).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+			page := mock.ExpectQuery(`SELECT \* FROM "orders" ` + tt.wantWhere + ` ORDER BY order_date DESC, id DESC ` + tt.wantPage).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(2))
+			if tt.wantArgs != nil {
+				count.WithArgs(tt.wantArgs...)
+				page.WithArgs(tt.wantArgs...)
+			}
+
+			orders, total, err := NewOrderRepository(db, nil).Search(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 12 || len(orders) != 2 {
+				t.Errorf("got %d orders of %d, want 2 of 12", len(orders), total)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryUpdateCustomerName(t *testing.T) {
+	errUpdate := errors.New("update failed")
+
+	tests := []struct {
+		name      string
+		expect    func(mock sqlmock.Sqlmock)
+		wantCount int64
+		wantErr   error
+	}{
+		{
+			name: "renames all matching orders",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET "customer_name"=\$1,"updated_at"=\$2 WHERE customer_name = \$3 AND "orders"."deleted_at" IS NULL`).
+					WithArgs("John", sqlmock.AnyArg(), "Jon").WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			wantCount: 3,
+		},
+		{
+			name: "failure rolls back",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders"`).WillReturnError(errUpdate)
+				mock.ExpectRollback()
+			},
+			wantErr: errUpdate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			count, err := NewOrderRepository(db, nil).UpdateCustomerName("Jon", "John")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryUpdateStatus(t *testing.T) {
+	errUpdate := errors.New("update failed")
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		itemStatus string
+		expect     func(mock sqlmock.Sqlmock)
+		want       error
+	}{
+		{
+			name: "order only",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET "status"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+					WithArgs("processing", now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:       "cascades to the items",
+			itemStatus: "completed",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET "status"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "order_items" SET "status"=\$1,"updated_at"=\$2 WHERE order_id = \$3`).
+					WithArgs("completed", now, 1).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:       "missing order",
+			itemStatus: "completed",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			want: gorm.ErrRecordNotFound,
+		},
+		{
+			name:       "item failure rolls back the order",
+			itemStatus: "completed",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "order_items"`).WillReturnError(errUpdate)
+				mock.ExpectRollback()
+			},
+			want: errUpdate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			status := "processing"
+			if tt.itemStatus != "" {
+				status = "completed"
+			}
+			if err := NewOrderRepository(db, clock.NewFake(now)).UpdateStatus(1, status, tt.itemStatus); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryGetByStatus(t *testing.T) {
+	seeded := []struct {
+		id     int
+		status string
+	}{
+		{1, "pending"}, {2, "completed"}, {3, "pending"}, {4, "cancelled"}, {5, "processing"},
+	}
+
+	tests := []struct {
+		status  string
+		wantIDs []uint
+	}{
+		{status: "pending", wantIDs: []uint{3, 1}},
+		{status: "cancelled", wantIDs: []uint{4}},
+		{status: "completed", wantIDs: []uint{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			// The database does the filtering and ordering; hand back what it would
+			rows := sqlmock.NewRows([]string{"id", "status"})
+			for i := len(seeded) - 1; i >= 0; i-- {
+				if seeded[i].status == tt.status {
+					rows.AddRow(seeded[i].id, seeded[i].status)
+				}
+			}
+			mock.ExpectQuery(`SELECT \* FROM "orders" WHERE status = \$1 AND "orders"."deleted_at" IS NULL ORDER BY order_date DESC`).
+				WithArgs(tt.status).WillReturnRows(rows)
+
+			orders, err := NewOrderRepository(db, nil).GetByStatus(tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(orders) != len(tt.wantIDs) {
+				t.Fatalf("got %d orders, want %d", len(orders), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if orders[i].ID != id || orders[i].Status != tt.status {
+					t.Errorf("orders[%d] = #%d %s, want #%d %s", i, orders[i].ID, orders[i].Status, id, tt.status)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderRepositorySoftDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		run    func(r OrderRepository) error
+		want   error
+	}{
+		{
+			name: "delete only stamps deleted_at",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET "deleted_at"=\$1 WHERE "orders"."id" = \$2 AND "orders"."deleted_at" IS NULL`).
+					WithArgs(sqlmock.AnyArg(), 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(r OrderRepository) error { return r.Delete(5) },
+		},
+		{
+			name: "GetAll excludes deleted orders",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "orders" WHERE "orders"."deleted_at" IS NULL$`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			run: func(r OrderRepository) error { _, err := r.GetAll(); return err },
+		},
+		{
+			name: "GetByID misses a deleted order",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "orders" WHERE "orders"."id" = \$1 AND "orders"."deleted_at" IS NULL`).
+					WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			run:  func(r OrderRepository) error { _, err := r.GetByID(5); return err },
+			want: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "GetDeleted lists only deleted orders",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "orders" WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC$`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(5, time.Now()))
+			},
+			run: func(r OrderRepository) error {
+				orders, err := r.GetDeleted()
+				if err == nil && (len(orders) != 1 || orders[0].ID != 5) {
+					return errors.New("deleted order not listed")
+				}
+				return err
+			},
+		},
+		{
+			name: "Restore clears deleted_at",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET "deleted_at"=\$1,"updated_at"=\$2 WHERE id = \$3 AND deleted_at IS NOT NULL$`).
+					WithArgs(nil, sqlmock.AnyArg(), 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(r OrderRepository) error { return r.Restore(5) },
+		},
+		{
+			name: "Restore of an order that is not deleted",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "orders" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run:  func(r OrderRepository) error { return r.Restore(1) },
+			want: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			if err := tt.run(NewOrderRepository(db, nil)); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryGetByCustomer(t *testing.T) {
+	tests := []struct {
+		name     string
+		customer string
+		query    string
+		args     []driver.Value
+	}{
+		{
+			name:     "by name",
+			customer: "Siti Aminah",
+			query:    `SELECT * FROM "orders" WHERE LOWER(customer_name) = LOWER($1) AND "orders"."deleted_at" IS NULL ORDER BY order_date DESC`,
+			args:     []driver.Value{"Siti Aminah"},
+		},
+		{
+			// A number short enough to be a customer name is not a phone
+			name:     "numeric name",
+			customer: "7Eleven",
+			query:    `SELECT * FROM "orders" WHERE LOWER(customer_name) = LOWER($1) AND "orders"."deleted_at" IS NULL ORDER BY order_date DESC`,
+			args:     []driver.Value{"7Eleven"},
+		},
+		{
+			name:     "by local phone",
+			customer: "0812-3456-7890",
+			query:    `SELECT * FROM "orders" WHERE (LOWER(customer_name) = LOWER($1) OR customer_phone IN ($2,$3,$4,$5)) AND "orders"."deleted_at" IS NULL ORDER BY order_date DESC`,
+			args:     []driver.Value{"0812-3456-7890", "6281234567890", "+6281234567890", "081234567890", "81234567890"},
+		},
+		{
+			name:     "by international phone",
+			customer: "+62 812 3456 7890",
+			query:    `SELECT * FROM "orders" WHERE (LOWER(customer_name) = LOWER($1) OR customer_phone IN ($2,$3,$4,$5)) AND "orders"."deleted_at" IS NULL ORDER BY order_date DESC`,
+			args:     []driver.Value{"+62 812 3456 7890", "6281234567890", "+6281234567890", "081234567890", "81234567890"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "customer_name", "customer_phone"}).
+					AddRow(7, "Siti Aminah", "6281234567890").AddRow(2, "siti aminah", ""))
+
+			orders, err := NewOrderRepository(db, nil).GetByCustomer(tt.customer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(orders) != 2 || orders[0].ID != 7 || orders[1].ID != 2 {
+				t.Errorf("orders = %+v, want orders 7 and 2", orders)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryGetUpcomingDeliveries(t *testing.T) {
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT * FROM "orders" WHERE (delivery_date >= $1 AND delivery_date <= $2) AND status NOT IN ($3,$4) AND "orders"."deleted_at" IS NULL ORDER BY delivery_date ASC`)
+
+	tests := []struct {
+		name   string
+		within time.Duration
+		to     time.Time
+	}{
+		{name: "one day", within: 24 * time.Hour, to: from.AddDate(0, 0, 1)},
+		{name: "a week and a half", within: 7*24*time.Hour + 12*time.Hour, to: time.Date(2025, 1, 22, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(query).WithArgs(from, tt.to, "completed", "cancelled").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "delivery_date"}).AddRow(3, from))
+
+			orders, err := NewOrderRepository(db, nil).GetUpcomingDeliveries(from, tt.within)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(orders) != 1 || orders[0].ID != 3 || orders[0].DeliveryDate == nil || !orders[0].DeliveryDate.Equal(from) {
+				t.Errorf("orders = %+v, want order 3 delivered %s", orders, from)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryGetByDateRange(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	// Soft-deleted orders are always left out; cancelled ones only when asked
+	tests := []struct {
+		name    string
+		exclude []string
+		query   string
+		args    []driver.Value
+	}{
+		{
+			name:  "every status",
+			query: `SELECT * FROM "orders" WHERE (order_date BETWEEN $1 AND $2) AND "orders"."deleted_at" IS NULL`,
+			args:  []driver.Value{start, end},
+		},
+		{
+			name:    "without cancelled orders",
+			exclude: []string{"cancelled"},
+			query:   `SELECT * FROM "orders" WHERE (order_date BETWEEN $1 AND $2) AND status NOT IN ($3) AND "orders"."deleted_at" IS NULL`,
+			args:    []driver.Value{start, end, "cancelled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery("^" + regexp.QuoteMeta(tt.query) + "$").WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "completed"))
+
+			orders, err := NewOrderRepository(db, nil).GetByDateRange(start, end, tt.exclude...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(orders) != 1 || orders[0].ID != 1 {
+				t.Errorf("orders = %+v, want order 1", orders)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryCreateWithItems(t *testing.T) {
+	errInsert := errors.New("insert failed")
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		want   error
+	}{
+		{
+			name: "order and items commit together",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO "orders"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(`INSERT INTO "order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO "order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "order failure inserts no items",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO "orders"`).WillReturnError(errInsert)
+				mock.ExpectRollback()
+			},
+			want: errInsert,
+		},
+		{
+			name: "item failure rolls back the order",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO "orders"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(`INSERT INTO "order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO "order_items"`).WillReturnError(errInsert)
+				mock.ExpectRollback()
+			},
+			want: errInsert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			order := &models.Order{OrderNumber: "ORD-1", CustomerName: "Siti", TotalAmount: 150000}
+			items := []models.OrderItem{
+				{ItemName: "Kue Lapis", Quantity: 2, UnitPrice: 50000, TotalPrice: 100000},
+				{ItemName: "Bolu", Quantity: 1, UnitPrice: 50000, TotalPrice: 50000},
+			}
+			err := NewOrderRepository(db, nil).CreateWithItems(order, items)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (items[0].OrderID != 7 || items[1].OrderID != 7) {
+				t.Errorf("items = %+v, want both on order 7", items)
+			}
+		})
+	}
+}
@@ -10,7 +10,9 @@ import (
 type ReminderRepository interface {
 	Create(reminder *models.Reminder) error
 	GetByTaskID(taskID uint) ([]models.Reminder, error)
-	GetPendingReminders() ([]models.Reminder, error)
+	GetByUser(userID uint) ([]models.Reminder, error)
+	FindExisting(taskID uint, reminderType string, scheduledTime time.Time) (*models.Reminder, error)
+	GetPendingReminders(now time.Time) ([]models.Reminder, error)
 	Update(reminder *models.Reminder) error
 	Delete(id uint) error
 	MarkAsSent(id uint) error
@@ -34,9 +36,31 @@ func (r *reminderRepository) GetByTaskID(taskID uint) ([]models.Reminder, error)
 	return reminders, err
 }
 
-func (r *reminderRepository) GetPendingReminders() ([]models.Reminder, error) {
+// GetByUser returns the reminders of every task assigned to the user, earliest first
+func (r *reminderRepository) GetByUser(userID uint) ([]models.Reminder, error) {
 	var reminders []models.Reminder
-	err := r.db.Where("whatsapp_sent = ? AND scheduled_time <= ?", false, time.Now()).Find(&reminders).Error
+	err := r.db.Joins("JOIN tasks ON tasks.id = reminders.task_id AND tasks.deleted_at IS NULL").
+		Where("tasks.assigned_to = ?", userID).
+		Order("reminders.scheduled_time ASC").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+// FindExisting returns the unsent reminder for the task, type and time, if
+// any. A reminder that was already sent does not block scheduling it again
+func (r *reminderRepository) FindExisting(taskID uint, reminderType string, scheduledTime time.Time) (*models.Reminder, error) {
+	var reminder models.Reminder
+	err := r.db.Where("task_id = ? AND reminder_type = ? AND scheduled_time = ? AND whatsapp_sent = ?", taskID, reminderType, scheduledTime, false).First(&reminder).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// GetPendingReminders returns unsent reminders scheduled at or before now
+func (r *reminderRepository) GetPendingReminders(now time.Time) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.Where("whatsapp_sent = ? AND scheduled_time <= ?", false, now).Find(&reminders).Error
 	return reminders, err
 }
 
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"sync"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestReminderRepositoryFindExisting(t *testing.T) {
+	at := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+		want error
+	}{
+		{name: "unsent reminder found", rows: sqlmock.NewRows([]string{"id", "task_id"}).AddRow(7, 1)},
+		{name: "none pending", rows: sqlmock.NewRows([]string{"id"}), want: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT \* FROM "reminders" WHERE \(task_id = \$1 AND reminder_type = \$2 AND scheduled_time = \$3 AND whatsapp_sent = \$4\) AND "reminders"."deleted_at" IS NULL`).
+				WithArgs(1, "deadline", at, false).
+				WillReturnRows(tt.rows)
+
+			reminder, err := NewReminderRepository(db).FindExisting(1, "deadline", at)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && reminder.ID != 7 {
+				t.Errorf("reminder ID = %d, want 7", reminder.ID)
+			}
+		})
+	}
+}
+
+func TestReminderUniqueIndexIsPartial(t *testing.T) {
+	s, err := schema.Parse(&models.Reminder{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, ok := s.ParseIndexes()["idx_reminder_task_type_time"]
+	if !ok {
+		t.Fatal("idx_reminder_task_type_time is not declared")
+	}
+	if index.Class != "UNIQUE" {
+		t.Errorf("index class = %q, want UNIQUE", index.Class)
+	}
+	if index.Where != "deleted_at IS NULL AND whatsapp_sent = false" {
+		t.Errorf("index WHERE = %q, want only live, unsent reminders covered", index.Where)
+	}
+	var columns []string
+	for _, field := range index.Fields {
+		columns = append(columns, field.DBName)
+	}
+	if len(columns) != 3 || columns[0] != "task_id" || columns[1] != "reminder_type" || columns[2] != "scheduled_time" {
+		t.Errorf("index columns = %v", columns)
+	}
+}
+
+func TestReminderRepositoryGetByUser(t *testing.T) {
+	// Reminders belong to users through their task's assignee; other users'
+	// tasks and deleted tasks are filtered out by the join
+	query := regexp.QuoteMeta(`SELECT "reminders"."id","reminders"."task_id","reminders"."reminder_type","reminders"."scheduled_time","reminders"."whats_app_sent","reminders"."recurrence_pattern","reminders"."created_at","reminders"."deleted_at" FROM "reminders" JOIN tasks ON tasks.id = reminders.task_id AND tasks.deleted_at IS NULL WHERE tasks.assigned_to = $1 AND "reminders"."deleted_at" IS NULL ORDER BY reminders.scheduled_time ASC`)
+	at := time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		userID  uint
+		rows    *sqlmock.Rows
+		wantIDs []uint
+	}{
+		{
+			name:   "reminders across several tasks",
+			userID: 1,
+			rows: sqlmock.NewRows([]string{"id", "task_id", "scheduled_time"}).
+				AddRow(4, 3, at).
+				AddRow(1, 2, at.Add(time.Hour)).
+				AddRow(6, 3, at.Add(24*time.Hour)),
+			wantIDs: []uint{4, 1, 6},
+		},
+		{
+			name:    "another user's tasks",
+			userID:  2,
+			rows:    sqlmock.NewRows([]string{"id", "task_id", "scheduled_time"}).AddRow(5, 7, at),
+			wantIDs: []uint{5},
+		},
+		{
+			name:   "no reminders",
+			userID: 3,
+			rows:   sqlmock.NewRows([]string{"id"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(query).WithArgs(tt.userID).WillReturnRows(tt.rows)
+
+			reminders, err := NewReminderRepository(db).GetByUser(tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []uint
+			for _, r := range reminders {
+				ids = append(ids, r.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("reminder IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
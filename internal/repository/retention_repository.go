@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"task_manager/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetentionRepository removes history rows that are past their retention
+// period. Each method returns the number of rows deleted
+type RetentionRepository interface {
+	DeleteTaskProgressBefore(before time.Time) (int64, error)
+	DeleteCalculationHistoryBefore(before time.Time) (int64, error)
+	DeleteReportQueriesBefore(before time.Time) (int64, error)
+}
+
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+func (r *retentionRepository) DeleteTaskProgressBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.TaskProgress{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *retentionRepository) DeleteCalculationHistoryBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.CalculationHistory{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *retentionRepository) DeleteReportQueriesBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.ReportQuery{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRetentionRepositoryDeletesOnlyOlderRows(t *testing.T) {
+	cutoff := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		query  string
+		delete func(r RetentionRepository) (int64, error)
+	}{
+		{name: "task progress", query: `DELETE FROM "task_progresses" WHERE created_at < $1`, delete: func(r RetentionRepository) (int64, error) { return r.DeleteTaskProgressBefore(cutoff) }},
+		{name: "calculation history", query: `DELETE FROM "calculation_histories" WHERE created_at < $1`, delete: func(r RetentionRepository) (int64, error) { return r.DeleteCalculationHistoryBefore(cutoff) }},
+		{name: "report queries", query: `DELETE FROM "report_queries" WHERE created_at < $1`, delete: func(r RetentionRepository) (int64, error) { return r.DeleteReportQueriesBefore(cutoff) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(tt.query)).WithArgs(cutoff).WillReturnResult(sqlmock.NewResult(0, 4))
+			mock.ExpectCommit()
+
+			deleted, err := tt.delete(NewRetentionRepository(db))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 4 {
+				t.Errorf("deleted %d rows, want 4", deleted)
+			}
+		})
+	}
+}
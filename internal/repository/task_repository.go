@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"strings"
+	"task_manager/internal/clock"
 	"task_manager/internal/models"
 	"time"
 
@@ -9,28 +11,58 @@ import (
 
 type TaskRepository interface {
 	Create(task *models.Task) error
+	CreateBatch(tasks []*models.Task) error
 	GetByID(id uint) (*models.Task, error)
 	GetByUserID(userID uint) ([]models.Task, error)
+	GetByUserAndStatus(userID uint, status string) ([]models.Task, error)
+	GetByUserAndPriority(userID uint, priority string) ([]models.Task, error)
+	GetByCreator(creatorID uint) ([]models.Task, error)
+	Search(userID uint, keyword string, allUsers bool) ([]models.Task, error)
 	GetAll() ([]models.Task, error)
+	GetAllPaginated(offset, limit int) ([]models.Task, int64, error)
 	GetDailyTasks(userID uint, date time.Time) ([]models.Task, error)
+	GetWeeklyTasks(userID uint, weekEnd time.Time) ([]models.Task, error)
 	GetMonthlyTasks(userID uint, monthYear string) ([]models.Task, error)
+	GetByType(taskType string) ([]models.Task, error)
+	GetOpenDueBefore(before time.Time) ([]models.Task, error)
+	GetCompletedBetween(start, end time.Time) ([]models.Task, error)
+	UpdatePriority(taskID uint, priority string) error
+	UpdateStatus(taskID uint, status string) error
+	FindInBatches(batchSize int, fn func(tasks []models.Task) error) error
+	ResetProgressByType(taskType string) error
 	Update(task *models.Task) error
 	Delete(id uint) error
 	UpdateProgress(taskID uint, progress int, isImplemented bool, notes string, updatedBy uint) error
+	GetProgressHistory(taskID uint) ([]models.TaskProgress, error)
+	ReassignAll(fromID, toID uint) (int64, error)
 }
 
 type taskRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	clock clock.Clock
 }
 
-func NewTaskRepository(db *gorm.DB) TaskRepository {
-	return &taskRepository{db: db}
+func NewTaskRepository(db *gorm.DB, clk clock.Clock) TaskRepository {
+	return &taskRepository{db: db, clock: clock.OrReal(clk)}
 }
 
 func (r *taskRepository) Create(task *models.Task) error {
 	return r.db.Create(task).Error
 }
 
+// CreateBatch inserts all tasks in one transaction; if any insert fails none
+// of them are kept
+func (r *taskRepository) CreateBatch(tasks []*models.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, task := range tasks {
+			if err := tx.Create(task).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *taskRepository) GetByID(id uint) (*models.Task, error) {
 	var task models.Task
 	err := r.db.First(&task, id).Error
@@ -46,24 +78,93 @@ func (r *taskRepository) GetByUserID(userID uint) ([]models.Task, error) {
 	return tasks, err
 }
 
+func (r *taskRepository) GetByUserAndStatus(userID uint, status string) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("assigned_to = ? AND status = ?", userID, status).Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) GetByUserAndPriority(userID uint, priority string) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("assigned_to = ? AND priority = ?", userID, priority).Find(&tasks).Error
+	return tasks, err
+}
+
+// GetByCreator returns the tasks creatorID assigned, grouped by assignee
+func (r *taskRepository) GetByCreator(creatorID uint) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("created_by = ?", creatorID).Order("assigned_to ASC, id ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// Search finds tasks whose title or description contains keyword, ignoring
+// case. Only userID's tasks are searched unless allUsers is set
+func (r *taskRepository) Search(userID uint, keyword string, allUsers bool) ([]models.Task, error) {
+	var tasks []models.Task
+	pattern := "%" + likeEscaper.Replace(keyword) + "%"
+	query := r.db.Where("title ILIKE ? OR description ILIKE ?", pattern, pattern)
+	if !allUsers {
+		query = query.Where("assigned_to = ?", userID)
+	}
+	err := query.Order("id ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// likeEscaper makes LIKE wildcards in user input match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (r *taskRepository) GetAll() ([]models.Task, error) {
 	var tasks []models.Task
 	err := r.db.Find(&tasks).Error
 	return tasks, err
 }
 
+// FindInBatches walks all tasks in id order, handing them to fn batchSize
+// at a time so large tables are never loaded at once
+func (r *taskRepository) FindInBatches(batchSize int, fn func(tasks []models.Task) error) error {
+	var tasks []models.Task
+	return r.db.Order("id ASC").FindInBatches(&tasks, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(tasks)
+	}).Error
+}
+
 func (r *taskRepository) GetDailyTasks(userID uint, date time.Time) ([]models.Task, error) {
 	var tasks []models.Task
 	err := r.db.Where("assigned_to = ? AND task_type = ?", userID, "daily").Find(&tasks).Error
 	return tasks, err
 }
 
+// GetWeeklyTasks returns the user's weekly tasks that already existed before weekEnd
+func (r *taskRepository) GetWeeklyTasks(userID uint, weekEnd time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("assigned_to = ? AND task_type = ? AND created_at < ?", userID, "weekly", weekEnd).Find(&tasks).Error
+	return tasks, err
+}
+
 func (r *taskRepository) GetMonthlyTasks(userID uint, monthYear string) ([]models.Task, error) {
 	var tasks []models.Task
 	err := r.db.Where("assigned_to = ? AND task_type = ?", userID, "monthly").Find(&tasks).Error
 	return tasks, err
 }
 
+func (r *taskRepository) GetByType(taskType string) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("task_type = ?", taskType).Find(&tasks).Error
+	return tasks, err
+}
+
+// ResetProgressByType clears progress on every task of the given type so
+// recurring tasks start fresh for the new period
+func (r *taskRepository) ResetProgressByType(taskType string) error {
+	return r.db.Model(&models.Task{}).Where("task_type = ?", taskType).Updates(map[string]interface{}{
+		"completion_percentage": 0,
+		"is_implemented":        false,
+		"status":                string(models.Pending),
+		"completed_at":          nil,
+		"updated_at":            r.clock.Now(),
+	}).Error
+}
+
 func (r *taskRepository) Update(task *models.Task) error {
 	return r.db.Save(task).Error
 }
@@ -73,7 +174,7 @@ func (r *taskRepository) Delete(id uint) error {
 }
 
 func (r *taskRepository) UpdateProgress(taskID uint, progress int, isImplemented bool, notes string, updatedBy uint) error {
-	now := time.Now()
+	now := r.clock.Now()
 	
 	// Update main task
 	err := r.db.Model(&models.Task{}).Where("id = ?", taskID).Updates(map[string]interface{}{
@@ -100,3 +201,80 @@ func (r *taskRepository) UpdateProgress(taskID uint, progress int, isImplemented
 
 	return r.db.Create(progressRecord).Error
 }
+
+// GetProgressHistory returns the task's progress updates, oldest first
+func (r *taskRepository) GetProgressHistory(taskID uint) ([]models.TaskProgress, error) {
+	var history []models.TaskProgress
+	err := r.db.Where("task_id = ?", taskID).Order("updated_at ASC, id ASC").Find(&history).Error
+	return history, err
+}
+
+// UpdateStatus sets the task status, stamping completed_at when it completes
+func (r *taskRepository) UpdateStatus(taskID uint, status string) error {
+	now := r.clock.Now()
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": now,
+	}
+	if status == string(models.Completed) {
+		updates["completed_at"] = now
+	} else {
+		updates["completed_at"] = nil
+	}
+	return r.db.Model(&models.Task{}).Where("id = ?", taskID).Updates(updates).Error
+}
+
+// GetOpenDueBefore returns tasks that are not completed and are due at or
+// before the given time, including ones already past due
+func (r *taskRepository) GetOpenDueBefore(before time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("due_date IS NOT NULL AND due_date <= ? AND status <> ?", before, string(models.Completed)).
+		Order("due_date ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// GetCompletedBetween returns tasks completed at or after start and before end
+func (r *taskRepository) GetCompletedBetween(start, end time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Where("completed_at >= ? AND completed_at < ?", start, end).
+		Order("completed_at ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) UpdatePriority(taskID uint, priority string) error {
+	return r.db.Model(&models.Task{}).Where("id = ?", taskID).Updates(map[string]interface{}{
+		"priority":   priority,
+		"updated_at": r.clock.Now(),
+	}).Error
+}
+
+// ReassignAll moves every task that is not yet completed from one user to another
+func (r *taskRepository) ReassignAll(fromID, toID uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Task{}).
+			Where("assigned_to = ? AND status <> ?", fromID, string(models.Completed)).
+			Updates(map[string]interface{}{
+				"assigned_to": toID,
+				"updated_at":  r.clock.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		return nil
+	})
+	return affected, err
+}
+
+// GetAllPaginated returns one page ordered by ID together with the total row count
+func (r *taskRepository) GetAllPaginated(offset, limit int) ([]models.Task, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.Task{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tasks []models.Task
+	err := r.db.Order("id ASC").Offset(offset).Limit(limit).Find(&tasks).Error
+	return tasks, total, err
+}
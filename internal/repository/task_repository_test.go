@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTaskRepositoryReassignAll(t *testing.T) {
+	// Completed tasks are left with their original assignee by the status
+	// condition
+	query := regexp.QuoteMeta(`UPDATE "tasks" SET "assigned_to"=$1,"updated_at"=$2 WHERE (assigned_to = $3 AND status <> $4) AND "tasks"."deleted_at" IS NULL`)
+	dbErr := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		moved     int64
+		updateErr error
+		want      int64
+	}{
+		{name: "open tasks move", moved: 3, want: 3},
+		{name: "nothing open", moved: 0, want: 0},
+		{name: "failure rolls back", updateErr: dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			update := mock.ExpectExec(query).WithArgs(2, sqlmock.AnyArg(), 1, "completed")
+			if tt.updateErr != nil {
+				update.WillReturnError(tt.updateErr)
+				mock.ExpectRollback()
+			} else {
+				update.WillReturnResult(sqlmock.NewResult(0, tt.moved))
+				mock.ExpectCommit()
+			}
+
+			moved, err := NewTaskRepository(db, nil).ReassignAll(1, 2)
+			if !errors.Is(err, tt.updateErr) {
+				t.Fatalf("err = %v, want %v", err, tt.updateErr)
+			}
+			if moved != tt.want {
+				t.Errorf("moved %d tasks, want %d", moved, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskRepositoryFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		get   func(r TaskRepository) ([]models.Task, error)
+		arg   string
+	}{
+		{
+			name:  "by status",
+			query: `SELECT * FROM "tasks" WHERE (assigned_to = $1 AND status = $2) AND "tasks"."deleted_at" IS NULL`,
+			get:   func(r TaskRepository) ([]models.Task, error) { return r.GetByUserAndStatus(4, "blocked") },
+			arg:   "blocked",
+		},
+		{
+			name:  "by priority",
+			query: `SELECT * FROM "tasks" WHERE (assigned_to = $1 AND priority = $2) AND "tasks"."deleted_at" IS NULL`,
+			get:   func(r TaskRepository) ([]models.Task, error) { return r.GetByUserAndPriority(4, "urgent") },
+			arg:   "urgent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(4, tt.arg).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "assigned_to"}).AddRow(1, "Pack boxes", 4).AddRow(3, "Restock", 4))
+
+			tasks, err := tt.get(NewTaskRepository(db, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 2 || tasks[0].ID != 1 || tasks[1].ID != 3 {
+				t.Errorf("tasks = %+v, want tasks 1 and 3", tasks)
+			}
+		})
+	}
+}
+
+func TestTaskRepositorySearch(t *testing.T) {
+	// ILIKE does the case folding, so the keyword is passed through as typed
+	tests := []struct {
+		name     string
+		keyword  string
+		allUsers bool
+		query    string
+		args     []driver.Value
+	}{
+		{
+			name:    "own tasks",
+			keyword: "Laporan",
+			query:   `SELECT * FROM "tasks" WHERE (title ILIKE $1 OR description ILIKE $2) AND assigned_to = $3 AND "tasks"."deleted_at" IS NULL ORDER BY id ASC`,
+			args:    []driver.Value{"%Laporan%", "%Laporan%", 4},
+		},
+		{
+			name:     "all users",
+			keyword:  "laporan",
+			allUsers: true,
+			query:    `SELECT * FROM "tasks" WHERE (title ILIKE $1 OR description ILIKE $2) AND "tasks"."deleted_at" IS NULL ORDER BY id ASC`,
+			args:     []driver.Value{"%laporan%", "%laporan%"},
+		},
+		{
+			name:     "wildcards match literally",
+			keyword:  `50%_off\`,
+			allUsers: true,
+			query:    `SELECT * FROM "tasks" WHERE (title ILIKE $1 OR description ILIKE $2) AND "tasks"."deleted_at" IS NULL ORDER BY id ASC`,
+			args:     []driver.Value{`%50\%\_off\\%`, `%50\%\_off\\%`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "Laporan harian"))
+
+			tasks, err := NewTaskRepository(db, nil).Search(4, tt.keyword, tt.allUsers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 1 || tasks[0].ID != 2 {
+				t.Errorf("tasks = %+v, want task 2", tasks)
+			}
+		})
+	}
+}
+
+func TestTaskRepositoryGetCompletedBetween(t *testing.T) {
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE (completed_at >= $1 AND completed_at < $2) AND "tasks"."deleted_at" IS NULL ORDER BY completed_at ASC`)).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "completed_at"}).AddRow(2, start).AddRow(3, start.Add(time.Hour)))
+
+	tasks, err := NewTaskRepository(db, nil).GetCompletedBetween(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != 2 || tasks[1].ID != 3 {
+		t.Errorf("tasks = %+v, want tasks 2 and 3", tasks)
+	}
+}
+
+func TestTaskRepositoryUpdateStatusStampsClock(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		status        string
+		wantCompleted driver.Value
+	}{
+		{name: "completed stamps completed_at", status: "completed", wantCompleted: now},
+		{name: "reopened clears completed_at", status: "pending", wantCompleted: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "completed_at"=$1,"status"=$2,"updated_at"=$3 WHERE id = $4`)).
+				WithArgs(tt.wantCompleted, tt.status, now, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			if err := NewTaskRepository(db, clock.NewFake(now)).UpdateStatus(7, tt.status); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestTaskRepositoryCreateBatch(t *testing.T) {
+	insert := `INSERT INTO "tasks"`
+	dbErr := errors.New("foreign key violation")
+
+	tests := []struct {
+		name    string
+		failAt  int // 1-based insert that fails; 0 for none
+		wantIDs []uint
+	}{
+		{name: "every task kept", wantIDs: []uint{10, 11, 12}},
+		{name: "failure rolls back the batch", failAt: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			for i := 1; i <= 3; i++ {
+				query := mock.ExpectQuery(insert)
+				if i == tt.failAt {
+					query.WillReturnError(dbErr)
+					break
+				}
+				query.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9 + i))
+			}
+			if tt.failAt > 0 {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			tasks := []*models.Task{{Title: "Stock opname", AssignedTo: 2}, {Title: "Stock opname", AssignedTo: 3}, {Title: "Stock opname", AssignedTo: 4}}
+			err := NewTaskRepository(db, nil).CreateBatch(tasks)
+			if tt.failAt > 0 {
+				if !errors.Is(err, dbErr) {
+					t.Fatalf("err = %v, want %v", err, dbErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, task := range tasks {
+				if task.ID != tt.wantIDs[i] {
+					t.Errorf("task %d got ID %d, want %d", i, task.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRepositoryGetProgressHistory(t *testing.T) {
+	at := time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)
+
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "task_progresses" WHERE task_id = $1 ORDER BY updated_at ASC, id ASC`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "completion_percentage", "updated_at"}).
+			AddRow(1, 3, 20, at).
+			AddRow(2, 3, 60, at.Add(time.Hour)).
+			AddRow(3, 3, 100, at.Add(2*time.Hour)))
+
+	history, err := NewTaskRepository(db, nil).GetProgressHistory(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, entry := range history {
+		got = append(got, entry.CompletionPercentage)
+	}
+	if len(got) != 3 || got[0] != 20 || got[1] != 60 || got[2] != 100 {
+		t.Errorf("percentages = %v, want 20, 60, 100 in order", got)
+	}
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"task_manager/internal/models"
+	"task_manager/pkg/whatsapp"
+
+	"gorm.io/gorm"
+)
+
+type TenantRepository interface {
+	Create(tenant *models.Tenant) error
+	GetByWhatsAppNumber(whatsappNumber string) (*models.Tenant, error)
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) Create(tenant *models.Tenant) error {
+	tenant.WhatsAppNumber = whatsapp.NormalizePhone(tenant.WhatsAppNumber)
+	return r.db.Create(tenant).Error
+}
+
+// GetByWhatsAppNumber finds the tenant owning a gateway number, accepting any
+// of the number's stored formats
+func (r *tenantRepository) GetByWhatsAppNumber(whatsappNumber string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.Where("whatsapp_number IN ?", whatsapp.PhoneVariants(whatsappNumber)).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
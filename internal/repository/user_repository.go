@@ -2,6 +2,8 @@ package repository
 
 import (
 	"task_manager/internal/models"
+	"task_manager/pkg/whatsapp"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,11 +11,15 @@ import (
 type UserRepository interface {
 	Create(user *models.User) error
 	GetByID(id uint) (*models.User, error)
+	GetByIDs(ids []uint) ([]models.User, error)
 	GetByUsername(username string) (*models.User, error)
 	GetByWhatsAppNumber(whatsappNumber string) (*models.User, error)
 	GetAll() ([]models.User, error)
+	GetAllPaginated(offset, limit int) ([]models.User, int64, error)
 	Update(user *models.User) error
+	SetPasswordHash(id uint, hash string) error
 	Delete(id uint) error
+	TouchLastSeen(id uint, seenAt time.Time) (bool, error)
 }
 
 type userRepository struct {
@@ -37,6 +43,16 @@ func (r *userRepository) GetByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// GetByIDs loads several users in one query; IDs without a user are skipped
+func (r *userRepository) GetByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	var user models.User
 	err := r.db.Where("username = ?", username).First(&user).Error
@@ -46,13 +62,26 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	return &user, nil
 }
 
+// GetByWhatsAppNumber finds a user by WhatsApp number regardless of the
+// format it was stored or received in (62..., +62..., 08... or bare). An
+// exact canonical match is preferred over a legacy spelling
 func (r *userRepository) GetByWhatsAppNumber(whatsappNumber string) (*models.User, error) {
-	var user models.User
-	err := r.db.Where("whatsapp_number = ?", whatsappNumber).First(&user).Error
+	var users []models.User
+	variants := whatsapp.PhoneVariants(whatsappNumber)
+	err := r.db.Where("whatsapp_number IN ?", variants).Order("id ASC").Find(&users).Error
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	if len(users) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	for i := range users {
+		if users[i].WhatsAppNumber == variants[0] {
+			return &users[i], nil
+		}
+	}
+	return &users[0], nil
 }
 
 func (r *userRepository) GetAll() ([]models.User, error) {
@@ -61,10 +90,51 @@ func (r *userRepository) GetAll() ([]models.User, error) {
 	return users, err
 }
 
+// Update saves the user's profile. The password hash is left alone so a user
+// loaded without it cannot wipe it; use SetPasswordHash to change it
 func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit("password_hash").Save(user).Error
+}
+
+func (r *userRepository) SetPasswordHash(id uint, hash string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("password_hash", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
+
+// GetAllPaginated returns one page ordered by ID together with the total row count
+func (r *userRepository) GetAllPaginated(offset, limit int) ([]models.User, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.User{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := r.db.Order("id ASC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, total, err
+}
+
+// TouchLastSeen records that the user has just interacted. It reports true
+// only for the call that set last_seen_at for the first time, so concurrent
+// first messages cannot both be treated as the first interaction
+func (r *userRepository) TouchLastSeen(id uint, seenAt time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).Where("id = ? AND last_seen_at IS NULL", id).Update("last_seen_at", seenAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	err := r.db.Model(&models.User{}).Where("id = ?", id).Update("last_seen_at", seenAt).Error
+	return false, err
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+func TestUserRepositoryGetByWhatsAppNumber(t *testing.T) {
+	variants := []driver.Value{"628123456789", "+628123456789", "08123456789", "8123456789"}
+
+	tests := []struct {
+		name    string
+		query   string
+		stored  [][2]interface{}
+		wantID  uint
+		wantErr error
+	}{
+		{name: "local query finds a canonical number", query: "08123456789", stored: [][2]interface{}{{3, "628123456789"}}, wantID: 3},
+		{name: "canonical query finds a local number", query: "628123456789", stored: [][2]interface{}{{4, "08123456789"}}, wantID: 4},
+		{name: "plus query finds a bare number", query: "+62 812 3456 789", stored: [][2]interface{}{{5, "8123456789"}}, wantID: 5},
+		{name: "webhook jid finds a plus number", query: "628123456789@s.whatsapp.net", stored: [][2]interface{}{{6, "+628123456789"}}, wantID: 6},
+		{name: "canonical spelling wins", query: "08123456789", stored: [][2]interface{}{{2, "08123456789"}, {7, "628123456789"}}, wantID: 7},
+		{name: "no match", query: "08123456789", wantErr: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			rows := sqlmock.NewRows([]string{"id", "whatsapp_number"})
+			for _, row := range tt.stored {
+				rows.AddRow(row[0], row[1])
+			}
+			mock.ExpectQuery(`SELECT \* FROM "users" WHERE whatsapp_number IN \(\$1,\$2,\$3,\$4\) AND "users"."deleted_at" IS NULL ORDER BY id ASC`).
+				WithArgs(variants...).WillReturnRows(rows)
+
+			user, err := NewUserRepository(db).GetByWhatsAppNumber(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && user.ID != tt.wantID {
+				t.Errorf("found user %d, want %d", user.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryTouchLastSeen(t *testing.T) {
+	tests := []struct {
+		name      string
+		expect    func(mock sqlmock.Sqlmock)
+		wantFirst bool
+	}{
+		{
+			name: "first message",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "users" SET "last_seen_at"=\$1,"updated_at"=\$2 WHERE \(id = \$3 AND last_seen_at IS NULL\)`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantFirst: true,
+		},
+		{
+			name: "seen before",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`AND last_seen_at IS NULL`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "users" SET "last_seen_at"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			first, err := NewUserRepository(db).TouchLastSeen(1, time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first != tt.wantFirst {
+				t.Errorf("first = %v, want %v", first, tt.wantFirst)
+			}
+		})
+	}
+}
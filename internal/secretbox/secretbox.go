@@ -0,0 +1,84 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// prefix marks values sealed by a Box, so a plaintext left in the database
+// is recognised instead of being fed to the cipher
+const prefix = "enc:v1:"
+
+// minKeyLength keeps obviously guessable keys out
+const minKeyLength = 16
+
+var (
+	// ErrKeyTooShort is returned by New for keys under 16 characters
+	ErrKeyTooShort = errors.New("secret key must be at least 16 characters")
+	// ErrNotSealed is returned by Open for values a Box did not produce
+	ErrNotSealed = errors.New("value is not encrypted")
+	// ErrCorrupt is returned by Open when a value was altered or sealed with
+	// another key
+	ErrCorrupt = errors.New("encrypted value is corrupt or was sealed with another key")
+)
+
+// Box encrypts short secrets, such as gateway passwords, that have to be
+// stored but read back in plain text later. It uses AES-256-GCM with a key
+// derived from a configured passphrase
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New returns a Box keyed by key
+func New(key string) (*Box, error) {
+	if len(key) < minKeyLength {
+		return nil, ErrKeyTooShort
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext under a fresh nonce
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal
+func (b *Box) Open(sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", ErrNotSealed
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, prefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrCorrupt
+	}
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value looks like the output of Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
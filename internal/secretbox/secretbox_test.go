@@ -0,0 +1,79 @@
+package secretbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef"
+
+func TestSealOpen(t *testing.T) {
+	box, err := New(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plaintext := range []string{"gateway-pass", "", "kata sandi ✓"} {
+		sealed, err := box.Seal(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsSealed(sealed) || (plaintext != "" && strings.Contains(sealed, plaintext)) {
+			t.Errorf("Seal(%q) = %q does not hide the plaintext", plaintext, sealed)
+		}
+		got, err := box.Open(sealed)
+		if err != nil || got != plaintext {
+			t.Errorf("Open(Seal(%q)) = %q, %v", plaintext, got, err)
+		}
+	}
+
+	a, _ := box.Seal("same")
+	b, _ := box.Seal("same")
+	if a == b {
+		t.Error("sealing twice gave the same ciphertext; nonces are reused")
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	box, err := New(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := New("another key of enough length")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := box.Seal("gateway-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := sealed[:len(sealed)-4] + "AAA="
+
+	tests := []struct {
+		name  string
+		box   *Box
+		value string
+		want  error
+	}{
+		{name: "plaintext", box: box, value: "gateway-pass", want: ErrNotSealed},
+		{name: "other key", box: other, value: sealed, want: ErrCorrupt},
+		{name: "tampered", box: box, value: tampered, want: ErrCorrupt},
+		{name: "not base64", box: box, value: prefix + "!!!", want: ErrCorrupt},
+		{name: "too short", box: box, value: prefix + "AAAA", want: ErrCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.box.Open(tt.value); !errors.Is(err, tt.want) {
+				t.Errorf("Open() err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsShortKey(t *testing.T) {
+	if _, err := New("short"); !errors.Is(err, ErrKeyTooShort) {
+		t.Errorf("New(short) err = %v, want ErrKeyTooShort", err)
+	}
+}
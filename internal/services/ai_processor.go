@@ -2,6 +2,8 @@ package services
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,6 +25,7 @@ type AIProcessor interface {
 	GetChatHistory(userID string) ([]ChatMessage, error)
 	SaveChatMessage(userID string, role string, content string) error
 	ClearChatHistory(userID string) error
+	HistoryConfig() HistoryConfig
 }
 
 type ChatMessage struct {
@@ -31,18 +34,153 @@ type ChatMessage struct {
 	Time    int64  `json:"time"`
 }
 
+// OpenAIConfig holds the chat completion parameters sent to OpenAI. CacheTTL
+// is how long a response is reused for an identical message in the same
+// conversation; zero disables the cache
+type OpenAIConfig struct {
+	Model       string
+	MaxTokens   int
+	Temperature float64
+	CacheTTL    time.Duration
+}
+
+// DefaultOpenAIConfig returns the parameters used when none are configured
+func DefaultOpenAIConfig() OpenAIConfig {
+	return OpenAIConfig{
+		Model:       "gpt-3.5-turbo",
+		MaxTokens:   500,
+		Temperature: 0.1,
+	}
+}
+
+// OpenAIError is returned when the OpenAI API rejects a request, carrying the
+// HTTP status and the API's error object so callers can tell failures apart
+type OpenAIError struct {
+	StatusCode int
+	Type       string
+	Code       string
+	Message    string
+}
+
+func (e *OpenAIError) Error() string {
+	return fmt.Sprintf("openai error (status %d, type %s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// IsQuotaExceeded reports whether the account has run out of credit
+func (e *OpenAIError) IsQuotaExceeded() bool {
+	return e.Type == "insufficient_quota" || e.Code == "insufficient_quota"
+}
+
+// IsRateLimited reports whether the request was throttled and may succeed later
+func (e *OpenAIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests && !e.IsQuotaExceeded()
+}
+
+// IsAuthError reports whether the API key was missing or invalid
+func (e *OpenAIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.Code == "invalid_api_key"
+}
+
+// IsBadRequest reports whether OpenAI rejected the request itself
+func (e *OpenAIError) IsBadRequest() bool {
+	return e.StatusCode == http.StatusBadRequest || e.Type == "invalid_request_error"
+}
+
+type openAIErrorBody struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Code    interface{} `json:"code"`
+}
+
+func (b *openAIErrorBody) toError(statusCode int) *OpenAIError {
+	code := ""
+	if b.Code != nil {
+		code = fmt.Sprintf("%v", b.Code)
+	}
+	return &OpenAIError{
+		StatusCode: statusCode,
+		Type:       b.Type,
+		Code:       code,
+		Message:    b.Message,
+	}
+}
+
+// parseOpenAIError builds an OpenAIError from a non-2xx response body
+func parseOpenAIError(statusCode int, body []byte) *OpenAIError {
+	var errorResponse struct {
+		Error *openAIErrorBody `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorResponse); err != nil || errorResponse.Error == nil {
+		return &OpenAIError{
+			StatusCode: statusCode,
+			Message:    strings.TrimSpace(string(body)),
+		}
+	}
+	return errorResponse.Error.toError(statusCode)
+}
+
+// HistoryConfig controls how much AI chat history is kept per user. MaxChars
+// caps the history content sent along with each request
+type HistoryConfig struct {
+	Size     int
+	TTL      time.Duration
+	MaxChars int
+}
+
+// DefaultHistoryConfig keeps the last 3 messages for 10 minutes and sends at
+// most 2000 characters of them
+func DefaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{
+		Size:     3,
+		TTL:      10 * time.Minute,
+		MaxChars: 2000,
+	}
+}
+
+// openAIChatURL is the chat completions endpoint
+const openAIChatURL = "https://api.openai.com/v1/chat/completions"
+
 type aiProcessor struct {
-	apiKey string
-	redis  *redis.Client
+	apiKey   string
+	redis    *redis.Client
+	openAI   OpenAIConfig
+	history  HistoryConfig
+	endpoint string
 }
 
-func NewAIProcessor(apiKey string, redisClient *redis.Client) AIProcessor {
+func NewAIProcessor(apiKey string, redisClient *redis.Client, openAI OpenAIConfig, history HistoryConfig) AIProcessor {
+	defaults := DefaultOpenAIConfig()
+	if openAI.Model == "" {
+		openAI.Model = defaults.Model
+	}
+	if openAI.MaxTokens <= 0 {
+		openAI.MaxTokens = defaults.MaxTokens
+	}
+
+	historyDefaults := DefaultHistoryConfig()
+	if history.Size <= 0 {
+		history.Size = historyDefaults.Size
+	}
+	if history.TTL <= 0 {
+		history.TTL = historyDefaults.TTL
+	}
+	if history.MaxChars <= 0 {
+		history.MaxChars = historyDefaults.MaxChars
+	}
+
 	return &aiProcessor{
-		apiKey: apiKey,
-		redis:  redisClient,
+		apiKey:   apiKey,
+		redis:    redisClient,
+		openAI:   openAI,
+		history:  history,
+		endpoint: openAIChatURL,
 	}
 }
 
+func (a *aiProcessor) HistoryConfig() HistoryConfig {
+	return a.history
+}
+
 // ParseOrderMessage processes natural language order messages
 func (a *aiProcessor) ParseOrderMessage(message string) (*models.Order, []models.OrderItem, error) {
 	// Extract order information using regex patterns
@@ -70,7 +208,7 @@ func (a *aiProcessor) ParseOrderMessage(message string) (*models.Order, []models
 	}
 	
 	// Set default values
-	order.Status = "pending"
+	order.Status = string(models.OrderPending)
 	order.OrderDate = time.Now()
 	
 	return order, items, nil
@@ -183,6 +321,11 @@ func (a *aiProcessor) ProcessWithOpenAI(message string, userID string) (string,
 		chatHistory = []ChatMessage{}
 	}
 
+	// Reuse the answer to an identical message in the same context
+	if content, ok := a.cachedResponse(userID, message, chatHistory); ok {
+		return classifyContent(content), content, nil
+	}
+
 	// Build messages array with system prompt and chat history
 	messages := []map[string]string{
 		{
@@ -191,16 +334,16 @@ func (a *aiProcessor) ProcessWithOpenAI(message string, userID string) (string,
 
 MESSAGE TYPES TO DETECT:
 1. add_user - "tambahkan user [username] [email] [phone] [role]", "/add_user"
-2. create_order - "buat order [customer_name] [total_amount]", "/create_order" 
+2. create_order - "buat order [customer_name] [total_amount]", "buat order [customer_name] [total_amount] hp [customer_phone]", "buat order [customer_name] [total_amount] tanpa biaya marketing", "/create_order [customer_name] [total_amount] tax:[n] marketing:[n] rental:[n]" 
 3. create_order_with_item - "buat order [customer] total [amount] item [item_name] [quantity] harga [price]"
-4. assign_task - "assign task [title] [description] to [username]", "/assign_task"
-5. view_tasks - "lihat tasks saya", "lihat task saya", "show my tasks", "show my task", "/my_tasks", "/my_daily_tasks", "/my_monthly_tasks"
+4. assign_task - "assign task [title] [description] to [username]", "assign task [title] [description] to [username] due [date]", "assign urgent task [title] [description] to [username]", "/assign_task"
+5. view_tasks - "lihat tasks saya", "lihat task saya", "show my tasks", "show my task", "/my_tasks", "/my_daily_tasks", "/my_monthly_tasks" (no status or priority mentioned)
 6. view_orders - "lihat orders", "lihat order", "show orders", "show order", "list order", "list orders", "/view_orders"
 7. list_users - "list user", "lihat users", "show users", "daftar user", "/list_users"
 8. list_tasks - "/list_tasks"
 9. add_order_item - "tambah item [order_id] [item_name] [quantity] [price] [description]"
 10. view_order_items - "lihat items order [order_id]", "show order items [order_id]"
-11. create_reminder - "buat reminder [task_id] [reminder_type] [scheduled_time]", "/create_reminder"
+11. create_reminder - "buat reminder [task_id] [reminder_type] [scheduled_time]", "ingatkan setiap hari/minggu" (recurrence: daily or weekly), "/create_reminder"
 12. view_reminders - "lihat reminders", "lihat reminder", "show reminders", "show reminder", "/view_reminders"
 13. update_progress - "/update_progress"
 14. mark_complete - "/mark_complete"
@@ -209,11 +352,34 @@ MESSAGE TYPES TO DETECT:
 17. clear_history - "/clear_history"
 18. show_history - "/show_history"
 19. help - "/help"
-20. general - greetings, questions, general chat
+20. clone_task - "clone task [task_id]", "duplikat task [task_id] untuk [username]", "/clone_task"
+21. delete_user - "hapus user [username]", "delete user [username]", "/delete_user"
+22. set_role - "ubah role [username] jadi [role]", "set role [username] [role]", "/set_role"
+23. view_user_tasks - "lihat task [username]", "tasks milik [username]", "show [username]'s tasks", "/user_tasks"
+24. search_orders - "cari order [filters]", "search orders [filters]", "/search_orders"
+25. delete_task - "hapus task [task_id]", "delete task [task_id]", "/delete_task"
+26. update_order_status - "ubah status order [order_id] jadi [status]", "update order [order_id] status [status]", "/update_order_status" (status: pending, processing or completed; cancelling is cancel_order)
+27. orders_by_status - "order yang pending", "tampilkan order cancelled", "orders with status [status]", "/orders_by_status"
+28. customer_summary - "ringkasan customer [nama]", "total order customer [nama]", "customer summary for [name]", "/customer_summary"
+29. view_tasks_by_status - "lihat task pending saya", "task saya yang blocked", "show my in progress tasks", "/tasks_by_status"
+30. view_tasks_by_priority - "lihat task urgent saya", "task prioritas tinggi saya", "show my high priority tasks", "/tasks_by_priority"
+31. order_history - "riwayat kalkulasi order [id]", "history order [id]", "calculation history for order [id]", "/order_history"
+32. create_weekly_task - "buat task mingguan [title] [description] untuk [username]", "weekly task [title] [description] for [username]", "/create_weekly_task"
+33. my_assigned_tasks - "task yang saya tugaskan", "tasks I assigned", "/my_assigned_tasks"
+34. search_tasks - "cari task [keyword]", "search tasks [keyword]", "task tentang [keyword]", "/search_tasks"
+35. orders_for_customer - "order milik [nama/nomor hp]", "orders for customer [name or phone]", "/orders_for_customer"
+36. upcoming_deliveries - "pengiriman minggu ini", "kiriman [n] hari ke depan", "upcoming deliveries", "/upcoming_deliveries"
+37. whoami - "/whoami", "siapa saya", "role saya apa", "who am i"
+38. cancel_order - "batalkan order [order_id] [alasan]", "cancel order", "/cancel_order"
+39. assign_task_bulk - "tugaskan [title] ke [user1], [user2] dan [user3]", "assign task to several users", "/assign_task_bulk"
+40. task_progress_history - "riwayat progress task [task_id]", "progress history task", "/task_progress_history"
+41. my_orders - "my orders", "order saya", "order yang saya buat", "/my_orders"
+42. all_orders - "all orders", "semua order", "order semua user", "/all_orders" (Admin/Super Admin)
+43. general - greetings, questions, general chat
 
 RESPONSE FORMAT (JSON only):
 {
-  "type": "add_user|create_order|create_order_with_item|assign_task|view_tasks|view_orders|list_users|list_tasks|add_order_item|view_order_items|create_reminder|view_reminders|update_progress|mark_complete|my_report|report_by_date|clear_history|show_history|help|general",
+  "type": "add_user|create_order|create_order_with_item|assign_task|view_tasks|view_orders|list_users|list_tasks|add_order_item|view_order_items|create_reminder|view_reminders|update_progress|mark_complete|my_report|report_by_date|clear_history|show_history|help|clone_task|delete_user|set_role|view_user_tasks|search_orders|delete_task|update_order_status|orders_by_status|customer_summary|view_tasks_by_status|view_tasks_by_priority|order_history|create_weekly_task|my_assigned_tasks|search_tasks|orders_for_customer|upcoming_deliveries|whoami|cancel_order|assign_task_bulk|task_progress_history|my_orders|all_orders|general",
   "data": {
     "username": "string",
     "email": "string", 
@@ -230,7 +396,22 @@ RESPONSE FORMAT (JSON only):
     "price": "number",
     "task_id": "number",
     "reminder_type": "string",
-    "scheduled_time": "string"
+    "scheduled_time": "string",
+    "due_date": "YYYY-MM-DD|today|tomorrow|next week",
+    "page": "number",
+    "filters": "status:<s> customer:<name> min:<n> max:<n> from:YYYY-MM-DD to:YYYY-MM-DD",
+    "status": "orders: pending|processing|completed|cancelled, tasks: pending|in_progress|completed|overdue|blocked",
+    "priority": "low|medium|high|urgent",
+    "keyword": "string",
+    "customer_phone": "string",
+    "delivery_date": "YYYY-MM-DD|today|tomorrow|next week",
+    "days": "number",
+    "recurrence": "none|daily|weekly",
+    "reason": "string",
+    "assignees": "comma separated usernames",
+    "tax_rate": "number (percent, only when the order overrides the default)",
+    "marketing_rate": "number (percent, only when the order overrides the default)",
+    "rental_rate": "number (percent, only when the order overrides the default)"
   },
   "message": "Friendly response message"
 }
@@ -242,9 +423,27 @@ Output: {"type":"add_user","data":{"username":"ega","email":"egatryagung@gmail.c
 Input: "buat order John Doe 1000000"
 Output: {"type":"create_order","data":{"customer_name":"John Doe","total_amount":1000000},"message":"I'll create an order for John Doe with total 1000000"}
 
+Input: "buat order Rina 500000 kirim lusa"
+Output: {"type":"create_order","data":{"customer_name":"Rina","total_amount":500000,"delivery_date":"lusa"},"message":"I'll create an order for Rina with total 500000, delivered lusa"}
+
+Input: "buat order Siti 250000 hp 081234567890"
+Output: {"type":"create_order","data":{"customer_name":"Siti","total_amount":250000,"customer_phone":"081234567890"},"message":"I'll create an order for Siti with total 250000"}
+
+Input: "buat order promo Andi 300000 tanpa biaya marketing"
+Output: {"type":"create_order","data":{"customer_name":"Andi","total_amount":300000,"marketing_rate":0},"message":"I'll create an order for Andi with total 300000 and no marketing cost"}
+
+Input: "/create_order Dewi 400000 tax:5 rental:0"
+Output: {"type":"create_order","data":{"customer_name":"Dewi","total_amount":400000,"tax_rate":5,"rental_rate":0},"message":"I'll create an order for Dewi with total 400000 using custom rates"}
+
 Input: "buatkan order jhon total 10000 item ayam goreng 1 harga 10000"
 Output: {"type":"create_order_with_item","data":{"customer_name":"jhon","total_amount":10000,"item_name":"ayam goreng","quantity":1,"price":10000},"message":"I'll create an order for jhon with ayam goreng item"}
 
+Input: "assign task Laporan buat laporan bulanan to budi besok"
+Output: {"type":"assign_task","data":{"title":"Laporan","description":"buat laporan bulanan","assigned_to":"budi","due_date":"tomorrow"},"message":"I'll assign task Laporan to budi, due tomorrow"}
+
+Input: "assign urgent task Server fix the login outage to andi"
+Output: {"type":"assign_task","data":{"title":"Server","description":"fix the login outage","assigned_to":"andi","priority":"urgent"},"message":"I'll assign urgent task Server to andi"}
+
 Input: "list user"
 Output: {"type":"list_users","data":{},"message":"I'll show you the list of users"}
 
@@ -260,6 +459,9 @@ Output: {"type":"view_tasks","data":{},"message":"I'll show you your tasks"}
 Input: "/list_tasks"
 Output: {"type":"list_tasks","data":{},"message":"I'll show you all tasks in the system"}
 
+Input: "list user halaman 2"
+Output: {"type":"list_users","data":{"page":2},"message":"I'll show you page 2 of the users"}
+
 Input: "/update_progress"
 Output: {"type":"update_progress","data":{},"message":"I'll help you update task progress"}
 
@@ -278,9 +480,84 @@ Output: {"type":"view_order_items","data":{"order_id":1},"message":"I'll show yo
 Input: "buat reminder 1 deadline 2025-10-05 10:00"
 Output: {"type":"create_reminder","data":{"task_id":1,"reminder_type":"deadline","scheduled_time":"2025-10-05 10:00"},"message":"I'll create a deadline reminder for task 1"}
 
+Input: "ingatkan task 3 besok jam 9"
+Output: {"type":"create_reminder","data":{"task_id":3,"reminder_type":"progress","scheduled_time":"besok jam 9"},"message":"I'll remind you about task 3 tomorrow at 9"}
+
+Input: "ingatkan task 4 setiap pagi jam 8"
+Output: {"type":"create_reminder","data":{"task_id":4,"reminder_type":"progress","scheduled_time":"besok jam 8","recurrence":"daily"},"message":"I'll remind you about task 4 every morning at 8"}
+
 Input: "lihat reminders"
 Output: {"type":"view_reminders","data":{},"message":"I'll show you all reminders"}
 
+Input: "clone task 5 untuk budi"
+Output: {"type":"clone_task","data":{"task_id":5,"assigned_to":"budi"},"message":"I'll clone task 5 for budi"}
+
+Input: "hapus user john"
+Output: {"type":"delete_user","data":{"username":"john"},"message":"I'll delete user john"}
+
+Input: "ubah role john jadi admin"
+Output: {"type":"set_role","data":{"username":"john","role":"Admin"},"message":"I'll change john's role to Admin"}
+
+Input: "show me john's tasks"
+Output: {"type":"view_user_tasks","data":{"assigned_to":"john"},"message":"I'll show the tasks assigned to john"}
+
+Input: "cari order pending customer john minimal 100000"
+Output: {"type":"search_orders","data":{"filters":"status:pending customer:john min:100000"},"message":"I'll search pending orders from john of at least 100000"}
+
+Input: "hapus task 7"
+Output: {"type":"delete_task","data":{"task_id":7},"message":"I'll delete task 7"}
+
+Input: "ubah status order 12 jadi completed"
+Output: {"type":"update_order_status","data":{"order_id":12,"status":"completed"},"message":"I'll mark order 12 as completed"}
+
+Input: "tampilkan order yang masih pending"
+Output: {"type":"orders_by_status","data":{"status":"pending"},"message":"Here are the pending orders"}
+
+Input: "berapa total order dari budi santoso?"
+Output: {"type":"customer_summary","data":{"customer_name":"budi santoso"},"message":"Here is the summary for budi santoso"}
+
+Input: "lihat task saya yang masih pending"
+Output: {"type":"view_tasks_by_status","data":{"status":"pending"},"message":"Here are your pending tasks"}
+
+Input: "lihat task urgent saya"
+Output: {"type":"view_tasks_by_priority","data":{"priority":"urgent"},"message":"Here are your urgent tasks"}
+
+Input: "riwayat kalkulasi order 12"
+Output: {"type":"order_history","data":{"order_id":12},"message":"Here is the calculation history for order 12"}
+
+Input: "buat task mingguan Rekap rekap penjualan minggu ini untuk sari"
+Output: {"type":"create_weekly_task","data":{"title":"Rekap","description":"rekap penjualan minggu ini","assigned_to":"sari"},"message":"I'll create a weekly task Rekap for sari"}
+
+Input: "task yang saya tugaskan"
+Output: {"type":"my_assigned_tasks","data":{},"message":"Here are the tasks you assigned"}
+
+Input: "cari task laporan"
+Output: {"type":"search_tasks","data":{"keyword":"laporan"},"message":"Searching tasks for laporan"}
+
+Input: "order milik 081234567890"
+Output: {"type":"orders_for_customer","data":{"customer_phone":"081234567890"},"message":"Here are the orders for 081234567890"}
+
+Input: "kiriman 3 hari ke depan"
+Output: {"type":"upcoming_deliveries","data":{"days":3},"message":"Here are the deliveries for the next 3 days"}
+
+Input: "siapa saya?"
+Output: {"type":"whoami","data":{},"message":"Checking who you are"}
+
+Input: "batalkan order 12 karena customer tidak jadi"
+Output: {"type":"cancel_order","data":{"order_id":12,"reason":"customer tidak jadi"},"message":"I'll cancel order 12"}
+
+Input: "tugaskan stock opname ke andi, budi dan citra"
+Output: {"type":"assign_task_bulk","data":{"title":"Stock opname","description":"Stock opname","assignees":"andi,budi,citra"},"message":"I'll assign Stock opname to andi, budi and citra"}
+
+Input: "riwayat progress task 7"
+Output: {"type":"task_progress_history","data":{"task_id":7},"message":"I'll show the progress history of task 7"}
+
+Input: "order saya"
+Output: {"type":"my_orders","data":{},"message":"Berikut order yang Anda buat"}
+
+Input: "tampilkan semua order"
+Output: {"type":"all_orders","data":{},"message":"Berikut semua order dari semua user"}
+
 Input: "halo"
 Output: {"type":"general","data":{},"message":"Hello! How can I help you today?"}
 
@@ -292,10 +569,11 @@ IMPORTANT: Always return valid JSON format only. No additional text.`,
 	}
 
 	// Add chat history (in reverse order to maintain chronological order)
-	for i := len(chatHistory) - 1; i >= 0; i-- {
+	budgeted := budgetHistory(chatHistory, a.history.MaxChars)
+	for i := len(budgeted) - 1; i >= 0; i-- {
 		messages = append(messages, map[string]string{
-			"role":    chatHistory[i].Role,
-			"content": chatHistory[i].Content,
+			"role":    budgeted[i].Role,
+			"content": budgeted[i].Content,
 		})
 	}
 
@@ -306,19 +584,12 @@ IMPORTANT: Always return valid JSON format only. No additional text.`,
 	})
 
 	// OpenAI API request
-	requestBody := map[string]interface{}{
-		"model":       "gpt-3.5-turbo",
-		"messages":    messages,
-		"max_tokens":  500,
-		"temperature": 0.1,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
+	jsonData, err := a.buildRequestBody(messages)
 	if err != nil {
 		return "", nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", a.endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", nil, err
 	}
@@ -338,18 +609,27 @@ IMPORTANT: Always return valid JSON format only. No additional text.`,
 		return "", nil, err
 	}
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", nil, parseOpenAIError(resp.StatusCode, body)
+	}
+
 	var openAIResponse struct {
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Error *openAIErrorBody `json:"error"`
 	}
 
 	if err := json.Unmarshal(body, &openAIResponse); err != nil {
 		return "", nil, err
 	}
 
+	if openAIResponse.Error != nil {
+		return "", nil, openAIResponse.Error.toError(resp.StatusCode)
+	}
+
 	if len(openAIResponse.Choices) == 0 {
 		return "", nil, fmt.Errorf("no response from OpenAI")
 	}
@@ -360,23 +640,87 @@ IMPORTANT: Always return valid JSON format only. No additional text.`,
 	// Save user message and AI response to chat history
 	a.SaveChatMessage(userID, "user", message)
 	a.SaveChatMessage(userID, "assistant", content)
+
+	// Cache against the updated history, which is the context a repeat of
+	// this message will arrive in
+	if updatedHistory, err := a.GetChatHistory(userID); err == nil {
+		a.cacheResponse(userID, message, updatedHistory, content)
+	}
 	
+	return classifyContent(content), content, nil
+}
+
+// budgetHistory keeps the newest messages of history (newest first) whose
+// content fits in maxChars, cutting the message that crosses the limit
+func budgetHistory(history []ChatMessage, maxChars int) []ChatMessage {
+	var kept []ChatMessage
+	remaining := maxChars
+	for _, msg := range history {
+		if remaining <= 0 {
+			break
+		}
+		content := []rune(msg.Content)
+		if len(content) > remaining {
+			msg.Content = string(content[:remaining]) + "…"
+		}
+		remaining -= len(content)
+		kept = append(kept, msg)
+	}
+	return kept
+}
+
+// classifyContent guesses whether an AI response is about an order or a task
+func classifyContent(content string) string {
 	// Try to determine if it's an order or task based on content
 	if strings.Contains(strings.ToLower(content), "order") || strings.Contains(strings.ToLower(content), "total") {
-		return "order", content, nil
+		return "order"
 	} else if strings.Contains(strings.ToLower(content), "task") || strings.Contains(strings.ToLower(content), "create") {
-		return "task", content, nil
+		return "task"
 	}
 
-	return "unknown", content, nil
+	return "unknown"
 }
 
-// GetChatHistory retrieves the last 3 chat messages for a user
+// responseCacheKey identifies a message from a user in a given conversation
+// state. The key is scoped to the user and changes whenever the history does
+func responseCacheKey(userID, message string, history []ChatMessage) string {
+	hash := sha256.New()
+	hash.Write([]byte(message))
+	for _, msg := range history {
+		hash.Write([]byte{0})
+		hash.Write([]byte(msg.Role))
+		hash.Write([]byte{0})
+		hash.Write([]byte(msg.Content))
+	}
+	return fmt.Sprintf("ai_response_cache:%s:%s", userID, hex.EncodeToString(hash.Sum(nil)))
+}
+
+func (a *aiProcessor) cachedResponse(userID, message string, history []ChatMessage) (string, bool) {
+	if a.openAI.CacheTTL <= 0 {
+		return "", false
+	}
+
+	content, err := a.redis.Get(responseCacheKey(userID, message, history)).Result()
+	if err != nil || content == "" {
+		return "", false
+	}
+	return content, true
+}
+
+func (a *aiProcessor) cacheResponse(userID, message string, history []ChatMessage, content string) {
+	if a.openAI.CacheTTL <= 0 {
+		return
+	}
+
+	a.redis.Set(responseCacheKey(userID, message, history), content, a.openAI.CacheTTL)
+}
+
+// GetChatHistory retrieves the last configured number of chat messages for a user
 func (a *aiProcessor) GetChatHistory(userID string) ([]ChatMessage, error) {
 	key := fmt.Sprintf("ai_chat_history:%s", userID)
 	
 	// Get all messages from Redis list
-	messages, err := a.redis.LRange(key, 0, 2).Result()
+	messages, err := a.redis.LRange(key, 0, int64(a.history.Size-1)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -414,14 +758,14 @@ func (a *aiProcessor) SaveChatMessage(userID string, role string, content string
 		return err
 	}
 	
-	// Keep only the last 3 messages
-	err = a.redis.LTrim(key, 0, 2).Err()
+	// Keep only the last configured number of messages
+	err = a.redis.LTrim(key, 0, int64(a.history.Size-1)).Err()
 	if err != nil {
 		return err
 	}
 	
-	// Set expiration to 10 minutes
-	err = a.redis.Expire(key, 10*time.Minute).Err()
+	// Refresh the expiration
+	err = a.redis.Expire(key, a.history.TTL).Err()
 	if err != nil {
 		return err
 	}
@@ -434,3 +778,15 @@ func (a *aiProcessor) ClearChatHistory(userID string) error {
 	key := fmt.Sprintf("ai_chat_history:%s", userID)
 	return a.redis.Del(key).Err()
 }
+
+// buildRequestBody encodes a chat completion request with the configured
+// model parameters
+func (a *aiProcessor) buildRequestBody(messages []map[string]string) ([]byte, error) {
+	requestBody := map[string]interface{}{
+		"model":       a.openAI.Model,
+		"messages":    messages,
+		"max_tokens":  a.openAI.MaxTokens,
+		"temperature": a.openAI.Temperature,
+	}
+	return json.Marshal(requestBody)
+}
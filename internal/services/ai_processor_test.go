@@ -0,0 +1,330 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestOpenAI returns a processor sending to a fake OpenAI that answers
+// every request with status and body, and the request bodies it received
+func newTestOpenAI(t *testing.T, openAI OpenAIConfig, status int, body string) (*aiProcessor, *[]map[string]interface{}) {
+	t.Helper()
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, payload)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, _ := newTestRedis(t)
+	processor := NewAIProcessor("sk-test", client, openAI, HistoryConfig{}).(*aiProcessor)
+	processor.endpoint = server.URL
+	return processor, &requests
+}
+
+// chatReply is an OpenAI chat completion answering with content
+func chatReply(content string) string {
+	reply, _ := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+	})
+	return string(reply)
+}
+
+func TestOpenAIRequestParameters(t *testing.T) {
+	tests := []struct {
+		name            string
+		openAI          OpenAIConfig
+		wantModel       string
+		wantMaxTokens   float64
+		wantTemperature float64
+	}{
+		{name: "defaults", wantModel: "gpt-3.5-turbo", wantMaxTokens: 500, wantTemperature: 0},
+		{name: "configured", openAI: OpenAIConfig{Model: "gpt-4o-mini", MaxTokens: 800, Temperature: 0.7}, wantModel: "gpt-4o-mini", wantMaxTokens: 800, wantTemperature: 0.7},
+		{name: "default config", openAI: DefaultOpenAIConfig(), wantModel: "gpt-3.5-turbo", wantMaxTokens: 500, wantTemperature: 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, requests := newTestOpenAI(t, tt.openAI, http.StatusOK, chatReply(`{"type":"general","data":{}}`))
+			if _, _, err := processor.ProcessWithOpenAI("halo", "1"); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(*requests) != 1 {
+				t.Fatalf("OpenAI called %d times", len(*requests))
+			}
+			payload := (*requests)[0]
+			if payload["model"] != tt.wantModel || payload["max_tokens"] != tt.wantMaxTokens || payload["temperature"] != tt.wantTemperature {
+				t.Errorf("payload model=%v max_tokens=%v temperature=%v, want %s %v %v",
+					payload["model"], payload["max_tokens"], payload["temperature"], tt.wantModel, tt.wantMaxTokens, tt.wantTemperature)
+			}
+		})
+	}
+}
+
+func TestProcessWithOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantMessage   string
+		wantQuota     bool
+		wantRateLimit bool
+		wantAuth      bool
+		wantBadReq    bool
+	}{
+		{
+			name:          "429 rate limit",
+			status:        http.StatusTooManyRequests,
+			body:          `{"error":{"message":"Rate limit reached for gpt-3.5-turbo","type":"requests","code":"rate_limit_exceeded"}}`,
+			wantMessage:   "Rate limit reached for gpt-3.5-turbo",
+			wantRateLimit: true,
+		},
+		{
+			name:        "429 quota exceeded",
+			status:      http.StatusTooManyRequests,
+			body:        `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			wantMessage: "You exceeded your current quota",
+			wantQuota:   true,
+		},
+		{
+			name:        "401 invalid key",
+			status:      http.StatusUnauthorized,
+			body:        `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			wantMessage: "Incorrect API key provided",
+			wantAuth:    true,
+			wantBadReq:  true,
+		},
+		{
+			name:        "400 bad request",
+			status:      http.StatusBadRequest,
+			body:        `{"error":{"message":"max_tokens is too large","type":"invalid_request_error","code":null}}`,
+			wantMessage: "max_tokens is too large",
+			wantBadReq:  true,
+		},
+		{
+			name:        "non-JSON error body",
+			status:      http.StatusBadGateway,
+			body:        "upstream connect error\n",
+			wantMessage: "upstream connect error",
+		},
+		{
+			name:        "error object on a 200",
+			status:      http.StatusOK,
+			body:        `{"error":{"message":"server overloaded","type":"server_error"}}`,
+			wantMessage: "server overloaded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, _ := newTestOpenAI(t, OpenAIConfig{}, tt.status, tt.body)
+
+			_, _, err := processor.ProcessWithOpenAI("halo", "1")
+			var openAIErr *OpenAIError
+			if !errors.As(err, &openAIErr) {
+				t.Fatalf("err = %v, want an *OpenAIError", err)
+			}
+			if openAIErr.StatusCode != tt.status || openAIErr.Message != tt.wantMessage {
+				t.Errorf("got status %d message %q, want %d %q", openAIErr.StatusCode, openAIErr.Message, tt.status, tt.wantMessage)
+			}
+			if openAIErr.IsQuotaExceeded() != tt.wantQuota || openAIErr.IsRateLimited() != tt.wantRateLimit ||
+				openAIErr.IsAuthError() != tt.wantAuth || openAIErr.IsBadRequest() != tt.wantBadReq {
+				t.Errorf("quota=%v rate=%v auth=%v bad=%v, want %v %v %v %v",
+					openAIErr.IsQuotaExceeded(), openAIErr.IsRateLimited(), openAIErr.IsAuthError(), openAIErr.IsBadRequest(),
+					tt.wantQuota, tt.wantRateLimit, tt.wantAuth, tt.wantBadReq)
+			}
+		})
+	}
+
+	t.Run("no choices", func(t *testing.T) {
+		processor, _ := newTestOpenAI(t, OpenAIConfig{}, http.StatusOK, `{"choices":[]}`)
+		if _, _, err := processor.ProcessWithOpenAI("halo", "1"); err == nil || err.Error() != "no response from OpenAI" {
+			t.Errorf("err = %v, want no response from OpenAI", err)
+		}
+	})
+}
+
+func TestChatHistoryLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  HistoryConfig
+		saved    int
+		wantKept int
+		wantTTL  time.Duration
+	}{
+		{name: "defaults", saved: 5, wantKept: 3, wantTTL: 10 * time.Minute},
+		{name: "configured", history: HistoryConfig{Size: 8, TTL: 30 * time.Minute}, saved: 10, wantKept: 8, wantTTL: 30 * time.Minute},
+		{name: "fewer than the limit", history: HistoryConfig{Size: 8, TTL: time.Hour}, saved: 2, wantKept: 2, wantTTL: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			processor := NewAIProcessor("", client, OpenAIConfig{}, tt.history)
+
+			for i := 1; i <= tt.saved; i++ {
+				if err := processor.SaveChatMessage("1", "user", fmt.Sprintf("message %d", i)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			history, err := processor.GetChatHistory("1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != tt.wantKept {
+				t.Fatalf("kept %d messages, want %d", len(history), tt.wantKept)
+			}
+			if want := fmt.Sprintf("message %d", tt.saved); history[0].Content != want {
+				t.Errorf("newest message = %q, want %q", history[0].Content, want)
+			}
+			if ttl := server.TTL("ai_chat_history:1"); ttl != tt.wantTTL {
+				t.Errorf("history expires in %v, want %v", ttl, tt.wantTTL)
+			}
+			if other, _ := processor.GetChatHistory("2"); len(other) != 0 {
+				t.Errorf("another user's history has %d messages", len(other))
+			}
+		})
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		cacheTTL  time.Duration
+		second    func(p *aiProcessor) (string, string)
+		wantCalls int
+	}{
+		{
+			name:      "repeat is served from the cache",
+			cacheTTL:  time.Minute,
+			second:    func(p *aiProcessor) (string, string) { return "/my_tasks", "1" },
+			wantCalls: 1,
+		},
+		{
+			name:      "another user misses",
+			cacheTTL:  time.Minute,
+			second:    func(p *aiProcessor) (string, string) { return "/my_tasks", "2" },
+			wantCalls: 2,
+		},
+		{
+			name:      "another message misses",
+			cacheTTL:  time.Minute,
+			second:    func(p *aiProcessor) (string, string) { return "/my_orders", "1" },
+			wantCalls: 2,
+		},
+		{
+			name:     "changed history misses",
+			cacheTTL: time.Minute,
+			second: func(p *aiProcessor) (string, string) {
+				p.SaveChatMessage("1", "user", "something else")
+				return "/my_tasks", "1"
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "disabled cache",
+			second:    func(p *aiProcessor) (string, string) { return "/my_tasks", "1" },
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := `{"type":"view_tasks","data":{}}`
+			processor, requests := newTestOpenAI(t, OpenAIConfig{CacheTTL: tt.cacheTTL}, http.StatusOK, chatReply(reply))
+
+			if _, _, err := processor.ProcessWithOpenAI("/my_tasks", "1"); err != nil {
+				t.Fatal(err)
+			}
+			message, userID := tt.second(processor)
+			_, content, err := processor.ProcessWithOpenAI(message, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(*requests) != tt.wantCalls {
+				t.Errorf("OpenAI called %d times, want %d", len(*requests), tt.wantCalls)
+			}
+			if content != reply {
+				t.Errorf("content = %v, want %s", content, reply)
+			}
+		})
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	history := []ChatMessage{{Role: "user", Content: "halo"}}
+	base := responseCacheKey("1", "/my_tasks", history)
+
+	tests := []struct {
+		name    string
+		userID  string
+		message string
+		history []ChatMessage
+		same    bool
+	}{
+		{name: "identical", userID: "1", message: "/my_tasks", history: history, same: true},
+		{name: "other user", userID: "2", message: "/my_tasks", history: history},
+		{name: "other message", userID: "1", message: "/my_orders", history: history},
+		{name: "no history", userID: "1", message: "/my_tasks"},
+		{name: "other role", userID: "1", message: "/my_tasks", history: []ChatMessage{{Role: "assistant", Content: "halo"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := responseCacheKey(tt.userID, tt.message, tt.history) == base; got != tt.same {
+				t.Errorf("same key = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+func TestBudgetHistory(t *testing.T) {
+	// History is newest first, as GetChatHistory returns it
+	history := []ChatMessage{
+		{Role: "assistant", Content: "newest reply"},
+		{Role: "user", Content: "a question"},
+		{Role: "assistant", Content: "oldest"},
+	}
+
+	tests := []struct {
+		name     string
+		maxChars int
+		want     []string
+	}{
+		{name: "everything fits", maxChars: 100, want: []string{"newest reply", "a question", "oldest"}},
+		{name: "exact fit", maxChars: 28, want: []string{"newest reply", "a question", "oldest"}},
+		{name: "oldest cut", maxChars: 25, want: []string{"newest reply", "a question", "old…"}},
+		{name: "older messages dropped", maxChars: 12, want: []string{"newest reply"}},
+		{name: "newest cut", maxChars: 6, want: []string{"newest…"}},
+		{name: "no budget", maxChars: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, msg := range budgetHistory(history, tt.maxChars) {
+				got = append(got, msg.Content)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("kept %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if history[2].Content != "oldest" {
+		t.Errorf("budgetHistory changed its input: %+v", history)
+	}
+}
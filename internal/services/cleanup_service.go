@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"task_manager/internal/repository"
+	"time"
+)
+
+// RetentionConfig holds how many days each history table is kept. Zero or
+// less keeps a table forever
+type RetentionConfig struct {
+	TaskProgressDays       int
+	CalculationHistoryDays int
+	ReportQueryDays        int
+}
+
+// CleanupResult counts the rows removed per table by one cleanup run
+type CleanupResult struct {
+	TaskProgress       int64
+	CalculationHistory int64
+	ReportQueries      int64
+}
+
+type CleanupService interface {
+	Run(now time.Time) (*CleanupResult, error)
+}
+
+type cleanupService struct {
+	retentionRepo repository.RetentionRepository
+	retention     RetentionConfig
+}
+
+func NewCleanupService(retentionRepo repository.RetentionRepository, retention RetentionConfig) CleanupService {
+	return &cleanupService{retentionRepo: retentionRepo, retention: retention}
+}
+
+// Run deletes the rows of every table that are older than its retention
+// period. Redis chat history expires on its own and is not touched
+func (s *cleanupService) Run(now time.Time) (*CleanupResult, error) {
+	result := &CleanupResult{}
+	steps := []struct {
+		name  string
+		days  int
+		purge func(before time.Time) (int64, error)
+		count *int64
+	}{
+		{"task progress", s.retention.TaskProgressDays, s.retentionRepo.DeleteTaskProgressBefore, &result.TaskProgress},
+		{"calculation history", s.retention.CalculationHistoryDays, s.retentionRepo.DeleteCalculationHistoryBefore, &result.CalculationHistory},
+		{"report queries", s.retention.ReportQueryDays, s.retentionRepo.DeleteReportQueriesBefore, &result.ReportQueries},
+	}
+
+	for _, step := range steps {
+		if step.days <= 0 {
+			continue
+		}
+		deleted, err := step.purge(now.AddDate(0, 0, -step.days))
+		if err != nil {
+			return result, fmt.Errorf("failed to clean up %s: %w", step.name, err)
+		}
+		*step.count = deleted
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCleanupRemovesOnlyOldRows(t *testing.T) {
+	now := time.Date(2025, 1, 10, 3, 0, 0, 0, time.UTC)
+	daysAgo := func(days ...int) []time.Time {
+		var times []time.Time
+		for _, d := range days {
+			times = append(times, now.AddDate(0, 0, -d))
+		}
+		return times
+	}
+	dbErr := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		retention RetentionConfig
+		err       error
+		want      CleanupResult
+		wantKept  [3]int
+		wantErr   error
+	}{
+		{
+			name:      "each table has its own period",
+			retention: RetentionConfig{TaskProgressDays: 30, CalculationHistoryDays: 365, ReportQueryDays: 7},
+			want:      CleanupResult{TaskProgress: 2, CalculationHistory: 1, ReportQueries: 3},
+			wantKept:  [3]int{2, 3, 1},
+		},
+		{
+			name:      "zero keeps a table forever",
+			retention: RetentionConfig{TaskProgressDays: 30},
+			want:      CleanupResult{TaskProgress: 2},
+			wantKept:  [3]int{2, 4, 4},
+		},
+		{
+			name:      "a row exactly at the cutoff is kept",
+			retention: RetentionConfig{TaskProgressDays: 60, CalculationHistoryDays: 400, ReportQueryDays: 400},
+			want:      CleanupResult{TaskProgress: 1},
+			wantKept:  [3]int{3, 4, 4},
+		},
+		{
+			name:      "database error",
+			retention: RetentionConfig{TaskProgressDays: 30},
+			err:       dbErr,
+			wantKept:  [3]int{4, 4, 4},
+			wantErr:   dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRetentionRepo{
+				taskProgress:       daysAgo(1, 29, 60, 90),
+				calculationHistory: daysAgo(10, 100, 364, 366),
+				reportQueries:      daysAgo(3, 8, 30, 90),
+				err:                tt.err,
+			}
+
+			result, err := NewCleanupService(repo, tt.retention).Run(now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && *result != tt.want {
+				t.Errorf("result = %+v, want %+v", *result, tt.want)
+			}
+			kept := [3]int{len(repo.taskProgress), len(repo.calculationHistory), len(repo.reportQueries)}
+			if kept != tt.wantKept {
+				t.Errorf("rows kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,633 @@
+package services
+
+import (
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"task_manager/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// fakeOrderRepo keeps orders in memory; methods a test does not need panic
+// through the embedded nil interface
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders    map[uint]*models.Order
+	statusSet []string
+	// itemStatusSet holds the item status cascaded by each UpdateStatus
+	itemStatusSet []string
+	cancelled     []uint
+	histories     []*models.CalculationHistory
+	notes         []*models.OrderNote
+	deleted       []uint
+	deleteErr     error
+	// createErrs are returned by successive creates before any succeeds
+	createErrs []error
+}
+
+func newFakeOrderRepo(orders ...*models.Order) *fakeOrderRepo {
+	r := &fakeOrderRepo{orders: make(map[uint]*models.Order)}
+	for _, o := range orders {
+		r.orders[o.ID] = o
+	}
+	return r
+}
+
+// GetUpcomingDeliveries filters like the query: open orders delivered within
+// the window, soonest first
+func (r *fakeOrderRepo) GetUpcomingDeliveries(from time.Time, within time.Duration) ([]models.Order, error) {
+	var orders []models.Order
+	for _, o := range r.orders {
+		if o.DeliveryDate == nil || o.DeliveryDate.Before(from) || o.DeliveryDate.After(from.Add(within)) {
+			continue
+		}
+		if o.Status == string(models.OrderCompleted) || o.Status == string(models.OrderCancelled) {
+			continue
+		}
+		orders = append(orders, *o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].DeliveryDate.Before(*orders[j].DeliveryDate) })
+	return orders, nil
+}
+
+func (r *fakeOrderRepo) Create(order *models.Order) error {
+	if len(r.createErrs) > 0 {
+		err := r.createErrs[0]
+		r.createErrs = r.createErrs[1:]
+		return err
+	}
+	for _, existing := range r.orders {
+		if existing.OrderNumber == order.OrderNumber {
+			return &pgconn.PgError{Code: "23505", ConstraintName: "orders_order_number_key"}
+		}
+	}
+	order.ID = uint(len(r.orders) + 1)
+	copied := *order
+	r.orders[order.ID] = &copied
+	return nil
+}
+
+func (r *fakeOrderRepo) CreateWithItems(order *models.Order, items []models.OrderItem) error {
+	return r.Create(order)
+}
+
+func (r *fakeOrderRepo) GetByID(id uint) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrderRepo) Update(order *models.Order) error {
+	if _, ok := r.orders[order.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	copied := *order
+	r.orders[order.ID] = &copied
+	return nil
+}
+
+func (r *fakeOrderRepo) UpdateStatus(orderID uint, status string, itemStatus string) error {
+	order, ok := r.orders[orderID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	order.Status = status
+	r.statusSet = append(r.statusSet, status)
+	r.itemStatusSet = append(r.itemStatusSet, itemStatus)
+	return nil
+}
+
+func (r *fakeOrderRepo) GetByStatus(status string) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if order.Status == status {
+			orders = append(orders, *order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders, nil
+}
+
+// SearchByCustomer matches customer names ignoring case, as the LOWER()
+// comparison in the repository does
+func (r *fakeOrderRepo) SearchByCustomer(name string) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if strings.EqualFold(order.CustomerName, name) {
+			orders = append(orders, *order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders, nil
+}
+
+func (r *fakeOrderRepo) UpdateCustomerName(from, to string) (int64, error) {
+	var renamed int64
+	for _, order := range r.orders {
+		if order.CustomerName == from {
+			order.CustomerName = to
+			renamed++
+		}
+	}
+	return renamed, nil
+}
+
+// GetByDateRange filters like the query: orders dated within the range
+// whose status is not excluded, in ID order
+func (r *fakeOrderRepo) GetByDateRange(startDate, endDate time.Time, excludeStatuses ...string) ([]models.Order, error) {
+	var orders []models.Order
+	for _, o := range r.orders {
+		if o.OrderDate.Before(startDate) || o.OrderDate.After(endDate) || excluded(o.Status, excludeStatuses) {
+			continue
+		}
+		orders = append(orders, *o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders, nil
+}
+
+func excluded(status string, statuses []string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeOrderRepo) Cancel(orderID uint, history *models.CalculationHistory, note *models.OrderNote) error {
+	order, ok := r.orders[orderID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	order.Status = string(models.OrderCancelled)
+	r.cancelled = append(r.cancelled, orderID)
+	r.histories = append(r.histories, history)
+	r.notes = append(r.notes, note)
+	return nil
+}
+
+// fakeOrderNoteRepo keeps notes in memory in the order they were added
+type fakeOrderNoteRepo struct {
+	repository.OrderNoteRepository
+	notes []models.OrderNote
+}
+
+func (r *fakeOrderNoteRepo) Create(note *models.OrderNote) error {
+	note.ID = uint(len(r.notes) + 1)
+	r.notes = append(r.notes, *note)
+	return nil
+}
+
+func (r *fakeOrderNoteRepo) GetByOrderID(orderID uint) ([]models.OrderNote, error) {
+	var notes []models.OrderNote
+	for _, note := range r.notes {
+		if note.OrderID == orderID {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+// fakeRetentionRepo holds the creation times of each history table's rows;
+// err, when set, fails every delete
+type fakeRetentionRepo struct {
+	taskProgress       []time.Time
+	calculationHistory []time.Time
+	reportQueries      []time.Time
+	err                error
+}
+
+func (r *fakeRetentionRepo) DeleteTaskProgressBefore(before time.Time) (int64, error) {
+	return r.deleteBefore(&r.taskProgress, before)
+}
+
+func (r *fakeRetentionRepo) DeleteCalculationHistoryBefore(before time.Time) (int64, error) {
+	return r.deleteBefore(&r.calculationHistory, before)
+}
+
+func (r *fakeRetentionRepo) DeleteReportQueriesBefore(before time.Time) (int64, error) {
+	return r.deleteBefore(&r.reportQueries, before)
+}
+
+func (r *fakeRetentionRepo) deleteBefore(rows *[]time.Time, before time.Time) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	var kept []time.Time
+	for _, createdAt := range *rows {
+		if !createdAt.Before(before) {
+			kept = append(kept, createdAt)
+		}
+	}
+	deleted := int64(len(*rows) - len(kept))
+	*rows = kept
+	return deleted, nil
+}
+
+// fakeReminderRepo keeps reminders in memory
+type fakeReminderRepo struct {
+	repository.ReminderRepository
+	reminders []*models.Reminder
+}
+
+func (r *fakeReminderRepo) Create(reminder *models.Reminder) error {
+	reminder.ID = uint(len(r.reminders) + 1)
+	r.reminders = append(r.reminders, reminder)
+	return nil
+}
+
+func (r *fakeReminderRepo) FindExisting(taskID uint, reminderType string, scheduledTime time.Time) (*models.Reminder, error) {
+	for _, reminder := range r.reminders {
+		if reminder.TaskID == taskID && reminder.ReminderType == reminderType &&
+			reminder.ScheduledTime.Equal(scheduledTime) && !reminder.WhatsAppSent {
+			return reminder, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeReminderRepo) GetPendingReminders(now time.Time) ([]models.Reminder, error) {
+	var pending []models.Reminder
+	for _, reminder := range r.reminders {
+		if !reminder.WhatsAppSent && !reminder.ScheduledTime.After(now) {
+			pending = append(pending, *reminder)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeReminderRepo) MarkAsSent(id uint) error {
+	for _, reminder := range r.reminders {
+		if reminder.ID == id {
+			reminder.WhatsAppSent = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeOrderRepo) DeleteWithItems(id uint) error {
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	delete(r.orders, id)
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// newTestRedis returns a Client on an in-memory Redis that is shut down when
+// the test ends
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client, err := redis.Initialize("redis://" + server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+// newTestSettings returns financial settings backed by repo, with warnings
+// about unconfigured settings discarded
+func newTestSettings(repo *fakeFinancialRepo) FinancialSettingsService {
+	if repo.settings == nil {
+		repo.settings = map[string]*models.FinancialSettings{}
+	}
+	return NewFinancialSettingsService(repo, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// fakeFinancialRepo serves settings from memory and counts lookups. err, when
+// set, is returned by every lookup
+type fakeFinancialRepo struct {
+	repository.FinancialRepository
+	settings map[string]*models.FinancialSettings
+	err      error
+	lookups  int
+	history  []*models.CalculationHistory
+	reports  []*models.ReportQuery
+}
+
+func (r *fakeFinancialRepo) GetSettings(name string) (*models.FinancialSettings, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	settings, ok := r.settings[name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *settings
+	return &copied, nil
+}
+
+func (r *fakeFinancialRepo) UpdateSettings(settings *models.FinancialSettings) error {
+	copied := *settings
+	r.settings[settings.SettingName] = &copied
+	return nil
+}
+
+func (r *fakeFinancialRepo) CreateCalculationHistory(history *models.CalculationHistory) error {
+	r.history = append(r.history, history)
+	return nil
+}
+
+func (r *fakeFinancialRepo) CreateReportQuery(query *models.ReportQuery) error {
+	query.ID = uint(len(r.reports) + 1)
+	r.reports = append(r.reports, query)
+	return nil
+}
+
+// fakeTaskRepo serves tasks from memory
+type fakeTaskRepo struct {
+	repository.TaskRepository
+	tasks    []models.Task
+	statuses map[uint]string
+	// resets lists the task type of each ResetProgressByType call
+	resets []string
+	// weekEnds records the bound of each GetWeeklyTasks call
+	weekEnds []time.Time
+	// progress records each UpdateProgress call
+	progress []models.TaskProgress
+}
+
+func (r *fakeTaskRepo) GetByID(id uint) (*models.Task, error) {
+	for _, task := range r.tasks {
+		if task.ID == id {
+			return &task, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTaskRepo) UpdateProgress(taskID uint, progress int, isImplemented bool, notes string, updatedBy uint) error {
+	r.progress = append(r.progress, models.TaskProgress{
+		TaskID: taskID, CompletionPercentage: progress, IsImplemented: isImplemented,
+		ImplementationNotes: notes, UpdatedBy: updatedBy,
+	})
+	return nil
+}
+
+// GetCompletedBetween filters like the query: completed in [start, end),
+// earliest first
+func (r *fakeTaskRepo) GetCompletedBetween(start, end time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if task.CompletedAt != nil && !task.CompletedAt.Before(start) && task.CompletedAt.Before(end) {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CompletedAt.Before(*tasks[j].CompletedAt) })
+	return tasks, nil
+}
+
+func (r *fakeTaskRepo) Create(task *models.Task) error {
+	task.ID = uint(len(r.tasks) + 1)
+	r.tasks = append(r.tasks, *task)
+	return nil
+}
+
+func (r *fakeTaskRepo) ResetProgressByType(taskType string) error {
+	r.resets = append(r.resets, taskType)
+	return nil
+}
+
+func (r *fakeTaskRepo) GetWeeklyTasks(userID uint, weekEnd time.Time) ([]models.Task, error) {
+	r.weekEnds = append(r.weekEnds, weekEnd)
+	var matched []models.Task
+	for _, task := range r.tasks {
+		if task.AssignedTo == userID && task.TaskType == string(models.Weekly) && task.CreatedAt.Before(weekEnd) {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+func (r *fakeTaskRepo) GetOpenDueBefore(before time.Time) ([]models.Task, error) {
+	var matched []models.Task
+	for _, task := range r.tasks {
+		if task.DueDate != nil && task.DueDate.Before(before) && task.Status != string(models.Completed) {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+func (r *fakeTaskRepo) UpdateStatus(taskID uint, status string) error {
+	if r.statuses == nil {
+		r.statuses = map[uint]string{}
+	}
+	r.statuses[taskID] = status
+	return nil
+}
+
+func (r *fakeTaskRepo) UpdatePriority(taskID uint, priority string) error {
+	for i := range r.tasks {
+		if r.tasks[i].ID == taskID {
+			r.tasks[i].Priority = priority
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeTaskRepo) GetByUserAndStatus(userID uint, status string) ([]models.Task, error) {
+	var matched []models.Task
+	for _, task := range r.tasks {
+		if task.AssignedTo == userID && task.Status == status {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+func (r *fakeTaskRepo) GetByUserAndPriority(userID uint, priority string) ([]models.Task, error) {
+	var matched []models.Task
+	for _, task := range r.tasks {
+		if task.AssignedTo == userID && task.Priority == priority {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+func (r *fakeTaskRepo) GetByType(taskType string) ([]models.Task, error) {
+	var matched []models.Task
+	for _, task := range r.tasks {
+		if task.TaskType == taskType {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+// fakeWhatsAppService records outgoing messages and their recipients. Sends
+// to a phone listed in failFor fail
+type fakeWhatsAppService struct {
+	WhatsAppService
+	sent    []string
+	phones  []string
+	failFor map[string]error
+}
+
+func (f *fakeWhatsAppService) SendMessage(phone, message string) error {
+	if err := f.failFor[phone]; err != nil {
+		return err
+	}
+	f.sent = append(f.sent, message)
+	f.phones = append(f.phones, phone)
+	return nil
+}
+
+// fakeTaskService serves tasks from memory
+type fakeTaskService struct {
+	TaskService
+	tasks map[uint]*models.Task
+}
+
+func (f *fakeTaskService) GetTaskByID(id uint) (*models.Task, error) {
+	task, ok := f.tasks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return task, nil
+}
+
+// fakeUserService serves users from memory
+type fakeUserService struct {
+	UserService
+	users []*models.User
+}
+
+func (f *fakeUserService) GetUserByID(id uint) (*models.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeOrderItemRepo serves order items from memory
+type fakeOrderItemRepo struct {
+	repository.OrderItemRepository
+	items map[uint][]*models.OrderItem
+}
+
+func (r *fakeOrderItemRepo) GetByOrderID(orderID uint) ([]*models.OrderItem, error) {
+	return r.items[orderID], nil
+}
+
+// fakeTenantRepo keeps tenants in memory
+type fakeTenantRepo struct {
+	repository.TenantRepository
+	tenants []*models.Tenant
+}
+
+func (r *fakeTenantRepo) Create(tenant *models.Tenant) error {
+	tenant.ID = uint(len(r.tenants) + 1)
+	r.tenants = append(r.tenants, tenant)
+	return nil
+}
+
+func (r *fakeTenantRepo) GetByWhatsAppNumber(whatsappNumber string) (*models.Tenant, error) {
+	for _, tenant := range r.tenants {
+		if tenant.WhatsAppNumber == whatsappNumber {
+			return tenant, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOrderItemRepo) GetByID(id uint) (*models.OrderItem, error) {
+	for _, items := range r.items {
+		for _, item := range items {
+			if item.ID == id {
+				return item, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOrderItemRepo) Update(item *models.OrderItem) error {
+	return nil
+}
+
+// fakeUserRepo keeps users in memory and matches WhatsApp numbers exactly,
+// as the database would for a single stored spelling; lookups counts
+// GetByWhatsAppNumber calls
+type fakeUserRepo struct {
+	repository.UserRepository
+	users   []*models.User
+	lookups int
+}
+
+func (r *fakeUserRepo) Create(user *models.User) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			stored := *user
+			return &stored, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetByWhatsAppNumber(whatsappNumber string) (*models.User, error) {
+	r.lookups++
+	for _, user := range r.users {
+		if user.WhatsAppNumber == whatsappNumber {
+			stored := *user
+			return &stored, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) Update(user *models.User) error {
+	for i, existing := range r.users {
+		if existing.ID == user.ID {
+			stored := *user
+			r.users[i] = &stored
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) SetPasswordHash(userID uint, hash string) error {
+	for _, user := range r.users {
+		if user.ID == userID {
+			user.PasswordHash = hash
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	for i, user := range r.users {
+		if user.ID == id {
+			r.users = append(r.users[:i], r.users[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
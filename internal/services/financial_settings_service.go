@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"task_manager/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// financialSettingsCacheTTL bounds how long a cached setting may lag behind
+// the database when it is changed without going through this service
+const financialSettingsCacheTTL = 5 * time.Minute
+
+// FinancialSettingsService reads the tax, marketing and rental settings used
+// by every order calculation, caching them in Redis
+type FinancialSettingsService interface {
+	GetSetting(name string) (*models.FinancialSettings, error)
+	CreateSettings(settings *models.FinancialSettings) error
+	UpdateSettings(settings *models.FinancialSettings) error
+	Warm() (int, error)
+}
+
+// financialSettingNames are the settings every order calculation reads
+var financialSettingNames = []string{"tax_rate", "marketing_rate", "rental_rate"}
+
+type financialSettingsService struct {
+	financialRepo repository.FinancialRepository
+	redis         *redis.Client
+	logger        *slog.Logger
+}
+
+// NewFinancialSettingsService builds the settings service. redis may be nil,
+// in which case every lookup goes to the database; a nil logger means
+// slog.Default()
+func NewFinancialSettingsService(financialRepo repository.FinancialRepository, redis *redis.Client, logger *slog.Logger) FinancialSettingsService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &financialSettingsService{financialRepo: financialRepo, redis: redis, logger: logger}
+}
+
+func financialSettingCacheKey(name string) string {
+	return "financial_setting:" + name
+}
+
+// GetSetting returns the active setting called name. A setting that was never
+// configured counts as 0% so a fresh database can still take orders; only
+// real database errors are returned. Either result is cached
+func (s *financialSettingsService) GetSetting(name string) (*models.FinancialSettings, error) {
+	key := financialSettingCacheKey(name)
+	if s.redis != nil {
+		if cached, err := s.redis.Get(key).Result(); err == nil {
+			var settings models.FinancialSettings
+			if err := json.Unmarshal([]byte(cached), &settings); err == nil {
+				return &settings, nil
+			}
+		}
+	}
+
+	settings, err := s.load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.redis != nil {
+		if data, err := json.Marshal(settings); err == nil {
+			if err := s.redis.Set(key, data, financialSettingsCacheTTL).Err(); err != nil {
+				s.logger.Error("Failed to cache financial setting", "setting", name, "error", err)
+			}
+		}
+	}
+	return settings, nil
+}
+
+// Warm caches every setting an order calculation reads that is not cached
+// yet, returning how many it filled. It only fills missing keys, with SET NX,
+// so a value cached by a concurrent lookup is never overwritten and a setting
+// that was just invalidated is reloaded from the database
+func (s *financialSettingsService) Warm() (int, error) {
+	if s.redis == nil {
+		return 0, nil
+	}
+
+	warmed := 0
+	for _, name := range financialSettingNames {
+		key := financialSettingCacheKey(name)
+		if err := s.redis.Get(key).Err(); err == nil {
+			continue
+		}
+
+		settings, err := s.load(name)
+		if err != nil {
+			return warmed, fmt.Errorf("failed to load financial setting %s: %w", name, err)
+		}
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return warmed, err
+		}
+		set, err := s.redis.SetNX(key, data, financialSettingsCacheTTL).Result()
+		if err != nil {
+			return warmed, fmt.Errorf("failed to cache financial setting %s: %w", name, err)
+		}
+		if set {
+			warmed++
+		}
+	}
+	return warmed, nil
+}
+
+// load reads the setting called name from the database, counting one that
+// was never configured as 0%
+func (s *financialSettingsService) load(name string) (*models.FinancialSettings, error) {
+	settings, err := s.financialRepo.GetSettings(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Warn("Financial setting is not configured, using 0%", "setting", name)
+		return &models.FinancialSettings{SettingName: name, IsPercentage: true}, nil
+	}
+	return settings, err
+}
+
+func (s *financialSettingsService) CreateSettings(settings *models.FinancialSettings) error {
+	if err := s.financialRepo.CreateSettings(settings); err != nil {
+		return err
+	}
+	s.invalidate(settings.SettingName)
+	return nil
+}
+
+func (s *financialSettingsService) UpdateSettings(settings *models.FinancialSettings) error {
+	if err := s.financialRepo.UpdateSettings(settings); err != nil {
+		return err
+	}
+	s.invalidate(settings.SettingName)
+	return nil
+}
+
+// invalidate drops the cached copy of the setting called name
+func (s *financialSettingsService) invalidate(name string) {
+	if s.redis == nil {
+		return
+	}
+	if err := s.redis.Del(financialSettingCacheKey(name)).Err(); err != nil {
+		s.logger.Error("Failed to invalidate cached financial setting", "setting", name, "error", err)
+	}
+}
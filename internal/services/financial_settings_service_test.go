@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCalculateFinancialsSettings(t *testing.T) {
+	dbErr := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		settings      map[string]*models.FinancialSettings
+		repoErr       error
+		wantErr       error
+		wantNetProfit float64
+		wantWarnings  int
+	}{
+		{
+			name: "all settings configured",
+			settings: map[string]*models.FinancialSettings{
+				"tax_rate":       {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+				"marketing_rate": {SettingName: "marketing_rate", PercentageValue: 5, IsPercentage: true},
+				"rental_rate":    {SettingName: "rental_rate", FixedAmount: 5000},
+			},
+			wantNetProfit: 80000,
+		},
+		{
+			name: "missing setting counts as 0%",
+			settings: map[string]*models.FinancialSettings{
+				"tax_rate": {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+			},
+			wantNetProfit: 90000,
+			wantWarnings:  2,
+		},
+		{
+			name:          "fresh database",
+			settings:      map[string]*models.FinancialSettings{},
+			wantNetProfit: 100000,
+			wantWarnings:  3,
+		},
+		{
+			name:    "database error is returned",
+			repoErr: dbErr,
+			wantErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			repo := &fakeFinancialRepo{settings: tt.settings, err: tt.repoErr}
+			settings := NewFinancialSettingsService(repo, nil, slog.New(slog.NewTextHandler(&logs, nil)))
+			svc := NewOrderService(nil, nil, nil, repo, settings, ItemStatusConfig{}, "IDR", clock.Real{})
+
+			order := &models.Order{ID: 1, TotalAmount: 100000}
+			err := svc.CalculateFinancials(order)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.history) != 0 {
+					t.Errorf("calculation recorded despite the error")
+				}
+				return
+			}
+			if order.NetProfit != tt.wantNetProfit {
+				t.Errorf("NetProfit = %v, want %v", order.NetProfit, tt.wantNetProfit)
+			}
+			if got := strings.Count(logs.String(), "level=WARN"); got != tt.wantWarnings {
+				t.Errorf("logged %d warnings, want %d:\n%s", got, tt.wantWarnings, logs.String())
+			}
+			if tt.wantWarnings > 0 && !strings.Contains(logs.String(), "setting=rental_rate") {
+				t.Errorf("warning does not name the setting:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+func TestFinancialSettingsCache(t *testing.T) {
+	client, _ := newTestRedis(t)
+	repo := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+		"tax_rate":       {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+		"marketing_rate": {SettingName: "marketing_rate", PercentageValue: 5, IsPercentage: true},
+	}}
+	settings := NewFinancialSettingsService(repo, client, nil)
+	svc := NewOrderService(nil, nil, nil, repo, settings, ItemStatusConfig{}, "IDR", clock.Real{})
+
+	for i := 0; i < 5; i++ {
+		if err := svc.CalculateFinancials(&models.Order{ID: uint(i + 1), TotalAmount: 100000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// One lookup per setting, the unconfigured rental_rate included
+	if repo.lookups != 3 {
+		t.Errorf("database hit %d times for 5 orders, want 3", repo.lookups)
+	}
+
+	updated := &models.FinancialSettings{SettingName: "tax_rate", PercentageValue: 11, IsPercentage: true}
+	if err := settings.UpdateSettings(updated); err != nil {
+		t.Fatal(err)
+	}
+	got, err := settings.GetSetting("tax_rate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PercentageValue != 11 {
+		t.Errorf("tax_rate = %v after update, want 11", got.PercentageValue)
+	}
+	if repo.lookups != 4 {
+		t.Errorf("database hit %d times, want 4 after invalidation", repo.lookups)
+	}
+}
+
+func TestFinancialSettingsCacheUnavailable(t *testing.T) {
+	client, server := newTestRedis(t)
+	server.Close()
+
+	var logs bytes.Buffer
+	repo := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+		"tax_rate": {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+	}}
+	settings := NewFinancialSettingsService(repo, client, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	got, err := settings.GetSetting("tax_rate")
+	if err != nil {
+		t.Fatalf("GetSetting with Redis down: %v", err)
+	}
+	if got.PercentageValue != 10 {
+		t.Errorf("tax_rate = %v, want 10", got.PercentageValue)
+	}
+	if !strings.Contains(logs.String(), "setting=tax_rate") || !strings.Contains(logs.String(), "error=") {
+		t.Errorf("cache failure not logged with setting and error:\n%s", logs.String())
+	}
+}
+
+func TestFinancialSettingsCacheExpires(t *testing.T) {
+	tests := []struct {
+		name        string
+		elapsed     time.Duration
+		wantLookups int
+	}{
+		{name: "within the TTL", elapsed: financialSettingsCacheTTL - time.Second, wantLookups: 1},
+		{name: "after the TTL", elapsed: financialSettingsCacheTTL, wantLookups: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			repo := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+				"tax_rate": {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+			}}
+			settings := NewFinancialSettingsService(repo, client, nil)
+
+			if _, err := settings.GetSetting("tax_rate"); err != nil {
+				t.Fatal(err)
+			}
+			server.FastForward(tt.elapsed)
+			if _, err := settings.GetSetting("tax_rate"); err != nil {
+				t.Fatal(err)
+			}
+			if repo.lookups != tt.wantLookups {
+				t.Errorf("database hit %d times, want %d", repo.lookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestFinancialSettingsWarm(t *testing.T) {
+	tests := []struct {
+		name        string
+		cached      map[string]string
+		invalidate  bool
+		wantWarmed  int
+		wantLookups int
+		wantTax     float64
+	}{
+		{name: "cold cache", wantWarmed: 3, wantLookups: 3, wantTax: 10},
+		{name: "cached value is kept", cached: map[string]string{"tax_rate": `{"setting_name":"tax_rate","percentage_value":12,"is_percentage":true}`}, wantWarmed: 2, wantLookups: 2, wantTax: 12},
+		{name: "invalidated value is reloaded", cached: map[string]string{"tax_rate": `{"setting_name":"tax_rate","percentage_value":12,"is_percentage":true}`}, invalidate: true, wantWarmed: 3, wantLookups: 3, wantTax: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			for name, value := range tt.cached {
+				server.Set(financialSettingCacheKey(name), value)
+			}
+			repo := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+				"tax_rate":       {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+				"marketing_rate": {SettingName: "marketing_rate", PercentageValue: 5, IsPercentage: true},
+			}}
+			settings := NewFinancialSettingsService(repo, client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if tt.invalidate {
+				if err := settings.UpdateSettings(&models.FinancialSettings{SettingName: "tax_rate", PercentageValue: 11, IsPercentage: true}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			warmed, err := settings.Warm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if warmed != tt.wantWarmed || repo.lookups != tt.wantLookups {
+				t.Errorf("warmed %d with %d lookups, want %d with %d", warmed, repo.lookups, tt.wantWarmed, tt.wantLookups)
+			}
+			for _, name := range financialSettingNames {
+				if !server.Exists(financialSettingCacheKey(name)) {
+					t.Errorf("%s not cached", name)
+				}
+				if ttl := server.TTL(financialSettingCacheKey(name)); tt.cached[name] == "" && ttl != financialSettingsCacheTTL {
+					t.Errorf("%s cached for %v, want %v", name, ttl, financialSettingsCacheTTL)
+				}
+			}
+
+			// Lookups are now served from the warmed cache
+			lookups := repo.lookups
+			got, err := settings.GetSetting("tax_rate")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.PercentageValue != tt.wantTax || repo.lookups != lookups {
+				t.Errorf("tax_rate = %v after %d more lookups, want %v from the cache", got.PercentageValue, repo.lookups-lookups, tt.wantTax)
+			}
+		})
+	}
+
+	t.Run("without Redis", func(t *testing.T) {
+		repo := &fakeFinancialRepo{}
+		warmed, err := NewFinancialSettingsService(repo, nil, nil).Warm()
+		if err != nil || warmed != 0 || repo.lookups != 0 {
+			t.Errorf("Warm() = %d, %v with %d lookups, want nothing done", warmed, err, repo.lookups)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"log/slog"
+	"task_manager/internal/redis"
+	"time"
+)
+
+// RunExclusive runs fn only if this instance wins the named lock, so a job
+// scheduled on every replica runs once per ttl. The lock is left to expire
+// rather than released, keeping replicas whose timers fire slightly later
+// from running the job again. Without Redis fn always runs
+func RunExclusive(redisClient *redis.Client, logger *slog.Logger, name string, ttl time.Duration, fn func()) {
+	if redisClient == nil {
+		fn()
+		return
+	}
+
+	acquired, err := redisClient.AcquireLock(name, ttl)
+	if err != nil {
+		logger.Error("Skipping job: failed to acquire lock", "job", name, "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	fn()
+}
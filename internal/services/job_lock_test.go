@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"task_manager/internal/redis"
+	"testing"
+	"time"
+)
+
+func TestRunExclusive(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int
+		noRedis  bool
+		down     bool
+		wantRuns int
+		wantLog  string
+	}{
+		{name: "one replica", replicas: 1, wantRuns: 1},
+		{name: "job runs once across replicas", replicas: 3, wantRuns: 1},
+		{name: "without Redis every run goes ahead", replicas: 2, noRedis: true, wantRuns: 2},
+		{name: "Redis down skips the job", replicas: 1, down: true, wantLog: "Skipping job: failed to acquire lock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, server := newTestRedis(t)
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+			// Each replica has its own connection to the shared Redis
+			runs := 0
+			for i := 0; i < tt.replicas; i++ {
+				var client *redis.Client
+				if !tt.noRedis {
+					var err error
+					if client, err = redis.Initialize("redis://" + server.Addr()); err != nil {
+						t.Fatal(err)
+					}
+					defer client.Close()
+				}
+				if tt.down {
+					server.Close()
+				}
+				RunExclusive(client, logger, "daily_jobs:2025-01-15", time.Hour, func() { runs++ })
+			}
+
+			if runs != tt.wantRuns {
+				t.Errorf("job ran %d times, want %d", runs, tt.wantRuns)
+			}
+			if tt.wantLog != "" && !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("log = %q, want %q", logs.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestRunExclusiveAfterLockExpires(t *testing.T) {
+	client, server := newTestRedis(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	runs := 0
+	job := func() { runs++ }
+	RunExclusive(client, logger, "escalation", time.Minute, job)
+	RunExclusive(client, logger, "escalation", time.Minute, job)
+	server.FastForward(time.Minute)
+	RunExclusive(client, logger, "escalation", time.Minute, job)
+
+	if runs != 2 {
+		t.Errorf("job ran %d times, want once per lock period", runs)
+	}
+}
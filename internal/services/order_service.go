@@ -1,22 +1,48 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/currency"
+	"task_manager/internal/features"
 	"task_manager/internal/models"
 	"task_manager/internal/repository"
+	"task_manager/pkg/pdf"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
 )
 
 type OrderService interface {
 	CreateOrder(order *models.Order) error
+	CreateOrderWithItems(order *models.Order, items []models.OrderItem) error
 	GetOrderByID(id uint) (*models.Order, error)
 	GetOrdersByUser(userID uint) ([]models.Order, error)
 	GetOrdersByDateRange(startDate, endDate time.Time) ([]models.Order, error)
-	UpdateOrder(order *models.Order) error
+	GetOrdersByStatus(status string) ([]models.Order, error)
+	UpdateOrder(order *models.Order, actorID uint) error
 	DeleteOrder(id uint) error
+	GetDeletedOrders() ([]models.Order, error)
+	RestoreOrder(id uint) error
 	CalculateFinancials(order *models.Order) error
+	GetCalculationHistory(orderID uint) ([]models.CalculationHistory, error)
+	RecordReportQuery(userID uint, queryType string, startDate, endDate *time.Time, reportData interface{}) error
+	GetReportHistory(userID uint, limit int) ([]models.ReportQuery, error)
 	GetAllOrders() ([]models.Order, error)
+	GetAllOrdersPaginated(offset, limit int) ([]models.Order, int64, error)
+	SearchOrders(filter repository.OrderFilter) ([]models.Order, int64, error)
+	MergeCustomer(from, to string) (int64, error)
+	GetCustomerSummary(name string) (*CustomerSummary, error)
+	GetOrdersForCustomer(nameOrPhone string) ([]models.Order, error)
+	GetUpcomingDeliveries(within time.Duration) ([]models.Order, error)
+	UpdateStatus(orderID uint, status string) error
+	CancelOrder(orderID uint, reason string, actorID uint) error
+	GetFinancialSummary(startDate, endDate time.Time, includeCancelled bool) (*FinancialSummary, error)
 	
 	// Order Items methods
 	AddItemToOrder(orderID uint, itemName string, quantity int, price float64, description string) error
@@ -25,25 +51,179 @@ type OrderService interface {
 	DeleteOrderItem(itemID uint) error
 	UpdateItemStatus(itemID uint, status string) error
 	GetOrderItemsSummary(orderID uint) (map[string]interface{}, error)
+	ReconcileOrderTotal(orderID uint) (matched bool, itemsTotal float64, orderTotal float64, err error)
+	
+	// Order notes
+	AddNote(orderID, userID uint, content string) (*models.OrderNote, error)
+	GetNotes(orderID uint) ([]models.OrderNote, error)
+	
+	// Documents
+	GenerateInvoicePDF(orderID uint) ([]byte, error)
+}
+
+// ItemStatusConfig lists the order item statuses accepted by UpdateItemStatus
+// and which of them count as done when rolling up an order
+type ItemStatusConfig struct {
+	Statuses         []string
+	TerminalStatuses []string
+}
+
+// DefaultItemStatusConfig returns the built-in pending/completed/cancelled set
+func DefaultItemStatusConfig() ItemStatusConfig {
+	return ItemStatusConfig{
+		Statuses:         []string{string(models.ItemPending), string(models.ItemCompleted), string(models.ItemCancelled)},
+		TerminalStatuses: []string{string(models.ItemCompleted)},
+	}
+}
+
+// IsValid reports whether status is one of the configured item statuses
+func (c ItemStatusConfig) IsValid(status string) bool {
+	return containsStatus(c.Statuses, status)
+}
+
+// IsTerminal reports whether status counts as done for the order roll-up
+func (c ItemStatusConfig) IsTerminal(status string) bool {
+	return containsStatus(c.TerminalStatuses, status)
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if strings.EqualFold(s, status) {
+			return true
+		}
+	}
+	return false
 }
 
 type orderService struct {
 	orderRepo     repository.OrderRepository
 	orderItemRepo repository.OrderItemRepository
+	orderNoteRepo repository.OrderNoteRepository
 	financialRepo repository.FinancialRepository
+	settings      FinancialSettingsService
+	itemStatuses  ItemStatusConfig
+	currencyCode  string
+	clock         clock.Clock
 }
 
-func NewOrderService(orderRepo repository.OrderRepository, orderItemRepo repository.OrderItemRepository, financialRepo repository.FinancialRepository) OrderService {
-	return &orderService{orderRepo: orderRepo, orderItemRepo: orderItemRepo, financialRepo: financialRepo}
+// NewOrderService builds the order service. settings may be nil, in which
+// case financial settings are read from financialRepo uncached
+func NewOrderService(orderRepo repository.OrderRepository, orderItemRepo repository.OrderItemRepository, orderNoteRepo repository.OrderNoteRepository, financialRepo repository.FinancialRepository, settings FinancialSettingsService, itemStatuses ItemStatusConfig, currencyCode string, clk clock.Clock) OrderService {
+	if len(itemStatuses.Statuses) == 0 {
+		itemStatuses = DefaultItemStatusConfig()
+	}
+	if settings == nil {
+		settings = NewFinancialSettingsService(financialRepo, nil, nil)
+	}
+	return &orderService{orderRepo: orderRepo, orderItemRepo: orderItemRepo, orderNoteRepo: orderNoteRepo, financialRepo: financialRepo, settings: settings, itemStatuses: itemStatuses, currencyCode: currencyCode, clock: clock.OrReal(clk)}
 }
 
 func (s *orderService) CreateOrder(order *models.Order) error {
-	// Calculate financials before creating
-	if err := s.CalculateFinancials(order); err != nil {
+	// Every create path gets a unique order number, whoever the caller is
+	generated := order.OrderNumber == ""
+	if generated {
+		order.OrderNumber = generateOrderNumber()
+	}
+	if order.Status == "" {
+		order.Status = string(models.OrderPending)
+	}
+
+	// Calculate financials before creating; the calculation is recorded once
+	// the order has an ID to attach it to
+	applied, err := s.applyFinancials(order)
+	if err != nil {
 		return err
 	}
-	
-	return s.orderRepo.Create(order)
+
+	// A generated number can still collide under concurrent creates; pick a
+	// new one and try again rather than failing the order
+	for attempt := 1; ; attempt++ {
+		err = s.orderRepo.Create(order)
+		if err == nil {
+			break
+		}
+		if !generated || !isOrderNumberConflict(err) {
+			return err
+		}
+		if attempt == maxOrderNumberAttempts {
+			return fmt.Errorf("could not allocate a unique order number after %d attempts: %w", maxOrderNumberAttempts, err)
+		}
+		order.OrderNumber = generateOrderNumber()
+	}
+
+	return s.recordCalculation(order, applied, order.CreatedBy, nil)
+}
+
+// CreateOrderWithItems creates the order together with its items in a single
+// transaction. Item line totals are filled in, and an order without a total
+// takes the sum of its items
+func (s *orderService) CreateOrderWithItems(order *models.Order, items []models.OrderItem) error {
+	if len(items) == 0 {
+		return errors.New("order needs at least one item")
+	}
+
+	itemsTotal := 0.0
+	for i := range items {
+		if items[i].Quantity <= 0 || items[i].UnitPrice <= 0 {
+			return fmt.Errorf("item %q needs a positive quantity and price", items[i].ItemName)
+		}
+		items[i].TotalPrice = float64(items[i].Quantity) * items[i].UnitPrice
+		if items[i].Status == "" {
+			items[i].Status = string(models.ItemPending)
+		}
+		itemsTotal += items[i].TotalPrice
+	}
+	if order.TotalAmount == 0 {
+		order.TotalAmount = itemsTotal
+	}
+
+	generated := order.OrderNumber == ""
+	if generated {
+		order.OrderNumber = generateOrderNumber()
+	}
+	if order.Status == "" {
+		order.Status = string(models.OrderPending)
+	}
+
+	applied, err := s.applyFinancials(order)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = s.orderRepo.CreateWithItems(order, items)
+		if err == nil {
+			break
+		}
+		if !generated || !isOrderNumberConflict(err) {
+			return err
+		}
+		if attempt == maxOrderNumberAttempts {
+			return fmt.Errorf("could not allocate a unique order number after %d attempts: %w", maxOrderNumberAttempts, err)
+		}
+		order.ID = 0
+		order.OrderNumber = generateOrderNumber()
+	}
+
+	return s.recordCalculation(order, applied, order.CreatedBy, nil)
+}
+
+// maxOrderNumberAttempts bounds the retries on order number conflicts
+const maxOrderNumberAttempts = 5
+
+// generateOrderNumber returns an order number unique to the nanosecond
+func generateOrderNumber() string {
+	return fmt.Sprintf("ORD-%d", time.Now().UnixNano())
+}
+
+// isOrderNumberConflict reports whether err is a Postgres unique violation
+// (SQLSTATE 23505) on the order_number column
+func isOrderNumberConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return false
+	}
+	return strings.Contains(pgErr.ConstraintName, "order_number") || strings.Contains(pgErr.Detail, "(order_number)")
 }
 
 func (s *orderService) GetOrderByID(id uint) (*models.Order, error) {
@@ -58,62 +238,183 @@ func (s *orderService) GetOrdersByDateRange(startDate, endDate time.Time) ([]mod
 	return s.orderRepo.GetByDateRange(startDate, endDate)
 }
 
-func (s *orderService) UpdateOrder(order *models.Order) error {
-	// Recalculate financials before updating
-	if err := s.CalculateFinancials(order); err != nil {
+func (s *orderService) GetOrdersByStatus(status string) ([]models.Order, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if err := validateOrderStatus(status); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetByStatus(status)
+}
+
+// UpdateOrder recalculates the order's financials, saves it and records who
+// made the change along with the net profit it replaced
+func (s *orderService) UpdateOrder(order *models.Order, actorID uint) error {
+	previous, err := s.orderRepo.GetByID(order.ID)
+	if err != nil {
 		return err
 	}
-	
-	return s.orderRepo.Update(order)
+
+	applied, err := s.applyFinancials(order)
+	if err != nil {
+		return err
+	}
+
+	if err := s.orderRepo.Update(order); err != nil {
+		return err
+	}
+
+	previousNetProfit := previous.NetProfit
+	return s.recordCalculation(order, applied, actorID, &previousNetProfit)
+}
+
+func (s *orderService) GetCalculationHistory(orderID uint) ([]models.CalculationHistory, error) {
+	return s.financialRepo.GetCalculationHistory(orderID)
+}
+
+// RecordReportQuery stores a generated report so it shows up in the user's
+// report history
+func (s *orderService) RecordReportQuery(userID uint, queryType string, startDate, endDate *time.Time, reportData interface{}) error {
+	data, err := json.Marshal(reportData)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	return s.financialRepo.CreateReportQuery(&models.ReportQuery{
+		UserID:      userID,
+		QueryType:   queryType,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		ReportData:  string(data),
+		GeneratedAt: now,
+		CreatedAt:   now,
+	})
+}
+
+func (s *orderService) GetReportHistory(userID uint, limit int) ([]models.ReportQuery, error) {
+	return s.financialRepo.GetReportQueriesByUser(userID, limit)
 }
 
 func (s *orderService) DeleteOrder(id uint) error {
 	return s.orderRepo.Delete(id)
 }
 
+func (s *orderService) GetDeletedOrders() ([]models.Order, error) {
+	return s.orderRepo.GetDeleted()
+}
+
+// ErrOrderNotDeleted is returned when restoring an order that does not exist
+// or was never deleted
+var ErrOrderNotDeleted = errors.New("no deleted order with this ID")
+
+func (s *orderService) RestoreOrder(id uint) error {
+	err := s.orderRepo.Restore(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOrderNotDeleted
+	}
+	return err
+}
+
 func (s *orderService) CalculateFinancials(order *models.Order) error {
+	applied, err := s.applyFinancials(order)
+	if err != nil {
+		return err
+	}
+	return s.recordCalculation(order, applied, order.CreatedBy, nil)
+}
+
+// appliedDeductions sums what applyFinancials took off an order: the
+// percentage settings and the flat fixed-amount ones
+type appliedDeductions struct {
+	Percentage  float64
+	FixedAmount float64
+}
+
+// add accounts for one setting and returns the amount it deducts from total
+func (d *appliedDeductions) add(settings *models.FinancialSettings, total float64) float64 {
+	if !settings.IsPercentage {
+		d.FixedAmount += settings.FixedAmount
+		return settings.FixedAmount
+	}
+	d.Percentage += settings.PercentageValue
+	return total * (settings.PercentageValue / 100)
+}
+
+// settingPercentage is the rate stored on the order for settings; fixed
+// amounts have none
+func settingPercentage(settings *models.FinancialSettings) float64 {
+	if !settings.IsPercentage {
+		return 0
+	}
+	return settings.PercentageValue
+}
+
+// applyFinancials fills in the order's tax, cost and profit fields from the
+// current settings and returns the deductions applied. Percentage settings
+// take a share of the total; the others subtract their fixed amount. A rate
+// override on the order replaces the matching setting
+func (s *orderService) applyFinancials(order *models.Order) (appliedDeductions, error) {
+	var applied appliedDeductions
+
 	// Get financial settings
-	taxSettings, err := s.financialRepo.GetSettings("tax_rate")
+	taxSettings, err := s.orderSetting("tax_rate", order.TaxRateOverride)
 	if err != nil {
-		return fmt.Errorf("failed to get tax settings: %w", err)
+		return applied, fmt.Errorf("failed to get tax settings: %w", err)
 	}
 	
-	marketingSettings, err := s.financialRepo.GetSettings("marketing_rate")
+	marketingSettings, err := s.orderSetting("marketing_rate", order.MarketingRateOverride)
 	if err != nil {
-		return fmt.Errorf("failed to get marketing settings: %w", err)
+		return applied, fmt.Errorf("failed to get marketing settings: %w", err)
 	}
 	
-	rentalSettings, err := s.financialRepo.GetSettings("rental_rate")
+	rentalSettings, err := s.orderSetting("rental_rate", order.RentalRateOverride)
 	if err != nil {
-		return fmt.Errorf("failed to get rental settings: %w", err)
+		return applied, fmt.Errorf("failed to get rental settings: %w", err)
 	}
 	
 	// Calculate tax amount
-	order.TaxPercentage = taxSettings.PercentageValue
-	order.TaxAmount = order.TotalAmount * (taxSettings.PercentageValue / 100)
+	order.TaxPercentage = settingPercentage(taxSettings)
+	order.TaxAmount = applied.add(taxSettings, order.TotalAmount)
 	
 	// Calculate marketing cost
-	order.MarketingPercentage = marketingSettings.PercentageValue
-	order.MarketingCost = order.TotalAmount * (marketingSettings.PercentageValue / 100)
+	order.MarketingPercentage = settingPercentage(marketingSettings)
+	order.MarketingCost = applied.add(marketingSettings, order.TotalAmount)
 	
 	// Calculate rental cost
-	order.RentalPercentage = rentalSettings.PercentageValue
-	order.RentalCost = order.TotalAmount * (rentalSettings.PercentageValue / 100)
+	order.RentalPercentage = settingPercentage(rentalSettings)
+	order.RentalCost = applied.add(rentalSettings, order.TotalAmount)
 	
 	// Calculate net profit
 	order.NetProfit = order.TotalAmount - order.TaxAmount - order.MarketingCost - order.RentalCost
 	
 	// Set calculation timestamp
-	order.CalculationTimestamp = time.Now()
+	order.CalculationTimestamp = s.clock.Now()
 	
-	// Create calculation history
+	return applied, nil
+}
+
+// orderSetting is the setting called name as it applies to one order: a
+// percentage of override when the order has one, the global setting otherwise
+func (s *orderService) orderSetting(name string, override *float64) (*models.FinancialSettings, error) {
+	if override != nil {
+		return &models.FinancialSettings{SettingName: name, PercentageValue: *override, IsPercentage: true}, nil
+	}
+	return s.settings.GetSetting(name)
+}
+
+// recordCalculation writes a net profit calculation history row attributed
+// to actorID
+func (s *orderService) recordCalculation(order *models.Order, applied appliedDeductions, actorID uint, previousNetProfit *float64) error {
 	history := &models.CalculationHistory{
 		OrderID:              order.ID,
 		CalculationType:      "net_profit",
 		InputValue:           order.TotalAmount,
-		PercentageUsed:       taxSettings.PercentageValue + marketingSettings.PercentageValue + rentalSettings.PercentageValue,
+		PercentageUsed:       applied.Percentage,
+		FixedAmountUsed:      applied.FixedAmount,
 		CalculatedAmount:     order.NetProfit,
-		CalculationTimestamp: time.Now(),
+		PreviousNetProfit:    previousNetProfit,
+		UpdatedBy:            actorID,
+		CalculationTimestamp: s.clock.Now(),
 	}
 	
 	return s.financialRepo.CreateCalculationHistory(history)
@@ -123,6 +424,220 @@ func (s *orderService) GetAllOrders() ([]models.Order, error) {
 	return s.orderRepo.GetAll()
 }
 
+// IsValidOrderStatus reports whether status is one of the OrderStatus constants
+func IsValidOrderStatus(status string) bool {
+	switch models.OrderStatus(status) {
+	case models.OrderPending, models.OrderProcessing, models.OrderCompleted, models.OrderCancelled:
+		return true
+	}
+	return false
+}
+
+func validateOrderStatus(status string) error {
+	if !IsValidOrderStatus(status) {
+		return fmt.Errorf("invalid order status %q, valid statuses: pending, processing, completed, cancelled", status)
+	}
+	return nil
+}
+
+// ErrCancelWithCancelOrder is returned when UpdateStatus is asked to cancel
+// an order; cancelling goes through CancelOrder so the revenue is reversed
+var ErrCancelWithCancelOrder = errors.New("orders are cancelled with CancelOrder so their revenue is reversed")
+
+// ErrOrderCancelled is returned when changing the status of a cancelled order
+var ErrOrderCancelled = errors.New("order is cancelled and its status can no longer change")
+
+// ErrNoTerminalItemStatus is returned when completing an order while no item
+// status is configured as terminal, so its items cannot be completed with it
+var ErrNoTerminalItemStatus = errors.New("no terminal order item status is configured")
+
+// UpdateStatus moves an order to status. Completing an order also moves all
+// of its items to the first terminal item status. Orders cannot be cancelled
+// or leave cancelled this way
+func (s *orderService) UpdateStatus(orderID uint, status string) error {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if err := validateOrderStatus(status); err != nil {
+		return err
+	}
+	if status == string(models.OrderCancelled) {
+		return ErrCancelWithCancelOrder
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+	if order.Status == string(models.OrderCancelled) {
+		return ErrOrderCancelled
+	}
+
+	itemStatus := ""
+	if status == string(models.OrderCompleted) {
+		if len(s.itemStatuses.TerminalStatuses) == 0 {
+			return ErrNoTerminalItemStatus
+		}
+		itemStatus = s.itemStatuses.TerminalStatuses[0]
+	}
+
+	return s.orderRepo.UpdateStatus(orderID, status, itemStatus)
+}
+
+// CustomerSummary aggregates a customer's orders. Cancelled orders are
+// counted separately and left out of the totals
+type CustomerSummary struct {
+	Customer          string
+	OrderCount        int
+	CancelledCount    int
+	TotalRevenue      float64
+	TotalNetProfit    float64
+	AverageOrderValue float64
+}
+
+// GetUpcomingDeliveries returns open orders to be delivered from today until
+// within from now
+func (s *orderService) GetUpcomingDeliveries(within time.Duration) ([]models.Order, error) {
+	now := s.clock.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return s.orderRepo.GetUpcomingDeliveries(today, now.Add(within).Sub(today))
+}
+
+// GetOrdersForCustomer returns a customer's orders found by name or by phone
+// number in any format
+func (s *orderService) GetOrdersForCustomer(nameOrPhone string) ([]models.Order, error) {
+	nameOrPhone = strings.TrimSpace(nameOrPhone)
+	if nameOrPhone == "" {
+		return nil, errors.New("customer name or phone cannot be empty")
+	}
+	return s.orderRepo.GetByCustomer(nameOrPhone)
+}
+
+// GetCustomerSummary totals the orders of the named customer, matched
+// case-insensitively. A customer without orders yields an empty summary
+func (s *orderService) GetCustomerSummary(name string) (*CustomerSummary, error) {
+	name = strings.TrimSpace(name)
+	orders, err := s.orderRepo.SearchByCustomer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &CustomerSummary{Customer: name}
+	for _, order := range orders {
+		summary.Customer = order.CustomerName
+		if order.Status == string(models.OrderCancelled) {
+			summary.CancelledCount++
+			continue
+		}
+		summary.OrderCount++
+		summary.TotalRevenue += order.TotalAmount
+		summary.TotalNetProfit += order.NetProfit
+	}
+
+	if summary.OrderCount > 0 {
+		summary.AverageOrderValue = summary.TotalRevenue / float64(summary.OrderCount)
+	}
+	return summary, nil
+}
+
+// ErrOrderAlreadyCancelled is returned when cancelling a cancelled order
+var ErrOrderAlreadyCancelled = errors.New("order is already cancelled")
+
+// calculationTypeReversal marks the history row that takes a cancelled
+// order's revenue and profit back out
+const calculationTypeReversal = "net_profit_reversal"
+
+// CancelOrder cancels the order, writes a calculation history row reversing
+// its revenue and net profit, and keeps the reason as an order note, all in
+// one transaction
+func (s *orderService) CancelOrder(orderID uint, reason string, actorID uint) error {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+	if order.Status == string(models.OrderCancelled) {
+		return ErrOrderAlreadyCancelled
+	}
+
+	now := s.clock.Now()
+	netProfit := order.NetProfit
+	history := &models.CalculationHistory{
+		OrderID:              order.ID,
+		CalculationType:      calculationTypeReversal,
+		InputValue:           -order.TotalAmount,
+		CalculatedAmount:     -order.NetProfit,
+		PreviousNetProfit:    &netProfit,
+		UpdatedBy:            actorID,
+		CalculationTimestamp: now,
+	}
+
+	content := "Order cancelled"
+	if reason = strings.TrimSpace(reason); reason != "" {
+		content += ": " + reason
+	}
+	note := &models.OrderNote{
+		OrderID:   order.ID,
+		UserID:    actorID,
+		Content:   content,
+		CreatedAt: now,
+	}
+
+	return s.orderRepo.Cancel(order.ID, history, note)
+}
+
+// FinancialSummary totals the orders in a date range. CancelledCount says
+// how many of the counted orders were cancelled; it is always zero unless
+// cancelled orders were asked for
+type FinancialSummary struct {
+	OrderCount     int
+	CancelledCount int
+	TotalRevenue   float64
+	TotalNetProfit float64
+}
+
+// GetFinancialSummary totals the orders in the range. Cancelled orders are
+// left out unless includeCancelled is set
+func (s *orderService) GetFinancialSummary(startDate, endDate time.Time, includeCancelled bool) (*FinancialSummary, error) {
+	var exclude []string
+	if !includeCancelled {
+		exclude = append(exclude, string(models.OrderCancelled))
+	}
+
+	orders, err := s.orderRepo.GetByDateRange(startDate, endDate, exclude...)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &FinancialSummary{}
+	for _, order := range orders {
+		if order.Status == string(models.OrderCancelled) {
+			summary.CancelledCount++
+		}
+		summary.OrderCount++
+		summary.TotalRevenue += order.TotalAmount
+		summary.TotalNetProfit += order.NetProfit
+	}
+	return summary, nil
+}
+
+// MergeCustomer moves all orders of customer from onto customer to
+func (s *orderService) MergeCustomer(from, to string) (int64, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return 0, errors.New("customer names must not be empty")
+	}
+	if from == to {
+		return 0, errors.New("source and target customer must be different")
+	}
+	return s.orderRepo.UpdateCustomerName(from, to)
+}
+
+func (s *orderService) SearchOrders(filter repository.OrderFilter) ([]models.Order, int64, error) {
+	return s.orderRepo.Search(filter)
+}
+
+func (s *orderService) GetAllOrdersPaginated(offset, limit int) ([]models.Order, int64, error) {
+	return s.orderRepo.GetAllPaginated(offset, limit)
+}
+
 // Order Items methods implementation
 
 func (s *orderService) AddItemToOrder(orderID uint, itemName string, quantity int, price float64, description string) error {
@@ -146,7 +661,51 @@ func (s *orderService) AddItemToOrder(orderID uint, itemName string, quantity in
 		Status:      string(models.ItemPending),
 	}
 
-	return s.orderItemRepo.Create(orderItem)
+	if err := s.orderItemRepo.Create(orderItem); err != nil {
+		return err
+	}
+
+	if features.Enabled(features.SyncOrderTotal) {
+		if _, _, _, err := s.ReconcileOrderTotal(orderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// totalTolerance absorbs float rounding when comparing money amounts
+const totalTolerance = 0.005
+
+// ReconcileOrderTotal compares the sum of an order's item line totals with its
+// stated total. When the sync_order_total feature is on and the order has
+// items, a mismatched total is replaced by the items total and the returned
+// figures describe the order after that update
+func (s *orderService) ReconcileOrderTotal(orderID uint) (bool, float64, float64, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	items, err := s.orderItemRepo.GetByOrderID(orderID)
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	itemsTotal := 0.0
+	for _, item := range items {
+		itemsTotal += item.TotalPrice
+	}
+
+	matched := math.Abs(itemsTotal-order.TotalAmount) < totalTolerance
+	if matched || len(items) == 0 || !features.Enabled(features.SyncOrderTotal) {
+		return matched, itemsTotal, order.TotalAmount, nil
+	}
+
+	order.TotalAmount = itemsTotal
+	if err := s.UpdateOrder(order, order.CreatedBy); err != nil {
+		return false, itemsTotal, order.TotalAmount, err
+	}
+	return true, itemsTotal, order.TotalAmount, nil
 }
 
 func (s *orderService) GetOrderItems(orderID uint) ([]*models.OrderItem, error) {
@@ -162,6 +721,11 @@ func (s *orderService) DeleteOrderItem(itemID uint) error {
 }
 
 func (s *orderService) UpdateItemStatus(itemID uint, status string) error {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if !s.itemStatuses.IsValid(status) {
+		return fmt.Errorf("invalid item status %q, valid statuses: %s", status, strings.Join(s.itemStatuses.Statuses, ", "))
+	}
+
 	orderItem, err := s.orderItemRepo.GetByID(itemID)
 	if err != nil {
 		return err
@@ -174,6 +738,33 @@ func (s *orderService) UpdateItemStatus(itemID uint, status string) error {
 	return s.orderItemRepo.Update(orderItem)
 }
 
+// AddNote attaches an internal note by userID to an existing order
+func (s *orderService) AddNote(orderID, userID uint, content string) (*models.OrderNote, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("note cannot be empty")
+	}
+
+	if _, err := s.orderRepo.GetByID(orderID); err != nil {
+		return nil, err
+	}
+
+	note := &models.OrderNote{
+		OrderID:   orderID,
+		UserID:    userID,
+		Content:   content,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.orderNoteRepo.Create(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+func (s *orderService) GetNotes(orderID uint) ([]models.OrderNote, error) {
+	return s.orderNoteRepo.GetByOrderID(orderID)
+}
+
 func (s *orderService) GetOrderItemsSummary(orderID uint) (map[string]interface{}, error) {
 	orderItems, err := s.orderItemRepo.GetByOrderID(orderID)
 	if err != nil {
@@ -185,24 +776,103 @@ func (s *orderService) GetOrderItemsSummary(orderID uint) (map[string]interface{
 	totalValue := 0.0
 	pendingItems := 0
 	completedItems := 0
+	statusCounts := make(map[string]int)
 
 	for _, item := range orderItems {
 		totalQuantity += item.Quantity
 		totalValue += item.TotalPrice
+		statusCounts[item.Status]++
 		
-		if item.Status == string(models.ItemPending) {
-			pendingItems++
-		} else if item.Status == string(models.ItemCompleted) {
+		// Any configured terminal status counts towards completion; every
+		// other status, such as in_production or ready, is still pending
+		if s.itemStatuses.IsTerminal(item.Status) {
 			completedItems++
+		} else {
+			pendingItems++
 		}
 	}
 
-	return map[string]interface{}{
+	// An order without items has nothing to complete; report 0 rather than NaN
+	completionRate := 0.0
+	if totalItems > 0 {
+		completionRate = float64(completedItems) / float64(totalItems) * 100
+	}
+
+	summary := map[string]interface{}{
 		"total_items":      totalItems,
 		"total_quantity":   totalQuantity,
 		"total_value":      totalValue,
 		"pending_items":    pendingItems,
 		"completed_items":  completedItems,
-		"completion_rate":  float64(completedItems) / float64(totalItems) * 100,
-	}, nil
+		"status_counts":    statusCounts,
+		"completion_rate":  completionRate,
+		"has_items":        totalItems > 0,
+	}
+
+	// Flag orders whose items no longer add up to the stated total
+	matched, _, orderTotal, err := s.ReconcileOrderTotal(orderID)
+	if err != nil {
+		return nil, err
+	}
+	summary["order_total"] = orderTotal
+	summary["total_matches"] = matched
+	if !matched {
+		summary["warning"] = fmt.Sprintf("items total %.2f does not match order total %.2f", totalValue, orderTotal)
+	}
+
+	return summary, nil
+}
+
+// GenerateInvoicePDF renders a printable invoice with the order's items,
+// total, tax and net figures
+func (s *orderService) GenerateInvoicePDF(orderID uint) ([]byte, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.orderItemRepo.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	money := func(amount float64) string {
+		return currency.Format(amount, s.currencyCode)
+	}
+
+	doc := pdf.New()
+	doc.Heading("INVOICE")
+	doc.Space()
+	doc.Text("Order Number: " + order.OrderNumber)
+	doc.Text("Order Date: " + order.OrderDate.Format("2006-01-02"))
+	doc.Text("Customer: " + order.CustomerName)
+	if order.CustomerPhone != "" {
+		doc.Text("Phone: " + order.CustomerPhone)
+	}
+	doc.Text("Status: " + order.Status)
+	doc.Space()
+
+	columns := []float64{0, 230, 290, 390}
+	doc.Row(pdf.Bold, []string{"Item", "Qty", "Unit Price", "Line Total"}, columns)
+	itemsTotal := 0.0
+	for _, item := range items {
+		doc.Row(pdf.Regular, []string{item.ItemName, fmt.Sprintf("%d", item.Quantity), money(item.UnitPrice), money(item.TotalPrice)}, columns)
+		itemsTotal += item.TotalPrice
+	}
+	if len(items) == 0 {
+		doc.Text("(no items)")
+	}
+	doc.Space()
+
+	summary := []float64{0, 390}
+	if len(items) > 0 {
+		doc.Row(pdf.Regular, []string{"Items Total", money(itemsTotal)}, summary)
+	}
+	doc.Row(pdf.Bold, []string{"Order Total", money(order.TotalAmount)}, summary)
+	doc.Row(pdf.Regular, []string{fmt.Sprintf("Tax (%.2f%%)", order.TaxPercentage), money(order.TaxAmount)}, summary)
+	doc.Row(pdf.Regular, []string{fmt.Sprintf("Marketing (%.2f%%)", order.MarketingPercentage), money(order.MarketingCost)}, summary)
+	doc.Row(pdf.Regular, []string{fmt.Sprintf("Rental (%.2f%%)", order.RentalPercentage), money(order.RentalCost)}, summary)
+	doc.Row(pdf.Bold, []string{"Net", money(order.NetProfit)}, summary)
+
+	return doc.Bytes()
 }
@@ -0,0 +1,1017 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"task_manager/internal/clock"
+	"task_manager/internal/features"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestOrderServiceUpdateStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current models.OrderStatus
+		status  string
+		want    error
+	}{
+		{name: "pending to processing", current: models.OrderPending, status: "processing"},
+		{name: "processing to completed", current: models.OrderProcessing, status: " Completed "},
+		{name: "cancel goes through CancelOrder", current: models.OrderPending, status: "cancelled", want: ErrCancelWithCancelOrder},
+		{name: "cancelled order cannot be reopened", current: models.OrderCancelled, status: "pending", want: ErrOrderCancelled},
+		{name: "cancelled order cannot be completed", current: models.OrderCancelled, status: "completed", want: ErrOrderCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(&models.Order{ID: 1, Status: string(tt.current)})
+			svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+
+			err := svc.UpdateStatus(1, tt.status)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil && len(repo.statusSet) != 0 {
+				t.Errorf("status was written despite the error: %v", repo.statusSet)
+			}
+		})
+	}
+
+	t.Run("invalid status", func(t *testing.T) {
+		repo := newFakeOrderRepo(&models.Order{ID: 1, Status: string(models.OrderPending)})
+		svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+		if err := svc.UpdateStatus(1, "shipped"); err == nil {
+			t.Fatal("expected an error for an unknown status")
+		}
+	})
+}
+
+func TestOrderServiceUpdateStatusCascadesToItems(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		itemStatuses   ItemStatusConfig
+		wantItemStatus string
+		wantErr        error
+	}{
+		{name: "completed completes the items", status: "completed", itemStatuses: DefaultItemStatusConfig(), wantItemStatus: "completed"},
+		{name: "processing leaves the items", status: "processing", itemStatuses: DefaultItemStatusConfig()},
+		{name: "pending leaves the items", status: "pending", itemStatuses: DefaultItemStatusConfig()},
+		{
+			name:           "custom terminal status",
+			status:         "completed",
+			itemStatuses:   ItemStatusConfig{Statuses: []string{"queued", "baked", "delivered"}, TerminalStatuses: []string{"delivered"}},
+			wantItemStatus: "delivered",
+		},
+		{
+			name:           "first of several terminal statuses",
+			status:         "completed",
+			itemStatuses:   ItemStatusConfig{Statuses: []string{"queued", "picked_up", "delivered"}, TerminalStatuses: []string{"picked_up", "delivered"}},
+			wantItemStatus: "picked_up",
+		},
+		{
+			name:         "no terminal status configured",
+			status:       "completed",
+			itemStatuses: ItemStatusConfig{Statuses: []string{"queued", "baked"}},
+			wantErr:      ErrNoTerminalItemStatus,
+		},
+		{
+			name:         "no terminal status needed for processing",
+			status:       "processing",
+			itemStatuses: ItemStatusConfig{Statuses: []string{"queued", "baked"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(&models.Order{ID: 1, Status: string(models.OrderProcessing)})
+			svc := NewOrderService(repo, nil, nil, nil, nil, tt.itemStatuses, "IDR", clock.Real{})
+
+			err := svc.UpdateStatus(1, tt.status)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.itemStatusSet) != 0 {
+					t.Errorf("status saved despite the error: %q", repo.itemStatusSet)
+				}
+				return
+			}
+			if len(repo.itemStatusSet) != 1 || repo.itemStatusSet[0] != tt.wantItemStatus {
+				t.Errorf("item status = %q, want %q", repo.itemStatusSet, tt.wantItemStatus)
+			}
+		})
+	}
+}
+
+func TestOrderServiceCancelOrder(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   models.OrderStatus
+		reason   string
+		want     error
+		wantNote string
+	}{
+		{name: "with reason", status: models.OrderPending, reason: " customer changed mind ", wantNote: "Order cancelled: customer changed mind"},
+		{name: "without reason", status: models.OrderCompleted, wantNote: "Order cancelled"},
+		{name: "already cancelled", status: models.OrderCancelled, want: ErrOrderAlreadyCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(&models.Order{ID: 1, Status: string(tt.status), TotalAmount: 100000, NetProfit: 80000})
+			svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.NewFake(now))
+
+			err := svc.CancelOrder(1, tt.reason, 9)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(repo.cancelled) != 0 {
+					t.Error("order was cancelled again")
+				}
+				return
+			}
+
+			if len(repo.cancelled) != 1 {
+				t.Fatalf("Cancel called %d times, want 1", len(repo.cancelled))
+			}
+			history := repo.histories[0]
+			if history.CalculationType != calculationTypeReversal || history.InputValue != -100000 || history.CalculatedAmount != -80000 {
+				t.Errorf("unexpected reversal row %+v", history)
+			}
+			if history.UpdatedBy != 9 || !history.CalculationTimestamp.Equal(now) {
+				t.Errorf("reversal row not attributed: %+v", history)
+			}
+			if note := repo.notes[0]; note.Content != tt.wantNote || note.UserID != 9 {
+				t.Errorf("note = %+v, want %q by user 9", note, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestGenerateInvoicePDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []*models.OrderItem
+	}{
+		{name: "with items", items: []*models.OrderItem{
+			{ItemName: "Kue Lapis", Quantity: 2, UnitPrice: 50000, TotalPrice: 100000},
+			{ItemName: "Bolu Pandan", Quantity: 1, UnitPrice: 75000, TotalPrice: 75000},
+		}},
+		{name: "without items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(&models.Order{ID: 1, OrderNumber: "ORD-0001", CustomerName: "Siti", TotalAmount: 175000, Status: string(models.OrderPending)})
+			items := &fakeOrderItemRepo{items: map[uint][]*models.OrderItem{1: tt.items}}
+			svc := NewOrderService(repo, items, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+
+			out, err := svc.GenerateInvoicePDF(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(out, []byte("%PDF-")) || !bytes.Contains(out, []byte("%%EOF")) {
+				t.Errorf("output is not a complete PDF (%d bytes)", len(out))
+			}
+		})
+	}
+
+	t.Run("missing order", func(t *testing.T) {
+		svc := NewOrderService(newFakeOrderRepo(), &fakeOrderItemRepo{}, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+		if _, err := svc.GenerateInvoicePDF(9); err == nil {
+			t.Error("expected an error for a missing order")
+		}
+	})
+}
+
+func TestOrderItemsSummaryCustomStatuses(t *testing.T) {
+	statuses := ItemStatusConfig{
+		Statuses:         []string{"pending", "in_production", "ready", "delivered", "cancelled"},
+		TerminalStatuses: []string{"delivered", "cancelled"},
+	}
+
+	tests := []struct {
+		name          string
+		statuses      []string
+		wantPending   int
+		wantCompleted int
+		wantRate      float64
+	}{
+		{name: "all pending", statuses: []string{"pending", "pending"}, wantPending: 2},
+		{name: "intermediate statuses are pending", statuses: []string{"in_production", "ready", "pending", "delivered"}, wantPending: 3, wantCompleted: 1, wantRate: 25},
+		{name: "every terminal status completes", statuses: []string{"delivered", "cancelled"}, wantCompleted: 2, wantRate: 100},
+		{name: "no items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []*models.OrderItem
+			total := 0.0
+			for i, status := range tt.statuses {
+				items = append(items, &models.OrderItem{ID: uint(i + 1), OrderID: 1, Status: status, Quantity: 1, TotalPrice: 10000})
+				total += 10000
+			}
+			repo := newFakeOrderRepo(&models.Order{ID: 1, TotalAmount: total})
+			svc := NewOrderService(repo, &fakeOrderItemRepo{items: map[uint][]*models.OrderItem{1: items}}, nil, nil, nil, statuses, "IDR", clock.Real{})
+
+			summary, err := svc.GetOrderItemsSummary(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary["pending_items"] != tt.wantPending || summary["completed_items"] != tt.wantCompleted {
+				t.Errorf("pending/completed = %v/%v, want %d/%d", summary["pending_items"], summary["completed_items"], tt.wantPending, tt.wantCompleted)
+			}
+			if summary["completion_rate"] != tt.wantRate {
+				t.Errorf("completion_rate = %v, want %v", summary["completion_rate"], tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestUpdateItemStatusCustomStatuses(t *testing.T) {
+	statuses := ItemStatusConfig{Statuses: []string{"pending", "in_production", "delivered"}, TerminalStatuses: []string{"delivered"}}
+
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{status: "in_production"},
+		{status: " Delivered "},
+		{status: "completed", wantErr: true},
+		{status: "shipped", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			item := &models.OrderItem{ID: 1, OrderID: 1, Status: "pending"}
+			svc := NewOrderService(newFakeOrderRepo(), &fakeOrderItemRepo{items: map[uint][]*models.OrderItem{1: {item}}}, nil, nil, nil, statuses, "IDR", clock.Real{})
+
+			err := svc.UpdateItemStatus(1, tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && item.Status != "pending" {
+				t.Errorf("status changed to %q despite the error", item.Status)
+			}
+		})
+	}
+}
+
+func TestCreateOrderNumbers(t *testing.T) {
+	repo := newFakeOrderRepo()
+	financial := &fakeFinancialRepo{}
+	svc := NewOrderService(repo, nil, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+	first := &models.Order{CustomerName: "Siti", TotalAmount: 100000}
+	second := &models.Order{CustomerName: "Budi", TotalAmount: 50000}
+	for _, order := range []*models.Order{first, second} {
+		if err := svc.CreateOrder(order); err != nil {
+			t.Fatal(err)
+		}
+		if order.OrderNumber == "" {
+			t.Errorf("order %d has no order number", order.ID)
+		}
+		if order.Status != string(models.OrderPending) {
+			t.Errorf("status = %q, want %q", order.Status, models.OrderPending)
+		}
+	}
+	if first.OrderNumber == second.OrderNumber {
+		t.Errorf("both orders got %q", first.OrderNumber)
+	}
+}
+
+func TestCreateOrderRetriesNumberConflicts(t *testing.T) {
+	conflict := &pgconn.PgError{Code: "23505", ConstraintName: "orders_order_number_key"}
+	otherUnique := &pgconn.PgError{Code: "23505", ConstraintName: "orders_pkey"}
+	dbErr := errors.New("connection reset")
+
+	tests := []struct {
+		name         string
+		orderNumber  string
+		createErrs   []error
+		withItems    bool
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "conflict then success", createErrs: []error{conflict}, wantAttempts: 2},
+		{name: "conflict then success with items", createErrs: []error{conflict}, withItems: true, wantAttempts: 2},
+		{name: "gives up after the attempt limit", createErrs: []error{conflict, conflict, conflict, conflict, conflict}, wantErr: conflict, wantAttempts: maxOrderNumberAttempts},
+		{name: "gives up with items too", createErrs: []error{conflict, conflict, conflict, conflict, conflict}, withItems: true, wantErr: conflict, wantAttempts: maxOrderNumberAttempts},
+		{name: "other unique violation is not retried", createErrs: []error{otherUnique}, wantErr: otherUnique, wantAttempts: 1},
+		{name: "other errors are not retried", createErrs: []error{dbErr}, wantErr: dbErr, wantAttempts: 1},
+		{name: "caller's own number is not replaced", orderNumber: "INV-7", createErrs: []error{conflict}, wantErr: conflict, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo()
+			repo.createErrs = tt.createErrs
+			financial := &fakeFinancialRepo{}
+			svc := NewOrderService(repo, nil, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+			order := &models.Order{OrderNumber: tt.orderNumber, CustomerName: "Siti", TotalAmount: 100000}
+			var err error
+			if tt.withItems {
+				err = svc.CreateOrderWithItems(order, []models.OrderItem{{ItemName: "Kue", Quantity: 1, UnitPrice: 100000}})
+			} else {
+				err = svc.CreateOrder(order)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if attempts := len(tt.createErrs) - len(repo.createErrs) + len(repo.orders); attempts != tt.wantAttempts {
+				t.Errorf("%d insert attempts, want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantErr == nil && len(repo.orders) != 1 {
+				t.Errorf("%d orders stored, want 1", len(repo.orders))
+			}
+			if tt.orderNumber != "" && order.OrderNumber != tt.orderNumber {
+				t.Errorf("OrderNumber = %q, want the caller's %q", order.OrderNumber, tt.orderNumber)
+			}
+		})
+	}
+}
+
+func TestIsOrderNumberConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "constraint name", err: &pgconn.PgError{Code: "23505", ConstraintName: "idx_orders_order_number"}, want: true},
+		{name: "detail", err: &pgconn.PgError{Code: "23505", Detail: "Key (order_number)=(ORD-1) already exists."}, want: true},
+		{name: "wrapped", err: fmt.Errorf("create: %w", &pgconn.PgError{Code: "23505", ConstraintName: "orders_order_number_key"}), want: true},
+		{name: "other column", err: &pgconn.PgError{Code: "23505", ConstraintName: "orders_pkey"}},
+		{name: "other code", err: &pgconn.PgError{Code: "23503", ConstraintName: "orders_order_number_key"}},
+		{name: "not a postgres error", err: errors.New("duplicate order_number")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOrderNumberConflict(tt.err); got != tt.want {
+				t.Errorf("isOrderNumberConflict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeCustomer(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  string
+		wantCount int64
+		wantNames map[uint]string
+		wantErr   bool
+	}{
+		{name: "renames every matching order", from: "Jon", to: "John", wantCount: 2, wantNames: map[uint]string{1: "John", 2: "John", 3: "John", 4: "jon"}},
+		{name: "names are trimmed", from: " Jon ", to: " John ", wantCount: 2, wantNames: map[uint]string{1: "John", 2: "John", 3: "John", 4: "jon"}},
+		{name: "no matching orders", from: "Ann", to: "John", wantNames: map[uint]string{1: "Jon", 2: "Jon", 3: "John", 4: "jon"}},
+		{name: "same name", from: "Jon", to: "Jon", wantErr: true},
+		{name: "empty target", from: "Jon", to: "  ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(
+				&models.Order{ID: 1, CustomerName: "Jon"},
+				&models.Order{ID: 2, CustomerName: "Jon"},
+				&models.Order{ID: 3, CustomerName: "John"},
+				&models.Order{ID: 4, CustomerName: "jon"},
+			)
+			svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+
+			count, err := svc.MergeCustomer(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+			for id, name := range tt.wantNames {
+				if got := repo.orders[id].CustomerName; got != name {
+					t.Errorf("order %d customer = %q, want %q", id, got, name)
+				}
+			}
+		})
+	}
+}
+
+func TestGetOrdersByStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantIDs []uint
+		wantErr bool
+	}{
+		{status: "pending", wantIDs: []uint{1, 3}},
+		{status: " Cancelled ", wantIDs: []uint{4}},
+		{status: "processing"},
+		{status: "shipped", wantErr: true},
+		{status: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			repo := newFakeOrderRepo(
+				&models.Order{ID: 1, Status: "pending"},
+				&models.Order{ID: 2, Status: "completed"},
+				&models.Order{ID: 3, Status: "pending"},
+				&models.Order{ID: 4, Status: "cancelled"},
+			)
+			svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+
+			orders, err := svc.GetOrdersByStatus(tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "valid statuses: pending, processing, completed, cancelled") {
+				t.Errorf("error %q does not list the valid statuses", err)
+			}
+			if len(orders) != len(tt.wantIDs) {
+				t.Fatalf("got %d orders, want %d", len(orders), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if orders[i].ID != id {
+					t.Errorf("orders[%d] = #%d, want #%d", i, orders[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateOrderRecordsActor(t *testing.T) {
+	financial := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+		"tax_rate": {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+	}}
+	orders := newFakeOrderRepo(&models.Order{ID: 1, TotalAmount: 100000, NetProfit: 90000})
+	svc := NewOrderService(orders, nil, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+	updates := []struct {
+		actor              uint
+		total              float64
+		wantNetProfit      float64
+		wantPreviousProfit float64
+	}{
+		{actor: 2, total: 200000, wantNetProfit: 180000, wantPreviousProfit: 90000},
+		{actor: 3, total: 150000, wantNetProfit: 135000, wantPreviousProfit: 180000},
+	}
+
+	for i, update := range updates {
+		order, err := orders.GetByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		order.TotalAmount = update.total
+		if err := svc.UpdateOrder(order, update.actor); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(financial.history) != i+1 {
+			t.Fatalf("%d history rows after %d updates", len(financial.history), i+1)
+		}
+		row := financial.history[i]
+		if row.OrderID != 1 || row.UpdatedBy != update.actor || row.CalculatedAmount != update.wantNetProfit {
+			t.Errorf("update %d: history order=%d by=%d amount=%v, want order 1 by %d amount %v",
+				i, row.OrderID, row.UpdatedBy, row.CalculatedAmount, update.actor, update.wantNetProfit)
+		}
+		if row.PreviousNetProfit == nil || *row.PreviousNetProfit != update.wantPreviousProfit {
+			t.Errorf("update %d: previous net profit = %v, want %v", i, row.PreviousNetProfit, update.wantPreviousProfit)
+		}
+	}
+}
+
+func TestGetCustomerSummary(t *testing.T) {
+	repo := newFakeOrderRepo(
+		&models.Order{ID: 1, CustomerName: "Siti", TotalAmount: 100000, NetProfit: 80000, Status: "completed"},
+		&models.Order{ID: 2, CustomerName: "siti", TotalAmount: 300000, NetProfit: 240000, Status: "pending"},
+		&models.Order{ID: 3, CustomerName: "SITI", TotalAmount: 500000, NetProfit: 400000, Status: "cancelled"},
+		&models.Order{ID: 4, CustomerName: "Budi", TotalAmount: 900000, NetProfit: 700000, Status: "completed"},
+	)
+	svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+
+	tests := []struct {
+		name string
+		want CustomerSummary
+	}{
+		{name: " siti ", want: CustomerSummary{Customer: "SITI", OrderCount: 2, CancelledCount: 1, TotalRevenue: 400000, TotalNetProfit: 320000, AverageOrderValue: 200000}},
+		{name: "BUDI", want: CustomerSummary{Customer: "Budi", OrderCount: 1, TotalRevenue: 900000, TotalNetProfit: 700000, AverageOrderValue: 900000}},
+		{name: "Nobody", want: CustomerSummary{Customer: "Nobody"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.GetCustomerSummary(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("GetCustomerSummary(%q) = %+v, want %+v", tt.name, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAndListOrderNotes(t *testing.T) {
+	now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+	notes := &fakeOrderNoteRepo{}
+	svc := NewOrderService(newFakeOrderRepo(&models.Order{ID: 1}, &models.Order{ID: 2}), nil, notes, nil, nil, ItemStatusConfig{}, "IDR", clk)
+
+	tests := []struct {
+		name    string
+		orderID uint
+		content string
+		wantErr bool
+	}{
+		{name: "first note", orderID: 1, content: "  customer requested gift wrap  "},
+		{name: "second note", orderID: 1, content: "deliver after 5pm"},
+		{name: "another order", orderID: 2, content: "paid in cash"},
+		{name: "empty", orderID: 1, content: "   ", wantErr: true},
+		{name: "unknown order", orderID: 99, content: "lost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		clk.Advance(time.Minute)
+		note, err := svc.AddNote(tt.orderID, 7, tt.content)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && (note.Content != strings.TrimSpace(tt.content) || note.UserID != 7 || !note.CreatedAt.Equal(clk.Now())) {
+			t.Errorf("%s: note = %+v", tt.name, note)
+		}
+	}
+
+	got, err := svc.GetNotes(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"customer requested gift wrap", "deliver after 5pm"}
+	if len(got) != len(want) {
+		t.Fatalf("order 1 notes = %+v, want %q", got, want)
+	}
+	for i := range want {
+		if got[i].Content != want[i] || got[i].OrderID != 1 {
+			t.Errorf("note %d = %+v, want %q on order 1", i, got[i], want[i])
+		}
+	}
+	if len(notes.notes) != 3 {
+		t.Errorf("stored %d notes, want 3", len(notes.notes))
+	}
+}
+
+func TestCalculateFinancialsUsesClock(t *testing.T) {
+	at := time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
+	repo := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+		"tax_rate": {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+	}}
+	svc := NewOrderService(nil, nil, nil, repo, newTestSettings(repo), ItemStatusConfig{}, "IDR", clock.NewFake(at))
+
+	order := &models.Order{ID: 1, TotalAmount: 100000}
+	if err := svc.CalculateFinancials(order); err != nil {
+		t.Fatal(err)
+	}
+	if !order.CalculationTimestamp.Equal(at) {
+		t.Errorf("CalculationTimestamp = %v, want %v", order.CalculationTimestamp, at)
+	}
+	if len(repo.history) == 0 {
+		t.Fatal("no calculation history recorded")
+	}
+	for _, entry := range repo.history {
+		if !entry.CalculationTimestamp.Equal(at) {
+			t.Errorf("%s history at %v, want %v", entry.CalculationType, entry.CalculationTimestamp, at)
+		}
+	}
+}
+
+func TestReconcileOrderTotal(t *testing.T) {
+	items := []*models.OrderItem{
+		{ID: 1, OrderID: 1, Quantity: 2, UnitPrice: 25000, TotalPrice: 50000},
+		{ID: 2, OrderID: 1, Quantity: 1, UnitPrice: 30000.10, TotalPrice: 30000.10},
+	}
+
+	tests := []struct {
+		name           string
+		orderTotal     float64
+		items          []*models.OrderItem
+		sync           bool
+		wantMatched    bool
+		wantItemsTotal float64
+		wantOrderTotal float64
+		wantWarning    string
+	}{
+		{name: "matched", orderTotal: 80000.10, items: items, wantMatched: true, wantItemsTotal: 80000.10, wantOrderTotal: 80000.10},
+		{name: "within rounding", orderTotal: 80000.101, items: items, wantMatched: true, wantItemsTotal: 80000.10, wantOrderTotal: 80000.101},
+		{name: "mismatched", orderTotal: 100000, items: items, wantItemsTotal: 80000.10, wantOrderTotal: 100000, wantWarning: "items total 80000.10 does not match order total 100000.00"},
+		{name: "mismatched and synced", orderTotal: 100000, items: items, sync: true, wantMatched: true, wantItemsTotal: 80000.10, wantOrderTotal: 80000.10},
+		{name: "no items is never synced", orderTotal: 100000, sync: true, wantOrderTotal: 100000, wantWarning: "items total 0.00 does not match order total 100000.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := features.Set(features.SyncOrderTotal, tt.sync); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { features.Set(features.SyncOrderTotal, false) })
+
+			financial := &fakeFinancialRepo{}
+			orders := newFakeOrderRepo(&models.Order{ID: 1, TotalAmount: tt.orderTotal})
+			itemRepo := &fakeOrderItemRepo{items: map[uint][]*models.OrderItem{1: tt.items}}
+			svc := NewOrderService(orders, itemRepo, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+			matched, itemsTotal, orderTotal, err := svc.ReconcileOrderTotal(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched != tt.wantMatched || math.Abs(itemsTotal-tt.wantItemsTotal) > 1e-6 || math.Abs(orderTotal-tt.wantOrderTotal) > 1e-6 {
+				t.Errorf("ReconcileOrderTotal() = %v, %v, %v; want %v, %v, %v", matched, itemsTotal, orderTotal, tt.wantMatched, tt.wantItemsTotal, tt.wantOrderTotal)
+			}
+			if stored := orders.orders[1].TotalAmount; math.Abs(stored-tt.wantOrderTotal) > 1e-6 {
+				t.Errorf("stored total = %v, want %v", stored, tt.wantOrderTotal)
+			}
+
+			summary, err := svc.GetOrderItemsSummary(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			warning, _ := summary["warning"].(string)
+			if warning != tt.wantWarning {
+				t.Errorf("summary warning = %q, want %q", warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestOrderItemsSummaryWithoutItems(t *testing.T) {
+	tests := []struct {
+		name         string
+		items        []*models.OrderItem
+		wantRate     float64
+		wantHasItems bool
+	}{
+		{name: "no items", wantRate: 0, wantHasItems: false},
+		{name: "one completed item", items: []*models.OrderItem{{ID: 1, OrderID: 1, Status: string(models.ItemCompleted), Quantity: 1, TotalPrice: 10000}}, wantRate: 100, wantHasItems: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := newFakeOrderRepo(&models.Order{ID: 1, TotalAmount: 10000})
+			itemRepo := &fakeOrderItemRepo{items: map[uint][]*models.OrderItem{1: tt.items}}
+			svc := NewOrderService(orders, itemRepo, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.Real{})
+
+			summary, err := svc.GetOrderItemsSummary(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rate, _ := summary["completion_rate"].(float64)
+			if math.IsNaN(rate) || rate != tt.wantRate {
+				t.Errorf("completion_rate = %v, want %v", summary["completion_rate"], tt.wantRate)
+			}
+			if summary["has_items"] != tt.wantHasItems {
+				t.Errorf("has_items = %v, want %v", summary["has_items"], tt.wantHasItems)
+			}
+			// NaN cannot be encoded, so a clean rate always serializes
+			if _, err := json.Marshal(summary); err != nil {
+				t.Errorf("summary does not serialize: %v", err)
+			}
+		})
+	}
+}
+
+func TestRecordReportQuery(t *testing.T) {
+	now := time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	financial := &fakeFinancialRepo{}
+	svc := NewOrderService(nil, nil, nil, financial, nil, ItemStatusConfig{}, "IDR", clock.NewFake(now))
+
+	data := map[string]interface{}{"total_orders": 3, "total_amount": 450000.0}
+	if err := svc.RecordReportQuery(7, "custom_range", &start, &end, data); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(financial.reports) != 1 {
+		t.Fatalf("wrote %d report queries, want 1", len(financial.reports))
+	}
+	row := financial.reports[0]
+	if row.UserID != 7 || row.QueryType != "custom_range" || !row.StartDate.Equal(start) || !row.EndDate.Equal(end) {
+		t.Errorf("row = %+v, want user 7's custom_range query for January", row)
+	}
+	if !row.GeneratedAt.Equal(now) {
+		t.Errorf("GeneratedAt = %v, want %v", row.GeneratedAt, now)
+	}
+	if want := `{"total_amount":450000,"total_orders":3}`; row.ReportData != want {
+		t.Errorf("ReportData = %s, want %s", row.ReportData, want)
+	}
+
+	if err := svc.RecordReportQuery(7, "custom_range", &start, &end, make(chan int)); err == nil {
+		t.Error("unserializable report data was stored")
+	}
+}
+
+func TestOrderServiceGetUpcomingDeliveries(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, jakarta)
+	day := func(d int) *time.Time {
+		at := time.Date(2025, 1, d, 0, 0, 0, 0, jakarta)
+		return &at
+	}
+	seeded := func() []*models.Order {
+		return []*models.Order{
+			{ID: 1, DeliveryDate: day(14), Status: "pending"},
+			{ID: 2, DeliveryDate: day(15), Status: "pending"},
+			{ID: 3, DeliveryDate: day(18), Status: "processing"},
+			{ID: 4, DeliveryDate: day(17), Status: "completed"},
+			{ID: 5, DeliveryDate: day(16), Status: "cancelled"},
+			{ID: 6, DeliveryDate: day(22), Status: "pending"},
+			{ID: 7, DeliveryDate: day(23), Status: "pending"},
+			{ID: 8, Status: "pending"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		within  time.Duration
+		wantIDs []uint
+	}{
+		// Today's deliveries count even though midnight has passed
+		{name: "today", within: 0, wantIDs: []uint{2}},
+		{name: "three days", within: 3 * 24 * time.Hour, wantIDs: []uint{2, 3}},
+		{name: "a week", within: 7 * 24 * time.Hour, wantIDs: []uint{2, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(seeded()...)
+			svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.NewFake(now))
+
+			orders, err := svc.GetUpcomingDeliveries(tt.within)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uint
+			for _, o := range orders {
+				got = append(got, o.ID)
+			}
+			if !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("deliveries = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestCancelledOrderLeavesRevenue(t *testing.T) {
+	day := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	start, end := day.Add(-24*time.Hour), day.Add(24*time.Hour)
+
+	tests := []struct {
+		name             string
+		cancel           []uint
+		includeCancelled bool
+		want             FinancialSummary
+	}{
+		{name: "nothing cancelled", want: FinancialSummary{OrderCount: 2, TotalRevenue: 250000, TotalNetProfit: 200000}},
+		{name: "cancelled order dropped", cancel: []uint{2}, want: FinancialSummary{OrderCount: 1, TotalRevenue: 100000, TotalNetProfit: 80000}},
+		{name: "all cancelled", cancel: []uint{1, 2}, want: FinancialSummary{}},
+		{name: "cancelled order counted on request", cancel: []uint{2}, includeCancelled: true, want: FinancialSummary{OrderCount: 2, CancelledCount: 1, TotalRevenue: 250000, TotalNetProfit: 200000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo(
+				&models.Order{ID: 1, Status: string(models.OrderCompleted), OrderDate: day, TotalAmount: 100000, NetProfit: 80000},
+				&models.Order{ID: 2, Status: string(models.OrderPending), OrderDate: day, TotalAmount: 150000, NetProfit: 120000},
+			)
+			svc := NewOrderService(repo, nil, nil, nil, nil, ItemStatusConfig{}, "IDR", clock.NewFake(day))
+
+			for _, id := range tt.cancel {
+				if err := svc.CancelOrder(id, "", 9); err != nil {
+					t.Fatal(err)
+				}
+			}
+			summary, err := svc.GetFinancialSummary(start, end, tt.includeCancelled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *summary != tt.want {
+				t.Errorf("summary = %+v, want %+v", *summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateOrderWithItems(t *testing.T) {
+	dbErr := errors.New("item insert failed")
+
+	tests := []struct {
+		name      string
+		total     float64
+		items     []models.OrderItem
+		createErr error
+		wantErr   bool
+		wantTotal float64
+	}{
+		{
+			name:      "total from items",
+			items:     []models.OrderItem{{ItemName: "Kue Lapis", Quantity: 2, UnitPrice: 50000}, {ItemName: "Bolu", Quantity: 1, UnitPrice: 30000}},
+			wantTotal: 130000,
+		},
+		{
+			name:      "given total kept",
+			total:     150000,
+			items:     []models.OrderItem{{ItemName: "Kue Lapis", Quantity: 2, UnitPrice: 50000}},
+			wantTotal: 150000,
+		},
+		{name: "no items", wantErr: true},
+		{name: "item without quantity", items: []models.OrderItem{{ItemName: "Kue Lapis", UnitPrice: 50000}}, wantErr: true},
+		{
+			name:      "failed insert records no calculation",
+			items:     []models.OrderItem{{ItemName: "Kue Lapis", Quantity: 1, UnitPrice: 50000}},
+			createErr: dbErr,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepo()
+			if tt.createErr != nil {
+				repo.createErrs = []error{tt.createErr}
+			}
+			financial := &fakeFinancialRepo{}
+			svc := NewOrderService(repo, nil, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+			order := &models.Order{CustomerName: "Siti", TotalAmount: tt.total}
+			err := svc.CreateOrderWithItems(order, tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(repo.orders) != 0 || len(financial.history) != 0 {
+					t.Errorf("stored %d orders and %d calculations, want none", len(repo.orders), len(financial.history))
+				}
+				return
+			}
+
+			if order.TotalAmount != tt.wantTotal || order.Status != string(models.OrderPending) || order.OrderNumber == "" {
+				t.Errorf("order = %+v, want a numbered pending order of %v", order, tt.wantTotal)
+			}
+			for _, item := range tt.items {
+				if item.TotalPrice != float64(item.Quantity)*item.UnitPrice || item.Status != string(models.ItemPending) {
+					t.Errorf("item = %+v, want its line total and pending status", item)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateFinancialsFixedAmounts(t *testing.T) {
+	percent := func(name string, value float64) *models.FinancialSettings {
+		return &models.FinancialSettings{SettingName: name, PercentageValue: value, IsPercentage: true}
+	}
+	fixed := func(name string, amount float64) *models.FinancialSettings {
+		return &models.FinancialSettings{SettingName: name, FixedAmount: amount, PercentageValue: 99}
+	}
+
+	tests := []struct {
+		name        string
+		tax         *models.FinancialSettings
+		marketing   *models.FinancialSettings
+		rental      *models.FinancialSettings
+		want        models.Order
+		wantPercent float64
+		wantFixed   float64
+	}{
+		{
+			name:        "all percentages",
+			tax:         percent("tax_rate", 10),
+			marketing:   percent("marketing_rate", 5),
+			rental:      percent("rental_rate", 2),
+			want:        models.Order{TaxPercentage: 10, TaxAmount: 20000, MarketingPercentage: 5, MarketingCost: 10000, RentalPercentage: 2, RentalCost: 4000, NetProfit: 166000},
+			wantPercent: 17,
+		},
+		{
+			name:        "fixed rental per order",
+			tax:         percent("tax_rate", 10),
+			marketing:   percent("marketing_rate", 5),
+			rental:      fixed("rental_rate", 15000),
+			want:        models.Order{TaxPercentage: 10, TaxAmount: 20000, MarketingPercentage: 5, MarketingCost: 10000, RentalCost: 15000, NetProfit: 155000},
+			wantPercent: 15,
+			wantFixed:   15000,
+		},
+		{
+			name:      "all fixed",
+			tax:       fixed("tax_rate", 1000),
+			marketing: fixed("marketing_rate", 2500),
+			rental:    fixed("rental_rate", 15000),
+			want:      models.Order{TaxAmount: 1000, MarketingCost: 2500, RentalCost: 15000, NetProfit: 181500},
+			wantFixed: 18500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			financial := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+				"tax_rate": tt.tax, "marketing_rate": tt.marketing, "rental_rate": tt.rental,
+			}}
+			svc := NewOrderService(nil, nil, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+			order := &models.Order{ID: 1, TotalAmount: 200000}
+			if err := svc.CalculateFinancials(order); err != nil {
+				t.Fatal(err)
+			}
+			got := models.Order{
+				TaxPercentage: order.TaxPercentage, TaxAmount: order.TaxAmount,
+				MarketingPercentage: order.MarketingPercentage, MarketingCost: order.MarketingCost,
+				RentalPercentage: order.RentalPercentage, RentalCost: order.RentalCost,
+				NetProfit: order.NetProfit,
+			}
+			if got != tt.want {
+				t.Errorf("financials = %+v, want %+v", got, tt.want)
+			}
+
+			if len(financial.history) != 1 {
+				t.Fatalf("recorded %d calculations, want 1", len(financial.history))
+			}
+			history := financial.history[0]
+			if history.PercentageUsed != tt.wantPercent || history.FixedAmountUsed != tt.wantFixed || history.CalculatedAmount != tt.want.NetProfit {
+				t.Errorf("history = %+v, want %v%% and %v fixed giving %v", history, tt.wantPercent, tt.wantFixed, tt.want.NetProfit)
+			}
+		})
+	}
+}
+
+func TestCalculateFinancialsOverrides(t *testing.T) {
+	rate := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name  string
+		order models.Order
+		want  models.Order
+	}{
+		{
+			name:  "global settings",
+			order: models.Order{TotalAmount: 200000},
+			want:  models.Order{TaxPercentage: 10, TaxAmount: 20000, MarketingPercentage: 5, MarketingCost: 10000, RentalCost: 15000, NetProfit: 155000},
+		},
+		{
+			name:  "tax override",
+			order: models.Order{TotalAmount: 200000, TaxRateOverride: rate(11)},
+			want:  models.Order{TaxPercentage: 11, TaxAmount: 22000, MarketingPercentage: 5, MarketingCost: 10000, RentalCost: 15000, NetProfit: 153000},
+		},
+		{
+			name:  "zero override replaces the setting",
+			order: models.Order{TotalAmount: 200000, MarketingRateOverride: rate(0)},
+			want:  models.Order{TaxPercentage: 10, TaxAmount: 20000, RentalCost: 15000, NetProfit: 165000},
+		},
+		{
+			name:  "percentage override replaces a fixed setting",
+			order: models.Order{TotalAmount: 200000, RentalRateOverride: rate(2)},
+			want:  models.Order{TaxPercentage: 10, TaxAmount: 20000, MarketingPercentage: 5, MarketingCost: 10000, RentalPercentage: 2, RentalCost: 4000, NetProfit: 166000},
+		},
+		{
+			name:  "all overridden",
+			order: models.Order{TotalAmount: 200000, TaxRateOverride: rate(0), MarketingRateOverride: rate(0), RentalRateOverride: rate(0)},
+			want:  models.Order{NetProfit: 200000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			financial := &fakeFinancialRepo{settings: map[string]*models.FinancialSettings{
+				"tax_rate":       {SettingName: "tax_rate", PercentageValue: 10, IsPercentage: true},
+				"marketing_rate": {SettingName: "marketing_rate", PercentageValue: 5, IsPercentage: true},
+				"rental_rate":    {SettingName: "rental_rate", FixedAmount: 15000},
+			}}
+			svc := NewOrderService(nil, nil, nil, financial, newTestSettings(financial), ItemStatusConfig{}, "IDR", clock.Real{})
+
+			order := tt.order
+			if err := svc.CalculateFinancials(&order); err != nil {
+				t.Fatal(err)
+			}
+			got := models.Order{
+				TaxPercentage: order.TaxPercentage, TaxAmount: order.TaxAmount,
+				MarketingPercentage: order.MarketingPercentage, MarketingCost: order.MarketingCost,
+				RentalPercentage: order.RentalPercentage, RentalCost: order.RentalCost,
+				NetProfit: order.NetProfit,
+			}
+			if got != tt.want {
+				t.Errorf("financials = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"task_manager/internal/redis"
+	"time"
+)
+
+// RateLimitConfig caps how many messages one phone number may send per window.
+// A zero Limit disables rate limiting
+type RateLimitConfig struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimiter interface {
+	// Allow counts a message from phone. notify is true only for the first
+	// message over the limit, so the sender is told to slow down once per window
+	Allow(phone string) (allowed bool, notify bool, err error)
+}
+
+type rateLimiter struct {
+	redis  *redis.Client
+	config RateLimitConfig
+}
+
+func NewRateLimiter(redis *redis.Client, config RateLimitConfig) RateLimiter {
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	return &rateLimiter{redis: redis, config: config}
+}
+
+func (r *rateLimiter) Allow(phone string) (bool, bool, error) {
+	if r.config.Limit <= 0 || r.redis == nil {
+		return true, false, nil
+	}
+
+	// Fixed window: the counter expires with the window that created it
+	key := fmt.Sprintf("rate_limit:%s", phone)
+	count, err := r.redis.Incr(key).Result()
+	if err != nil {
+		return true, false, err
+	}
+	if count == 1 {
+		if err := r.redis.Expire(key, r.config.Window).Err(); err != nil {
+			return true, false, err
+		}
+	}
+
+	limit := int64(r.config.Limit)
+	return count <= limit, count == limit+1, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	type result struct{ allowed, notify bool }
+	allow := result{allowed: true}
+	notify := result{notify: true}
+	drop := result{}
+
+	tests := []struct {
+		name   string
+		config RateLimitConfig
+		// burst messages from one number, then the window passes and one more
+		burst     int
+		want      []result
+		wantAfter result
+	}{
+		{
+			name:      "under the limit",
+			config:    RateLimitConfig{Limit: 3, Window: time.Minute},
+			burst:     3,
+			want:      []result{allow, allow, allow},
+			wantAfter: allow,
+		},
+		{
+			name:      "burst over the limit",
+			config:    RateLimitConfig{Limit: 2, Window: time.Minute},
+			burst:     5,
+			want:      []result{allow, allow, notify, drop, drop},
+			wantAfter: allow,
+		},
+		{
+			name:      "default window",
+			config:    RateLimitConfig{Limit: 1},
+			burst:     3,
+			want:      []result{allow, notify, drop},
+			wantAfter: allow,
+		},
+		{
+			name:      "disabled",
+			config:    RateLimitConfig{Window: time.Minute},
+			burst:     50,
+			want:      nil,
+			wantAfter: allow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			limiter := NewRateLimiter(client, tt.config)
+
+			for i := 0; i < tt.burst; i++ {
+				allowed, notified, err := limiter.Allow("6281234567890")
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := allow
+				if tt.want != nil {
+					want = tt.want[i]
+				}
+				if got := (result{allowed, notified}); got != want {
+					t.Errorf("message %d = %+v, want %+v", i+1, got, want)
+				}
+			}
+
+			// Another number has its own budget
+			if allowed, _, _ := limiter.Allow("6289876543210"); !allowed {
+				t.Error("a different number was limited")
+			}
+
+			window := tt.config.Window
+			if window == 0 {
+				window = time.Minute
+			}
+			server.FastForward(window)
+			allowed, notified, err := limiter.Allow("6281234567890")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := (result{allowed, notified}); got != tt.wantAfter {
+				t.Errorf("after the window = %+v, want %+v", got, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimiterRedisDown(t *testing.T) {
+	client, server := newTestRedis(t)
+	limiter := NewRateLimiter(client, RateLimitConfig{Limit: 1, Window: time.Minute})
+	server.Close()
+
+	// Failing open keeps the bot usable when Redis is unavailable
+	allowed, notified, err := limiter.Allow("6281234567890")
+	if err == nil || !allowed || notified {
+		t.Errorf("Allow() = %v, %v, %v; want allowed with an error", allowed, notified, err)
+	}
+}
@@ -1,34 +1,85 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"task_manager/internal/clock"
+	"task_manager/internal/features"
 	"task_manager/internal/models"
+	"task_manager/internal/redis"
 	"task_manager/internal/repository"
 	"time"
-	"fmt"
+
+	"gorm.io/gorm"
 )
 
 type ReminderService interface {
 	CreateReminder(reminder *models.Reminder) error
 	GetRemindersByTask(taskID uint) ([]models.Reminder, error)
+	GetRemindersByUser(userID uint) ([]models.Reminder, error)
 	GetPendingReminders() ([]models.Reminder, error)
 	UpdateReminder(reminder *models.Reminder) error
 	DeleteReminder(id uint) error
 	MarkReminderAsSent(id uint) error
 	ProcessPendingReminders() error
+	StartReminderScheduler(ctx context.Context, interval time.Duration)
 	CreateTaskReminder(taskID uint, reminderType string, scheduledTime time.Time) error
-	SendDailyProgressReminder(userPhone string, progress int) error
+	CreateRecurringReminder(taskID uint, reminderType string, firstTime time.Time, pattern string) error
+	SendDailyProgressReminder(userPhone string, progress int, streak int) error
 	SendMonthlyProgressReminder(userPhone string, progress int) error
+	SendEscalationNotice(userPhone string, task *models.Task, from, to string) error
 }
 
 type reminderService struct {
 	reminderRepo    repository.ReminderRepository
 	whatsappService WhatsAppService
+	taskService     TaskService
+	userService     UserService
+	redis           *redis.Client
+	quietHours      QuietHours
+	logger          *slog.Logger
+	clock           clock.Clock
+}
+
+// QuietHours is the nightly window in which reminders are held back while
+// the quiet_hours feature is on. Start and End are hours of the day in
+// Location; the window wraps past midnight when Start is after End
+type QuietHours struct {
+	Start    int
+	End      int
+	Location *time.Location
 }
 
-func NewReminderService(reminderRepo repository.ReminderRepository, whatsappService WhatsAppService) ReminderService {
+// Contains reports whether t falls inside the window. An empty window
+// (Start equal to End) contains nothing
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Location != nil {
+		t = t.In(q.Location)
+	}
+	hour := t.Hour()
+	if q.Start <= q.End {
+		return hour >= q.Start && hour < q.End
+	}
+	return hour >= q.Start || hour < q.End
+}
+
+// NewReminderService builds the reminder service. quietHours only applies
+// while the quiet_hours feature is on. A nil logger logs through slog.Default
+func NewReminderService(reminderRepo repository.ReminderRepository, whatsappService WhatsAppService, taskService TaskService, userService UserService, redis *redis.Client, quietHours QuietHours, logger *slog.Logger, clk clock.Clock) ReminderService {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &reminderService{
 		reminderRepo:    reminderRepo,
 		whatsappService: whatsappService,
+		taskService:     taskService,
+		userService:     userService,
+		redis:           redis,
+		quietHours:      quietHours,
+		logger:          logger,
+		clock:           clock.OrReal(clk),
 	}
 }
 
@@ -40,8 +91,12 @@ func (s *reminderService) GetRemindersByTask(taskID uint) ([]models.Reminder, er
 	return s.reminderRepo.GetByTaskID(taskID)
 }
 
+func (s *reminderService) GetRemindersByUser(userID uint) ([]models.Reminder, error) {
+	return s.reminderRepo.GetByUser(userID)
+}
+
 func (s *reminderService) GetPendingReminders() ([]models.Reminder, error) {
-	return s.reminderRepo.GetPendingReminders()
+	return s.reminderRepo.GetPendingReminders(s.clock.Now())
 }
 
 func (s *reminderService) UpdateReminder(reminder *models.Reminder) error {
@@ -56,44 +111,174 @@ func (s *reminderService) MarkReminderAsSent(id uint) error {
 	return s.reminderRepo.MarkAsSent(id)
 }
 
+// ProcessPendingReminders sends every due reminder. During quiet hours
+// nothing is sent; the reminders stay pending and go out once the window ends
 func (s *reminderService) ProcessPendingReminders() error {
+	if features.Enabled(features.QuietHours) && s.quietHours.Contains(s.clock.Now()) {
+		return nil
+	}
+
 	reminders, err := s.GetPendingReminders()
 	if err != nil {
 		return err
 	}
 
 	for _, reminder := range reminders {
-		// Send WhatsApp message
-		message := "Reminder: " + reminder.ReminderType
-		err := s.whatsappService.SendMessage("", message) // Phone number should be retrieved from task
+		// Resolve the recipient through the task's assignee
+		task, err := s.taskService.GetTaskByID(reminder.TaskID)
 		if err != nil {
-			continue // Log error but continue with other reminders
+			s.logger.Warn("Skipping reminder: task not found", "reminder_id", reminder.ID, "task_id", reminder.TaskID, "error", err)
+			continue
 		}
 
-		// Mark as sent
-		err = s.MarkReminderAsSent(reminder.ID)
+		user, err := s.userService.GetUserByID(task.AssignedTo)
 		if err != nil {
-			continue // Log error but continue
+			s.logger.Warn("Skipping reminder: user not found", "reminder_id", reminder.ID, "user_id", task.AssignedTo, "error", err)
+			continue
+		}
+
+		if user.WhatsAppNumber == "" {
+			s.logger.Warn("Skipping reminder: user has no WhatsApp number", "reminder_id", reminder.ID, "user_id", user.ID)
+			continue
+		}
+
+		// Send WhatsApp message
+		message := fmt.Sprintf("🔔 Reminder (%s): Task #%d %s\nProgress: %d%%", reminder.ReminderType, task.ID, task.Title, task.CompletionPercentage)
+		if err := s.whatsappService.SendMessage(user.WhatsAppNumber, message); err != nil {
+			s.logger.Error("Failed to send reminder", "reminder_id", reminder.ID, "phone", user.WhatsAppNumber, "error", err)
+			continue
+		}
+
+		// Mark as sent only once delivery succeeded
+		if err := s.MarkReminderAsSent(reminder.ID); err != nil {
+			s.logger.Error("Failed to mark reminder as sent", "reminder_id", reminder.ID, "error", err)
+		}
+
+		// Recurring reminders queue their next occurrence until the task is done
+		if task.Status != string(models.Completed) {
+			if err := s.scheduleNextOccurrence(reminder); err != nil {
+				s.logger.Error("Failed to schedule next occurrence of reminder", "reminder_id", reminder.ID, "error", err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// StartReminderScheduler sends due reminders every interval until ctx is
+// done. It blocks, so run it in its own goroutine. With several replicas only
+// the one holding the "reminders" lock sends in a given interval
+func (s *reminderService) StartReminderScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RunExclusive(s.redis, s.logger, "reminders", interval, func() {
+				if err := s.ProcessPendingReminders(); err != nil {
+					s.logger.Error("Failed to process pending reminders", "error", err)
+				}
+			})
+		}
+	}
+}
+
 func (s *reminderService) CreateTaskReminder(taskID uint, reminderType string, scheduledTime time.Time) error {
+	// Creating the same reminder twice is a no-op
+	existing, err := s.reminderRepo.FindExisting(taskID, reminderType, scheduledTime)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
 	reminder := &models.Reminder{
 		TaskID:        taskID,
 		ReminderType:  reminderType,
 		ScheduledTime: scheduledTime,
 		WhatsAppSent:  false,
-		CreatedAt:     time.Now(),
+		CreatedAt:     s.clock.Now(),
 	}
 
 	return s.CreateReminder(reminder)
 }
 
-func (s *reminderService) SendDailyProgressReminder(userPhone string, progress int) error {
+// CreateRecurringReminder schedules a reminder at firstTime that repeats
+// daily or weekly after each send
+func (s *reminderService) CreateRecurringReminder(taskID uint, reminderType string, firstTime time.Time, pattern string) error {
+	if _, err := recurrenceInterval(pattern); err != nil {
+		return err
+	}
+
+	existing, err := s.reminderRepo.FindExisting(taskID, reminderType, firstTime)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	return s.CreateReminder(&models.Reminder{
+		TaskID:            taskID,
+		ReminderType:      reminderType,
+		ScheduledTime:     firstTime,
+		RecurrencePattern: pattern,
+		CreatedAt:         s.clock.Now(),
+	})
+}
+
+// recurrenceInterval is the gap between occurrences of pattern
+func recurrenceInterval(pattern string) (time.Duration, error) {
+	switch pattern {
+	case models.RecurrenceDaily:
+		return 24 * time.Hour, nil
+	case models.RecurrenceWeekly:
+		return 7 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("invalid recurrence %q, use daily or weekly", pattern)
+}
+
+// scheduleNextOccurrence creates the next reminder after a recurring one was
+// sent. Occurrences missed while the scheduler was down are skipped rather
+// than sent in a burst
+func (s *reminderService) scheduleNextOccurrence(reminder models.Reminder) error {
+	if reminder.RecurrencePattern == "" || reminder.RecurrencePattern == models.RecurrenceNone {
+		return nil
+	}
+	interval, err := recurrenceInterval(reminder.RecurrencePattern)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	next := reminder.ScheduledTime.Add(interval)
+	for !next.After(now) {
+		next = next.Add(interval)
+	}
+	return s.CreateRecurringReminder(reminder.TaskID, reminder.ReminderType, next, reminder.RecurrencePattern)
+}
+
+func (s *reminderService) SendDailyProgressReminder(userPhone string, progress int, streak int) error {
 	message := fmt.Sprintf("📅 Daily Progress Reminder: %d%% completed", progress)
+	if streak > 0 {
+		message += fmt.Sprintf("\n🔥 %d-day streak!", streak)
+	}
+	return s.whatsappService.SendMessage(userPhone, message)
+}
+
+func (s *reminderService) SendEscalationNotice(userPhone string, task *models.Task, from, to string) error {
+	message := fmt.Sprintf("⏫ Task #%d '%s' priority raised from %s to %s", task.ID, task.Title, from, to)
+	if task.DueDate != nil {
+		message += fmt.Sprintf("\n📅 Due: %s", task.DueDate.Format("2006-01-02 15:04"))
+	}
 	return s.whatsappService.SendMessage(userPhone, message)
 }
 
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"task_manager/internal/clock"
+	"task_manager/internal/features"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestScheduleNextOccurrence(t *testing.T) {
+	first := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		pattern  string
+		wantNext time.Time
+		wantNone bool
+	}{
+		{name: "daily, sent on time", now: first.Add(time.Minute), pattern: models.RecurrenceDaily, wantNext: first.AddDate(0, 0, 1)},
+		{name: "daily, exactly at the next occurrence", now: first.AddDate(0, 0, 1), pattern: models.RecurrenceDaily, wantNext: first.AddDate(0, 0, 2)},
+		{name: "daily, missed days are skipped", now: first.AddDate(0, 0, 3).Add(time.Hour), pattern: models.RecurrenceDaily, wantNext: first.AddDate(0, 0, 4)},
+		{name: "weekly", now: first.Add(time.Hour), pattern: models.RecurrenceWeekly, wantNext: first.AddDate(0, 0, 7)},
+		{name: "one-shot", now: first.Add(time.Minute), pattern: models.RecurrenceNone, wantNone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeReminderRepo{}
+			svc := NewReminderService(repo, nil, nil, nil, nil, QuietHours{}, nil, clock.NewFake(tt.now)).(*reminderService)
+
+			sent := models.Reminder{TaskID: 1, ReminderType: "deadline", ScheduledTime: first, RecurrencePattern: tt.pattern, WhatsAppSent: true}
+			if err := svc.scheduleNextOccurrence(sent); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantNone {
+				if len(repo.reminders) != 0 {
+					t.Fatalf("one-shot reminder was rescheduled: %+v", repo.reminders[0])
+				}
+				return
+			}
+			if len(repo.reminders) != 1 {
+				t.Fatalf("created %d reminders, want 1", len(repo.reminders))
+			}
+			next := repo.reminders[0]
+			if !next.ScheduledTime.Equal(tt.wantNext) || next.RecurrencePattern != tt.pattern {
+				t.Errorf("next = %v (%s), want %v (%s)", next.ScheduledTime, next.RecurrencePattern, tt.wantNext, tt.pattern)
+			}
+			if !next.CreatedAt.Equal(tt.now) {
+				t.Errorf("CreatedAt = %v, want the fake clock's %v", next.CreatedAt, tt.now)
+			}
+		})
+	}
+}
+
+func TestCreateTaskReminderIsIdempotent(t *testing.T) {
+	at := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		existing []*models.Reminder
+		want     int
+	}{
+		{name: "first reminder is created", want: 1},
+		{name: "same pending reminder is skipped", existing: []*models.Reminder{{TaskID: 1, ReminderType: "deadline", ScheduledTime: at}}, want: 1},
+		{name: "different type is created", existing: []*models.Reminder{{TaskID: 1, ReminderType: "follow_up", ScheduledTime: at}}, want: 2},
+		{name: "already sent reminder can be scheduled again", existing: []*models.Reminder{{TaskID: 1, ReminderType: "deadline", ScheduledTime: at, WhatsAppSent: true}}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeReminderRepo{reminders: tt.existing}
+			svc := NewReminderService(repo, nil, nil, nil, nil, QuietHours{}, nil, clock.NewFake(at.Add(-time.Hour)))
+
+			for i := 0; i < 2; i++ {
+				if err := svc.CreateTaskReminder(1, "deadline", at); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(repo.reminders) != tt.want {
+				t.Errorf("%d reminders exist, want %d", len(repo.reminders), tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	at := func(hour, minute int) time.Time { return time.Date(2025, 1, 10, hour, minute, 0, 0, jakarta) }
+
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		t     time.Time
+		want  bool
+	}{
+		{name: "overnight, late evening", quiet: QuietHours{Start: 21, End: 7}, t: at(22, 0), want: true},
+		{name: "overnight, early morning", quiet: QuietHours{Start: 21, End: 7}, t: at(6, 59), want: true},
+		{name: "overnight, window end", quiet: QuietHours{Start: 21, End: 7}, t: at(7, 0)},
+		{name: "overnight, daytime", quiet: QuietHours{Start: 21, End: 7}, t: at(12, 0)},
+		{name: "same day window", quiet: QuietHours{Start: 12, End: 14}, t: at(13, 30), want: true},
+		{name: "empty window", quiet: QuietHours{Start: 7, End: 7}, t: at(7, 0)},
+		{name: "read in its location", quiet: QuietHours{Start: 21, End: 7, Location: jakarta}, t: time.Date(2025, 1, 10, 15, 0, 0, 0, time.UTC), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.Contains(tt.t); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessPendingRemindersQuietHours(t *testing.T) {
+	night := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		now     time.Time
+		want    int
+	}{
+		{name: "held back at night", enabled: true, now: night},
+		{name: "sent during the day", enabled: true, now: day, want: 1},
+		{name: "feature off sends at night", now: night, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := features.Set(features.QuietHours, tt.enabled); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { features.Set(features.QuietHours, false) })
+
+			repo := &fakeReminderRepo{reminders: []*models.Reminder{
+				{ID: 1, TaskID: 1, ReminderType: "deadline", ScheduledTime: tt.now.Add(-time.Minute)},
+			}}
+			wa := &fakeWhatsAppService{}
+			tasks := &fakeTaskService{tasks: map[uint]*models.Task{1: {ID: 1, Title: "Report", AssignedTo: 1}}}
+			users := &fakeUserService{users: []*models.User{{ID: 1, WhatsAppNumber: "628123456789"}}}
+			svc := NewReminderService(repo, wa, tasks, users, nil, QuietHours{Start: 21, End: 7, Location: time.UTC}, nil, clock.NewFake(tt.now))
+
+			if err := svc.ProcessPendingReminders(); err != nil {
+				t.Fatal(err)
+			}
+			if len(wa.sent) != tt.want {
+				t.Errorf("sent %d reminders, want %d", len(wa.sent), tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessPendingRemindersRecipients(t *testing.T) {
+	now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Minute)
+
+	repo := &fakeReminderRepo{reminders: []*models.Reminder{
+		{ID: 1, TaskID: 1, ReminderType: "deadline", ScheduledTime: due},
+		{ID: 2, TaskID: 2, ReminderType: "deadline", ScheduledTime: due},
+		{ID: 3, TaskID: 99, ReminderType: "deadline", ScheduledTime: due},
+		{ID: 4, TaskID: 3, ReminderType: "deadline", ScheduledTime: due},
+		{ID: 5, TaskID: 4, ReminderType: "deadline", ScheduledTime: due},
+		{ID: 6, TaskID: 5, ReminderType: "deadline", ScheduledTime: due},
+		{ID: 7, TaskID: 1, ReminderType: "follow_up", ScheduledTime: now.Add(time.Hour)},
+	}}
+	tasks := &fakeTaskService{tasks: map[uint]*models.Task{
+		1: {ID: 1, Title: "Report", AssignedTo: 1, CompletionPercentage: 40},
+		2: {ID: 2, Title: "Stock opname", AssignedTo: 2},
+		3: {ID: 3, Title: "Orphan", AssignedTo: 42},
+		4: {ID: 4, Title: "No phone", AssignedTo: 3},
+		5: {ID: 5, Title: "Unreachable", AssignedTo: 4},
+	}}
+	users := &fakeUserService{users: []*models.User{
+		{ID: 1, WhatsAppNumber: "628111"},
+		{ID: 2, WhatsAppNumber: "628222"},
+		{ID: 3},
+		{ID: 4, WhatsAppNumber: "628444"},
+	}}
+	wa := &fakeWhatsAppService{failFor: map[string]error{"628444": errors.New("gateway down")}}
+	svc := NewReminderService(repo, wa, tasks, users, nil, QuietHours{}, slog.New(slog.NewTextHandler(io.Discard, nil)), clock.NewFake(now))
+
+	if err := svc.ProcessPendingReminders(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantPhones := []string{"628111", "628222"}
+	if len(wa.phones) != len(wantPhones) {
+		t.Fatalf("sent to %v, want %v", wa.phones, wantPhones)
+	}
+	for i, phone := range wantPhones {
+		if wa.phones[i] != phone {
+			t.Errorf("reminder %d went to %s, want %s", i+1, wa.phones[i], phone)
+		}
+	}
+	if want := "🔔 Reminder (deadline): Task #1 Report\nProgress: 40%"; wa.sent[0] != want {
+		t.Errorf("message = %q, want %q", wa.sent[0], want)
+	}
+
+	// Only delivered reminders are marked sent; the rest stay pending
+	wantSent := map[uint]bool{1: true, 2: true}
+	for _, reminder := range repo.reminders {
+		if reminder.WhatsAppSent != wantSent[reminder.ID] {
+			t.Errorf("reminder %d sent = %v, want %v", reminder.ID, reminder.WhatsAppSent, wantSent[reminder.ID])
+		}
+	}
+}
+
+func TestSendEscalationNotice(t *testing.T) {
+	due := time.Date(2025, 1, 12, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		task *models.Task
+		want string
+	}{
+		{name: "with due date", task: &models.Task{ID: 4, Title: "Pack boxes", DueDate: &due}, want: "⏫ Task #4 'Pack boxes' priority raised from low to medium\n📅 Due: 2025-01-12 17:00"},
+		{name: "without due date", task: &models.Task{ID: 5, Title: "Tidy up"}, want: "⏫ Task #5 'Tidy up' priority raised from low to medium"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wa := &fakeWhatsAppService{}
+			svc := NewReminderService(nil, wa, nil, nil, nil, QuietHours{}, nil, nil)
+			if err := svc.SendEscalationNotice("628123456789", tt.task, "low", "medium"); err != nil {
+				t.Fatal(err)
+			}
+			if len(wa.sent) != 1 || wa.sent[0] != tt.want {
+				t.Errorf("sent %q, want %q", wa.sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendMonthlyProgressReminder(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress int
+		want     string
+	}{
+		{name: "partway", progress: 85, want: "📆 Monthly Progress Reminder: 85% completed"},
+		{name: "nothing done", progress: 0, want: "📆 Monthly Progress Reminder: 0% completed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wa := &fakeWhatsAppService{}
+			svc := NewReminderService(nil, wa, nil, nil, nil, QuietHours{}, nil, nil)
+			if err := svc.SendMonthlyProgressReminder("628123456789", tt.progress); err != nil {
+				t.Fatal(err)
+			}
+			if len(wa.sent) != 1 || wa.sent[0] != tt.want {
+				t.Errorf("sent %q, want %q", wa.sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartReminderSchedulerProcessesPendingReminders(t *testing.T) {
+	now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	repo := &fakeReminderRepo{reminders: []*models.Reminder{
+		{ID: 1, TaskID: 1, ReminderType: "deadline", ScheduledTime: now.Add(-time.Minute)},
+	}}
+	tasks := &fakeTaskService{tasks: map[uint]*models.Task{1: {ID: 1, Title: "Report", AssignedTo: 1}}}
+	users := &fakeUserService{users: []*models.User{{ID: 1, WhatsAppNumber: "628111"}}}
+	wa := &fakeWhatsAppService{}
+	svc := NewReminderService(repo, wa, tasks, users, nil, QuietHours{}, slog.New(slog.NewTextHandler(io.Discard, nil)), clock.NewFake(now))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.StartReminderScheduler(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after its context was cancelled")
+	}
+
+	if len(wa.phones) != 1 || wa.phones[0] != "628111" {
+		t.Errorf("sent to %v, want the reminder delivered once to 628111", wa.phones)
+	}
+	if !repo.reminders[0].WhatsAppSent {
+		t.Error("reminder not marked sent")
+	}
+}
+
+func TestProcessPendingRemindersAtScheduledTime(t *testing.T) {
+	scheduled := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	repo := &fakeReminderRepo{reminders: []*models.Reminder{
+		{ID: 1, TaskID: 1, ReminderType: "deadline", ScheduledTime: scheduled},
+	}}
+	tasks := &fakeTaskService{tasks: map[uint]*models.Task{1: {ID: 1, Title: "Report", AssignedTo: 1}}}
+	users := &fakeUserService{users: []*models.User{{ID: 1, WhatsAppNumber: "628111"}}}
+	wa := &fakeWhatsAppService{}
+	now := clock.NewFake(scheduled.Add(-time.Second))
+	svc := NewReminderService(repo, wa, tasks, users, nil, QuietHours{}, slog.New(slog.NewTextHandler(io.Discard, nil)), now)
+
+	passes := []struct {
+		advance  time.Duration
+		wantSent int
+	}{
+		{advance: 0, wantSent: 0},           // a second early
+		{advance: time.Second, wantSent: 1}, // exactly on time
+		{advance: time.Minute, wantSent: 1}, // not sent twice
+	}
+
+	for _, pass := range passes {
+		now.Advance(pass.advance)
+		if err := svc.ProcessPendingReminders(); err != nil {
+			t.Fatal(err)
+		}
+		if len(wa.sent) != pass.wantSent {
+			t.Errorf("at %s sent %d reminders, want %d", now.Now().Format("15:04:05"), len(wa.sent), pass.wantSent)
+		}
+	}
+}
+
+func TestCreateRecurringReminder(t *testing.T) {
+	first := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{name: "daily", pattern: models.RecurrenceDaily},
+		{name: "weekly", pattern: models.RecurrenceWeekly},
+		{name: "one-shot is not recurring", pattern: models.RecurrenceNone, wantErr: true},
+		{name: "unknown pattern", pattern: "monthly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeReminderRepo{}
+			svc := NewReminderService(repo, nil, nil, nil, nil, QuietHours{}, nil, clock.NewFake(first.Add(-time.Hour)))
+
+			// Asking twice leaves a single reminder
+			for i := 0; i < 2; i++ {
+				err := svc.CreateRecurringReminder(1, "daily", first, tt.pattern)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+				}
+			}
+			if tt.wantErr {
+				if len(repo.reminders) != 0 {
+					t.Errorf("created %+v, want nothing", repo.reminders[0])
+				}
+				return
+			}
+			if len(repo.reminders) != 1 {
+				t.Fatalf("created %d reminders, want 1", len(repo.reminders))
+			}
+			if got := repo.reminders[0]; !got.ScheduledTime.Equal(first) || got.RecurrencePattern != tt.pattern {
+				t.Errorf("reminder = %+v, want %v repeating %s", got, first, tt.pattern)
+			}
+		})
+	}
+}
+
+func TestProcessPendingRemindersSchedulesNextOccurrence(t *testing.T) {
+	first := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		pattern  string
+		wantNext []time.Time
+	}{
+		{name: "every morning", pattern: models.RecurrenceDaily, wantNext: []time.Time{first, first.AddDate(0, 0, 1), first.AddDate(0, 0, 2)}},
+		{name: "every week", pattern: models.RecurrenceWeekly, wantNext: []time.Time{first, first.AddDate(0, 0, 7)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeReminderRepo{}
+			tasks := &fakeTaskService{tasks: map[uint]*models.Task{1: {ID: 1, Title: "Report", AssignedTo: 1}}}
+			users := &fakeUserService{users: []*models.User{{ID: 1, WhatsAppNumber: "628111"}}}
+			wa := &fakeWhatsAppService{}
+			now := clock.NewFake(first.Add(-time.Hour))
+			svc := NewReminderService(repo, wa, tasks, users, nil, QuietHours{}, slog.New(slog.NewTextHandler(io.Discard, nil)), now)
+
+			if err := svc.CreateRecurringReminder(1, "daily", first, tt.pattern); err != nil {
+				t.Fatal(err)
+			}
+
+			// Each occurrence is sent once and replaced by the following one
+			for i := range tt.wantNext[:len(tt.wantNext)-1] {
+				now.Set(tt.wantNext[i])
+				if err := svc.ProcessPendingReminders(); err != nil {
+					t.Fatal(err)
+				}
+				if len(wa.sent) != i+1 {
+					t.Fatalf("after occurrence %d sent %d messages, want %d", i+1, len(wa.sent), i+1)
+				}
+			}
+
+			var scheduled []time.Time
+			for _, r := range repo.reminders {
+				scheduled = append(scheduled, r.ScheduledTime)
+				if r.RecurrencePattern != tt.pattern {
+					t.Errorf("reminder %d repeats %q, want %q", r.ID, r.RecurrencePattern, tt.pattern)
+				}
+			}
+			if !slices.EqualFunc(scheduled, tt.wantNext, time.Time.Equal) {
+				t.Errorf("scheduled %v, want %v", scheduled, tt.wantNext)
+			}
+			if last := repo.reminders[len(repo.reminders)-1]; last.WhatsAppSent {
+				t.Errorf("next occurrence %v is already marked sent", last.ScheduledTime)
+			}
+		})
+	}
+}
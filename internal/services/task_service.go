@@ -1,6 +1,10 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"task_manager/internal/clock"
 	"task_manager/internal/models"
 	"task_manager/internal/repository"
 	"task_manager/internal/redis"
@@ -9,33 +13,150 @@ import (
 
 type TaskService interface {
 	CreateTask(task *models.Task) error
+	CreateTasksBulk(tasks []*models.Task) error
 	GetTaskByID(id uint) (*models.Task, error)
 	GetTasksByUser(userID uint) ([]models.Task, error)
+	GetTasksByUserAndStatus(userID uint, status string) ([]models.Task, error)
+	GetTasksByUserAndPriority(userID uint, priority string) ([]models.Task, error)
+	GetTasksByCreator(creatorID uint) ([]models.Task, error)
+	SearchTasks(userID uint, keyword string, allUsers bool) ([]models.Task, error)
+	GetCompletedTasks(start, end time.Time) ([]models.Task, error)
 	GetAllTasks() ([]models.Task, error)
+	GetAllTasksPaginated(offset, limit int) ([]models.Task, int64, error)
+	StreamAllTasks(batchSize int, fn func(tasks []models.Task) error) error
 	GetDailyTasks(userID uint, date time.Time) ([]models.Task, error)
+	GetWeeklyTasks(userID uint, weekKey string) ([]models.Task, error)
 	GetMonthlyTasks(userID uint, monthYear string) ([]models.Task, error)
 	UpdateTask(task *models.Task) error
 	UpdateTaskProgress(taskID uint, progress int, isImplemented bool, notes string, updatedBy uint) error
+	GetProgressHistory(taskID uint) ([]models.TaskProgress, error)
 	DeleteTask(id uint) error
 	CreateDailyTask(task *models.Task) error
+	CreateWeeklyTask(task *models.Task) error
 	CreateMonthlyTask(task *models.Task) error
 	ResetDailyTasks() error
+	ResetWeeklyTasks(now time.Time) (bool, error)
 	ResetMonthlyTasks() error
+	TransferTasks(fromUserID, toUserID uint) (int64, error)
+	UpdateDailyStreaks(day time.Time) ([]DailyStreak, error)
+	UpdateStatus(taskID uint, status string, actor uint) error
+	GetDailyStreak(userID uint) (int, error)
+	EscalatePriorities(now time.Time, cfg EscalationConfig) ([]Escalation, error)
+	MarkOverdue(now time.Time) ([]models.Task, error)
 }
 
 type taskService struct {
 	taskRepo repository.TaskRepository
+	userRepo repository.UserRepository
 	redis    *redis.Client
+	clock    clock.Clock
 }
 
-func NewTaskService(taskRepo repository.TaskRepository, redis *redis.Client) TaskService {
-	return &taskService{taskRepo: taskRepo, redis: redis}
+func NewTaskService(taskRepo repository.TaskRepository, userRepo repository.UserRepository, redis *redis.Client, clk clock.Clock) TaskService {
+	return &taskService{taskRepo: taskRepo, userRepo: userRepo, redis: redis, clock: clock.OrReal(clk)}
+}
+
+var (
+	ErrTaskStatusForbidden   = errors.New("only the assignee or an admin can change this task's status")
+	ErrTaskProgressForbidden = errors.New("only the assignee or an admin can update this task's progress")
+	ErrInvalidTransition     = errors.New("invalid status transition")
+)
+
+// taskTransitions lists the status changes anyone allowed to touch the task
+// may make. Reopening a completed task is reserved for admins
+var taskTransitions = map[string][]string{
+	string(models.Pending):    {string(models.InProgress), string(models.Blocked), string(models.Completed)},
+	string(models.InProgress): {string(models.Pending), string(models.Blocked), string(models.Completed)},
+	string(models.Blocked):    {string(models.Pending), string(models.InProgress)},
+	string(models.Overdue):    {string(models.InProgress), string(models.Blocked), string(models.Completed)},
+	string(models.Completed):  {},
+}
+
+// adminTransitions are the extra changes only admins may make
+var adminTransitions = map[string][]string{
+	string(models.Completed): {string(models.Pending), string(models.InProgress)},
+}
+
+// CanTransition reports whether a task may move from one status to another
+func CanTransition(from, to string, isAdmin bool) bool {
+	allowed := taskTransitions[from]
+	if isAdmin {
+		allowed = append(append([]string{}, allowed...), adminTransitions[from]...)
+	}
+	for _, status := range allowed {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// EscalationConfig sets how close to its due date an unfinished task is
+// raised to medium and to high priority
+type EscalationConfig struct {
+	MediumWithin time.Duration
+	HighWithin   time.Duration
+}
+
+// DefaultEscalationConfig raises tasks to medium three days and to high one
+// day before they are due
+func DefaultEscalationConfig() EscalationConfig {
+	return EscalationConfig{MediumWithin: 72 * time.Hour, HighWithin: 24 * time.Hour}
+}
+
+// Escalation records a priority bump made by EscalatePriorities
+type Escalation struct {
+	Task models.Task
+	From string
+	To   string
+}
+
+var priorityRank = map[string]int{
+	string(models.Low):    0,
+	string(models.Medium): 1,
+	string(models.High):   2,
+	string(models.Urgent): 3,
+}
+
+// EscalatedPriority returns the priority task should have at now. Priorities
+// are only ever raised, and never past high; completed tasks and tasks
+// without a due date keep theirs
+func EscalatedPriority(task *models.Task, now time.Time, cfg EscalationConfig) string {
+	current := task.Priority
+	if current == "" {
+		current = string(models.Medium)
+	}
+	if task.DueDate == nil || task.Status == string(models.Completed) {
+		return current
+	}
+
+	target := current
+	remaining := task.DueDate.Sub(now)
+	switch {
+	case remaining <= cfg.HighWithin:
+		target = string(models.High)
+	case remaining <= cfg.MediumWithin:
+		target = string(models.Medium)
+	}
+
+	if priorityRank[target] > priorityRank[current] {
+		return target
+	}
+	return current
 }
 
 func (s *taskService) CreateTask(task *models.Task) error {
 	return s.taskRepo.Create(task)
 }
 
+// CreateTasksBulk creates all tasks or, on any failure, none of them
+func (s *taskService) CreateTasksBulk(tasks []*models.Task) error {
+	if len(tasks) == 0 {
+		return errors.New("no tasks to create")
+	}
+	return s.taskRepo.CreateBatch(tasks)
+}
+
 func (s *taskService) GetTaskByID(id uint) (*models.Task, error) {
 	return s.taskRepo.GetByID(id)
 }
@@ -44,14 +165,106 @@ func (s *taskService) GetTasksByUser(userID uint) ([]models.Task, error) {
 	return s.taskRepo.GetByUserID(userID)
 }
 
+// IsValidTaskStatus reports whether status is one of the TaskStatus constants
+func IsValidTaskStatus(status string) bool {
+	switch models.TaskStatus(status) {
+	case models.Pending, models.InProgress, models.Completed, models.Overdue, models.Blocked:
+		return true
+	}
+	return false
+}
+
+// IsValidTaskPriority reports whether priority is one of the TaskPriority constants
+func IsValidTaskPriority(priority string) bool {
+	switch models.TaskPriority(priority) {
+	case models.Low, models.Medium, models.High, models.Urgent:
+		return true
+	}
+	return false
+}
+
+func (s *taskService) GetTasksByUserAndStatus(userID uint, status string) ([]models.Task, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if !IsValidTaskStatus(status) {
+		return nil, fmt.Errorf("invalid task status %q, valid statuses: pending, in_progress, completed, overdue, blocked", status)
+	}
+	return s.taskRepo.GetByUserAndStatus(userID, status)
+}
+
+func (s *taskService) GetTasksByUserAndPriority(userID uint, priority string) ([]models.Task, error) {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if !IsValidTaskPriority(priority) {
+		return nil, fmt.Errorf("invalid task priority %q, valid priorities: low, medium, high, urgent", priority)
+	}
+	return s.taskRepo.GetByUserAndPriority(userID, priority)
+}
+
 func (s *taskService) GetAllTasks() ([]models.Task, error) {
 	return s.taskRepo.GetAll()
 }
 
+func (s *taskService) GetAllTasksPaginated(offset, limit int) ([]models.Task, int64, error) {
+	return s.taskRepo.GetAllPaginated(offset, limit)
+}
+
+func (s *taskService) GetTasksByCreator(creatorID uint) ([]models.Task, error) {
+	return s.taskRepo.GetByCreator(creatorID)
+}
+
+func (s *taskService) SearchTasks(userID uint, keyword string, allUsers bool) ([]models.Task, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil, errors.New("search keyword cannot be empty")
+	}
+	return s.taskRepo.Search(userID, keyword, allUsers)
+}
+
+// GetCompletedTasks returns tasks completed between the start and end days,
+// both inclusive
+func (s *taskService) GetCompletedTasks(start, end time.Time) ([]models.Task, error) {
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	to := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, end.Location())
+	if !to.After(from) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	return s.taskRepo.GetCompletedBetween(from, to)
+}
+
+func (s *taskService) GetProgressHistory(taskID uint) ([]models.TaskProgress, error) {
+	return s.taskRepo.GetProgressHistory(taskID)
+}
+
 func (s *taskService) GetDailyTasks(userID uint, date time.Time) ([]models.Task, error) {
 	return s.taskRepo.GetDailyTasks(userID, date)
 }
 
+// WeekKey identifies the ISO week t falls in, e.g. "2025-W07"
+func WeekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// weekStart returns midnight on the Monday of the ISO week named by key
+func weekStart(key string, loc *time.Location) (time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(key, "%d-W%d", &year, &week); err != nil || week < 1 || week > 53 {
+		return time.Time{}, fmt.Errorf("invalid week %q, expected YYYY-Www", key)
+	}
+
+	// January 4th is always in ISO week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	return jan4.AddDate(0, 0, -offset+(week-1)*7), nil
+}
+
+func (s *taskService) GetWeeklyTasks(userID uint, weekKey string) ([]models.Task, error) {
+	start, err := weekStart(weekKey, s.clock.Now().Location())
+	if err != nil {
+		return nil, err
+	}
+	return s.taskRepo.GetWeeklyTasks(userID, start.AddDate(0, 0, 7))
+}
+
 func (s *taskService) GetMonthlyTasks(userID uint, monthYear string) ([]models.Task, error) {
 	return s.taskRepo.GetMonthlyTasks(userID, monthYear)
 }
@@ -60,9 +273,24 @@ func (s *taskService) UpdateTask(task *models.Task) error {
 	return s.taskRepo.Update(task)
 }
 
+// UpdateTaskProgress records progress on behalf of updatedBy, who must be the
+// assignee or an admin
 func (s *taskService) UpdateTaskProgress(taskID uint, progress int, isImplemented bool, notes string, updatedBy uint) error {
+	task, err := s.taskRepo.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	isAdmin, err := s.isTaskAdmin(updatedBy)
+	if err != nil {
+		return err
+	}
+	if task.AssignedTo != updatedBy && !isAdmin {
+		return ErrTaskProgressForbidden
+	}
+
 	// Update in database
-	err := s.taskRepo.UpdateProgress(taskID, progress, isImplemented, notes, updatedBy)
+	err = s.taskRepo.UpdateProgress(taskID, progress, isImplemented, notes, updatedBy)
 	if err != nil {
 		return err
 	}
@@ -83,6 +311,13 @@ func (s *taskService) CreateDailyTask(task *models.Task) error {
 	return s.taskRepo.Create(task)
 }
 
+func (s *taskService) CreateWeeklyTask(task *models.Task) error {
+	task.TaskType = string(models.Weekly)
+	task.IsRecurring = true
+	task.RecurringPattern = "weekly"
+	return s.taskRepo.Create(task)
+}
+
 func (s *taskService) CreateMonthlyTask(task *models.Task) error {
 	task.TaskType = string(models.Monthly)
 	task.IsRecurring = true
@@ -90,11 +325,39 @@ func (s *taskService) CreateMonthlyTask(task *models.Task) error {
 	return s.taskRepo.Create(task)
 }
 
+func (s *taskService) StreamAllTasks(batchSize int, fn func(tasks []models.Task) error) error {
+	return s.taskRepo.FindInBatches(batchSize, fn)
+}
+
 func (s *taskService) ResetDailyTasks() error {
-	// This would be called by a cron job or scheduler
-	// Reset all daily tasks to 0% completion
-	// Implementation depends on your specific requirements
-	return nil
+	// Called by the daily scheduler after streaks were updated
+	return s.taskRepo.ResetProgressByType(string(models.Daily))
+}
+
+// weeklyResetKey holds the ISO week weekly tasks were last reset for
+const weeklyResetKey = "weekly_tasks:last_reset"
+
+// ResetWeeklyTasks starts weekly tasks over once per ISO week. It reports
+// whether a reset happened; calling it again within the same week is a no-op.
+// When no reset was recorded yet, as on a fresh or flushed Redis, the current
+// week is recorded instead of wiping progress made earlier in it
+func (s *taskService) ResetWeeklyTasks(now time.Time) (bool, error) {
+	week := WeekKey(now)
+	last, err := s.redis.Get(weeklyResetKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, s.redis.SetNX(weeklyResetKey, week, 0).Err()
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read last weekly reset: %w", err)
+	}
+	if last == week {
+		return false, nil
+	}
+
+	if err := s.taskRepo.ResetProgressByType(string(models.Weekly)); err != nil {
+		return false, err
+	}
+	return true, s.redis.Set(weeklyResetKey, week, 0).Err()
 }
 
 func (s *taskService) ResetMonthlyTasks() error {
@@ -103,3 +366,197 @@ func (s *taskService) ResetMonthlyTasks() error {
 	// Implementation depends on your specific requirements
 	return nil
 }
+
+func (s *taskService) TransferTasks(fromUserID, toUserID uint) (int64, error) {
+	return s.taskRepo.ReassignAll(fromUserID, toUserID)
+}
+
+// DailyStreak is one user's result for a closed day: the average progress
+// of their daily tasks and the streak that day left them on
+type DailyStreak struct {
+	UserID   uint
+	Progress int
+	Streak   int
+}
+
+// UpdateDailyStreaks records, for every user with daily tasks, whether all of
+// them were completed on day, extending or resetting their streak. It returns
+// each user's result so they can be told about it
+func (s *taskService) UpdateDailyStreaks(day time.Time) ([]DailyStreak, error) {
+	tasks, err := s.taskRepo.GetByType(string(models.Daily))
+	if err != nil {
+		return nil, err
+	}
+
+	var order []uint
+	allDone := make(map[uint]bool)
+	progress := make(map[uint][]int)
+	for _, task := range tasks {
+		done, seen := allDone[task.AssignedTo]
+		if !seen {
+			done = true
+			order = append(order, task.AssignedTo)
+		}
+		allDone[task.AssignedTo] = done && task.CompletionPercentage >= 100
+		progress[task.AssignedTo] = append(progress[task.AssignedTo], task.CompletionPercentage)
+	}
+
+	date := day.Format("2006-01-02")
+	results := make([]DailyStreak, 0, len(order))
+	for _, userID := range order {
+		streak, err := s.redis.GetStreak(userID)
+		if err != nil {
+			return results, err
+		}
+		next := NextStreak(streak, date, allDone[userID])
+		if err := s.redis.SetStreak(userID, next); err != nil {
+			return results, err
+		}
+
+		total := 0
+		for _, p := range progress[userID] {
+			total += p
+		}
+		results = append(results, DailyStreak{UserID: userID, Progress: total / len(progress[userID]), Streak: next.Count})
+	}
+
+	return results, nil
+}
+
+// NextStreak returns the streak after a day: it grows by one when all daily
+// tasks were done and the previous day was also part of the streak, starts
+// over at one after a gap, and drops to zero when the day was missed
+func NextStreak(current *redis.StreakData, date string, allDone bool) *redis.StreakData {
+	if !allDone {
+		return &redis.StreakData{}
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return &redis.StreakData{}
+	}
+
+	if current != nil && current.LastDate == date {
+		return current
+	}
+
+	if current != nil && current.LastDate == day.AddDate(0, 0, -1).Format("2006-01-02") {
+		return &redis.StreakData{Count: current.Count + 1, LastDate: date}
+	}
+
+	return &redis.StreakData{Count: 1, LastDate: date}
+}
+
+// GetDailyStreak returns the user's current streak of fully completed days.
+// A streak whose last day is older than yesterday has lapsed
+func (s *taskService) GetDailyStreak(userID uint) (int, error) {
+	streak, err := s.redis.GetStreak(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now()
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	today := now.Format("2006-01-02")
+	if streak.LastDate != yesterday && streak.LastDate != today {
+		return 0, nil
+	}
+	return streak.Count, nil
+}
+
+// EscalatePriorities raises the priority of unfinished tasks as their due
+// date approaches and returns the tasks that changed
+func (s *taskService) EscalatePriorities(now time.Time, cfg EscalationConfig) ([]Escalation, error) {
+	window := cfg.MediumWithin
+	if cfg.HighWithin > window {
+		window = cfg.HighWithin
+	}
+
+	tasks, err := s.taskRepo.GetOpenDueBefore(now.Add(window))
+	if err != nil {
+		return nil, err
+	}
+
+	var escalations []Escalation
+	for _, task := range tasks {
+		from := task.Priority
+		if from == "" {
+			from = string(models.Medium)
+		}
+		to := EscalatedPriority(&task, now, cfg)
+		if to == from {
+			continue
+		}
+
+		if err := s.taskRepo.UpdatePriority(task.ID, to); err != nil {
+			return escalations, fmt.Errorf("failed to escalate task %d: %w", task.ID, err)
+		}
+		escalations = append(escalations, Escalation{Task: task, From: from, To: to})
+	}
+	return escalations, nil
+}
+
+// overdueFrom are the statuses a task leaves for Overdue once its due day
+// has passed. Blocked tasks keep their status so the blocker stays visible
+var overdueFrom = map[string]bool{
+	string(models.Pending):    true,
+	string(models.InProgress): true,
+}
+
+// MarkOverdue moves open tasks whose due day ended before now to Overdue and
+// returns the tasks it changed
+func (s *taskService) MarkOverdue(now time.Time) ([]models.Task, error) {
+	tasks, err := s.taskRepo.GetOpenDueBefore(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var marked []models.Task
+	for _, task := range tasks {
+		if !overdueFrom[task.Status] || !task.IsOverdue(now) {
+			continue
+		}
+		if err := s.taskRepo.UpdateStatus(task.ID, string(models.Overdue)); err != nil {
+			return marked, fmt.Errorf("failed to mark task %d overdue: %w", task.ID, err)
+		}
+		marked = append(marked, task)
+	}
+	return marked, nil
+}
+
+// isTaskAdmin reports whether userID may act on tasks assigned to others
+func (s *taskService) isTaskAdmin(userID uint) (bool, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	role := models.NormalizeRole(user.Role)
+	return role == string(models.Admin) || role == string(models.SuperAdmin), nil
+}
+
+// UpdateStatus moves a task to status on behalf of actor, who must be the
+// assignee or an admin. Setting the current status again is a no-op
+func (s *taskService) UpdateStatus(taskID uint, status string, actor uint) error {
+	task, err := s.taskRepo.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	isAdmin, err := s.isTaskAdmin(actor)
+	if err != nil {
+		return err
+	}
+	if task.AssignedTo != actor && !isAdmin {
+		return ErrTaskStatusForbidden
+	}
+
+	if task.Status == status {
+		return nil
+	}
+
+	if !CanTransition(task.Status, status, isAdmin) {
+		return fmt.Errorf("%w: %s → %s", ErrInvalidTransition, task.Status, status)
+	}
+
+	return s.taskRepo.UpdateStatus(taskID, status)
+}
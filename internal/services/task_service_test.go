@@ -0,0 +1,566 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestNextStreak(t *testing.T) {
+	tests := []struct {
+		name    string
+		current *redis.StreakData
+		date    string
+		allDone bool
+		want    redis.StreakData
+	}{
+		{name: "first completed day", date: "2025-01-10", allDone: true, want: redis.StreakData{Count: 1, LastDate: "2025-01-10"}},
+		{name: "consecutive day extends", current: &redis.StreakData{Count: 4, LastDate: "2025-01-09"}, date: "2025-01-10", allDone: true, want: redis.StreakData{Count: 5, LastDate: "2025-01-10"}},
+		{name: "across a month end", current: &redis.StreakData{Count: 2, LastDate: "2025-01-31"}, date: "2025-02-01", allDone: true, want: redis.StreakData{Count: 3, LastDate: "2025-02-01"}},
+		{name: "gap starts over", current: &redis.StreakData{Count: 4, LastDate: "2025-01-07"}, date: "2025-01-10", allDone: true, want: redis.StreakData{Count: 1, LastDate: "2025-01-10"}},
+		{name: "same day twice is counted once", current: &redis.StreakData{Count: 4, LastDate: "2025-01-10"}, date: "2025-01-10", allDone: true, want: redis.StreakData{Count: 4, LastDate: "2025-01-10"}},
+		{name: "missed day resets", current: &redis.StreakData{Count: 4, LastDate: "2025-01-09"}, date: "2025-01-10"},
+		{name: "bad date resets", current: &redis.StreakData{Count: 4, LastDate: "2025-01-09"}, date: "10/01/2025", allDone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextStreak(tt.current, tt.date, tt.allDone); *got != tt.want {
+				t.Errorf("NextStreak() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateDailyStreaks(t *testing.T) {
+	client, _ := newTestRedis(t)
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	if err := client.SetStreak(1, &redis.StreakData{Count: 4, LastDate: "2025-01-09"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetStreak(2, &redis.StreakData{Count: 7, LastDate: "2025-01-09"}); err != nil {
+		t.Fatal(err)
+	}
+
+	daily := string(models.Daily)
+	repo := &fakeTaskRepo{tasks: []models.Task{
+		{AssignedTo: 1, TaskType: daily, CompletionPercentage: 100},
+		{AssignedTo: 1, TaskType: daily, CompletionPercentage: 100},
+		{AssignedTo: 2, TaskType: daily, CompletionPercentage: 100},
+		{AssignedTo: 2, TaskType: daily, CompletionPercentage: 50},
+		{AssignedTo: 3, TaskType: string(models.Weekly), CompletionPercentage: 0},
+	}}
+	svc := NewTaskService(repo, nil, client, clock.NewFake(day.Add(time.Hour)))
+
+	results, err := svc.UpdateDailyStreaks(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DailyStreak{
+		{UserID: 1, Progress: 100, Streak: 5},
+		{UserID: 2, Progress: 75, Streak: 0},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+
+	if streak, err := svc.GetDailyStreak(1); err != nil || streak != 5 {
+		t.Errorf("GetDailyStreak(1) = %d, %v; want 5", streak, err)
+	}
+	if streak, err := svc.GetDailyStreak(2); err != nil || streak != 0 {
+		t.Errorf("GetDailyStreak(2) = %d, %v; want 0", streak, err)
+	}
+}
+
+func TestSendDailyProgressReminder(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress int
+		streak   int
+		want     string
+	}{
+		{name: "with streak", progress: 100, streak: 5, want: "📅 Daily Progress Reminder: 100% completed\n🔥 5-day streak!"},
+		{name: "no streak", progress: 40, want: "📅 Daily Progress Reminder: 40% completed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wa := &fakeWhatsAppService{}
+			svc := NewReminderService(nil, wa, nil, nil, nil, QuietHours{}, nil, nil)
+			if err := svc.SendDailyProgressReminder("628123456789", tt.progress, tt.streak); err != nil {
+				t.Fatal(err)
+			}
+			if len(wa.sent) != 1 || wa.sent[0] != tt.want {
+				t.Errorf("sent %q, want %q", wa.sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkOverdue(t *testing.T) {
+	now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	earlierToday := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name       string
+		task       models.Task
+		wantMarked bool
+	}{
+		{name: "pending past its due day", task: models.Task{ID: 1, Status: string(models.Pending), DueDate: &yesterday}, wantMarked: true},
+		{name: "in progress past its due day", task: models.Task{ID: 2, Status: string(models.InProgress), DueDate: &yesterday}, wantMarked: true},
+		{name: "due earlier today", task: models.Task{ID: 3, Status: string(models.Pending), DueDate: &earlierToday}},
+		{name: "completed", task: models.Task{ID: 4, Status: string(models.Completed), DueDate: &yesterday}},
+		{name: "blocked keeps its status", task: models.Task{ID: 5, Status: string(models.Blocked), DueDate: &yesterday}},
+		{name: "already overdue", task: models.Task{ID: 6, Status: string(models.Overdue), DueDate: &yesterday}},
+		{name: "no due date", task: models.Task{ID: 7, Status: string(models.Pending)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTaskRepo{tasks: []models.Task{tt.task}}
+			svc := NewTaskService(repo, nil, nil, clock.NewFake(now))
+
+			marked, err := svc.MarkOverdue(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(marked) == 1; got != tt.wantMarked {
+				t.Fatalf("marked = %+v, want marked %v", marked, tt.wantMarked)
+			}
+			status, updated := repo.statuses[tt.task.ID]
+			if updated != tt.wantMarked {
+				t.Fatalf("status updated = %v, want %v", updated, tt.wantMarked)
+			}
+			if updated && status != string(models.Overdue) {
+				t.Errorf("status = %q, want %q", status, models.Overdue)
+			}
+		})
+	}
+}
+
+func TestEscalatedPriority(t *testing.T) {
+	now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	cfg := DefaultEscalationConfig()
+	in := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+
+	tests := []struct {
+		name string
+		task models.Task
+		want string
+	}{
+		{name: "far from due", task: models.Task{Priority: "low", DueDate: in(5 * 24 * time.Hour)}, want: "low"},
+		{name: "within three days", task: models.Task{Priority: "low", DueDate: in(48 * time.Hour)}, want: "medium"},
+		{name: "within a day", task: models.Task{Priority: "low", DueDate: in(12 * time.Hour)}, want: "high"},
+		{name: "past due", task: models.Task{Priority: "medium", DueDate: in(-time.Hour)}, want: "high"},
+		{name: "urgent is never lowered", task: models.Task{Priority: "urgent", DueDate: in(12 * time.Hour)}, want: "urgent"},
+		{name: "high stays high far from due", task: models.Task{Priority: "high", DueDate: in(5 * 24 * time.Hour)}, want: "high"},
+		{name: "empty priority counts as medium", task: models.Task{DueDate: in(5 * 24 * time.Hour)}, want: "medium"},
+		{name: "completed", task: models.Task{Priority: "low", Status: string(models.Completed), DueDate: in(time.Hour)}, want: "low"},
+		{name: "no due date", task: models.Task{Priority: "low"}, want: "low"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscalatedPriority(&tt.task, now, cfg); got != tt.want {
+				t.Errorf("EscalatedPriority() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscalatePrioritiesAsDueDateNears(t *testing.T) {
+	start := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	due := start.Add(5 * 24 * time.Hour)
+	done := start.Add(2 * 24 * time.Hour)
+	repo := &fakeTaskRepo{tasks: []models.Task{
+		{ID: 1, Title: "Pack boxes", Priority: "low", Status: string(models.InProgress), DueDate: &due},
+		{ID: 2, Title: "Finished", Priority: "low", Status: string(models.Completed), DueDate: &done},
+	}}
+	now := clock.NewFake(start)
+	svc := NewTaskService(repo, nil, nil, now)
+	cfg := EscalationConfig{MediumWithin: 72 * time.Hour, HighWithin: 24 * time.Hour}
+
+	passes := []struct {
+		advance  time.Duration
+		wantFrom string
+		wantTo   string
+	}{
+		{advance: 0},
+		{advance: 24 * time.Hour},
+		{advance: 25 * time.Hour, wantFrom: "low", wantTo: "medium"},
+		{advance: 24 * time.Hour},
+		{advance: 24 * time.Hour, wantFrom: "medium", wantTo: "high"},
+		{advance: 24 * time.Hour},
+	}
+
+	for i, pass := range passes {
+		now.Advance(pass.advance)
+		escalations, err := svc.EscalatePriorities(now.Now(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pass.wantTo == "" {
+			if len(escalations) != 0 {
+				t.Errorf("pass %d at %s escalated %+v, want nothing", i, now.Now(), escalations)
+			}
+			continue
+		}
+		if len(escalations) != 1 {
+			t.Fatalf("pass %d at %s escalated %d tasks, want 1", i, now.Now(), len(escalations))
+		}
+		e := escalations[0]
+		if e.Task.ID != 1 || e.From != pass.wantFrom || e.To != pass.wantTo {
+			t.Errorf("pass %d escalated task %d %s → %s, want task 1 %s → %s", i, e.Task.ID, e.From, e.To, pass.wantFrom, pass.wantTo)
+		}
+	}
+
+	if repo.tasks[0].Priority != "high" || repo.tasks[1].Priority != "low" {
+		t.Errorf("priorities = %q, %q; want high for the open task and low for the completed one", repo.tasks[0].Priority, repo.tasks[1].Priority)
+	}
+}
+
+func TestGetTasksByUserFilters(t *testing.T) {
+	repo := &fakeTaskRepo{tasks: []models.Task{
+		{ID: 1, AssignedTo: 1, Status: string(models.Pending), Priority: string(models.Urgent)},
+		{ID: 2, AssignedTo: 1, Status: string(models.InProgress), Priority: string(models.Low)},
+		{ID: 3, AssignedTo: 1, Status: string(models.Pending), Priority: string(models.Low)},
+		{ID: 4, AssignedTo: 2, Status: string(models.Pending), Priority: string(models.Urgent)},
+	}}
+	svc := NewTaskService(repo, nil, nil, clock.Real{})
+
+	tests := []struct {
+		name    string
+		get     func(userID uint, value string) ([]models.Task, error)
+		value   string
+		wantIDs []uint
+		wantErr bool
+	}{
+		{name: "status", get: svc.GetTasksByUserAndStatus, value: "pending", wantIDs: []uint{1, 3}},
+		{name: "status is normalized", get: svc.GetTasksByUserAndStatus, value: " In_Progress ", wantIDs: []uint{2}},
+		{name: "status with no tasks", get: svc.GetTasksByUserAndStatus, value: "blocked"},
+		{name: "unknown status", get: svc.GetTasksByUserAndStatus, value: "done", wantErr: true},
+		{name: "order status is not a task status", get: svc.GetTasksByUserAndStatus, value: "cancelled", wantErr: true},
+		{name: "priority", get: svc.GetTasksByUserAndPriority, value: "urgent", wantIDs: []uint{1}},
+		{name: "priority is normalized", get: svc.GetTasksByUserAndPriority, value: "LOW", wantIDs: []uint{2, 3}},
+		{name: "unknown priority", get: svc.GetTasksByUserAndPriority, value: "critical", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := tt.get(1, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(tasks) != len(tt.wantIDs) {
+				t.Fatalf("tasks = %+v, want IDs %v", tasks, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if tasks[i].ID != id {
+					t.Errorf("tasks[%d].ID = %d, want %d", i, tasks[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDailyStreakAcrossMidnight(t *testing.T) {
+	client, _ := newTestRedis(t)
+	if err := client.SetStreak(1, &redis.StreakData{Count: 6, LastDate: "2025-01-10"}); err != nil {
+		t.Fatal(err)
+	}
+	now := clock.NewFake(time.Date(2025, 1, 10, 23, 59, 0, 0, time.UTC))
+	svc := NewTaskService(nil, nil, client, now)
+
+	checks := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{advance: 0, want: 6},                          // the same day
+		{advance: time.Minute, want: 6},                // midnight: the streak day is yesterday
+		{advance: 24*time.Hour - time.Second, want: 6}, // last second of the next day
+		{advance: time.Second, want: 0},                // a full day missed
+	}
+
+	for _, check := range checks {
+		now.Advance(check.advance)
+		got, err := svc.GetDailyStreak(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != check.want {
+			t.Errorf("GetDailyStreak at %s = %d, want %d", now.Now(), got, check.want)
+		}
+	}
+}
+
+func TestWeekKey(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{name: "mid-year", t: time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC), want: "2025-W07"},
+		{name: "Sunday ends the week", t: time.Date(2024, 12, 29, 23, 59, 59, 0, time.UTC), want: "2024-W52"},
+		{name: "Monday in December starts the next year's week 1", t: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), want: "2025-W01"},
+		{name: "January days in the previous year's last week", t: time.Date(2021, 1, 3, 10, 0, 0, 0, time.UTC), want: "2020-W53"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekKey(tt.t); got != tt.want {
+				t.Errorf("WeekKey(%s) = %q, want %q", tt.t.Format("Mon 2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWeeklyTasks(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	repo := &fakeTaskRepo{}
+	svc := NewTaskService(repo, nil, nil, clock.NewFake(time.Date(2025, 1, 15, 9, 0, 0, 0, jakarta)))
+
+	tests := []struct {
+		week    string
+		wantEnd time.Time
+		wantErr bool
+	}{
+		{week: "2025-W03", wantEnd: time.Date(2025, 1, 20, 0, 0, 0, 0, jakarta)},
+		{week: "2025-W01", wantEnd: time.Date(2025, 1, 6, 0, 0, 0, 0, jakarta)},
+		{week: "2020-W53", wantEnd: time.Date(2021, 1, 4, 0, 0, 0, 0, jakarta)},
+		{week: "2025-W54", wantErr: true},
+		{week: "this week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		repo.weekEnds = nil
+		_, err := svc.GetWeeklyTasks(1, tt.week)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("GetWeeklyTasks(%q) err = %v, wantErr %v", tt.week, err, tt.wantErr)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(repo.weekEnds) != 1 || !repo.weekEnds[0].Equal(tt.wantEnd) {
+			t.Errorf("GetWeeklyTasks(%q) queried up to %v, want the following Monday %v", tt.week, repo.weekEnds, tt.wantEnd)
+		}
+	}
+}
+
+func TestCreateWeeklyTask(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := NewTaskService(repo, nil, nil, clock.Real{})
+
+	task := &models.Task{Title: "Stock opname", AssignedTo: 2, TaskType: string(models.Custom)}
+	if err := svc.CreateWeeklyTask(task); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.tasks) != 1 {
+		t.Fatalf("created %d tasks, want 1", len(repo.tasks))
+	}
+	got := repo.tasks[0]
+	if got.TaskType != string(models.Weekly) || !got.IsRecurring || got.RecurringPattern != "weekly" {
+		t.Errorf("task = %+v, want a recurring weekly task", got)
+	}
+}
+
+func TestResetWeeklyTasksAtWeekBoundary(t *testing.T) {
+	client, _ := newTestRedis(t)
+	repo := &fakeTaskRepo{}
+	now := clock.NewFake(time.Date(2025, 1, 12, 23, 0, 0, 0, time.UTC)) // Sunday of 2025-W02
+	svc := NewTaskService(repo, nil, client, now)
+
+	passes := []struct {
+		advance   time.Duration
+		wantReset bool
+	}{
+		{advance: 0, wantReset: false},                // first run records the week
+		{advance: 59 * time.Minute, wantReset: false}, // still Sunday
+		{advance: time.Minute, wantReset: true},       // Monday midnight starts 2025-W03
+		{advance: 6 * 24 * time.Hour, wantReset: false},
+		{advance: 24 * time.Hour, wantReset: true}, // 2025-W04
+	}
+
+	for i, pass := range passes {
+		now.Advance(pass.advance)
+		reset, err := svc.ResetWeeklyTasks(now.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reset != pass.wantReset {
+			t.Errorf("pass %d at %s: reset = %v, want %v", i, now.Now().Format("Mon 2006-01-02 15:04"), reset, pass.wantReset)
+		}
+	}
+
+	if len(repo.resets) != 2 {
+		t.Fatalf("reset %d times, want once per week", len(repo.resets))
+	}
+	for _, taskType := range repo.resets {
+		if taskType != string(models.Weekly) {
+			t.Errorf("reset %q tasks, want only weekly ones", taskType)
+		}
+	}
+}
+
+func TestResetWeeklyTasksLastReset(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC) // Wednesday of 2025-W03
+
+	tests := []struct {
+		name      string
+		lastReset string
+		redisDown bool
+		wantReset bool
+		wantErr   bool
+		wantKey   string
+	}{
+		{name: "missing key is seeded without a reset", wantKey: "2025-W03"},
+		{name: "same week", lastReset: "2025-W03", wantKey: "2025-W03"},
+		{name: "previous week", lastReset: "2025-W02", wantReset: true, wantKey: "2025-W03"},
+		{name: "Redis unavailable", redisDown: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			if tt.lastReset != "" {
+				server.Set(weeklyResetKey, tt.lastReset)
+			}
+			if tt.redisDown {
+				server.Close()
+			}
+			repo := &fakeTaskRepo{}
+			svc := NewTaskService(repo, nil, client, clock.NewFake(now))
+
+			reset, err := svc.ResetWeeklyTasks(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if reset != tt.wantReset || (len(repo.resets) == 1) != tt.wantReset {
+				t.Errorf("reset = %v after %d resets, want %v", reset, len(repo.resets), tt.wantReset)
+			}
+			if tt.redisDown {
+				return
+			}
+			if got, _ := server.Get(weeklyResetKey); got != tt.wantKey {
+				t.Errorf("%s = %q, want %q", weeklyResetKey, got, tt.wantKey)
+			}
+			if server.TTL(weeklyResetKey) != 0 {
+				t.Errorf("%s expires, want it kept", weeklyResetKey)
+			}
+		})
+	}
+}
+
+func TestGetCompletedTasks(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	at := func(d, hour int) *time.Time {
+		t := time.Date(2025, 1, d, hour, 0, 0, 0, jakarta)
+		return &t
+	}
+	day := func(d int) time.Time { return *at(d, 0) }
+	repo := &fakeTaskRepo{tasks: []models.Task{
+		{ID: 1, CompletedAt: at(9, 23)},
+		{ID: 2, CompletedAt: at(10, 0)},
+		{ID: 3, CompletedAt: at(12, 15)},
+		{ID: 4, CompletedAt: at(15, 23)},
+		{ID: 5, CompletedAt: at(16, 0)},
+		{ID: 6, Status: string(models.InProgress)},
+	}}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		wantIDs    []uint
+		wantErr    bool
+	}{
+		{name: "both days inclusive", start: day(10), end: day(15), wantIDs: []uint{2, 3, 4}},
+		// A time of day on the bounds does not cut the day short
+		{name: "times ignored", start: *at(10, 12), end: *at(15, 1), wantIDs: []uint{2, 3, 4}},
+		{name: "single day", start: day(12), end: day(12), wantIDs: []uint{3}},
+		{name: "nothing completed", start: day(20), end: day(25)},
+		{name: "reversed", start: day(15), end: day(10), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewTaskService(repo, nil, nil, clock.NewFake(day(20)))
+
+			tasks, err := svc.GetCompletedTasks(tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var got []uint
+			for _, task := range tasks {
+				got = append(got, task.ID)
+			}
+			if !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("completed = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestTaskUpdatesRequireOwnership(t *testing.T) {
+	users := &fakeUserRepo{users: []*models.User{
+		{ID: 1, Role: string(models.Users)},
+		{ID: 2, Role: string(models.Users)},
+		{ID: 3, Role: string(models.Admin)},
+		{ID: 4, Role: "SuperAdmin"},
+	}}
+
+	tests := []struct {
+		name       string
+		actor      uint
+		wantErr    error
+		wantStored bool
+	}{
+		{name: "assignee", actor: 1, wantStored: true},
+		{name: "another user", actor: 2, wantErr: ErrTaskProgressForbidden},
+		{name: "admin", actor: 3, wantStored: true},
+		{name: "super admin under a legacy role name", actor: 4, wantStored: true},
+		{name: "unknown actor", actor: 9, wantErr: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestRedis(t)
+			repo := &fakeTaskRepo{tasks: []models.Task{{ID: 7, AssignedTo: 1, Status: string(models.InProgress)}}}
+			svc := NewTaskService(repo, users, client, clock.Real{})
+
+			err := svc.UpdateTaskProgress(7, 50, false, "halfway", tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateTaskProgress err = %v, want %v", err, tt.wantErr)
+			}
+			if stored := len(repo.progress) == 1; stored != tt.wantStored {
+				t.Errorf("progress stored = %v, want %v", stored, tt.wantStored)
+			}
+
+			// Completing the task is held to the same rule
+			wantStatusErr := tt.wantErr
+			if errors.Is(wantStatusErr, ErrTaskProgressForbidden) {
+				wantStatusErr = ErrTaskStatusForbidden
+			}
+			if err := svc.UpdateStatus(7, string(models.Completed), tt.actor); !errors.Is(err, wantStatusErr) {
+				t.Fatalf("UpdateStatus err = %v, want %v", err, wantStatusErr)
+			}
+			if _, updated := repo.statuses[7]; updated != tt.wantStored {
+				t.Errorf("status updated = %v, want %v", updated, tt.wantStored)
+			}
+		})
+	}
+}
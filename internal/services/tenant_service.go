@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"task_manager/internal/models"
+	"task_manager/internal/repository"
+	"task_manager/internal/secretbox"
+	"task_manager/pkg/whatsapp"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTenantInactive is returned when a message arrives for a tenant that
+	// has been switched off
+	ErrTenantInactive = errors.New("tenant is inactive")
+	// ErrNoTenantKey is returned when tenant credentials are needed but no
+	// TENANT_SECRET_KEY is configured to encrypt them with
+	ErrNoTenantKey = errors.New("TENANT_SECRET_KEY is not set")
+	// ErrInvalidTenant is returned by CreateTenant for incomplete tenants
+	ErrInvalidTenant = errors.New("invalid tenant")
+)
+
+type TenantService interface {
+	// ResolveTenant returns the tenant owning the gateway number a message was
+	// sent to, or nil when the number belongs to no tenant and the default
+	// gateway should be used
+	ResolveTenant(to string) (*models.Tenant, error)
+	// ClientFor returns a gateway client using the tenant's credentials; a nil
+	// tenant gets the default client
+	ClientFor(tenant *models.Tenant) (*whatsapp.Client, error)
+	// CreateTenant stores a new tenant with password encrypted
+	CreateTenant(tenant *models.Tenant, password string) error
+}
+
+type tenantService struct {
+	tenantRepo    repository.TenantRepository
+	defaultClient *whatsapp.Client
+	box           *secretbox.Box
+
+	mu      sync.Mutex
+	clients map[uint]*whatsapp.Client
+}
+
+// NewTenantService builds the tenant service. box encrypts tenant gateway
+// passwords; without one, tenants can be neither created nor served
+func NewTenantService(tenantRepo repository.TenantRepository, defaultClient *whatsapp.Client, box *secretbox.Box) TenantService {
+	return &tenantService{
+		tenantRepo:    tenantRepo,
+		defaultClient: defaultClient,
+		box:           box,
+		clients:       make(map[uint]*whatsapp.Client),
+	}
+}
+
+func (s *tenantService) CreateTenant(tenant *models.Tenant, password string) error {
+	if s.box == nil {
+		return ErrNoTenantKey
+	}
+	tenant.Name = strings.TrimSpace(tenant.Name)
+	tenant.WhatsAppNumber = whatsapp.NormalizePhone(tenant.WhatsAppNumber)
+	if tenant.Name == "" || tenant.WhatsAppNumber == "" {
+		return fmt.Errorf("%w: name and whatsapp_number are required", ErrInvalidTenant)
+	}
+
+	sealed, err := s.box.Seal(password)
+	if err != nil {
+		return err
+	}
+	tenant.Password = sealed
+	tenant.IsActive = true
+	return s.tenantRepo.Create(tenant)
+}
+
+func (s *tenantService) ResolveTenant(to string) (*models.Tenant, error) {
+	to = whatsapp.NormalizePhone(to)
+	if to == "" {
+		return nil, nil
+	}
+
+	tenant, err := s.tenantRepo.GetByWhatsAppNumber(to)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.IsActive {
+		return nil, ErrTenantInactive
+	}
+	return tenant, nil
+}
+
+func (s *tenantService) ClientFor(tenant *models.Tenant) (*whatsapp.Client, error) {
+	if tenant == nil {
+		return s.defaultClient, nil
+	}
+	if s.box == nil {
+		return nil, ErrNoTenantKey
+	}
+	password, err := s.box.Open(tenant.Password)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %d password: %w", tenant.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Rebuild the client when the tenant's credentials changed since it was cached
+	if client, ok := s.clients[tenant.ID]; ok && sameCredentials(client, tenant, password) {
+		return client, nil
+	}
+
+	baseURL := tenant.APIURL
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = s.defaultClient.BaseURL
+	}
+	client := whatsapp.NewClient(baseURL, tenant.Username, password, tenant.Path)
+	s.clients[tenant.ID] = client
+	return client, nil
+}
+
+func sameCredentials(client *whatsapp.Client, tenant *models.Tenant, password string) bool {
+	return (tenant.APIURL == "" || client.BaseURL == tenant.APIURL) &&
+		client.Username == tenant.Username &&
+		client.Password == password &&
+		client.Path == tenant.Path
+}
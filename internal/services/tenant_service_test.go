@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"task_manager/internal/models"
+	"task_manager/internal/secretbox"
+	"task_manager/pkg/whatsapp"
+	"testing"
+)
+
+func newTestBox(t *testing.T) *secretbox.Box {
+	t.Helper()
+	box, err := secretbox.New("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+func TestCreateTenant(t *testing.T) {
+	tests := []struct {
+		name    string
+		noKey   bool
+		tenant  models.Tenant
+		wantErr error
+	}{
+		{name: "valid", tenant: models.Tenant{Name: "Toko Kue", WhatsAppNumber: "0812-3456-7890@s.whatsapp.net"}},
+		{name: "missing name", tenant: models.Tenant{Name: "  ", WhatsAppNumber: "081234567890"}, wantErr: ErrInvalidTenant},
+		{name: "missing number", tenant: models.Tenant{Name: "Toko Kue"}, wantErr: ErrInvalidTenant},
+		{name: "no secret key", noKey: true, tenant: models.Tenant{Name: "Toko Kue", WhatsAppNumber: "081234567890"}, wantErr: ErrNoTenantKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := newTestBox(t)
+			if tt.noKey {
+				box = nil
+			}
+			repo := &fakeTenantRepo{}
+			svc := NewTenantService(repo, whatsapp.NewClient("https://gateway.test", "", "", ""), box)
+
+			tenant := tt.tenant
+			err := svc.CreateTenant(&tenant, "gateway-pass")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.tenants) != 0 {
+					t.Error("tenant stored despite the error")
+				}
+				return
+			}
+
+			stored := repo.tenants[0]
+			if stored.Password == "gateway-pass" || !secretbox.IsSealed(stored.Password) {
+				t.Errorf("password stored as %q, want it encrypted", stored.Password)
+			}
+			if stored.WhatsAppNumber != "6281234567890" || !stored.IsActive {
+				t.Errorf("stored %+v", stored)
+			}
+		})
+	}
+}
+
+func TestTenantClientFor(t *testing.T) {
+	box := newTestBox(t)
+	repo := &fakeTenantRepo{}
+	defaultClient := whatsapp.NewClient("https://gateway.test", "default", "default-pass", "main")
+	svc := NewTenantService(repo, defaultClient, box)
+
+	tenant := &models.Tenant{Name: "Toko Kue", WhatsAppNumber: "081234567890", Username: "toko", Path: "toko"}
+	if err := svc.CreateTenant(tenant, "gateway-pass"); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := svc.ResolveTenant("6281234567890@s.whatsapp.net")
+	if err != nil || resolved == nil || resolved.ID != tenant.ID {
+		t.Fatalf("ResolveTenant = %+v, %v", resolved, err)
+	}
+
+	client, err := svc.ClientFor(resolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Password != "gateway-pass" || client.Username != "toko" || client.BaseURL != "https://gateway.test" {
+		t.Errorf("client = %+v, want the tenant's decrypted credentials on the default URL", client)
+	}
+	if again, _ := svc.ClientFor(resolved); again != client {
+		t.Error("client was not reused for unchanged credentials")
+	}
+
+	if client, err := svc.ClientFor(nil); err != nil || client != defaultClient {
+		t.Errorf("ClientFor(nil) = %p, %v; want the default client", client, err)
+	}
+
+	plain := &models.Tenant{ID: 9, Password: "gateway-pass"}
+	if _, err := svc.ClientFor(plain); !errors.Is(err, secretbox.ErrNotSealed) {
+		t.Errorf("ClientFor(plaintext password) err = %v, want ErrNotSealed", err)
+	}
+
+	resolved.IsActive = false
+	if _, err := svc.ResolveTenant("6281234567890"); !errors.Is(err, ErrTenantInactive) {
+		t.Errorf("ResolveTenant(inactive) err = %v, want ErrTenantInactive", err)
+	}
+	if tenant, err := svc.ResolveTenant("6289999999999"); tenant != nil || err != nil {
+		t.Errorf("ResolveTenant(unknown) = %+v, %v; want nil, nil", tenant, err)
+	}
+}
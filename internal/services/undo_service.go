@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"task_manager/internal/redis"
+	"task_manager/internal/repository"
+	"time"
+)
+
+// Undoable action types
+const (
+	UndoOrderCreated = "order_created"
+	UndoOrderStatus  = "order_status"
+	UndoTaskStatus   = "task_status"
+	UndoTaskProgress = "task_progress"
+)
+
+// UndoWindow is how long after an action /undo can still reverse it
+const UndoWindow = 5 * time.Minute
+
+// ErrNothingToUndo is returned when the user has no recent action on record
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+type UndoService interface {
+	RecordOrderCreated(userID uint, order *models.Order) error
+	RecordOrderStatus(userID uint, order *models.Order, items []*models.OrderItem) error
+	RecordTaskChange(userID uint, actionType string, task *models.Task) error
+	Undo(userID uint) (*redis.LastAction, error)
+}
+
+type undoService struct {
+	taskRepo      repository.TaskRepository
+	orderRepo     repository.OrderRepository
+	orderItemRepo repository.OrderItemRepository
+	redis         *redis.Client
+	clock         clock.Clock
+}
+
+func NewUndoService(taskRepo repository.TaskRepository, orderRepo repository.OrderRepository, orderItemRepo repository.OrderItemRepository, redis *redis.Client, clk clock.Clock) UndoService {
+	return &undoService{taskRepo: taskRepo, orderRepo: orderRepo, orderItemRepo: orderItemRepo, redis: redis, clock: clock.OrReal(clk)}
+}
+
+func (s *undoService) record(userID uint, action *redis.LastAction) error {
+	action.CreatedAt = s.clock.Now()
+	return s.redis.SetLastAction(userID, action, UndoWindow)
+}
+
+func (s *undoService) RecordOrderCreated(userID uint, order *models.Order) error {
+	return s.record(userID, &redis.LastAction{Type: UndoOrderCreated, EntityID: order.ID})
+}
+
+// RecordOrderStatus stores the order and item statuses as they were before a
+// status change, since completing an order also completes its items
+func (s *undoService) RecordOrderStatus(userID uint, order *models.Order, items []*models.OrderItem) error {
+	itemStatuses := make(map[uint]string, len(items))
+	for _, item := range items {
+		itemStatuses[item.ID] = item.Status
+	}
+
+	return s.record(userID, &redis.LastAction{
+		Type:           UndoOrderStatus,
+		EntityID:       order.ID,
+		PreviousStatus: order.Status,
+		ItemStatuses:   itemStatuses,
+	})
+}
+
+// RecordTaskChange stores a task's status and progress as they were before a
+// status or progress update
+func (s *undoService) RecordTaskChange(userID uint, actionType string, task *models.Task) error {
+	return s.record(userID, &redis.LastAction{
+		Type:                actionType,
+		EntityID:            task.ID,
+		PreviousStatus:      task.Status,
+		PreviousProgress:    task.CompletionPercentage,
+		PreviousImplemented: task.IsImplemented,
+		PreviousNotes:       task.ImplementationNotes,
+	})
+}
+
+// Undo reverses the user's last recorded action if it is still within
+// UndoWindow and returns it. The action is taken off the record before it is
+// reversed, so it can only be undone once; if reversing fails it is put back
+// for the rest of the window
+func (s *undoService) Undo(userID uint) (*redis.LastAction, error) {
+	action, err := s.redis.PopLastAction(userID)
+	if err != nil {
+		return nil, err
+	}
+	if action == nil || s.clock.Now().Sub(action.CreatedAt) > UndoWindow {
+		return nil, ErrNothingToUndo
+	}
+
+	switch action.Type {
+	case UndoOrderCreated:
+		err = s.orderRepo.DeleteWithItems(action.EntityID)
+	case UndoOrderStatus:
+		err = s.undoOrderStatus(action)
+	case UndoTaskStatus, UndoTaskProgress:
+		err = s.undoTaskChange(userID, action)
+	default:
+		err = fmt.Errorf("unknown action type %q", action.Type)
+	}
+	if err != nil {
+		if remaining := UndoWindow - s.clock.Now().Sub(action.CreatedAt); remaining > 0 {
+			if restoreErr := s.redis.SetLastAction(userID, action, remaining); restoreErr != nil {
+				return nil, errors.Join(err, restoreErr)
+			}
+		}
+		return nil, err
+	}
+	return action, nil
+}
+
+func (s *undoService) undoOrderStatus(action *redis.LastAction) error {
+	if err := s.orderRepo.UpdateStatus(action.EntityID, action.PreviousStatus, ""); err != nil {
+		return err
+	}
+
+	for itemID, status := range action.ItemStatuses {
+		item, err := s.orderItemRepo.GetByID(itemID)
+		if err != nil {
+			continue // item removed since, nothing to restore
+		}
+		if item.Status == status {
+			continue
+		}
+		item.Status = status
+		if err := s.orderItemRepo.Update(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *undoService) undoTaskChange(userID uint, action *redis.LastAction) error {
+	task, err := s.taskRepo.GetByID(action.EntityID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != action.PreviousStatus {
+		if err := s.taskRepo.UpdateStatus(task.ID, action.PreviousStatus); err != nil {
+			return err
+		}
+	}
+
+	if task.CompletionPercentage != action.PreviousProgress || task.IsImplemented != action.PreviousImplemented {
+		if err := s.taskRepo.UpdateProgress(task.ID, action.PreviousProgress, action.PreviousImplemented, action.PreviousNotes, userID); err != nil {
+			return err
+		}
+		ttl := time.Hour * 24
+		return s.redis.SetTaskProgress(task.ID, action.PreviousProgress, ttl)
+	}
+	return nil
+}
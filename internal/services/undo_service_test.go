@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"task_manager/internal/clock"
+	"task_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestUndoOrderCreated(t *testing.T) {
+	errDelete := errors.New("delete failed")
+
+	tests := []struct {
+		name        string
+		recordedAgo time.Duration
+		record      bool
+		deleteErr   error
+		want        error
+		wantDeleted bool
+		wantKept    bool // the action can still be undone afterwards
+	}{
+		{name: "undoes a recent creation", record: true, wantDeleted: true},
+		{name: "nothing recorded", want: ErrNothingToUndo},
+		{name: "end of the window", record: true, recordedAgo: UndoWindow, wantDeleted: true},
+		{name: "outside the window", record: true, recordedAgo: UndoWindow + time.Second, want: ErrNothingToUndo},
+		{name: "failed reversal can be retried", record: true, deleteErr: errDelete, want: errDelete, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestRedis(t)
+			repo := newFakeOrderRepo(&models.Order{ID: 5})
+			repo.deleteErr = tt.deleteErr
+			now := clock.NewFake(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+			svc := NewUndoService(nil, repo, nil, client, now)
+
+			if tt.record {
+				if err := svc.RecordOrderCreated(1, &models.Order{ID: 5}); err != nil {
+					t.Fatal(err)
+				}
+				now.Advance(tt.recordedAgo)
+			}
+
+			action, err := svc.Undo(1)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (action == nil || action.EntityID != 5) {
+				t.Errorf("action = %+v, want order 5", action)
+			}
+			if deleted := len(repo.deleted) == 1; deleted != tt.wantDeleted {
+				t.Errorf("order deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+
+			kept, err := client.PopLastAction(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (kept != nil) != tt.wantKept {
+				t.Errorf("action kept = %v, want %v", kept != nil, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestUndoOnlyOnce(t *testing.T) {
+	client, _ := newTestRedis(t)
+	repo := newFakeOrderRepo(&models.Order{ID: 5})
+	svc := NewUndoService(nil, repo, nil, client, clock.Real{})
+	if err := svc.RecordOrderCreated(1, &models.Order{ID: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Undo(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Undo(1); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("second undo err = %v, want ErrNothingToUndo", err)
+	}
+	if len(repo.deleted) != 1 {
+		t.Errorf("order deleted %d times, want once", len(repo.deleted))
+	}
+}
@@ -1,9 +1,18 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
 	"task_manager/internal/models"
+	"task_manager/internal/redis"
 	"task_manager/internal/repository"
+	"task_manager/pkg/whatsapp"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -11,20 +20,58 @@ import (
 type UserService interface {
 	CreateUser(user *models.User, password string) error
 	GetUserByID(id uint) (*models.User, error)
+	GetUsersByIDs(ids []uint) ([]models.User, error)
 	GetUserByUsername(username string) (*models.User, error)
 	GetUserByWhatsAppNumber(whatsappNumber string) (*models.User, error)
 	GetAllUsers() ([]models.User, error)
+	GetAllUsersPaginated(offset, limit int) ([]models.User, int64, error)
 	UpdateUser(user *models.User) error
 	DeleteUser(id uint) error
+	CountUsersByRole(role string) (int, error)
+	RecordSeen(userID uint) (bool, error)
+	ResetPassword(userID uint) (string, error)
 	ValidateUserRole(userID uint, requiredRole string) error
 }
 
+// userCacheTTL bounds how long a cached phone lookup may lag behind the database
+const userCacheTTL = 5 * time.Minute
+
 type userService struct {
 	userRepo repository.UserRepository
+	redis    *redis.Client
+	logger   *slog.Logger
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+// NewUserService builds the user service. redis may be nil, in which case
+// every lookup goes to the database; a nil logger logs through slog.Default
+func NewUserService(userRepo repository.UserRepository, redis *redis.Client, logger *slog.Logger) UserService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &userService{userRepo: userRepo, redis: redis, logger: logger}
+}
+
+func userPhoneCacheKey(whatsappNumber string) string {
+	return "user:phone:" + whatsappNumber
+}
+
+// invalidateUser drops the cached phone lookups for the given numbers
+func (s *userService) invalidateUser(numbers ...string) {
+	if s.redis == nil {
+		return
+	}
+	var keys []string
+	for _, number := range numbers {
+		if number != "" {
+			keys = append(keys, userPhoneCacheKey(number))
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	if err := s.redis.Del(keys...).Err(); err != nil {
+		s.logger.Error("Failed to invalidate cached user", "phones", numbers, "error", err)
+	}
 }
 
 func (s *userService) CreateUser(user *models.User, password string) error {
@@ -33,36 +80,112 @@ func (s *userService) CreateUser(user *models.User, password string) error {
 	if err != nil {
 		return err
 	}
-	
-	// For now, we'll store password in a separate field if needed
-	// In a real implementation, you might want to add a password field to User model
-	_ = hashedPassword
+	user.PasswordHash = string(hashedPassword)
+
+	if err := normalizeUserRole(user); err != nil {
+		return err
+	}
+
+	// Phone numbers are always stored in canonical form so lookups match
+	user.PhoneNumber = whatsapp.NormalizePhone(user.PhoneNumber)
+	user.WhatsAppNumber = whatsapp.NormalizePhone(user.WhatsAppNumber)
 	
 	return s.userRepo.Create(user)
 }
 
+// normalizeUserRole stores the role as one of the role constants, so friendly
+// spellings such as "SuperAdmin" match the permission checks. An empty role
+// becomes a plain user
+func normalizeUserRole(user *models.User) error {
+	if strings.TrimSpace(user.Role) == "" {
+		user.Role = string(models.Users)
+		return nil
+	}
+	if !models.IsValidRole(user.Role) {
+		return fmt.Errorf("invalid role %q, use SuperAdmin, Admin or User", user.Role)
+	}
+	user.Role = models.NormalizeRole(user.Role)
+	return nil
+}
+
 func (s *userService) GetUserByID(id uint) (*models.User, error) {
 	return s.userRepo.GetByID(id)
 }
 
+func (s *userService) GetUsersByIDs(ids []uint) ([]models.User, error) {
+	return s.userRepo.GetByIDs(ids)
+}
+
 func (s *userService) GetUserByUsername(username string) (*models.User, error) {
 	return s.userRepo.GetByUsername(username)
 }
 
+// GetUserByWhatsAppNumber checks the Redis cache before the database; the
+// database stays the source of truth on a miss or cache error
 func (s *userService) GetUserByWhatsAppNumber(whatsappNumber string) (*models.User, error) {
-	return s.userRepo.GetByWhatsAppNumber(whatsappNumber)
+	number := whatsapp.NormalizePhone(whatsappNumber)
+	if s.redis == nil {
+		return s.userRepo.GetByWhatsAppNumber(number)
+	}
+
+	key := userPhoneCacheKey(number)
+	if cached, err := s.redis.Get(key).Result(); err == nil {
+		var user models.User
+		if err := json.Unmarshal([]byte(cached), &user); err == nil {
+			return &user, nil
+		}
+	}
+
+	user, err := s.userRepo.GetByWhatsAppNumber(number)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(user); err == nil {
+		if err := s.redis.Set(key, data, userCacheTTL).Err(); err != nil {
+			s.logger.Error("Failed to cache user", "user_id", user.ID, "error", err)
+		}
+	}
+	return user, nil
 }
 
 func (s *userService) GetAllUsers() ([]models.User, error) {
 	return s.userRepo.GetAll()
 }
 
+func (s *userService) GetAllUsersPaginated(offset, limit int) ([]models.User, int64, error) {
+	return s.userRepo.GetAllPaginated(offset, limit)
+}
+
 func (s *userService) UpdateUser(user *models.User) error {
-	return s.userRepo.Update(user)
+	if err := normalizeUserRole(user); err != nil {
+		return err
+	}
+	user.PhoneNumber = whatsapp.NormalizePhone(user.PhoneNumber)
+	user.WhatsAppNumber = whatsapp.NormalizePhone(user.WhatsAppNumber)
+
+	// The number may be changing, so forget the old one as well as the new
+	previous := ""
+	if existing, err := s.userRepo.GetByID(user.ID); err == nil {
+		previous = existing.WhatsAppNumber
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	s.invalidateUser(previous, user.WhatsAppNumber)
+	return nil
 }
 
 func (s *userService) DeleteUser(id uint) error {
-	return s.userRepo.Delete(id)
+	existing, lookupErr := s.userRepo.GetByID(id)
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+	if lookupErr == nil {
+		s.invalidateUser(existing.WhatsAppNumber)
+	}
+	return nil
 }
 
 func (s *userService) ValidateUserRole(userID uint, requiredRole string) error {
@@ -72,9 +195,70 @@ func (s *userService) ValidateUserRole(userID uint, requiredRole string) error {
 	}
 	
 	// Check if user has required role
-	if user.Role != requiredRole {
+	if models.NormalizeRole(user.Role) != models.NormalizeRole(requiredRole) {
 		return errors.New("insufficient permissions")
 	}
 	
 	return nil
 }
+
+// CountUsersByRole counts users holding role, comparing normalized role names
+// so legacy spellings such as "SuperAdmin" are included
+func (s *userService) CountUsersByRole(role string) (int, error) {
+	users, err := s.userRepo.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, u := range users {
+		if models.NormalizeRole(u.Role) == models.NormalizeRole(role) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// generatedPasswordLength is the length of passwords made by ResetPassword
+const generatedPasswordLength = 12
+
+// passwordAlphabet leaves out characters that are easy to misread (0/O, 1/l/I)
+const passwordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ResetPassword gives the user a new random password, stores its bcrypt hash
+// and returns the plaintext so it can be delivered to them
+func (s *userService) ResetPassword(userID uint) (string, error) {
+	password, err := generatePassword(generatedPasswordLength)
+	if err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.userRepo.SetPasswordHash(userID, string(hashedPassword)); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func generatePassword(length int) (string, error) {
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// RecordSeen updates the user's last-seen time and reports whether this was
+// their first interaction
+func (s *userService) RecordSeen(userID uint) (bool, error) {
+	return s.userRepo.TouchLastSeen(userID, time.Now())
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"task_manager/internal/models"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserPhonesAreNormalized(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		lookup string
+	}{
+		{name: "local number, webhook jid", stored: "08123456789", lookup: "628123456789@s.whatsapp.net"},
+		{name: "international number, local lookup", stored: "+62 812-3456-789", lookup: "08123456789"},
+		{name: "bare number, device jid", stored: "8123456789", lookup: "628123456789:3@s.whatsapp.net"},
+		{name: "canonical number, plus lookup", stored: "628123456789", lookup: "+628123456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepo{}
+			svc := NewUserService(repo, nil, nil)
+
+			user := &models.User{Username: "john", PhoneNumber: tt.stored, WhatsAppNumber: tt.stored}
+			if err := svc.CreateUser(user, "secret123"); err != nil {
+				t.Fatal(err)
+			}
+			if user.PhoneNumber != "628123456789" || user.WhatsAppNumber != "628123456789" {
+				t.Errorf("stored phone=%q whatsapp=%q, want 628123456789", user.PhoneNumber, user.WhatsAppNumber)
+			}
+
+			found, err := svc.GetUserByWhatsAppNumber(tt.lookup)
+			if err != nil {
+				t.Fatalf("GetUserByWhatsAppNumber(%q): %v", tt.lookup, err)
+			}
+			if found.ID != user.ID {
+				t.Errorf("found user %d, want %d", found.ID, user.ID)
+			}
+		})
+	}
+}
+
+func TestUserServiceWhatsAppCache(t *testing.T) {
+	const number = "628123456789"
+
+	tests := []struct {
+		name string
+		// between runs between the first and second lookup
+		between     func(t *testing.T, svc UserService, server *miniredis.Miniredis)
+		lookup      string
+		wantRole    string
+		wantMissing bool
+		wantQueries int
+	}{
+		{name: "hit", lookup: number, wantRole: "admin", wantQueries: 1},
+		{name: "hit in another format", lookup: "0812-3456-789", wantRole: "admin", wantQueries: 1},
+		{
+			name:        "expired",
+			between:     func(t *testing.T, svc UserService, server *miniredis.Miniredis) { server.FastForward(userCacheTTL) },
+			lookup:      number,
+			wantRole:    "admin",
+			wantQueries: 2,
+		},
+		{
+			name: "role change",
+			between: func(t *testing.T, svc UserService, server *miniredis.Miniredis) {
+				if err := svc.UpdateUser(&models.User{ID: 1, Username: "john", WhatsAppNumber: number, Role: "SuperAdmin"}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			lookup:      number,
+			wantRole:    "super_admin",
+			wantQueries: 2,
+		},
+		{
+			name: "number change",
+			between: func(t *testing.T, svc UserService, server *miniredis.Miniredis) {
+				if err := svc.UpdateUser(&models.User{ID: 1, Username: "john", WhatsAppNumber: "0898765432", Role: "admin"}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			lookup:      number,
+			wantMissing: true,
+			wantQueries: 2,
+		},
+		{
+			name: "deleted",
+			between: func(t *testing.T, svc UserService, server *miniredis.Miniredis) {
+				if err := svc.DeleteUser(1); err != nil {
+					t.Fatal(err)
+				}
+			},
+			lookup:      number,
+			wantMissing: true,
+			wantQueries: 2,
+		},
+		{
+			// The database stays usable when Redis is not
+			name:        "redis down",
+			between:     func(t *testing.T, svc UserService, server *miniredis.Miniredis) { server.Close() },
+			lookup:      number,
+			wantRole:    "admin",
+			wantQueries: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			repo := &fakeUserRepo{users: []*models.User{{ID: 1, Username: "john", WhatsAppNumber: number, Role: "admin"}}}
+			svc := NewUserService(repo, client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			// A miss fills the cache
+			if _, err := svc.GetUserByWhatsAppNumber(number); err != nil {
+				t.Fatal(err)
+			}
+			if ttl := server.TTL(userPhoneCacheKey(number)); ttl != userCacheTTL {
+				t.Errorf("cache TTL = %s, want %s", ttl, userCacheTTL)
+			}
+
+			if tt.between != nil {
+				tt.between(t, svc, server)
+			}
+
+			user, err := svc.GetUserByWhatsAppNumber(tt.lookup)
+			if tt.wantMissing {
+				if err == nil {
+					t.Errorf("found %+v, want no user", user)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if user.ID != 1 || user.Role != tt.wantRole {
+				t.Errorf("user = %+v, want user 1 with role %s", user, tt.wantRole)
+			}
+			if repo.lookups != tt.wantQueries {
+				t.Errorf("database queried %d times, want %d", repo.lookups, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestUserServiceWhatsAppCacheSkipsUnknownNumbers(t *testing.T) {
+	client, server := newTestRedis(t)
+	svc := NewUserService(&fakeUserRepo{}, client, nil)
+
+	if _, err := svc.GetUserByWhatsAppNumber("628000000000"); err == nil {
+		t.Fatal("found a user for an unknown number")
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("cached %v for an unknown number", keys)
+	}
+}
+
+func TestUserServiceResetPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		wantErr bool
+	}{
+		{name: "existing user", userID: 1},
+		{name: "unknown user", userID: 9, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepo{}
+			svc := NewUserService(repo, nil, nil)
+			user := &models.User{Username: "john", Role: "user"}
+			if err := svc.CreateUser(user, "default123"); err != nil {
+				t.Fatal(err)
+			}
+			oldHash := user.PasswordHash
+
+			password, err := svc.ResetPassword(tt.userID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("reset a password for an unknown user")
+				}
+				if repo.users[0].PasswordHash != oldHash {
+					t.Error("existing hash changed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(password) != generatedPasswordLength || strings.Trim(password, passwordAlphabet) != "" {
+				t.Errorf("password %q is not %d characters from the alphabet", password, generatedPasswordLength)
+			}
+			stored := repo.users[0].PasswordHash
+			if stored == password || stored == oldHash {
+				t.Fatalf("stored hash %q was not replaced by a hash of the new password", stored)
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)); err != nil {
+				t.Errorf("stored hash does not verify the returned password: %v", err)
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(stored), []byte("default123")); err == nil {
+				t.Error("the old password still verifies")
+			}
+
+			again, err := svc.ResetPassword(tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again == password {
+				t.Errorf("two resets produced the same password %q", again)
+			}
+		})
+	}
+}
+
+func TestUserServiceStoresCanonicalRoles(t *testing.T) {
+	tests := []struct {
+		role    string
+		want    string
+		wantErr bool
+	}{
+		{role: "SuperAdmin", want: "super_admin"},
+		{role: "Admin", want: "admin"},
+		{role: "User", want: "user"},
+		{role: "", want: "user"},
+		{role: "Manager", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			repo := &fakeUserRepo{}
+			svc := NewUserService(repo, nil, nil)
+
+			err := svc.CreateUser(&models.User{Username: "ega", Role: tt.role}, "secret123")
+			if tt.wantErr {
+				if err == nil || len(repo.users) != 0 {
+					t.Errorf("created %+v with role %q", repo.users, tt.role)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := repo.users[0].Role; got != tt.want {
+				t.Errorf("created with role %q, want %q", got, tt.want)
+			}
+
+			// Role changes are stored canonically too
+			updated := *repo.users[0]
+			updated.Role = "SuperAdmin"
+			if err := svc.UpdateUser(&updated); err != nil {
+				t.Fatal(err)
+			}
+			if got := repo.users[0].Role; got != "super_admin" {
+				t.Errorf("updated to role %q, want super_admin", got)
+			}
+		})
+	}
+}
@@ -1,31 +1,128 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"task_manager/internal/currency"
 	"task_manager/internal/redis"
 	"task_manager/pkg/whatsapp"
 	"time"
+	"unicode/utf8"
 )
 
 type WhatsAppService interface {
 	SendMessage(phone, message string) error
+	SendLongMessage(phone, message string) error
+	MessageLimit() int
 	SendForwardedMessage(phone, message string, duration int) error
+	SendDocument(phone, filename string, data []byte, caption string) error
 	StartInteractiveSession(userID uint, phoneNumber, command string) (string, error)
+	AdvanceSession(phone, input string) (*redis.SessionData, error)
 	UpdateSession(sessionID string, data *redis.SessionData) error
 	GetSession(sessionID string) (*redis.SessionData, error)
 	EndSession(sessionID string) error
 	SetTempData(key string, value interface{}, ttl time.Duration) error
 	GetTempData(key string, dest interface{}) error
 	DeleteTempData(key string) error
+	WithClient(client *whatsapp.Client) WhatsAppService
 }
 
+// DefaultMessageLimit keeps messages safely under WhatsApp's ~4096 character limit
+const DefaultMessageLimit = 4000
+
 type whatsappService struct {
-	client *whatsapp.Client
-	redis  *redis.Client
+	client       *whatsapp.Client
+	redis        *redis.Client
+	messageLimit int
+}
+
+func NewWhatsAppService(client *whatsapp.Client, redis *redis.Client, messageLimit int) WhatsAppService {
+	if messageLimit <= 0 {
+		messageLimit = DefaultMessageLimit
+	}
+	return &whatsappService{client: client, redis: redis, messageLimit: messageLimit}
+}
+
+// WithClient returns a service sending through client, e.g. a tenant's own
+// gateway, while sharing sessions and temp data with s
+func (s *whatsappService) WithClient(client *whatsapp.Client) WhatsAppService {
+	return &whatsappService{client: client, redis: s.redis, messageLimit: s.messageLimit}
+}
+
+func (s *whatsappService) MessageLimit() int {
+	return s.messageLimit
+}
+
+// SendLongMessage sends message as several sequential messages when it
+// exceeds the configured limit
+func (s *whatsappService) SendLongMessage(phone, message string) error {
+	for _, chunk := range SplitMessage(message, s.messageLimit) {
+		if err := s.SendMessage(phone, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func NewWhatsAppService(client *whatsapp.Client, redis *redis.Client) WhatsAppService {
-	return &whatsappService{client: client, redis: redis}
+// SplitMessage breaks message into chunks of at most limit characters. It
+// splits on line boundaries, falling back to word boundaries for overlong
+// lines; only a single word longer than limit is ever cut
+func SplitMessage(message string, limit int) []string {
+	if limit <= 0 || utf8.RuneCountInString(message) <= limit {
+		return []string{message}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if currentLen > 0 {
+			chunks = append(chunks, strings.TrimRight(current.String(), "\n"))
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	// add appends a piece that is known to fit within limit
+	add := func(piece string, sep string) {
+		pieceLen := utf8.RuneCountInString(piece)
+		sepLen := utf8.RuneCountInString(sep)
+		if currentLen > 0 && currentLen+sepLen+pieceLen > limit {
+			flush()
+		}
+		if currentLen > 0 {
+			current.WriteString(sep)
+			currentLen += sepLen
+		}
+		current.WriteString(piece)
+		currentLen += pieceLen
+	}
+
+	for _, line := range strings.Split(message, "\n") {
+		if utf8.RuneCountInString(line) <= limit {
+			add(line, "\n")
+			continue
+		}
+
+		// Overlong line: split on words
+		flush()
+		for _, word := range strings.Fields(line) {
+			for utf8.RuneCountInString(word) > limit {
+				runes := []rune(word)
+				add(string(runes[:limit]), " ")
+				word = string(runes[limit:])
+			}
+			add(word, " ")
+		}
+		flush()
+	}
+	flush()
+
+	return chunks
 }
 
 func (s *whatsappService) SendMessage(phone, message string) error {
@@ -36,7 +133,179 @@ func (s *whatsappService) SendForwardedMessage(phone, message string, duration i
 	return s.client.SendForwardedMessage(phone, message, duration)
 }
 
+func (s *whatsappService) SendDocument(phone, filename string, data []byte, caption string) error {
+	return s.client.SendFile(phone, filename, data, caption)
+}
+
+// InteractiveCommands are the flows that can run as an interactive session
+var InteractiveCommands = []string{"create_order"}
+
+// ErrUnknownSessionCommand is returned when a session is started for a flow
+// that does not exist
+var ErrUnknownSessionCommand = errors.New("unknown interactive session command")
+
+// normalizeSessionCommand accepts "create_order", "/create_order" or any
+// casing and returns the canonical flow name
+func normalizeSessionCommand(command string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(command), "/"))
+	for _, known := range InteractiveCommands {
+		if normalized == known {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q, supported: %s", ErrUnknownSessionCommand, command, strings.Join(InteractiveCommands, ", "))
+}
+
+// Steps of the create_order wizard. A session at OrderWizardDone has all the
+// data needed to create the order and has already been ended
+const (
+	OrderWizardCustomer = 1
+	OrderWizardTotal    = 2
+	OrderWizardItems    = 3
+	OrderWizardDone     = 4
+)
+
+var (
+	// ErrNoActiveSession is returned by AdvanceSession when the phone is not
+	// in the middle of a flow
+	ErrNoActiveSession = errors.New("no active session")
+	// ErrSessionAbandoned is returned when the user cancels a flow
+	ErrSessionAbandoned = errors.New("session abandoned")
+	// ErrInvalidSessionInput is returned when a reply does not fit the current
+	// step; the session stays on that step
+	ErrInvalidSessionInput = errors.New("invalid input")
+)
+
+var abandonReplies = map[string]bool{"cancel": true, "/cancel": true, "batal": true, "stop": true}
+
+// OrderWizardItem is one item collected by the create_order wizard
+type OrderWizardItem struct {
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// activeSessionKey points a phone at the session it is currently in
+func activeSessionKey(phone string) string {
+	return "active_session:" + whatsapp.NormalizePhone(phone)
+}
+
+// SessionPrompt is the question asked at step of an interactive flow
+func SessionPrompt(command string, step int) string {
+	if command != "create_order" {
+		return ""
+	}
+	switch step {
+	case OrderWizardCustomer:
+		return "🧾 **New Order (1/3)**\nWhat is the customer name?\n\nSend 'cancel' at any time to stop."
+	case OrderWizardTotal:
+		return "💰 **New Order (2/3)**\nWhat is the order total?"
+	case OrderWizardItems:
+		return "🛒 **New Order (3/3)**\nList the items as 'name quantity price', one per line or separated by ';'.\nSend 'skip' for no items."
+	}
+	return ""
+}
+
+// CollectedOrderItems returns the items gathered by a create_order session
+func CollectedOrderItems(session *redis.SessionData) []OrderWizardItem {
+	raw, ok := session.Data["items"]
+	if !ok {
+		return nil
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var items []OrderWizardItem
+	if err := json.Unmarshal(jsonData, &items); err != nil {
+		return nil
+	}
+	return items
+}
+
+// advanceOrderWizard stores input as the answer to the session's current
+// step and moves it to the next one
+func advanceOrderWizard(session *redis.SessionData, input string) error {
+	switch session.Step {
+	case OrderWizardCustomer:
+		if input == "" {
+			return fmt.Errorf("%w: customer name cannot be empty", ErrInvalidSessionInput)
+		}
+		session.Data["customer_name"] = input
+	case OrderWizardTotal:
+		total, err := currency.Parse(input)
+		if err != nil || total <= 0 {
+			return fmt.Errorf("%w: total must be a positive amount, e.g. 150000 or Rp 150.000", ErrInvalidSessionInput)
+		}
+		session.Data["total_amount"] = total
+	case OrderWizardItems:
+		items, err := parseWizardItems(input)
+		if err != nil {
+			return err
+		}
+		session.Data["items"] = items
+	default:
+		return fmt.Errorf("%w: session is at unknown step %d", ErrInvalidSessionInput, session.Step)
+	}
+	session.Step++
+	return nil
+}
+
+// parseWizardItems reads "name quantity price" entries separated by new
+// lines or ';'; every price must be positive. "skip" or "-" means no items
+func parseWizardItems(input string) ([]OrderWizardItem, error) {
+	switch strings.ToLower(input) {
+	case "skip", "-", "none", "tidak ada":
+		return []OrderWizardItem{}, nil
+	}
+
+	var items []OrderWizardItem
+	for _, entry := range strings.FieldsFunc(input, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%w: '%s' should be 'name quantity price'", ErrInvalidSessionInput, strings.TrimSpace(entry))
+		}
+
+		quantity, err := strconv.Atoi(fields[len(fields)-2])
+		if err != nil || quantity <= 0 {
+			return nil, fmt.Errorf("%w: invalid quantity in '%s'", ErrInvalidSessionInput, strings.TrimSpace(entry))
+		}
+		price, err := currency.Parse(fields[len(fields)-1])
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("%w: invalid price in '%s'", ErrInvalidSessionInput, strings.TrimSpace(entry))
+		}
+
+		items = append(items, OrderWizardItem{
+			Name:     strings.Join(fields[:len(fields)-2], " "),
+			Quantity: quantity,
+			Price:    price,
+		})
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: no items given, send 'skip' for none", ErrInvalidSessionInput)
+	}
+	return items, nil
+}
+
 func (s *whatsappService) StartInteractiveSession(userID uint, phoneNumber, command string) (string, error) {
+	command, err := normalizeSessionCommand(command)
+	if err != nil {
+		return "", err
+	}
+	phoneNumber = whatsapp.NormalizePhone(phoneNumber)
+
+	// A phone can only be in one flow at a time
+	var previousID string
+	if err := s.redis.GetTempData(activeSessionKey(phoneNumber), &previousID); err == nil {
+		if err := s.EndSession(previousID); err != nil {
+			return "", err
+		}
+	}
+
 	// Generate session ID
 	sessionID := fmt.Sprintf("session_%d_%d", userID, time.Now().Unix())
 	
@@ -53,14 +322,60 @@ func (s *whatsappService) StartInteractiveSession(userID uint, phoneNumber, comm
 	
 	// Store session in Redis
 	ttl := time.Duration(3600) * time.Second // 1 hour
-	err := s.redis.SetSession(sessionID, sessionData, ttl)
+	err = s.redis.SetSession(sessionID, sessionData, ttl)
 	if err != nil {
 		return "", err
 	}
+	if err := s.redis.SetTempData(activeSessionKey(phoneNumber), sessionID, ttl); err != nil {
+		return "", err
+	}
 	
 	return sessionID, nil
 }
 
+// AdvanceSession feeds a message from phone into its active session. The
+// session is ended when the user cancels or the last step is answered
+func (s *whatsappService) AdvanceSession(phone, input string) (*redis.SessionData, error) {
+	key := activeSessionKey(phone)
+	var sessionID string
+	if err := s.redis.GetTempData(key, &sessionID); err != nil {
+		return nil, ErrNoActiveSession
+	}
+
+	session, err := s.redis.GetSession(sessionID)
+	if err != nil {
+		// The session expired or was removed behind the pointer's back
+		s.redis.DeleteTempData(key)
+		return nil, ErrNoActiveSession
+	}
+	if session.Data == nil {
+		session.Data = make(map[string]interface{})
+	}
+
+	input = strings.TrimSpace(input)
+	if abandonReplies[strings.ToLower(input)] {
+		if err := s.EndSession(sessionID); err != nil {
+			return nil, err
+		}
+		return session, ErrSessionAbandoned
+	}
+
+	if err := advanceOrderWizard(session, input); err != nil {
+		return session, err
+	}
+	session.UpdatedAt = time.Now()
+
+	if session.Step >= OrderWizardDone {
+		return session, s.EndSession(sessionID)
+	}
+
+	if err := s.UpdateSession(sessionID, session); err != nil {
+		return nil, err
+	}
+	ttl := time.Duration(3600) * time.Second // 1 hour
+	return session, s.redis.SetTempData(key, sessionID, ttl)
+}
+
 func (s *whatsappService) UpdateSession(sessionID string, data *redis.SessionData) error {
 	ttl := time.Duration(3600) * time.Second // 1 hour
 	return s.redis.UpdateSession(sessionID, data, ttl)
@@ -71,6 +386,15 @@ func (s *whatsappService) GetSession(sessionID string) (*redis.SessionData, erro
 }
 
 func (s *whatsappService) EndSession(sessionID string) error {
+	if session, err := s.redis.GetSession(sessionID); err == nil {
+		key := activeSessionKey(session.PhoneNumber)
+		var activeID string
+		if err := s.redis.GetTempData(key, &activeID); err == nil && activeID == sessionID {
+			if err := s.redis.DeleteTempData(key); err != nil {
+				return err
+			}
+		}
+	}
 	return s.redis.DeleteSession(sessionID)
 }
 
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"task_manager/internal/redis"
+	"task_manager/pkg/whatsapp"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAdvanceOrderWizard(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    int
+		input   string
+		wantErr bool
+		key     string
+		want    interface{}
+	}{
+		{name: "customer", step: OrderWizardCustomer, input: "Siti", key: "customer_name", want: "Siti"},
+		{name: "empty customer", step: OrderWizardCustomer, input: "", wantErr: true},
+		{name: "plain total", step: OrderWizardTotal, input: "100000", key: "total_amount", want: 100000.0},
+		{name: "dotted total", step: OrderWizardTotal, input: "100.000", key: "total_amount", want: 100000.0},
+		{name: "comma total", step: OrderWizardTotal, input: "100,000", key: "total_amount", want: 100000.0},
+		{name: "rupiah total", step: OrderWizardTotal, input: "Rp 100000", key: "total_amount", want: 100000.0},
+		{name: "zero total", step: OrderWizardTotal, input: "0", wantErr: true},
+		{name: "text total", step: OrderWizardTotal, input: "seratus ribu", wantErr: true},
+		{name: "skip items", step: OrderWizardItems, input: "skip", key: "items", want: 0},
+		{name: "items", step: OrderWizardItems, input: "Kue Lapis 2 50.000; Bolu 1 75000", key: "items", want: 2},
+		{name: "free item", step: OrderWizardItems, input: "Kue Lapis 2 0", wantErr: true},
+		{name: "bad quantity", step: OrderWizardItems, input: "Kue Lapis dua 50000", wantErr: true},
+		{name: "missing price", step: OrderWizardItems, input: "Kue 2", wantErr: true},
+		{name: "unknown step", step: 9, input: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &redis.SessionData{Command: "create_order", Step: tt.step, Data: map[string]interface{}{}}
+			err := advanceOrderWizard(session, tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSessionInput) {
+					t.Fatalf("err = %v, want ErrInvalidSessionInput", err)
+				}
+				if session.Step != tt.step {
+					t.Errorf("step moved to %d on invalid input", session.Step)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.Step != tt.step+1 {
+				t.Errorf("step = %d, want %d", session.Step, tt.step+1)
+			}
+			got := session.Data[tt.key]
+			if items, ok := got.([]OrderWizardItem); ok {
+				got = len(items)
+			}
+			if got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderWizardSession(t *testing.T) {
+	client, _ := newTestRedis(t)
+	svc := NewWhatsAppService(nil, client, 0)
+	const phone = "628123456789"
+
+	if _, err := svc.StartInteractiveSession(1, phone, "create_order"); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		input    string
+		wantErr  error
+		wantStep int
+	}{
+		{input: "Siti", wantStep: OrderWizardTotal},
+		{input: "banyak", wantErr: ErrInvalidSessionInput, wantStep: OrderWizardTotal},
+		{input: "Rp 150.000", wantStep: OrderWizardItems},
+		{input: "Kue 1 0", wantErr: ErrInvalidSessionInput, wantStep: OrderWizardItems},
+		{input: "Kue Lapis 3 50.000", wantStep: OrderWizardDone},
+	}
+
+	var session *redis.SessionData
+	for _, step := range steps {
+		var err error
+		session, err = svc.AdvanceSession(phone, step.input)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("AdvanceSession(%q) err = %v, want %v", step.input, err, step.wantErr)
+		}
+		if session.Step != step.wantStep {
+			t.Fatalf("after %q step = %d, want %d", step.input, session.Step, step.wantStep)
+		}
+	}
+
+	if session.Data["customer_name"] != "Siti" || session.Data["total_amount"] != 150000.0 {
+		t.Errorf("collected %v", session.Data)
+	}
+	items := CollectedOrderItems(session)
+	if len(items) != 1 || items[0] != (OrderWizardItem{Name: "Kue Lapis", Quantity: 3, Price: 50000}) {
+		t.Errorf("items = %+v", items)
+	}
+
+	// The finished wizard is closed, so the next message is not part of it
+	if _, err := svc.AdvanceSession(phone, "hello"); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("err after the last step = %v, want ErrNoActiveSession", err)
+	}
+}
+
+func TestOrderWizardCancel(t *testing.T) {
+	client, _ := newTestRedis(t)
+	svc := NewWhatsAppService(nil, client, 0)
+	const phone = "628123456789"
+
+	if _, err := svc.StartInteractiveSession(1, phone, "create_order"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AdvanceSession(phone, "batal"); !errors.Is(err, ErrSessionAbandoned) {
+		t.Fatalf("err = %v, want ErrSessionAbandoned", err)
+	}
+	if _, err := svc.AdvanceSession(phone, "Siti"); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("err after cancel = %v, want ErrNoActiveSession", err)
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	// 100 lines of 99 characters: 9,999 characters in all, 40 lines to a
+	// 4,096-character chunk
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("%03d ", i)+strings.Repeat("kata ", 18)+"akhir")
+	}
+	manyLines := strings.Join(lines, "\n")
+	oneLongLine := strings.TrimSpace(strings.Repeat("selamat pagi ", 800))
+
+	tests := []struct {
+		name       string
+		message    string
+		limit      int
+		wantChunks int
+		cutsWords  bool
+	}{
+		{name: "short message", message: "hello", limit: DefaultMessageLimit, wantChunks: 1},
+		{name: "exactly the limit", message: strings.Repeat("a", 10), limit: 10, wantChunks: 1},
+		{name: "10k characters on lines", message: manyLines, limit: DefaultMessageLimit, wantChunks: 3},
+		{name: "10k characters on one line", message: oneLongLine, limit: DefaultMessageLimit, wantChunks: 3},
+		{name: "word longer than the limit", message: strings.Repeat("x", 25), limit: 10, wantChunks: 3, cutsWords: true},
+		{name: "no limit", message: manyLines, limit: 0, wantChunks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitMessage(tt.message, tt.limit)
+			if len(chunks) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			for i, chunk := range chunks {
+				if n := utf8.RuneCountInString(chunk); tt.limit > 0 && n > tt.limit {
+					t.Errorf("chunk %d has %d characters, limit %d", i, n, tt.limit)
+				}
+			}
+			// Nothing is lost and no word is cut unless it alone is too long
+			got, want := strings.Fields(strings.Join(chunks, " ")), strings.Fields(tt.message)
+			if !tt.cutsWords && strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("rejoined chunks differ from the message")
+			}
+		})
+	}
+
+	t.Run("lines stay whole", func(t *testing.T) {
+		for i, chunk := range SplitMessage(manyLines, DefaultMessageLimit) {
+			for _, line := range strings.Split(chunk, "\n") {
+				if utf8.RuneCountInString(line) != 99 {
+					t.Errorf("chunk %d has a broken line %q", i, line)
+				}
+			}
+		}
+	})
+}
+
+func TestSendLongMessage(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req whatsapp.SendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		received = append(received, req.Message)
+		mu.Unlock()
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer gateway.Close()
+
+	svc := NewWhatsAppService(whatsapp.NewClient(gateway.URL, "user", "pass", "api"), nil, 100)
+	message := strings.TrimSpace(strings.Repeat("baris pesan yang cukup panjang\n", 10))
+	if err := svc.SendLongMessage("628123456789", message); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 4 {
+		t.Fatalf("gateway got %d messages, want 4", len(received))
+	}
+	if got := strings.Join(received, "\n"); got != message {
+		t.Errorf("chunks sent out of order or altered:\n%s", got)
+	}
+}
+
+func TestSendMessagePropagatesGatewayErrors(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"success":false,"message":"invalid credentials"}`))
+	}))
+	defer gateway.Close()
+
+	svc := NewWhatsAppService(whatsapp.NewClient(gateway.URL, "user", "wrong", "api"), nil, 0)
+	err := svc.SendMessage("628123456789", "hello")
+	if err == nil || !strings.Contains(err.Error(), "invalid credentials") {
+		t.Errorf("err = %v, want the gateway's message", err)
+	}
+}
+
+func TestStartInteractiveSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		phone   string
+		wantErr bool
+	}{
+		{name: "canonical", command: "create_order", phone: "628123456789"},
+		{name: "slash and case", command: " /Create_Order ", phone: "08123456789"},
+		{name: "webhook jid", command: "create_order", phone: "628123456789@s.whatsapp.net"},
+		{name: "unknown flow", command: "delete_everything", phone: "628123456789", wantErr: true},
+		{name: "empty", command: "", phone: "628123456789", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			svc := NewWhatsAppService(nil, client, 0)
+
+			sessionID, err := svc.StartInteractiveSession(1, tt.phone, tt.command)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownSessionCommand) {
+					t.Fatalf("err = %v, want ErrUnknownSessionCommand", err)
+				}
+				if keys := server.Keys(); len(keys) != 0 {
+					t.Errorf("stored %q for an invalid command", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			session, err := client.GetSession(sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.Command != "create_order" || session.PhoneNumber != "628123456789" {
+				t.Errorf("session command=%q phone=%q, want create_order 628123456789", session.Command, session.PhoneNumber)
+			}
+			if _, err := svc.AdvanceSession("+62 812 3456 789", "Siti"); err != nil {
+				t.Errorf("AdvanceSession from another spelling of the number: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"bytes"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// A4 margins in points
+const (
+	marginLeft   = 50.0
+	marginTop    = 60.0
+	marginBottom = 50.0
+)
+
+// Font styles understood by the document
+const (
+	Regular = ""
+	Bold    = "B"
+)
+
+const fontFamily = "Helvetica"
+
+// Document lays out simple printable reports such as invoices on A4 pages
+// in the standard Helvetica fonts, starting new pages as needed
+type Document struct {
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+}
+
+// New creates an empty document with one blank page
+func New() *Document {
+	f := fpdf.New("P", "pt", "A4", "")
+	f.SetMargins(marginLeft, marginTop, marginLeft)
+	f.SetAutoPageBreak(true, marginBottom)
+	f.AddPage()
+
+	// The standard fonts only cover cp1252; other characters print as "."
+	return &Document{pdf: f, translate: f.UnicodeTranslatorFromDescriptor("")}
+}
+
+// Heading writes a bold line of text
+func (d *Document) Heading(text string) {
+	d.pdf.SetFont(fontFamily, Bold, 16)
+	d.pdf.CellFormat(0, 22, d.translate(text), "", 1, "L", false, 0, "")
+}
+
+// Text writes a regular line of text
+func (d *Document) Text(text string) {
+	d.pdf.SetFont(fontFamily, Regular, 10)
+	d.pdf.CellFormat(0, 15, d.translate(text), "", 1, "L", false, 0, "")
+}
+
+// Row writes columns on one line, each starting at the matching offset
+// from the left margin
+func (d *Document) Row(style string, columns []string, offsets []float64) {
+	d.pdf.SetFont(fontFamily, style, 10)
+
+	// Break the page before the row so its columns stay together
+	_, pageHeight := d.pdf.GetPageSize()
+	if d.pdf.GetY()+15 > pageHeight-marginBottom {
+		d.pdf.AddPage()
+	}
+
+	y := d.pdf.GetY()
+	for i, column := range columns {
+		if i >= len(offsets) {
+			break
+		}
+		d.pdf.SetXY(marginLeft+offsets[i], y)
+		d.pdf.CellFormat(0, 15, d.translate(column), "", 0, "L", false, 0, "")
+	}
+	d.pdf.SetXY(marginLeft, y+15)
+}
+
+// Space adds an empty line
+func (d *Document) Space() {
+	d.pdf.Ln(10)
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		rows      int
+		wantPages int
+		wantText  []string
+	}{
+		{name: "single page", rows: 3, wantPages: 1, wantText: []string{"(INVOICE)", "(Customer: Siti)", "(Item 2)", "(Rp 10.000)"}},
+		{name: "rows spill onto more pages", rows: 120, wantPages: 3, wantText: []string{"(Item 119)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New()
+			doc.pdf.SetCompression(false)
+			doc.Heading("INVOICE")
+			doc.Space()
+			doc.Text("Customer: Siti")
+			for i := 0; i < tt.rows; i++ {
+				doc.Row(Regular, []string{fmt.Sprintf("Item %d", i), "1", "Rp 10.000"}, []float64{0, 230, 290})
+			}
+
+			out, err := doc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(out, []byte("%PDF-")) {
+				t.Fatalf("output does not start with a PDF header: %q", out[:min(len(out), 16)])
+			}
+			if got := doc.pdf.PageCount(); got != tt.wantPages {
+				t.Errorf("pages = %d, want %d", got, tt.wantPages)
+			}
+			for _, text := range tt.wantText {
+				if !strings.Contains(string(out), text) {
+					t.Errorf("output is missing %s", text)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentTranslatesText(t *testing.T) {
+	doc := New()
+	doc.pdf.SetCompression(false)
+	doc.Text("Café (✓)")
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// é is in cp1252; the check mark is not. Parentheses are escaped
+	if want := "Caf\xe9 \\(.\\)"; !strings.Contains(string(out), want) {
+		t.Errorf("output is missing %q", want)
+	}
+}
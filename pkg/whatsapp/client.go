@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"strings"
 	"time"
@@ -17,8 +18,20 @@ type Client struct {
 	Password   string
 	Path       string
 	HTTPClient *http.Client
+
+	// MaxRetries is the number of attempts made for a message when the API
+	// returns a 5xx response or the connection fails
+	MaxRetries int
+	// RetryBackoff is the wait before the second attempt; it doubles on
+	// every further attempt
+	RetryBackoff time.Duration
 }
 
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 500 * time.Millisecond
+)
+
 type SendMessageRequest struct {
 	Phone        string `json:"phone"`
 	Message      string `json:"message"`
@@ -52,21 +65,15 @@ func NewClient(baseURL, username, password, path string) *Client {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
 	}
 }
 
-// Convert phone number from 08xxx to 628xxx format
-func (c *Client) convertPhoneNumber(phone string) string {
-	if strings.HasPrefix(phone, "08") {
-		return "628" + phone[2:]
-	}
-	return phone
-}
-
 // Send message via WhatsApp
 func (c *Client) SendMessage(phone, message string, isForwarded bool, duration int) (*SendMessageResponse, error) {
 	// Convert phone number format
-	convertedPhone := c.convertPhoneNumber(phone)
+	convertedPhone := NormalizePhone(phone)
 	
 	// Prepare request data
 	requestData := SendMessageRequest{
@@ -85,41 +92,91 @@ func (c *Client) SendMessage(phone, message string, isForwarded bool, duration i
 	// Create request URL
 	url := fmt.Sprintf("%s/%s/send/message", c.BaseURL, c.Path)
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	// Send request, retrying transient failures
+	statusCode, body, err := c.postWithRetry(url, jsonData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	
-	// Create Basic Auth token
-	auth := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-
-	// Send request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	// Parse response
+	var response SendMessageResponse
+	parseErr := json.Unmarshal(body, &response)
+
+	if statusCode < 200 || statusCode >= 300 {
+		detail := strings.TrimSpace(string(body))
+		if parseErr == nil && response.Message != "" {
+			detail = response.Message
+		}
+		return nil, fmt.Errorf("send message failed with status %d: %s", statusCode, detail)
 	}
-	defer resp.Body.Close()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", parseErr)
 	}
 
-	// Parse response
-	var response SendMessageResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if !response.Success {
+		return &response, fmt.Errorf("send message rejected by whatsapp api: %s", response.Message)
 	}
 
 	return &response, nil
 }
 
+// postWithRetry posts a JSON payload and returns the status code and response
+// body. Connection errors and 5xx responses are retried with exponential
+// backoff; 4xx responses are returned as-is since repeating them cannot succeed
+func (c *Client) postWithRetry(url string, payload []byte) (int, []byte, error) {
+	attempts := c.MaxRetries
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := c.RetryBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		// Create HTTP request
+		req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		req.Header.Set("Content-Type", "application/json")
+
+		// Create Basic Auth token
+		auth := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+		req.Header.Set("Authorization", "Basic "+auth)
+
+		// Send request; HTTPClient.Timeout applies to each attempt
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request: %w", err)
+			continue
+		}
+
+		// Read response
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read response: %w", err)
+			continue
+		}
+
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("whatsapp api returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+			continue
+		}
+
+		return resp.StatusCode, body, nil
+	}
+
+	return 0, nil, fmt.Errorf("giving up after %d attempts: %w", attempts, lastErr)
+}
+
 // Send simple text message
 func (c *Client) SendTextMessage(phone, message string) error {
 	_, err := c.SendMessage(phone, message, false, 0)
@@ -131,3 +188,54 @@ func (c *Client) SendForwardedMessage(phone, message string, duration int) error
 	_, err := c.SendMessage(phone, message, true, duration)
 	return err
 }
+
+// Send a file (e.g. a PDF document) with an optional caption
+func (c *Client) SendFile(phone, filename string, data []byte, caption string) error {
+	// Convert phone number format
+	convertedPhone := NormalizePhone(phone)
+
+	// Build multipart form
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("phone", convertedPhone+"@s.whatsapp.net"); err != nil {
+		return fmt.Errorf("failed to write form field: %w", err)
+	}
+	if err := writer.WriteField("caption", caption); err != nil {
+		return fmt.Errorf("failed to write form field: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("failed to write file data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close form: %w", err)
+	}
+
+	// Create request URL
+	url := fmt.Sprintf("%s/%s/send/file", c.BaseURL, c.Path)
+
+	req, err := http.NewRequest("POST", url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	auth := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+	req.Header.Set("Authorization", "Basic "+auth)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("send file failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package whatsapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for server that retries without waiting
+func newTestClient(server *httptest.Server, maxRetries int) *Client {
+	client := NewClient(server.URL, "user", "pass", "api")
+	client.MaxRetries = maxRetries
+	client.RetryBackoff = time.Millisecond
+	return client
+}
+
+func TestSendMessageRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		failStatus   int
+		maxRetries   int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{name: "fails twice then succeeds", failures: 2, failStatus: http.StatusBadGateway, maxRetries: 3, wantAttempts: 3},
+		{name: "first attempt succeeds", maxRetries: 3, wantAttempts: 1},
+		{name: "gives up after max retries", failures: 5, failStatus: http.StatusServiceUnavailable, maxRetries: 3, wantAttempts: 3, wantErr: true},
+		{name: "4xx is not retried", failures: 5, failStatus: http.StatusBadRequest, maxRetries: 3, wantAttempts: 1, wantErr: true},
+		{name: "no retries configured", failures: 1, failStatus: http.StatusInternalServerError, maxRetries: 0, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n := atomic.AddInt32(&attempts, 1); int(n) <= tt.failures {
+					w.WriteHeader(tt.failStatus)
+					w.Write([]byte(`{"success":false,"message":"try later"}`))
+					return
+				}
+				w.Write([]byte(`{"success":true,"data":{"message_id":"m1","status":"sent"}}`))
+			}))
+			defer server.Close()
+
+			resp, err := newTestClient(server, tt.maxRetries).SendMessage("08123456789", "hello", false, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("gateway hit %d times, want %d", got, tt.wantAttempts)
+			}
+			if !tt.wantErr && resp.Data.MessageID != "m1" {
+				t.Errorf("MessageID = %q, want m1", resp.Data.MessageID)
+			}
+		})
+	}
+}
+
+func TestSendMessageRetriesConnectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	client := newTestClient(server, 2)
+	server.Close()
+
+	_, err := client.SendMessage("08123456789", "hello", false, 0)
+	if err == nil || !strings.Contains(err.Error(), "giving up after 2 attempts") {
+		t.Errorf("err = %v, want it to give up after 2 attempts", err)
+	}
+}
+
+func TestNewClientRetryDefaults(t *testing.T) {
+	client := NewClient("http://gateway", "user", "pass", "api")
+	if client.MaxRetries != DefaultMaxRetries || client.RetryBackoff != DefaultRetryBackoff {
+		t.Errorf("retries = %d every %v, want %d every %v", client.MaxRetries, client.RetryBackoff, DefaultMaxRetries, DefaultRetryBackoff)
+	}
+	if client.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("per-attempt timeout = %v, want 30s", client.HTTPClient.Timeout)
+	}
+}
+
+func TestSendMessageReportsFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "sent", status: http.StatusOK, body: `{"success":true,"message":"ok"}`},
+		{name: "unauthorized with API message", status: http.StatusUnauthorized, body: `{"success":false,"message":"invalid credentials"}`, wantErr: "send message failed with status 401: invalid credentials"},
+		{name: "not found with plain body", status: http.StatusNotFound, body: "no such route\n", wantErr: "send message failed with status 404: no such route"},
+		{name: "success false", status: http.StatusOK, body: `{"success":false,"message":"number not on WhatsApp"}`, wantErr: "send message rejected by whatsapp api: number not on WhatsApp"},
+		{name: "unparseable 200", status: http.StatusOK, body: "<html>", wantErr: "failed to parse response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := newTestClient(server, 1).SendTextMessage("08123456789", "hello")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
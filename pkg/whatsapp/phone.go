@@ -0,0 +1,45 @@
+package whatsapp
+
+import "strings"
+
+// NormalizePhone converts a phone number in any of the formats seen in
+// webhooks, commands and stored data (628123@s.whatsapp.net, +62 812-3,
+// 62812, 0812, 812) to the canonical 62xxxxxxxxxx form used for storage,
+// lookups and sending. Input that does not look like a phone number is
+// returned with only separators removed
+func NormalizePhone(raw string) string {
+	phone := strings.TrimSpace(raw)
+
+	// Strip JID suffixes such as "@s.whatsapp.net" and device parts like ":12"
+	if at := strings.Index(phone, "@"); at >= 0 {
+		phone = phone[:at]
+	}
+	if colon := strings.Index(phone, ":"); colon >= 0 {
+		phone = phone[:colon]
+	}
+
+	phone = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(phone)
+	phone = strings.TrimPrefix(phone, "+")
+
+	switch {
+	case strings.HasPrefix(phone, "62"):
+		return phone
+	case strings.HasPrefix(phone, "0"):
+		return "62" + phone[1:]
+	case strings.HasPrefix(phone, "8"):
+		return "62" + phone
+	}
+	return phone
+}
+
+// PhoneVariants returns the spellings under which the number may have been
+// stored before normalization: canonical 62..., +62..., local 0... and bare
+func PhoneVariants(raw string) []string {
+	canonical := NormalizePhone(raw)
+	if !strings.HasPrefix(canonical, "62") {
+		return []string{canonical}
+	}
+
+	local := canonical[2:]
+	return []string{canonical, "+" + canonical, "0" + local, local}
+}
@@ -0,0 +1,53 @@
+package whatsapp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "canonical", raw: "628123456789", want: "628123456789"},
+		{name: "international plus", raw: "+628123456789", want: "628123456789"},
+		{name: "local 08", raw: "08123456789", want: "628123456789"},
+		{name: "bare 8", raw: "8123456789", want: "628123456789"},
+		{name: "jid suffix", raw: "628123456789@s.whatsapp.net", want: "628123456789"},
+		{name: "jid with device", raw: "628123456789:12@s.whatsapp.net", want: "628123456789"},
+		{name: "formatted", raw: " +62 (812) 345-6789 ", want: "628123456789"},
+		{name: "dotted local", raw: "0812.3456.789", want: "628123456789"},
+		{name: "other country is kept", raw: "+6591234567", want: "6591234567"},
+		{name: "empty", raw: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizePhone(tt.raw); got != tt.want {
+				t.Errorf("NormalizePhone(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhoneVariants(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "indonesian", raw: "08123456789", want: []string{"628123456789", "+628123456789", "08123456789", "8123456789"}},
+		{name: "from a jid", raw: "628123456789@s.whatsapp.net", want: []string{"628123456789", "+628123456789", "08123456789", "8123456789"}},
+		{name: "other country", raw: "+6591234567", want: []string{"6591234567"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PhoneVariants(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PhoneVariants(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -61,7 +61,7 @@ func main() {
 	// Create default super admin user
 	fmt.Println("Creating default super admin user...")
 	userRepo := repository.NewUserRepository(db)
-	userService := services.NewUserService(userRepo)
+	userService := services.NewUserService(userRepo, nil, nil)
 
 	// Check if super admin already exists
 	existingUser, err := userService.GetUserByUsername("admin")